@@ -0,0 +1,539 @@
+// Package endortest fakes the Endor Labs API for unit tests of code built on
+// the api client. The fake answers API key authentication with a canned token
+// and lists findings, projects, repositories or any other resource page by
+// page, from payloads recorded from the API and shipped in fixtures/ or from
+// objects the test provides. Faults such as failing, empty, short, repeated
+// or malformed pages, stale page tokens and cursor cycles can be injected to
+// show how a client copes with a misbehaving API. It can be reached over a
+// local httptest server or in-process through an http.RoundTripper, and
+// records the requests it answers.
+package endortest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Defaults of a fake API without options, matching the fixtures
+const (
+	DefaultNamespace = "acme"
+	DefaultAPIKey    = "endortest-key"
+	DefaultAPISecret = "endortest-secret"
+	// Token is the token of the auth fixture
+	Token = "endortest-token"
+)
+
+// transportBaseURL is the base URL of clients that reach the fake in-process;
+// it is never resolved
+const transportBaseURL = "http://endortest.invalid/v1"
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixture returns the recorded payload fixtures/name, e.g. "findings.json".
+// It panics when there is no such fixture.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("endortest: %v", err))
+	}
+	return data
+}
+
+// Findings returns the recorded findings fixture as the client decodes it
+func Findings() []api.Finding {
+	var resp struct {
+		List struct {
+			Objects []struct {
+				api.Finding
+				TenantMeta struct {
+					Namespace string `json:"namespace"`
+				} `json:"tenant_meta"`
+			} `json:"objects"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(Fixture("findings.json"), &resp); err != nil {
+		panic(fmt.Sprintf("endortest: findings fixture: %v", err))
+	}
+	findings := make([]api.Finding, 0, len(resp.List.Objects))
+	for _, o := range resp.List.Objects {
+		f := o.Finding
+		f.Namespace = o.TenantMeta.Namespace
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// Request is a request the fake answered
+type Request struct {
+	Method string
+	// Path is the URL path without the base URL's, e.g. /namespaces/acme/findings
+	Path  string
+	Query url.Values
+	// Resource is the resource listed, e.g. "findings"; empty for auth
+	Resource string
+	Status   int
+	// Repeated is the number of objects of earlier pages served again, by a
+	// page of Faults.RepeatPages
+	Repeated int
+}
+
+// Option configures a fake API created by New or NewServer
+type Option func(*API)
+
+// WithNamespace sets the namespace clients authenticate in and list from.
+// Objects of its child namespaces are listed too unless traversal is off.
+func WithNamespace(namespace string) Option {
+	return func(a *API) { a.namespace = namespace }
+}
+
+// WithCredentials sets the API key and secret the fake accepts
+func WithCredentials(apiKey, apiSecret string) Option {
+	return func(a *API) { a.apiKey, a.apiSecret = apiKey, apiSecret }
+}
+
+// WithObjects replaces the objects listed for resource, e.g. "projects", with
+// objects encoded as JSON. An object's tenant_meta.namespace places it in a
+// namespace; objects without one are in the fake's namespace.
+func WithObjects(resource string, objects ...any) Option {
+	return func(a *API) {
+		raw := make([]map[string]any, 0, len(objects))
+		for _, o := range objects {
+			raw = append(raw, toObject(o))
+		}
+		a.objects[resource] = raw
+	}
+}
+
+// WithFindings replaces the findings listed, placing each in its Namespace
+func WithFindings(findings ...api.Finding) Option {
+	return func(a *API) {
+		raw := make([]map[string]any, 0, len(findings))
+		for _, f := range findings {
+			o := toObject(f)
+			if f.Namespace != "" {
+				o["tenant_meta"] = map[string]any{"namespace": f.Namespace}
+			}
+			delete(o, "namespace")
+			raw = append(raw, o)
+		}
+		a.objects["findings"] = raw
+	}
+}
+
+// WithPageSize pages listings by n objects instead of the page size requested
+func WithPageSize(n int) Option {
+	return func(a *API) { a.pageSize = n }
+}
+
+// WithStatus answers the first request for page of resource with status, as
+// a failing API would, before serving it normally. Pages start at 1; the
+// resource "auth" fails authentication, and page n of "policies" the n-th
+// request to create a policy.
+func WithStatus(resource string, page, status int) Option {
+	return func(a *API) { a.statuses[statusKey{resource, page}] = status }
+}
+
+// statusKey is a page of a resource
+type statusKey struct {
+	resource string
+	page     int
+}
+
+// API is the fake Endor Labs API, an http.Handler
+type API struct {
+	namespace string
+	apiKey    string
+	apiSecret string
+	pageSize  int
+	objects   map[string][]map[string]any
+	faults    map[string]Faults
+	denied    []string
+
+	mu        sync.Mutex
+	statuses  map[statusKey]int
+	rngs      map[string]*rand.Rand
+	malformed map[statusKey]bool
+	requests  []Request
+	// policies are the policies created, in order, and creates counts the
+	// requests to create one
+	policies []map[string]any
+	creates  int
+}
+
+// New returns a fake API serving the recorded fixtures, configured by opts
+func New(opts ...Option) *API {
+	a := &API{
+		namespace: DefaultNamespace,
+		apiKey:    DefaultAPIKey,
+		apiSecret: DefaultAPISecret,
+		objects:   make(map[string][]map[string]any),
+		faults:    make(map[string]Faults),
+		statuses:  make(map[statusKey]int),
+		rngs:      make(map[string]*rand.Rand),
+		malformed: make(map[statusKey]bool),
+	}
+	for _, name := range []string{"findings", "projects", "repositories", "repository-versions"} {
+		var resp struct {
+			List struct {
+				Objects []map[string]any `json:"objects"`
+			} `json:"list"`
+		}
+		if err := json.Unmarshal(Fixture(name+".json"), &resp); err != nil {
+			panic(fmt.Sprintf("endortest: %s fixture: %v", name, err))
+		}
+		a.objects[name] = resp.List.Objects
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Requests returns the requests answered so far, in order
+func (a *API) Requests() []Request {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.requests)
+}
+
+// Policies returns the policies created so far, as sent with their UUIDs added
+func (a *API) Policies() []map[string]any {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.policies)
+}
+
+// Transport returns a RoundTripper that hands requests to the fake directly,
+// without a listener
+func (a *API) Transport() http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, r)
+		resp := rec.Result()
+		resp.Request = r
+		return resp, nil
+	})
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// NewClient returns an api client that reaches the fake through Transport,
+// authenticated with its credentials and namespace and retrying failures
+// without delay. opts are applied after these and can override them.
+func (a *API) NewClient(opts ...api.Option) *api.Client {
+	return api.NewClient(append(a.clientOptions(transportBaseURL, &http.Client{Transport: a.Transport()}), opts...)...)
+}
+
+// clientOptions configures a client for the fake at baseURL
+func (a *API) clientOptions(baseURL string, hc *http.Client) []api.Option {
+	return []api.Option{
+		api.WithBaseURL(baseURL),
+		api.WithHTTPClient(hc),
+		api.WithCredentials(a.apiKey, a.apiSecret),
+		api.WithNamespace(a.namespace),
+		api.WithRetry(api.RetryPolicy{MaxRetries: api.DefaultMaxRetries, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	}
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if i := strings.Index(path, "/auth/"); i >= 0 {
+		path = path[i:]
+	} else if i := strings.Index(path, "/namespaces/"); i >= 0 {
+		path = path[i:]
+	}
+	req := Request{Method: r.Method, Path: path, Query: r.URL.Query()}
+	status := a.serve(w, r, &req)
+
+	a.mu.Lock()
+	req.Status = status
+	a.requests = append(a.requests, req)
+	a.mu.Unlock()
+}
+
+// serve answers r and returns the status it answered with
+func (a *API) serve(w http.ResponseWriter, r *http.Request, req *Request) int {
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(req.Path, "/auth/"):
+		return a.authenticate(w, r)
+	case strings.HasPrefix(req.Path, "/namespaces/"):
+		parts := strings.Split(strings.TrimPrefix(req.Path, "/namespaces/"), "/")
+		if len(parts) != 2 {
+			break
+		}
+		req.Resource = parts[1]
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			return writeError(w, http.StatusUnauthorized, 16, "invalid or missing token")
+		}
+		if parts[0] != a.namespace && !strings.HasPrefix(parts[0], a.namespace+".") || slices.Contains(a.denied, parts[0]) {
+			return writeError(w, http.StatusForbidden, 7, "permission denied for namespace "+parts[0])
+		}
+		switch {
+		case r.Method == http.MethodGet:
+			return a.list(w, parts[0], req)
+		case r.Method == http.MethodPost && parts[1] == "policies":
+			return a.createPolicy(w, r, parts[0])
+		}
+	}
+	return writeError(w, http.StatusNotFound, 5, "not found: "+req.Path)
+}
+
+// authenticate answers an API key authentication request with the token fixture
+func (a *API) authenticate(w http.ResponseWriter, r *http.Request) int {
+	if status := a.failure("auth", 1); status != 0 {
+		return writeError(w, status, 13, "simulated failure")
+	}
+	var creds struct {
+		Key    string `json:"key"`
+		Secret string `json:"secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		return writeError(w, http.StatusBadRequest, 3, "invalid auth request: "+err.Error())
+	}
+	if creds.Key != a.apiKey || creds.Secret != a.apiSecret {
+		return writeError(w, http.StatusUnauthorized, 16, "invalid API key or secret")
+	}
+	return writeRaw(w, http.StatusOK, Fixture("auth.json"))
+}
+
+// list answers a page of a resource listing, or its count with
+// list_parameters.count or its groups with list_parameters.group. Page IDs are the page numbers, and page tokens are
+// accepted too.
+func (a *API) list(w http.ResponseWriter, namespace string, req *Request) int {
+	resource, query := req.Resource, req.Query
+	faults := a.faults[resource]
+	page := 1
+	for _, param := range []string{"list_parameters.page_id", "list_parameters.page_token"} {
+		if v := query.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || param == "list_parameters.page_token" && contains(faults.StaleTokens, n-1) {
+				return writeError(w, http.StatusBadRequest, 3, fmt.Sprintf("invalid %s %q", param, v))
+			}
+			page = n
+			break
+		}
+	}
+	if status := a.failure(resource, page); status != 0 {
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		return writeError(w, status, 13, "simulated failure")
+	}
+
+	size := a.pageSize
+	if size < 1 {
+		size, _ = strconv.Atoi(query.Get("list_parameters.page_size"))
+	}
+	if size < 1 {
+		size = api.DefaultPageSize
+	}
+
+	traverse := query.Get("list_parameters.traverse") == "true"
+	filter := query.Get("list_parameters.filter")
+	var matched []map[string]any
+	for _, o := range a.objects[resource] {
+		ns, _ := lookup(o, "tenant_meta.namespace").(string)
+		if ns == "" {
+			ns = a.namespace
+		}
+		if ns != namespace && !(traverse && strings.HasPrefix(ns, namespace+".")) {
+			continue
+		}
+		if matchFilter(o, filter) {
+			matched = append(matched, o)
+		}
+	}
+
+	if query.Get("list_parameters.count") == "true" {
+		var body struct {
+			CountResponse struct {
+				Count int `json:"count"`
+			} `json:"count_response"`
+		}
+		body.CountResponse.Count = len(matched)
+		data, err := json.Marshal(body)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, 13, err.Error())
+		}
+		return writeRaw(w, http.StatusOK, data)
+	}
+
+	if paths := query.Get("list_parameters.group.aggregation_paths"); paths != "" {
+		return writeGroups(w, matched, strings.Split(paths, ","))
+	}
+
+	if status := a.injectFault(w, resource, page); status != 0 {
+		return status
+	}
+
+	start, end := a.pageRange(resource, page, size, len(matched))
+	var body struct {
+		List struct {
+			Objects  []map[string]any `json:"objects"`
+			Response struct {
+				NextPageID    string `json:"next_page_id,omitempty"`
+				NextPageToken int    `json:"next_page_token,omitempty"`
+			} `json:"response"`
+		} `json:"list"`
+	}
+	body.List.Objects = append([]map[string]any{}, matched[start:end]...)
+	if contains(faults.RepeatPages, page) && page > 1 && start < end {
+		prevStart, prevEnd := a.pageRange(resource, page-1, size, len(matched))
+		body.List.Objects = append(body.List.Objects, matched[prevStart:prevEnd]...)
+		req.Repeated = prevEnd - prevStart
+	}
+	if end < len(matched) || contains(faults.EmptyPages, page) && start < len(matched) {
+		body.List.Response.NextPageID = strconv.Itoa(page + 1)
+		body.List.Response.NextPageToken = page + 1
+		if contains(faults.StaleTokens, page) {
+			body.List.Response.NextPageToken = page
+		}
+	}
+	if faults.CyclePage > 0 && page == faults.CyclePage {
+		body.List.Response.NextPageID = "1"
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, 13, err.Error())
+	}
+	return writeRaw(w, http.StatusOK, data)
+}
+
+// createPolicy stores a created policy and answers with it
+func (a *API) createPolicy(w http.ResponseWriter, r *http.Request, namespace string) int {
+	a.mu.Lock()
+	a.creates++
+	attempt := a.creates
+	a.mu.Unlock()
+	if status := a.failure("policies", attempt); status != 0 {
+		return writeError(w, status, 13, "simulated failure")
+	}
+
+	var policy map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		return writeError(w, http.StatusBadRequest, 3, "invalid policy: "+err.Error())
+	}
+	a.mu.Lock()
+	policy["uuid"] = fmt.Sprintf("endortest-policy-%06d", len(a.policies)+1)
+	policy["tenant_meta"] = map[string]any{"namespace": namespace}
+	a.policies = append(a.policies, policy)
+	a.mu.Unlock()
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, 13, err.Error())
+	}
+	return writeRaw(w, http.StatusOK, data)
+}
+
+// writeGroups answers a grouped listing with the number of objects sharing
+// the values of paths
+func writeGroups(w http.ResponseWriter, objects []map[string]any, paths []string) int {
+	type key struct {
+		Key   string `json:"key"`
+		Value any    `json:"value"`
+	}
+	type group struct {
+		AggregationCount struct {
+			Count int `json:"count"`
+		} `json:"aggregation_count"`
+	}
+	groups := make(map[string]*group)
+	for _, o := range objects {
+		keys := make([]key, len(paths))
+		for i, path := range paths {
+			keys[i] = key{Key: path, Value: lookup(o, path)}
+		}
+		data, err := json.Marshal(keys)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, 13, err.Error())
+		}
+		g := groups[string(data)]
+		if g == nil {
+			g = &group{}
+			groups[string(data)] = g
+		}
+		g.AggregationCount.Count++
+	}
+	var body struct {
+		GroupResponse struct {
+			Groups map[string]*group `json:"groups"`
+		} `json:"group_response"`
+	}
+	body.GroupResponse.Groups = groups
+	data, err := json.Marshal(body)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, 13, err.Error())
+	}
+	return writeRaw(w, http.StatusOK, data)
+}
+
+// failure returns the status a page is to fail with once, or 0
+func (a *API) failure(resource string, page int) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := statusKey{resource, page}
+	status := a.statuses[key]
+	delete(a.statuses, key)
+	return status
+}
+
+// Server is a fake API listening on a local httptest server. Close it when
+// the test is done.
+type Server struct {
+	*httptest.Server
+	API *API
+}
+
+// NewServer starts a server for a fake API configured by opts
+func NewServer(opts ...Option) *Server {
+	a := New(opts...)
+	return &Server{Server: httptest.NewServer(a), API: a}
+}
+
+// NewClient returns an api client of the server, configured like
+// API.NewClient but sending its requests over the network
+func (s *Server) NewClient(opts ...api.Option) *api.Client {
+	return api.NewClient(append(s.API.clientOptions(s.URL+"/v1", s.Client()), opts...)...)
+}
+
+// toObject converts v to its JSON object form
+func toObject(v any) map[string]any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("endortest: %v", err))
+	}
+	var o map[string]any
+	if err := json.Unmarshal(data, &o); err != nil {
+		panic(fmt.Sprintf("endortest: object is not a JSON object: %v", err))
+	}
+	return o
+}
+
+func writeRaw(w http.ResponseWriter, status int, data []byte) int {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+	return status
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) int {
+	data, _ := json.Marshal(map[string]any{"code": code, "message": message})
+	return writeRaw(w, status, data)
+}
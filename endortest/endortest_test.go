@@ -0,0 +1,102 @@
+package endortest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/endor-labs/findings-api/endortest"
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+func TestServerListsFixtures(t *testing.T) {
+	srv := endortest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+	ctx := context.Background()
+
+	token, err := client.GetToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != endortest.Token {
+		t.Errorf("got token %q, want %q", token, endortest.Token)
+	}
+	findings, err := client.GetFindingsForAllProjects(ctx, token, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := uuids(findings), uuids(endortest.Findings()); !slices.Equal(got, want) {
+		t.Errorf("got findings %v, want %v", got, want)
+	}
+	for _, f := range findings {
+		if f.Namespace == "" {
+			t.Errorf("finding %s has no namespace", f.UUID)
+		}
+	}
+}
+
+func TestTransportRetriesFailedPage(t *testing.T) {
+	fake := endortest.New(endortest.WithPageSize(2), endortest.WithStatus("findings", 2, http.StatusInternalServerError))
+	client := fake.NewClient()
+	ctx := context.Background()
+
+	token, err := client.GetToken(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := client.GetFindings(ctx, token, "6650a0000000000000000002", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 3 {
+		t.Errorf("got %d findings, want 3", len(findings))
+	}
+
+	var statuses []int
+	for _, r := range fake.Requests() {
+		if r.Resource == "findings" {
+			statuses = append(statuses, r.Status)
+		}
+	}
+	if want := []int{200, 500, 200}; !slices.Equal(statuses, want) {
+		t.Errorf("got findings statuses %v, want %v", statuses, want)
+	}
+}
+
+func TestDeniedNamespace(t *testing.T) {
+	fake := endortest.New(endortest.WithDeniedNamespaces("acme.payments"))
+	client := fake.NewClient(api.WithNamespace("acme.payments"))
+
+	_, err := client.GetFindingsForAllProjects(context.Background(), endortest.Token, "")
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("got error %v, want a 403", err)
+	}
+}
+
+func TestCreateExceptionIsNotRetried(t *testing.T) {
+	fake := endortest.New(endortest.WithStatus("policies", 1, http.StatusServiceUnavailable))
+	client := fake.NewClient()
+	spec := api.ExceptionSpec{Reason: api.ExceptionFalsePositive, FindingUUIDs: []string{endortest.Findings()[0].UUID}}
+
+	if _, err := client.CreateException(context.Background(), endortest.Token, spec); err == nil {
+		t.Fatal("creating an exception answered with a 503 succeeded")
+	}
+	if policies := fake.Policies(); len(policies) != 0 {
+		t.Fatalf("got %d policies after a failed create, want 0", len(policies))
+	}
+	if requests := len(fake.Requests()); requests != 1 {
+		t.Fatalf("sent %d requests to create the exception, want 1", requests)
+	}
+
+	policy, err := client.CreateException(context.Background(), endortest.Token, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policies := fake.Policies(); len(policies) != 1 || policies[0]["uuid"] != policy.UUID {
+		t.Errorf("got policies %v, want the one created", policies)
+	}
+}
@@ -0,0 +1,138 @@
+package endortest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Faults are injected into the page listings of a resource, as a misbehaving
+// API would answer them. Pages start at 1.
+type Faults struct {
+	// EmptyPages are served with no objects; the objects they would have held
+	// move to the following page
+	EmptyPages []int
+	// ShortPages are served with half the page size, rounded down; the rest
+	// of their objects move to the following page
+	ShortPages []int
+	// MalformedPages are answered once with a body that is not valid JSON
+	MalformedPages []int
+	// RepeatPages serve the objects of the previous page again after their own
+	RepeatPages []int
+	// StaleTokens answer with the page's own number as next_page_token
+	// instead of the next one. The token of the page after one of them was
+	// never handed out and is rejected with a 400.
+	StaleTokens []int
+	// CyclePage, when set, points the next_page_id of that page back at the
+	// first page, so a client that follows cursors blindly never finishes
+	CyclePage int
+	// Latency delays every page
+	Latency time.Duration
+	// FailureRate is the probability of answering any page request with a
+	// 500, using Seed for reproducible runs
+	FailureRate float64
+	Seed        int64
+}
+
+// WithFaults injects faults into the listings of resource, e.g. "findings".
+// Failures set with WithStatus are answered first.
+func WithFaults(resource string, f Faults) Option {
+	return func(a *API) {
+		a.faults[resource] = f
+		a.rngs[resource] = rand.New(rand.NewSource(f.Seed))
+	}
+}
+
+// WithDeniedNamespaces answers requests for these namespaces with a 403, as
+// for credentials without permission to read them, although they are
+// children of the fake's namespace
+func WithDeniedNamespaces(namespaces ...string) Option {
+	return func(a *API) { a.denied = append(a.denied, namespaces...) }
+}
+
+// GeneratedProjects are the projects of generated findings
+var GeneratedProjects = []string{"endortest-project-0", "endortest-project-1", "endortest-project-2"}
+
+// GenerateFindings returns n findings spread over every level and the
+// GeneratedProjects, for tests that need more than the fixtures:
+//
+//	endortest.New(endortest.WithFindings(endortest.GenerateFindings(250)...))
+func GenerateFindings(n int) []api.Finding {
+	findings := make([]api.Finding, n)
+	for i := range findings {
+		f := &findings[i]
+		f.UUID = fmt.Sprintf("endortest-finding-%06d", i)
+		f.Meta.Name = fmt.Sprintf("generated finding %d", i)
+		f.Meta.Description = fmt.Sprintf("Generated vulnerability %d", i)
+		f.Spec.Level = api.FindingLevels[i%len(api.FindingLevels)]
+		f.Spec.ProjectUUID = GeneratedProjects[i%len(GeneratedProjects)]
+		f.Spec.Ecosystem = "ECOSYSTEM_NPM"
+		f.Spec.FindingCategories = []api.FindingCategory{api.CategoryVulnerability}
+		f.Spec.FindingTags = []api.FindingTag{api.TagNormal}
+		f.Spec.TargetDependencyPackageName = fmt.Sprintf("npm://generated-package-%d", i%10)
+		f.Spec.TargetDependencyVersion = "1.0.0"
+	}
+	return findings
+}
+
+// injectFault answers a page of resource with the failure its faults call
+// for, returning the status answered, or 0 to serve the page
+func (a *API) injectFault(w http.ResponseWriter, resource string, page int) int {
+	f, ok := a.faults[resource]
+	if !ok {
+		return 0
+	}
+	time.Sleep(f.Latency)
+
+	a.mu.Lock()
+	key := statusKey{resource, page}
+	malformed := contains(f.MalformedPages, page) && !a.malformed[key]
+	if malformed {
+		a.malformed[key] = true
+	}
+	failed := !malformed && f.FailureRate > 0 && a.rngs[resource].Float64() < f.FailureRate
+	a.mu.Unlock()
+
+	switch {
+	case malformed:
+		return writeRaw(w, http.StatusOK, []byte(`{"list":{"objects":[{"uuid":`))
+	case failed:
+		return writeError(w, http.StatusInternalServerError, 13, "simulated failure")
+	}
+	return 0
+}
+
+// pageRange returns the positions, among total objects, of the objects on
+// page of resource. Empty and short pages hold fewer than size, pushing the
+// rest of their objects to the following pages.
+func (a *API) pageRange(resource string, page, size, total int) (start, end int) {
+	f := a.faults[resource]
+	for p := 1; p < page; p++ {
+		start += f.capacity(p, size)
+	}
+	end = start + f.capacity(page, size)
+	return min(start, total), min(end, total)
+}
+
+// capacity returns how many objects page holds
+func (f Faults) capacity(page, size int) int {
+	switch {
+	case contains(f.EmptyPages, page):
+		return 0
+	case contains(f.ShortPages, page):
+		return size / 2
+	}
+	return size
+}
+
+func contains(pages []int, page int) bool {
+	for _, p := range pages {
+		if p == page {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package endortest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/endor-labs/findings-api/endortest"
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// seeds is the number of fault schedules each property is checked against
+const seeds = 200
+
+// runTimeout bounds a single listing; a listing still running then has not
+// terminated
+const runTimeout = 10 * time.Second
+
+// schedule is a findings list with faults and the client settings listing it,
+// drawn from a seed
+type schedule struct {
+	findings int
+	faults   endortest.Faults
+	// statuses fail the first request for a page
+	statuses map[int]int
+	pageSize int
+	prefetch int
+}
+
+func (s schedule) String() string {
+	return fmt.Sprintf("findings=%d page_size=%d prefetch=%d empty=%v short=%v repeat=%v stale=%v statuses=%v failure_rate=%g",
+		s.findings, s.pageSize, s.prefetch, s.faults.EmptyPages, s.faults.ShortPages, s.faults.RepeatPages, s.faults.StaleTokens, s.statuses, s.faults.FailureRate)
+}
+
+// newSchedule draws a schedule: empty, short and repeated pages, stale page
+// tokens, a 429 or 5xx on the first request for some pages and, for some
+// seeds, random 500s
+func newSchedule(seed int64) schedule {
+	rng := rand.New(rand.NewSource(seed))
+	s := schedule{
+		findings: rng.Intn(120),
+		faults:   endortest.Faults{Seed: seed},
+		statuses: make(map[int]int),
+		pageSize: 1 + rng.Intn(25),
+		prefetch: []int{0, 0, 2, 4}[rng.Intn(4)],
+	}
+	if rng.Intn(4) == 0 {
+		s.faults.FailureRate = 0.05
+	}
+
+	pages := s.findings/s.pageSize + 2
+	for p := 1; p <= pages; p++ {
+		switch rng.Intn(8) {
+		case 0:
+			s.faults.EmptyPages = append(s.faults.EmptyPages, p)
+		case 1:
+			s.faults.ShortPages = append(s.faults.ShortPages, p)
+		case 2:
+			s.faults.RepeatPages = append(s.faults.RepeatPages, p)
+		case 3:
+			s.faults.StaleTokens = append(s.faults.StaleTokens, p)
+		}
+		if rng.Intn(5) == 0 {
+			s.statuses[p] = []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}[rng.Intn(4)]
+		}
+	}
+	return s
+}
+
+// newServer starts a fake API serving the schedule's findings with its faults
+func newServer(s schedule) *endortest.Server {
+	opts := []endortest.Option{
+		endortest.WithFindings(endortest.GenerateFindings(s.findings)...),
+		endortest.WithFaults("findings", s.faults),
+	}
+	for page, status := range s.statuses {
+		opts = append(opts, endortest.WithStatus("findings", page, status))
+	}
+	return endortest.NewServer(opts...)
+}
+
+// newClient returns a client of srv listing pages of pageSize without a page
+// cap, prefetching as many pages
+func newClient(srv *endortest.Server, pageSize, prefetch int) *api.Client {
+	return srv.NewClient(
+		api.WithPageSize(pageSize),
+		api.WithListOptions(api.ListOptions{MaxPages: -1, Prefetch: prefetch}),
+	)
+}
+
+// uuids returns the UUIDs of findings, in order
+func uuids(findings []api.Finding) []string {
+	ids := make([]string, len(findings))
+	for i, f := range findings {
+		ids[i] = f.UUID
+	}
+	return ids
+}
+
+// checkListed checks the outcome of a listing: without an error it holds every
+// finding wanted exactly once, in the API's order; an error is only acceptable
+// from retries spent on random 500s
+func checkListed(t *testing.T, s schedule, want []string, findings []api.Finding, err error) {
+	t.Helper()
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("listing did not terminate within %s (%s)", runTimeout, s)
+	}
+	if err != nil {
+		var apiErr *api.APIError
+		if s.faults.FailureRate == 0 || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("listing failed: %v (%s)", err, s)
+		}
+		return
+	}
+
+	if got := uuids(findings); !slices.Equal(got, want) {
+		t.Fatalf("listed %d findings, want %d (%s)\ngot:  %v\nwant: %v", len(got), len(want), s, got, want)
+	}
+}
+
+// droppedDuplicates returns the repeated findings the client reported dropping
+func droppedDuplicates(t *testing.T, client *api.Client) int {
+	t.Helper()
+	dropped := 0
+	for _, w := range client.Warnings() {
+		if w.Code != api.WarningDuplicateObject {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(w.Message, "dropped %d findings", &n); err != nil {
+			t.Fatalf("unexpected duplicate warning %q", w.Message)
+		}
+		dropped += n
+	}
+	return dropped
+}
+
+func TestWalkFindingsUnderFaults(t *testing.T) {
+	for seed := int64(1); seed <= seeds; seed++ {
+		s := newSchedule(seed)
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			srv := newServer(s)
+			defer srv.Close()
+			client := newClient(srv, s.pageSize, s.prefetch)
+
+			ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+			defer cancel()
+			var findings []api.Finding
+			err := client.StreamFindings(ctx, endortest.Token, "", "", func(page []api.Finding) error {
+				findings = append(findings, page...)
+				return nil
+			})
+			checkListed(t, s, uuids(endortest.GenerateFindings(s.findings)), findings, err)
+			if err != nil || s.prefetch > 0 {
+				// Prefetched pages may be requested and discarded, so the
+				// requests are only counted one page at a time
+				return
+			}
+
+			pages, failures, repeated := 0, 0, 0
+			for _, r := range srv.API.Requests() {
+				pages++
+				if r.Status != http.StatusOK {
+					failures++
+				}
+				repeated += r.Repeated
+			}
+			report := client.FetchReport()
+			attempts := 0
+			for _, r := range report.Requests {
+				attempts += r.Attempts
+			}
+			if attempts != pages {
+				t.Errorf("report counts %d attempts, the API answered %d (%s)", attempts, pages, s)
+			}
+			if report.TotalRetries != failures {
+				t.Errorf("report counts %d retries, the API failed %d requests (%s)", report.TotalRetries, failures, s)
+			}
+			if report.FailedRequests != 0 {
+				t.Errorf("report counts %d failed requests, want 0 (%s)", report.FailedRequests, s)
+			}
+			if dropped := droppedDuplicates(t, client); dropped != repeated {
+				t.Errorf("reported %d dropped duplicates, the API repeated %d (%s)", dropped, repeated, s)
+			}
+		})
+	}
+}
+
+func TestGetFindingsForProjectsUnderFaults(t *testing.T) {
+	for seed := int64(1); seed <= seeds; seed++ {
+		s := newSchedule(seed)
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			srv := newServer(s)
+			defer srv.Close()
+			client := newClient(srv, s.pageSize, s.prefetch)
+
+			ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+			defer cancel()
+			results := client.GetFindingsForProjects(ctx, endortest.Token, endortest.GeneratedProjects, "", 0)
+			if len(results) != len(endortest.GeneratedProjects) {
+				t.Fatalf("got %d project results, want %d", len(results), len(endortest.GeneratedProjects))
+			}
+			for _, r := range results {
+				var want []string
+				for _, f := range endortest.GenerateFindings(s.findings) {
+					if f.Spec.ProjectUUID == r.ProjectUUID {
+						want = append(want, f.UUID)
+					}
+				}
+				checkListed(t, s, want, r.Findings, r.Err)
+			}
+		})
+	}
+}
+
+func TestWalkFindingsStopsOnCursorCycle(t *testing.T) {
+	srv := endortest.NewServer(
+		endortest.WithFindings(endortest.GenerateFindings(50)...),
+		endortest.WithFaults("findings", endortest.Faults{CyclePage: 3}),
+	)
+	defer srv.Close()
+	client := newClient(srv, 10, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+	_, err := client.GetFindingsForAllProjects(ctx, endortest.Token, "")
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want the repeated page ID reported", err)
+	}
+	// The first page, requested without a page ID, is requested once more by
+	// its ID before the cycle shows
+	if pages := len(srv.API.Requests()); pages != 4 {
+		t.Errorf("requested %d pages, want 4", pages)
+	}
+}
+
+func TestWalkFindingsFailsOnMalformedPage(t *testing.T) {
+	srv := endortest.NewServer(
+		endortest.WithFindings(endortest.GenerateFindings(50)...),
+		endortest.WithFaults("findings", endortest.Faults{MalformedPages: []int{2}}),
+	)
+	defer srv.Close()
+	client := newClient(srv, 10, 0)
+
+	findings, err := client.GetFindingsForAllProjects(context.Background(), endortest.Token, "")
+	if err == nil || !strings.Contains(err.Error(), "decode") {
+		t.Fatalf("got %d findings and error %v, want a decode error", len(findings), err)
+	}
+}
+
+func TestWalkFindingsReportsPageCap(t *testing.T) {
+	srv := endortest.NewServer(endortest.WithFindings(endortest.GenerateFindings(50)...))
+	defer srv.Close()
+	client := srv.NewClient(api.WithPageSize(10), api.WithListOptions(api.ListOptions{MaxPages: 2}))
+
+	findings, err := client.GetFindingsForAllProjects(context.Background(), endortest.Token, "")
+	var truncated *api.TruncatedError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("got error %v, want a *api.TruncatedError", err)
+	}
+	if len(findings) != 20 {
+		t.Errorf("got %d findings, want the 20 of the first two pages", len(findings))
+	}
+}
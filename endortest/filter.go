@@ -0,0 +1,192 @@
+package endortest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// clausePattern splits a filter clause into field, operator and value
+var clausePattern = regexp.MustCompile(`^([\w.]+)\s*(==|!=|>=|<=|>|<|\snot contains\s|\scontains\s|\snot in\s|\sin\s)\s*(.+)$`)
+
+// matchFilter reports whether the object obj matches filter. It evaluates
+// clauses joined by "and" that compare a field with ==, !=, <, <=, >, >=,
+// in, not in, contains or not contains. Clauses it cannot evaluate, and
+// filters joined by "or", match every object.
+func matchFilter(obj map[string]any, filter string) bool {
+	clauses, ok := splitAnd(filter)
+	if !ok {
+		return true
+	}
+	for _, clause := range clauses {
+		if inner, ok := unwrapParens(clause); ok {
+			if !matchFilter(obj, inner) {
+				return false
+			}
+			continue
+		}
+		if !matchClause(obj, clause) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitAnd splits filter into its top-level clauses; ok is false when they
+// are joined by "or"
+func splitAnd(filter string) (clauses []string, ok bool) {
+	depth, quoted, start := 0, false, 0
+	for i := 0; i < len(filter); i++ {
+		switch c := filter[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && hasWordAt(filter, i, "and"):
+			clauses = append(clauses, strings.TrimSpace(filter[start:i]))
+			start = i + len(" and ")
+		case depth == 0 && hasWordAt(filter, i, "or"):
+			return nil, false
+		}
+	}
+	if last := strings.TrimSpace(filter[start:]); last != "" {
+		clauses = append(clauses, last)
+	}
+	return clauses, true
+}
+
+// hasWordAt reports whether the keyword surrounded by spaces starts at i
+func hasWordAt(s string, i int, keyword string) bool {
+	word := " " + keyword + " "
+	return strings.HasPrefix(strings.ToLower(s[i:]), word)
+}
+
+// unwrapParens returns the expression inside a clause wrapped in parentheses
+func unwrapParens(clause string) (string, bool) {
+	if !strings.HasPrefix(clause, "(") || !strings.HasSuffix(clause, ")") {
+		return "", false
+	}
+	depth := 0
+	for i, c := range clause {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i < len(clause)-1 {
+				return "", false
+			}
+		}
+	}
+	return clause[1 : len(clause)-1], true
+}
+
+// matchClause evaluates one comparison
+func matchClause(obj map[string]any, clause string) bool {
+	m := clausePattern.FindStringSubmatch(clause)
+	if m == nil {
+		return true
+	}
+	field, op, operand := m[1], strings.TrimSpace(m[2]), strings.TrimSpace(m[3])
+	values := fieldStrings(lookup(obj, field))
+	wanted := parseValues(operand)
+
+	switch op {
+	case "==":
+		return len(values) == 1 && len(wanted) == 1 && values[0] == wanted[0]
+	case "!=":
+		return !(len(values) == 1 && len(wanted) == 1 && values[0] == wanted[0])
+	case "in", "contains":
+		return intersects(values, wanted)
+	case "not in", "not contains":
+		return !intersects(values, wanted)
+	}
+
+	// The remaining operators compare numbers
+	if len(values) != 1 || len(wanted) != 1 {
+		return false
+	}
+	v, err1 := strconv.ParseFloat(values[0], 64)
+	w, err2 := strconv.ParseFloat(wanted[0], 64)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	switch op {
+	case ">=":
+		return v >= w
+	case "<=":
+		return v <= w
+	case ">":
+		return v > w
+	default:
+		return v < w
+	}
+}
+
+// lookup returns the value at a dotted field path of obj, or nil
+func lookup(obj map[string]any, path string) any {
+	var v any = obj
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// fieldStrings converts a field value to the strings it is compared as
+func fieldStrings(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []any:
+		var s []string
+		for _, e := range v {
+			s = append(s, fieldStrings(e)...)
+		}
+		return s
+	case string:
+		return []string{v}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// parseValues parses the operand of a clause: a value, quoted or not, or a
+// bracketed list of them
+func parseValues(operand string) []string {
+	if strings.HasPrefix(operand, "[") && strings.HasSuffix(operand, "]") {
+		var values []string
+		for _, v := range strings.Split(operand[1:len(operand)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, unquote(v))
+			}
+		}
+		return values
+	}
+	return []string{unquote(operand)}
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Package aggregate groups findings by package, vulnerability, file or level
+// and summarises each group, so the remediation work behind hundreds of
+// findings of the same dependency reads as one line.
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Keys findings can be grouped by
+const (
+	// ByPackage groups findings by vulnerable package version
+	ByPackage = "package"
+	// ByCVE groups findings by CVE, or the first vulnerability ID or the
+	// finding name for findings without one
+	ByCVE = "cve"
+	// ByFile groups findings by dependency file; a finding in several files
+	// counts in each of them
+	ByFile = "file"
+	// ByLevel groups findings by level
+	ByLevel = "level"
+)
+
+// Keys lists the supported keys
+var Keys = []string{ByPackage, ByCVE, ByFile, ByLevel}
+
+// Group summarises the findings sharing one key
+type Group struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+	// Worst is the most severe level of the group's findings
+	Worst api.FindingLevel `json:"worst_level"`
+	// Levels counts the findings by short level, e.g. critical
+	Levels   map[string]int `json:"levels"`
+	Projects int            `json:"projects"`
+	// FixedVersions lists the versions that fix any of the group's findings
+	FixedVersions []string `json:"fixed_versions,omitempty"`
+	Findings      []string `json:"findings"`
+}
+
+// By groups findings by key. Groups are sorted by worst level, then by count,
+// largest first, then by key.
+func By(findings []api.Finding, key string) ([]Group, error) {
+	keysOf, err := keyFunc(key)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	var groups []Group
+	projects := make([]map[string]bool, 0)
+	fixed := make([]map[string]bool, 0)
+	for _, f := range findings {
+		for _, k := range keysOf(f) {
+			i, ok := index[k]
+			if !ok {
+				i = len(groups)
+				index[k] = i
+				groups = append(groups, Group{Key: k, Levels: make(map[string]int)})
+				projects = append(projects, make(map[string]bool))
+				fixed = append(fixed, make(map[string]bool))
+			}
+			g := &groups[i]
+			g.Count++
+			g.Levels[f.Spec.Level.Short()]++
+			if g.Worst == "" || f.Spec.Level.Rank() > g.Worst.Rank() {
+				g.Worst = f.Spec.Level
+			}
+			g.Findings = append(g.Findings, f.UUID)
+			if p := f.Spec.ProjectUUID; p != "" && !projects[i][p] {
+				projects[i][p] = true
+				g.Projects++
+			}
+			for _, v := range f.FixedVersions() {
+				if !fixed[i][v] {
+					fixed[i][v] = true
+					g.FixedVersions = append(g.FixedVersions, v)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.Worst.Rank() != b.Worst.Rank() {
+			return a.Worst.Rank() > b.Worst.Rank()
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Key < b.Key
+	})
+	if groups == nil {
+		groups = []Group{}
+	}
+	return groups, nil
+}
+
+// keyFunc returns the function giving the groups a finding belongs to under key
+func keyFunc(key string) (func(api.Finding) []string, error) {
+	switch strings.ToLower(key) {
+	case ByPackage:
+		return func(f api.Finding) []string {
+			pkg := f.Spec.TargetDependencyPackageName
+			if pkg == "" {
+				return []string{"(no package)"}
+			}
+			if v := f.Spec.TargetDependencyVersion; v != "" && !strings.HasSuffix(pkg, "@"+v) {
+				pkg += "@" + v
+			}
+			return []string{pkg}
+		}, nil
+	case ByCVE:
+		return func(f api.Finding) []string {
+			if cve := f.CVE(); cve != "" {
+				return []string{cve}
+			}
+			if ids := f.VulnerabilityIDs(); len(ids) > 0 {
+				return []string{ids[0]}
+			}
+			return []string{f.Meta.Name}
+		}, nil
+	case ByFile:
+		return func(f api.Finding) []string {
+			if len(f.Spec.DependencyFilePath) == 0 {
+				return []string{"(no file)"}
+			}
+			return f.Spec.DependencyFilePath
+		}, nil
+	case ByLevel:
+		return func(f api.Finding) []string { return []string{f.Spec.Level.Short()} }, nil
+	default:
+		return nil, fmt.Errorf("invalid group key %q (expected %s)", key, strings.Join(Keys, ", "))
+	}
+}
+
+// Validate checks that key is a supported key
+func Validate(key string) error {
+	_, err := keyFunc(key)
+	return err
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// Access statuses of a namespace
+const (
+	// AccessOK means the namespace could be read
+	AccessOK = "ok"
+	// AccessDenied means the credentials lack permission for the namespace (403)
+	AccessDenied = "denied"
+	// AccessUnauthorized means the credentials or token were rejected (401)
+	AccessUnauthorized = "unauthorized"
+	// AccessNotFound means the namespace does not exist (404)
+	AccessNotFound = "not_found"
+	// AccessError means reading the namespace failed for another reason
+	AccessError = "error"
+)
+
+// ClassifyAccess returns the access status err means for a namespace
+func ClassifyAccess(err error) string {
+	switch {
+	case err == nil:
+		return AccessOK
+	case errors.Is(err, ErrForbidden):
+		return AccessDenied
+	case errors.Is(err, ErrUnauthorized):
+		return AccessUnauthorized
+	case errors.Is(err, ErrNotFound):
+		return AccessNotFound
+	default:
+		return AccessError
+	}
+}
+
+// NamespaceAccess is whether one namespace could be read
+type NamespaceAccess struct {
+	Namespace string `json:"namespace"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// AccessReport records which namespaces a multi-namespace run could read, so
+// the run can continue past namespaces the credentials cannot access and report
+// them at the end. It is safe for concurrent use.
+type AccessReport struct {
+	mu         sync.Mutex
+	namespaces map[string]NamespaceAccess
+}
+
+// NewAccessReport creates an empty report
+func NewAccessReport() *AccessReport {
+	return &AccessReport{namespaces: make(map[string]NamespaceAccess)}
+}
+
+// Record sets the access of namespace from the error of reading it, replacing
+// any earlier result, and returns the status
+func (r *AccessReport) Record(namespace string, err error) string {
+	a := NamespaceAccess{Namespace: namespace, Status: ClassifyAccess(err)}
+	if err != nil {
+		a.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.namespaces[namespace] = a
+	r.mu.Unlock()
+	return a.Status
+}
+
+// Status returns the recorded status of namespace
+func (r *AccessReport) Status(namespace string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.namespaces[namespace]
+	return a.Status, ok
+}
+
+// Forget removes namespace from the report
+func (r *AccessReport) Forget(namespace string) {
+	r.mu.Lock()
+	delete(r.namespaces, namespace)
+	r.mu.Unlock()
+}
+
+// Namespaces returns every recorded namespace, sorted by name
+func (r *AccessReport) Namespaces() []NamespaceAccess {
+	r.mu.Lock()
+	all := make([]NamespaceAccess, 0, len(r.namespaces))
+	for _, a := range r.namespaces {
+		all = append(all, a)
+	}
+	r.mu.Unlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].Namespace < all[j].Namespace })
+	return all
+}
+
+// Failed returns the namespaces that could not be read, sorted by name
+func (r *AccessReport) Failed() []NamespaceAccess {
+	var failed []NamespaceAccess
+	for _, a := range r.Namespaces() {
+		if a.Status != AccessOK {
+			failed = append(failed, a)
+		}
+	}
+	return failed
+}
+
+// CheckAccess reads a single finding of the client's namespace to find out
+// whether the credentials can read it. Child namespaces are not checked.
+func (c *Client) CheckAccess(ctx context.Context, token string) error {
+	params := url.Values{}
+	params.Set("list_parameters.page_size", "1")
+	params.Set("list_parameters.mask", "uuid")
+	_, _, err := listPage[Finding](ctx, c, token, "findings", 1, params, "")
+	return err
+}
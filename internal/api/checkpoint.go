@@ -0,0 +1,194 @@
+package api
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Checkpoint records the progress of findings listings in a file, one line per
+// page fetched, so an interrupted fetch can resume after the last page it
+// completed instead of starting over. Listings are told apart by namespace,
+// filter and list options, so a checkpoint can hold the listings of several
+// projects and namespaces; completed ones are replayed without any request.
+type Checkpoint struct {
+	path string
+
+	mu       sync.Mutex
+	file     *os.File
+	listings map[string]*checkpointListing
+	failed   bool
+}
+
+// checkpointRecord is one line of a checkpoint file: a page of a listing
+type checkpointRecord struct {
+	Key        string    `json:"key"`
+	Page       int       `json:"page"`
+	NextPageID string    `json:"next_page_id,omitempty"`
+	Done       bool      `json:"done,omitempty"`
+	Findings   []Finding `json:"findings"`
+}
+
+// checkpointListing is the progress of one listing read from a checkpoint
+type checkpointListing struct {
+	pages      int
+	nextPageID string
+	done       bool
+	findings   []Finding
+}
+
+// OpenCheckpoint opens the checkpoint at path. With resume, the listings it
+// holds are resumed and new pages are appended to it; a missing file starts
+// an empty checkpoint. Without resume, any earlier checkpoint is discarded.
+func OpenCheckpoint(path string, resume bool) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, listings: make(map[string]*checkpointListing)}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		if err := c.load(); err != nil {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	c.file = f
+	return c, nil
+}
+
+// load reads the listings of the checkpoint file. A last line cut short by
+// the interruption is ignored, losing only the page it was recording.
+func (c *Checkpoint) load() error {
+	f, err := os.Open(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if len(data) > 0 && data[len(data)-1] == '\n' {
+			var rec checkpointRecord
+			if jerr := json.Unmarshal(data, &rec); jerr != nil {
+				return fmt.Errorf("failed to read checkpoint: line %d: %w", line, jerr)
+			}
+			l := c.listings[rec.Key]
+			if l == nil {
+				l = &checkpointListing{}
+				c.listings[rec.Key] = l
+			}
+			l.pages, l.nextPageID, l.done = rec.Page, rec.NextPageID, rec.Done
+			l.findings = append(l.findings, rec.Findings...)
+		} else if len(data) > 0 {
+			slog.Warn("Ignoring the incomplete last line of the checkpoint", "path", c.path)
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// Resumed returns the number of listings and findings read from the file
+func (c *Checkpoint) Resumed() (listings, findings int) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, l := range c.listings {
+		findings += len(l.findings)
+	}
+	return len(c.listings), findings
+}
+
+// Path returns the checkpoint file
+func (c *Checkpoint) Path() string { return c.path }
+
+// Close closes the checkpoint file, keeping it for a later resume
+func (c *Checkpoint) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Close()
+}
+
+// Remove closes and deletes the checkpoint file, once the fetch it records
+// has completed
+func (c *Checkpoint) Remove() error {
+	if c == nil {
+		return nil
+	}
+	c.Close()
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// resume returns the progress recorded for the listing key
+func (c *Checkpoint) resume(key string) (checkpointListing, bool) {
+	if c == nil {
+		return checkpointListing{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.listings[key]
+	if !ok {
+		return checkpointListing{}, false
+	}
+	return *l, true
+}
+
+// record appends a page of the listing key. A checkpoint that cannot be
+// written is a warning: the fetch goes on, it just cannot be resumed.
+func (c *Checkpoint) record(key string, page int, nextPageID string, done bool, findings []Finding) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(checkpointRecord{Key: key, Page: page, NextPageID: nextPageID, Done: done, Findings: findings})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failed {
+		return
+	}
+	if err == nil {
+		if _, err = c.file.Write(append(data, '\n')); err == nil {
+			err = c.file.Sync()
+		}
+	}
+	if err != nil {
+		c.failed = true
+		slog.Warn("Failed to write the checkpoint, the fetch cannot be resumed", "path", c.path, "error", err)
+	}
+}
+
+// WithCheckpoint records the progress of findings listings in cp and resumes
+// the listings it holds
+func WithCheckpoint(cp *Checkpoint) Option {
+	return func(c *Client) { c.checkpoint = cp }
+}
+
+// checkpointKey identifies a findings listing in a checkpoint: the same
+// namespace, filter and list options list the same findings
+func (c *Client) checkpointKey(filter string) string {
+	h := sha256.New()
+	for _, part := range []string{c.baseURL, c.namespace, filter, c.list.fieldMask(), c.list.Sort, strconv.Itoa(c.list.Limit), strconv.FormatBool(c.list.NoTraverse)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
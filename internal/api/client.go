@@ -2,79 +2,312 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// BaseURL is the default Endor Labs API endpoint
 const (
 	BaseURL = "https://api.endorlabs.com/v1"
 )
 
+// DefaultAuthPath is the API key authentication endpoint, relative to the base URL
+const DefaultAuthPath = "/auth/api-key"
+
+// AuthConfig controls how the client obtains a token
+type AuthConfig struct {
+	// Path is the auth endpoint, either relative to the base URL or an absolute URL
+	// (for gateways that authenticate elsewhere)
+	Path string
+	// Audience is sent as "audience" in the auth request when set
+	Audience string
+	// Claims are extra fields added to the auth request payload
+	Claims map[string]string
+}
+
+// DefaultPageSize is the number of objects requested per page
+const DefaultPageSize = 100
+
+// DefaultTimeout bounds each HTTP request of a client without WithTimeout
+const DefaultTimeout = 60 * time.Second
+
 // Client represents an Endor Labs API client
 type Client struct {
 	apiKey     string
 	apiSecret  string
 	namespace  string
+	baseURL    string
 	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	retry      RetryPolicy
+	auth       AuthConfig
+	pageSize   int
+	list       ListOptions
+	report     reportRecorder
+	telemetry  telemetryRecorder
+	warnings   warningRecorder
+	logger     *slog.Logger
+	progress   ProgressFunc
+	limiter    *RateLimiter
+	compress   bool
+	checkpoint *Checkpoint
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	tracer         trace.Tracer
+	duration       metric.Float64Histogram
 }
 
-// NewClient creates a new API client
-func NewClient(apiKey, apiSecret, namespace string) *Client {
-	return &Client{
-		apiKey:    apiKey,
-		apiSecret: apiSecret,
-		namespace: namespace,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+// Option configures a Client created by NewClient
+type Option func(*Client)
+
+// NewClient creates a new API client configured by opts, which are applied in
+// order. Clients need WithCredentials and WithNamespace to authenticate.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    BaseURL,
+		httpClient: &http.Client{},
+		timeout:    DefaultTimeout,
+		userAgent:  DefaultUserAgent(),
+		retry:      DefaultRetryPolicy(),
+		auth:       AuthConfig{Path: DefaultAuthPath},
+		pageSize:   DefaultPageSize,
+		compress:   true,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// Copy the HTTP client so the timeout does not change a caller's client
+	hc := *c.httpClient
+	hc.Timeout = c.timeout
+	c.httpClient = &hc
+	c.setupTelemetry()
+	return c
 }
 
-// GetToken authenticates with the API and returns a token
-func (c *Client) GetToken() (string, error) {
-	url := fmt.Sprintf("%s/auth/api-key", BaseURL)
+// DefaultUserAgent returns the User-Agent sent by clients without WithUserAgent
+func DefaultUserAgent() string {
+	return "findings-api/" + buildinfo.Version
+}
 
-	payload := map[string]string{
-		"key":    c.apiKey,
-		"secret": c.apiSecret,
+// WithCredentials sets the API key and secret the client authenticates with
+func WithCredentials(apiKey, apiSecret string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+		c.apiSecret = apiSecret
 	}
+}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal auth payload: %w", err)
+// WithNamespace sets the namespace the client queries
+func WithNamespace(namespace string) Option {
+	return func(c *Client) { c.namespace = namespace }
+}
+
+// WithBaseURL points the client at another API endpoint, such as a
+// single-tenant deployment. An empty url keeps BaseURL.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		if url == "" {
+			url = BaseURL
+		}
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithHTTPClient sends requests with hc, e.g. to use a proxy or custom TLS
+// settings. The client's timeout still applies; hc itself is not modified.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// WithTimeout bounds each HTTP request, including reading its response; 0
+// removes the limit
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d < 0 {
+			d = 0
+		}
+		c.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request. An empty value
+// keeps DefaultUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		if userAgent == "" {
+			userAgent = DefaultUserAgent()
+		}
+		c.userAgent = userAgent
+	}
+}
+
+// WithPageSize sets the number of objects requested per page for list endpoints
+func WithPageSize(n int) Option {
+	return func(c *Client) {
+		if n < 1 {
+			n = DefaultPageSize
+		}
+		c.pageSize = n
+	}
+}
+
+// WithAuthConfig changes the auth endpoint and token request options.
+// An empty Path keeps the default endpoint.
+func WithAuthConfig(cfg AuthConfig) Option {
+	return func(c *Client) {
+		if cfg.Path == "" {
+			cfg.Path = DefaultAuthPath
+		}
+		c.auth = cfg
+	}
+}
+
+// Namespace returns the namespace the client queries
+func (c *Client) Namespace() string {
+	return c.namespace
+}
+
+// authURL resolves the configured auth path against the base URL
+func (c *Client) authURL() string {
+	if strings.HasPrefix(c.auth.Path, "http://") || strings.HasPrefix(c.auth.Path, "https://") {
+		return c.auth.Path
+	}
+	return c.baseURL + "/" + strings.TrimPrefix(c.auth.Path, "/")
+}
+
+// Token is an auth token and when it expires. Expiry is zero when the API did
+// not say and the token is not a JWT with an exp claim.
+type Token struct {
+	Value  string    `json:"token"`
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// Valid reports whether the token is set and does not expire within margin
+func (t Token) Valid(margin time.Duration) bool {
+	return t.Value != "" && !t.Expiry.IsZero() && time.Now().Add(margin).Before(t.Expiry)
+}
+
+// CacheKey identifies the credentials, namespace and auth endpoint a token was
+// issued for, without revealing the API key
+func (c *Client) CacheKey() string {
+	h := sha256.New()
+	for _, part := range []string{c.authURL(), c.apiKey, c.namespace, c.auth.Audience} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GetToken authenticates with the API and returns a token
+func (c *Client) GetToken(ctx context.Context) (string, error) {
+	token, err := c.Authenticate(ctx)
+	return token.Value, err
+}
+
+// Authenticate authenticates with the API and returns a token with its expiry
+func (c *Client) Authenticate(ctx context.Context) (Token, error) {
+	url := c.authURL()
+
+	payload := make(map[string]string, len(c.auth.Claims)+3)
+	for k, v := range c.auth.Claims {
+		payload[k] = v
+	}
+	payload["key"] = c.apiKey
+	payload["secret"] = c.apiSecret
+	if c.auth.Audience != "" {
+		payload["audience"] = c.auth.Audience
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return Token{}, fmt.Errorf("failed to marshal auth payload: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Request-Timeout", "60")
+	// Exchanging the key for a token changes nothing, so it is safe to retry
+	key := newIdempotencyKey()
+	resp, err := c.doWithRetry(ctx, "auth", 0, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, err
+		}
 
-	resp, err := c.httpClient.Do(req)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Request-Timeout", "60")
+		req.Header.Set(HeaderIdempotencyKey, key)
+		return req, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return Token{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("authentication failed with status: %d", resp.StatusCode)
+		return Token{}, fmt.Errorf("authentication failed: %w", newAPIError("auth", resp))
 	}
 
+	// Gateways in front of the API may use the OAuth "access_token" and
+	// "expires_in" fields instead
 	var authResp struct {
-		Token string `json:"token"`
+		Token          string    `json:"token"`
+		AccessToken    string    `json:"access_token"`
+		ExpirationTime time.Time `json:"expiration_time"`
+		ExpiresIn      int64     `json:"expires_in"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return Token{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if authResp.Token == "" {
-		return "", fmt.Errorf("no token received in response")
+	token := Token{Value: firstNonEmpty(authResp.Token, authResp.AccessToken)}
+	if token.Value == "" {
+		return Token{}, fmt.Errorf("no token received in response")
 	}
 
-	return authResp.Token, nil
+	switch {
+	case !authResp.ExpirationTime.IsZero():
+		token.Expiry = authResp.ExpirationTime
+	case authResp.ExpiresIn > 0:
+		token.Expiry = time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second)
+	default:
+		token.Expiry = jwtExpiry(token.Value)
+	}
+	return token, nil
+}
+
+// jwtExpiry returns the exp claim of a JWT, or zero if token is not a JWT.
+// The signature is not checked; the expiry only decides when to re-authenticate.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
 }
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithCompression requests gzip-compressed responses, which is the default and
+// cuts the transfer time of large findings pages. false requests uncompressed
+// responses, e.g. to read bodies off the wire when debugging.
+func WithCompression(enabled bool) Option {
+	return func(c *Client) { c.compress = enabled }
+}
+
+// setAcceptEncoding asks for a compressed response unless compression is off.
+// Setting the header stops the transport from decompressing on its own, so
+// decompress undoes the encoding instead.
+func (c *Client) setAcceptEncoding(req *http.Request) {
+	if c.compress {
+		req.Header.Set("Accept-Encoding", "gzip")
+	} else {
+		req.Header.Set("Accept-Encoding", "identity")
+	}
+}
+
+// decompress replaces the body of a gzip-encoded response with its decoded
+// content, so callers read responses the same way whatever their encoding.
+// On error the body is left for the caller to close.
+func decompress(resp *http.Response) error {
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		return nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to decompress response: %w", err)
+	}
+	resp.Body = gzipBody{Reader: zr, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// gzipBody reads a decompressed response body and closes the underlying one
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// countResponse is the envelope of a list request made with list_parameters.count
+type countResponse struct {
+	CountResponse struct {
+		Count int `json:"count"`
+	} `json:"count_response"`
+}
+
+// CountFindings returns how many findings match filter in the projects with
+// the given UUIDs, or in every project when there are none, without listing
+// them. The count ignores the client's limit and page cap.
+func (c *Client) CountFindings(ctx context.Context, token string, projectUUIDs []string, filter string) (int, error) {
+	params := c.countParams(projectUUIDs, filter)
+	params.Set("list_parameters.count", "true")
+
+	var resp countResponse
+	if err := c.getFindingsAggregate(ctx, token, params, &resp); err != nil {
+		return 0, err
+	}
+	return resp.CountResponse.Count, nil
+}
+
+// CountFindingsBy returns how many findings matching filter have each value of
+// the field at path, e.g. spec.level, in the projects with the given UUIDs or
+// in every project, from the API's group aggregation rather than by listing
+// them. A finding whose field is a list, such as spec.finding_categories, is
+// counted under each of its values, and one without the field under "".
+func (c *Client) CountFindingsBy(ctx context.Context, token string, projectUUIDs []string, filter, path string) (map[string]int, error) {
+	groups, err := c.groupFindings(ctx, token, projectUUIDs, filter, []string{path})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(groups))
+	for _, g := range groups {
+		counts[g.Values[path]] += g.Count
+	}
+	return counts, nil
+}
+
+// countParams returns the list parameters selecting the findings of
+// projectUUIDs, or of every project, that match filter
+func (c *Client) countParams(projectUUIDs []string, filter string) url.Values {
+	switch len(projectUUIDs) {
+	case 0:
+	case 1:
+		filter = projectFilter(projectUUIDs[0], filter)
+	default:
+		quoted := make([]string, len(projectUUIDs))
+		for i, id := range projectUUIDs {
+			quoted[i] = fmt.Sprintf("%q", id)
+		}
+		filter = andFilter(fmt.Sprintf("spec.project_uuid in [%s]", strings.Join(quoted, ",")), filter)
+	}
+
+	params := url.Values{}
+	if filter != "" {
+		params.Set("list_parameters.filter", filter)
+	}
+	if !c.list.NoTraverse {
+		params.Set("list_parameters.traverse", "true")
+	}
+	return params
+}
+
+// getFindingsAggregate sends a findings list request asking for a count or
+// groups instead of objects and decodes the response into v
+func (c *Client) getFindingsAggregate(ctx context.Context, token string, params url.Values, v any) error {
+	fullURL := fmt.Sprintf("%s/namespaces/%s/findings?%s", c.baseURL, c.namespace, params.Encode())
+
+	resp, err := c.doWithRetry(ctx, "findings-count", 0, func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", fullURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Request-Timeout", "60")
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to count findings: %w", newAPIError("findings", resp))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// ProgressFunc is told of each page of findings as it is fetched, with the
+// number of findings it added. Projects fetched concurrently call it from
+// several goroutines at once.
+type ProgressFunc func(findings int)
+
+// WithProgress calls fn after each page of findings is fetched, e.g. to draw
+// a progress bar. Pages replayed from a checkpoint are reported as one page.
+func WithProgress(fn ProgressFunc) Option {
+	return func(c *Client) { c.progress = fn }
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redactedHeaders are never logged in full, along with any header whose name
+// mentions a token, secret or key
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// WithLogger makes the client log every API call to logger at debug level:
+// the method and full URL, headers with secrets redacted, the status and how
+// long it took. Request and response bodies are never logged. A nil logger
+// disables logging.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) { c.logger = logger }
+}
+
+// logRequest logs an attempt to send req
+func (c *Client) logRequest(req *http.Request, attempt int) {
+	if c.logger == nil {
+		return
+	}
+	c.logger.Debug("API request", "method", req.Method, "url", displayURL(req.URL), "attempt", attempt,
+		slog.Group("headers", headerAttrs(req.Header)...))
+}
+
+// logResponse logs the outcome of sending req
+func (c *Client) logResponse(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	if c.logger == nil {
+		return
+	}
+	elapsed = elapsed.Round(time.Millisecond)
+	if err != nil {
+		c.logger.Debug("API request failed", "method", req.Method, "path", req.URL.Path, "elapsed", elapsed, "error", err)
+		return
+	}
+	c.logger.Debug("API response", "status", resp.StatusCode, "method", req.Method, "path", req.URL.Path, "elapsed", elapsed,
+		slog.Group("headers", headerAttrs(resp.Header)...))
+}
+
+// displayURL returns u with its query unescaped, so filter expressions are readable
+func displayURL(u *url.URL) string {
+	s := u.Scheme + "://" + u.Host + u.Path
+	if u.RawQuery == "" {
+		return s
+	}
+	if q, err := url.QueryUnescape(u.RawQuery); err == nil {
+		return s + "?" + q
+	}
+	return s + "?" + u.RawQuery
+}
+
+// headerAttrs returns the headers of h as log attributes sorted by name, with
+// secrets redacted
+func headerAttrs(h http.Header) []any {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if isSecretHeader(name) {
+			value = redact(value)
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return attrs
+}
+
+func isSecretHeader(name string) bool {
+	if redactedHeaders[http.CanonicalHeaderKey(name)] {
+		return true
+	}
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "token") || strings.Contains(lower, "secret") || strings.Contains(lower, "key")
+}
+
+// redact hides a secret, keeping an auth scheme such as "Bearer" for context
+func redact(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok {
+		return scheme + " [REDACTED]"
+	}
+	return "[REDACTED]"
+}
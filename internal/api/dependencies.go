@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DependencyMetadata describes one dependency of a project's package version
+type DependencyMetadata struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		// Name is the dependency package version, e.g. npm://lodash@4.17.21
+		Name       string `json:"name"`
+		ParentUUID string `json:"parent_uuid"`
+	} `json:"meta"`
+	Spec struct {
+		ImporterData struct {
+			ProjectUUID        string `json:"project_uuid"`
+			PackageVersionUUID string `json:"package_version_uuid"`
+			PackageVersionName string `json:"package_version_name"`
+		} `json:"importer_data"`
+		DependencyData struct {
+			Direct    bool   `json:"direct"`
+			Reachable string `json:"reachable,omitempty"`
+			Scope     string `json:"scope,omitempty"`
+		} `json:"dependency_data"`
+	} `json:"spec"`
+}
+
+// Relationship returns "direct" or "transitive"
+func (d DependencyMetadata) Relationship() string {
+	if d.Spec.DependencyData.Direct {
+		return "direct"
+	}
+	return "transitive"
+}
+
+// dependencyMask lists the dependency metadata fields requested
+const dependencyMask = "uuid,meta.name,meta.parent_uuid," +
+	"spec.importer_data.project_uuid,spec.importer_data.package_version_uuid,spec.importer_data.package_version_name," +
+	"spec.dependency_data.direct,spec.dependency_data.reachable,spec.dependency_data.scope"
+
+// ListDependencies retrieves the dependencies of every package version in a project.
+// directOnly limits the result to direct dependencies.
+func (c *Client) ListDependencies(ctx context.Context, token, projectUUID string, directOnly bool) ([]DependencyMetadata, error) {
+	filter := fmt.Sprintf("spec.importer_data.project_uuid==%q", projectUUID)
+	if directOnly {
+		filter += " and spec.dependency_data.direct==true"
+	}
+
+	params := url.Values{}
+	params.Set("list_parameters.filter", filter)
+	params.Set("list_parameters.mask", dependencyMask)
+	params.Set("list_parameters.traverse", "true")
+
+	return listAll[DependencyMetadata](ctx, c, token, "dependency-metadata", params)
+}
+
+// DependencyGraph is the resolved dependency graph of a package version
+type DependencyGraph struct {
+	// Root is the package version the graph belongs to
+	Root string
+	// Edges maps each package version to its direct dependencies
+	Edges map[string][]string
+}
+
+// packageVersionGraph is the subset of a PackageVersion holding its graph
+type packageVersionGraph struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		Name string `json:"name"`
+	} `json:"meta"`
+	Spec struct {
+		ResolvedDependencies struct {
+			DependencyGraph map[string]graphNode `json:"dependency_graph"`
+		} `json:"resolved_dependencies"`
+	} `json:"spec"`
+}
+
+// graphNode is the list of dependencies of one graph node. The API returns it
+// either as a plain list or as an object with a "dependencies" list.
+type graphNode []string
+
+func (n *graphNode) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*n = list
+		return nil
+	}
+
+	var obj struct {
+		Dependencies []string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*n = obj.Dependencies
+	return nil
+}
+
+// GetDependencyGraph retrieves the dependency graph of a package version in a
+// project. packageVersion may be empty when the project has a single package
+// version; otherwise it must name one, e.g. npm://my-app@1.0.0.
+func (c *Client) GetDependencyGraph(ctx context.Context, token, projectUUID, packageVersion string) (*DependencyGraph, error) {
+	filter := fmt.Sprintf("spec.project_uuid==%q", projectUUID)
+	if packageVersion != "" {
+		filter += fmt.Sprintf(" and meta.name==%q", packageVersion)
+	}
+
+	params := url.Values{}
+	params.Set("list_parameters.filter", filter)
+	params.Set("list_parameters.mask", "uuid,meta.name,spec.resolved_dependencies.dependency_graph")
+	params.Set("list_parameters.traverse", "true")
+
+	versions, err := listAll[packageVersionGraph](ctx, c, token, "package-versions", params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(versions) {
+	case 0:
+		if packageVersion != "" {
+			return nil, fmt.Errorf("package version %s not found in project %s", packageVersion, projectUUID)
+		}
+		return nil, fmt.Errorf("no package versions found in project %s", projectUUID)
+	case 1:
+	default:
+		names := make([]string, len(versions))
+		for i, v := range versions {
+			names[i] = v.Meta.Name
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("project %s has %d package versions, choose one of: %s", projectUUID, len(versions), strings.Join(names, ", "))
+	}
+
+	pv := versions[0]
+	graph := &DependencyGraph{
+		Root:  pv.Meta.Name,
+		Edges: make(map[string][]string, len(pv.Spec.ResolvedDependencies.DependencyGraph)),
+	}
+	for node, deps := range pv.Spec.ResolvedDependencies.DependencyGraph {
+		graph.Edges[node] = deps
+	}
+	return graph, nil
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/filter"
+)
+
+// FindingLevel is the severity of a finding, e.g. FINDING_LEVEL_CRITICAL
+type FindingLevel string
+
+// Finding levels, from most to least severe
+const (
+	LevelCritical FindingLevel = "FINDING_LEVEL_CRITICAL"
+	LevelHigh     FindingLevel = "FINDING_LEVEL_HIGH"
+	LevelMedium   FindingLevel = "FINDING_LEVEL_MEDIUM"
+	LevelLow      FindingLevel = "FINDING_LEVEL_LOW"
+)
+
+// FindingLevels lists every level, from most to least severe
+var FindingLevels = []FindingLevel{LevelCritical, LevelHigh, LevelMedium, LevelLow}
+
+// levelRanks orders levels by severity; unknown levels rank 0
+var levelRanks = map[FindingLevel]int{
+	LevelLow:      1,
+	LevelMedium:   2,
+	LevelHigh:     3,
+	LevelCritical: 4,
+}
+
+// ParseFindingLevel parses a level in its API form or short form, such as
+// "critical", in any letter case
+func ParseFindingLevel(s string) (FindingLevel, error) {
+	l := FindingLevel(filter.Normalize(s, filter.LevelPrefix))
+	if !l.Valid() {
+		return "", fmt.Errorf("unknown finding level %q (expected %s)", s, shortNames(FindingLevels))
+	}
+	return l, nil
+}
+
+// Valid reports whether l is a known level
+func (l FindingLevel) Valid() bool { return levelRanks[l] > 0 }
+
+// Rank orders levels from low (1) to critical (4); unknown levels rank 0
+func (l FindingLevel) Rank() int { return levelRanks[l] }
+
+// AtLeast reports whether l is as severe as other or more
+func (l FindingLevel) AtLeast(other FindingLevel) bool { return l.Rank() >= other.Rank() }
+
+// Short returns the level without its prefix in lower case, e.g. "critical"
+func (l FindingLevel) Short() string { return short(string(l), filter.LevelPrefix) }
+
+func (l FindingLevel) String() string { return string(l) }
+
+// UnmarshalJSON accepts the short form too. Unknown values are kept as-is so
+// levels added to the API later do not break decoding.
+func (l *FindingLevel) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*l = canonical(s, filter.LevelPrefix, FindingLevel.Valid)
+	return nil
+}
+
+// FindingCategory is the kind of issue a finding reports, e.g. FINDING_CATEGORY_VULNERABILITY
+type FindingCategory string
+
+// Finding categories
+const (
+	CategoryVulnerability FindingCategory = "FINDING_CATEGORY_VULNERABILITY"
+	CategorySecurity      FindingCategory = "FINDING_CATEGORY_SECURITY"
+	CategorySupplyChain   FindingCategory = "FINDING_CATEGORY_SUPPLY_CHAIN"
+	CategoryLicenseRisk   FindingCategory = "FINDING_CATEGORY_LICENSE_RISK"
+	CategoryOperational   FindingCategory = "FINDING_CATEGORY_OPERATIONAL"
+	CategoryMalware       FindingCategory = "FINDING_CATEGORY_MALWARE"
+	CategorySecrets       FindingCategory = "FINDING_CATEGORY_SECRETS"
+	CategorySCPM          FindingCategory = "FINDING_CATEGORY_SCPM"
+	CategoryCICD          FindingCategory = "FINDING_CATEGORY_CICD"
+	CategoryGHActions     FindingCategory = "FINDING_CATEGORY_GHACTIONS"
+	CategoryTools         FindingCategory = "FINDING_CATEGORY_TOOLS"
+	CategorySAST          FindingCategory = "FINDING_CATEGORY_SAST"
+	CategoryContainer     FindingCategory = "FINDING_CATEGORY_CONTAINER"
+	CategoryAIModels      FindingCategory = "FINDING_CATEGORY_AI_MODELS"
+)
+
+// FindingCategories lists every known category
+var FindingCategories = []FindingCategory{
+	CategoryVulnerability, CategorySecurity, CategorySupplyChain, CategoryLicenseRisk,
+	CategoryOperational, CategoryMalware, CategorySecrets, CategorySCPM, CategoryCICD,
+	CategoryGHActions, CategoryTools, CategorySAST, CategoryContainer, CategoryAIModels,
+}
+
+// ParseFindingCategory parses a category in its API form or short form, such
+// as "vulnerability" or "license-risk"
+func ParseFindingCategory(s string) (FindingCategory, error) {
+	c := FindingCategory(filter.Normalize(s, filter.CategoryPrefix))
+	if !c.Valid() {
+		return "", fmt.Errorf("unknown finding category %q (expected %s)", s, shortNames(FindingCategories))
+	}
+	return c, nil
+}
+
+// Valid reports whether c is a known category
+func (c FindingCategory) Valid() bool { return contains(FindingCategories, c) }
+
+// Short returns the category without its prefix in lower case, e.g. "vulnerability"
+func (c FindingCategory) Short() string { return short(string(c), filter.CategoryPrefix) }
+
+func (c FindingCategory) String() string { return string(c) }
+
+// UnmarshalJSON accepts the short form too and keeps unknown values as-is
+func (c *FindingCategory) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*c = canonical(s, filter.CategoryPrefix, FindingCategory.Valid)
+	return nil
+}
+
+// FindingTag is an attribute of a finding, e.g. FINDING_TAGS_REACHABLE_FUNCTION
+type FindingTag string
+
+// Finding tags
+const (
+	TagDirect                         FindingTag = "FINDING_TAGS_DIRECT"
+	TagTransitive                     FindingTag = "FINDING_TAGS_TRANSITIVE"
+	TagNormal                         FindingTag = "FINDING_TAGS_NORMAL"
+	TagSelf                           FindingTag = "FINDING_TAGS_SELF"
+	TagTest                           FindingTag = "FINDING_TAGS_TEST"
+	TagProjectInternal                FindingTag = "FINDING_TAGS_PROJECT_INTERNAL"
+	TagNamespaceInternal              FindingTag = "FINDING_TAGS_NAMESPACE_INTERNAL"
+	TagPhantom                        FindingTag = "FINDING_TAGS_PHANTOM"
+	TagReachableFunction              FindingTag = "FINDING_TAGS_REACHABLE_FUNCTION"
+	TagPotentiallyReachableFunction   FindingTag = "FINDING_TAGS_POTENTIALLY_REACHABLE_FUNCTION"
+	TagUnreachableFunction            FindingTag = "FINDING_TAGS_UNREACHABLE_FUNCTION"
+	TagReachableDependency            FindingTag = "FINDING_TAGS_REACHABLE_DEPENDENCY"
+	TagPotentiallyReachableDependency FindingTag = "FINDING_TAGS_POTENTIALLY_REACHABLE_DEPENDENCY"
+	TagUnreachableDependency          FindingTag = "FINDING_TAGS_UNREACHABLE_DEPENDENCY"
+	TagFixAvailable                   FindingTag = "FINDING_TAGS_FIX_AVAILABLE"
+	TagUnfixable                      FindingTag = "FINDING_TAGS_UNFIXABLE"
+	TagException                      FindingTag = "FINDING_TAGS_EXCEPTION"
+	TagPolicy                         FindingTag = "FINDING_TAGS_POLICY"
+	TagCIBlocker                      FindingTag = "FINDING_TAGS_CI_BLOCKER"
+	TagCIWarning                      FindingTag = "FINDING_TAGS_CI_WARNING"
+	TagExploited                      FindingTag = "FINDING_TAGS_EXPLOITED"
+	TagMalware                        FindingTag = "FINDING_TAGS_MALWARE"
+)
+
+// FindingTags lists every known tag
+var FindingTags = []FindingTag{
+	TagDirect, TagTransitive, TagNormal, TagSelf, TagTest, TagProjectInternal, TagNamespaceInternal,
+	TagPhantom, TagReachableFunction, TagPotentiallyReachableFunction, TagUnreachableFunction,
+	TagReachableDependency, TagPotentiallyReachableDependency, TagUnreachableDependency,
+	TagFixAvailable, TagUnfixable, TagException, TagPolicy, TagCIBlocker, TagCIWarning,
+	TagExploited, TagMalware,
+}
+
+// ParseFindingTag parses a tag in its API form or short form, such as "fix_available"
+func ParseFindingTag(s string) (FindingTag, error) {
+	t := FindingTag(filter.Normalize(s, filter.TagPrefix))
+	if !t.Valid() {
+		return "", fmt.Errorf("unknown finding tag %q (expected %s)", s, shortNames(FindingTags))
+	}
+	return t, nil
+}
+
+// Valid reports whether t is a known tag
+func (t FindingTag) Valid() bool { return contains(FindingTags, t) }
+
+// Short returns the tag without its prefix in lower case, e.g. "fix_available"
+func (t FindingTag) Short() string { return short(string(t), filter.TagPrefix) }
+
+func (t FindingTag) String() string { return string(t) }
+
+// UnmarshalJSON accepts the short form too and keeps unknown values as-is
+func (t *FindingTag) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = canonical(s, filter.TagPrefix, FindingTag.Valid)
+	return nil
+}
+
+// HasTag reports whether the finding carries tag
+func (f Finding) HasTag(tag FindingTag) bool {
+	return contains(f.Spec.FindingTags, tag)
+}
+
+// HasCategory reports whether the finding is in category
+func (f Finding) HasCategory(category FindingCategory) bool {
+	return contains(f.Spec.FindingCategories, category)
+}
+
+// Reachability is what reachability analysis concluded about a finding's
+// vulnerable code
+type Reachability string
+
+// Reachability conclusions
+const (
+	// Reachable means the vulnerable function is, or may be, called
+	Reachable Reachability = "reachable"
+	// UnreachableFunction means the vulnerable function is never called
+	UnreachableFunction Reachability = "unreachable_function"
+	// UnreachableDependency means the vulnerable dependency is never used
+	UnreachableDependency Reachability = "unreachable_dependency"
+	// ReachabilityUnknown means the finding has no reachability tags
+	ReachabilityUnknown Reachability = "unknown"
+)
+
+// Reachability returns the reachability of the finding from its tags. A
+// potentially reachable function counts as reachable, as with --reachable-only.
+func (f Finding) Reachability() Reachability {
+	switch {
+	case f.HasTag(TagReachableFunction), f.HasTag(TagPotentiallyReachableFunction):
+		return Reachable
+	case f.HasTag(TagUnreachableFunction):
+		return UnreachableFunction
+	case f.HasTag(TagUnreachableDependency):
+		return UnreachableDependency
+	}
+	return ReachabilityUnknown
+}
+
+// JoinEnums joins enum values with sep, for tabular output
+func JoinEnums[T ~string](values []T, sep string) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return strings.Join(s, sep)
+}
+
+// canonical converts s to the API form when that is a known value, and
+// otherwise keeps it as received
+func canonical[T ~string](s, prefix string, valid func(T) bool) T {
+	if v := T(filter.Normalize(s, prefix)); valid(v) {
+		return v
+	}
+	return T(s)
+}
+
+// short strips prefix and lower-cases value
+func short(value, prefix string) string {
+	return strings.ToLower(strings.TrimPrefix(value, prefix))
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// shortNames lists the short forms of values, for error messages
+func shortNames[T interface {
+	~string
+	Short() string
+}](values []T) string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = v.Short()
+	}
+	return strings.Join(names, ", ")
+}
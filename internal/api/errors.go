@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors an APIError matches with errors.Is, by response status
+var (
+	// ErrUnauthorized means the credentials or token were rejected (401)
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden means the credentials lack permission for the namespace or resource (403)
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound means the resource or namespace does not exist (404)
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited means the API is throttling requests (429)
+	ErrRateLimited = errors.New("rate limited")
+	// ErrBadRequest means the request was invalid, e.g. a malformed filter (400)
+	ErrBadRequest = errors.New("bad request")
+)
+
+// maxErrorBody limits how much of an error response is read
+const maxErrorBody = 64 << 10
+
+// APIError is a non-200 response from the Endor Labs API, with the details
+// of the error body when the API sent one
+type APIError struct {
+	// Endpoint is the resource that was called, e.g. findings
+	Endpoint   string `json:"endpoint"`
+	StatusCode int    `json:"status_code"`
+	// Code is the gRPC status code from the error body, if any
+	Code    int               `json:"code,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Details []json.RawMessage `json:"details,omitempty"`
+	// RequestID identifies the request for Endor Labs support
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: status %d", e.Endpoint, e.StatusCode)
+	if text := http.StatusText(e.StatusCode); text != "" {
+		fmt.Fprintf(&sb, " (%s)", text)
+	}
+	if e.Message != "" {
+		sb.WriteString(": " + e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " [request ID %s]", e.RequestID)
+	}
+	return sb.String()
+}
+
+// Is matches the sentinel error for the response status
+func (e *APIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case http.StatusForbidden:
+		return target == ErrForbidden
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusTooManyRequests:
+		return target == ErrRateLimited
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	}
+	return false
+}
+
+// newAPIError builds an APIError from a failed response, reading its body.
+// Bodies that are not Endor's JSON error format are kept as the message.
+func newAPIError(endpoint string, resp *http.Response) *APIError {
+	e := &APIError{
+		Endpoint:   endpoint,
+		StatusCode: resp.StatusCode,
+		RequestID:  firstHeader(resp.Header, "X-Request-Id", "Request-Id", "X-Envoy-Request-Id"),
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var errBody struct {
+		Code    int               `json:"code"`
+		Message string            `json:"message"`
+		Error   string            `json:"error"`
+		Details []json.RawMessage `json:"details"`
+	}
+	if err := json.Unmarshal(body, &errBody); err == nil {
+		e.Code = errBody.Code
+		e.Message = firstNonEmpty(errBody.Message, errBody.Error)
+		e.Details = errBody.Details
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	return e
+}
+
+func firstHeader(h http.Header, names ...string) string {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
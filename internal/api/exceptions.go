@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ExceptionReason is why findings are excepted, e.g. EXCEPTION_REASON_FALSE_POSITIVE
+type ExceptionReason string
+
+// Exception reasons
+const (
+	ExceptionFalsePositive ExceptionReason = "EXCEPTION_REASON_FALSE_POSITIVE"
+	ExceptionRiskAccepted  ExceptionReason = "EXCEPTION_REASON_RISK_ACCEPTED"
+	ExceptionInTriage      ExceptionReason = "EXCEPTION_REASON_IN_TRIAGE"
+	ExceptionOther         ExceptionReason = "EXCEPTION_REASON_OTHER"
+)
+
+// ExceptionReasonPrefix is the prefix shared by every exception reason
+const ExceptionReasonPrefix = "EXCEPTION_REASON_"
+
+// ExceptionReasons lists the known exception reasons
+var ExceptionReasons = []ExceptionReason{ExceptionFalsePositive, ExceptionRiskAccepted, ExceptionInTriage, ExceptionOther}
+
+// ParseExceptionReason accepts a full reason or its short form, e.g.
+// "false-positive" or "false_positive"
+func ParseExceptionReason(s string) (ExceptionReason, error) {
+	name := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), "-", "_"))
+	if !strings.HasPrefix(name, ExceptionReasonPrefix) {
+		name = ExceptionReasonPrefix + name
+	}
+	r := ExceptionReason(name)
+	if !contains(ExceptionReasons, r) {
+		return "", fmt.Errorf("unknown exception reason %q (expected %s)", s, shortNames(ExceptionReasons))
+	}
+	return r, nil
+}
+
+// Short returns the reason without its prefix in lower case, e.g. "false_positive"
+func (r ExceptionReason) Short() string { return short(string(r), ExceptionReasonPrefix) }
+
+// ExceptionSpec describes an exception policy to create
+type ExceptionSpec struct {
+	// Name of the policy; a name listing the findings is used when empty
+	Name string
+	// Description is free text explaining the decision, e.g. a triage comment
+	Description string
+	Reason      ExceptionReason
+	// Expiration is when the exception stops applying; zero never expires
+	Expiration time.Time
+	// FindingUUIDs are the findings the exception applies to
+	FindingUUIDs []string
+}
+
+// Validate checks that the spec can be sent
+func (s ExceptionSpec) Validate() error {
+	if len(s.FindingUUIDs) == 0 {
+		return fmt.Errorf("an exception needs at least one finding UUID")
+	}
+	for _, uuid := range s.FindingUUIDs {
+		if uuid == "" || strings.ContainsAny(uuid, "\"\\\n") {
+			return fmt.Errorf("invalid finding UUID %q", uuid)
+		}
+	}
+	if !contains(ExceptionReasons, s.Reason) {
+		return fmt.Errorf("unknown exception reason %q (expected %s)", s.Reason, shortNames(ExceptionReasons))
+	}
+	return nil
+}
+
+// exceptionQuery is the Rego query matching the excepted findings
+const exceptionQuery = "data.findings_api_exception.match_finding"
+
+// exceptionRule returns the Rego rule of an exception policy matching the
+// findings with the given UUIDs
+func exceptionRule(uuids []string) string {
+	quoted := make([]string, len(uuids))
+	for i, uuid := range uuids {
+		quoted[i] = fmt.Sprintf("%q", uuid)
+	}
+	return fmt.Sprintf(`package findings_api_exception
+
+excepted := {%s}
+
+match_finding[result] {
+  some i
+  excepted[data.resources.Finding[i].uuid]
+  result := {"Endor": {"Finding": data.resources.Finding[i].uuid}}
+}
+`, strings.Join(quoted, ", "))
+}
+
+// exceptionRequest is the body of a policy create request for an exception
+type exceptionRequest struct {
+	Meta struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	} `json:"meta"`
+	Spec struct {
+		PolicyType      string   `json:"policy_type"`
+		Rule            string   `json:"rule"`
+		QueryStatements []string `json:"query_statements"`
+		ResourceKinds   []string `json:"resource_kinds"`
+		Exception       struct {
+			Reason         ExceptionReason `json:"reason"`
+			ExpirationTime *time.Time      `json:"expiration_time,omitempty"`
+		} `json:"exception"`
+	} `json:"spec"`
+}
+
+// CreateException creates an exception policy in the client's namespace so the
+// findings of spec are excepted from now on, and returns the created policy
+func (c *Client) CreateException(ctx context.Context, token string, spec ExceptionSpec) (*Policy, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	var body exceptionRequest
+	body.Meta.Name = spec.Name
+	if body.Meta.Name == "" {
+		body.Meta.Name = fmt.Sprintf("Exception for finding %s", spec.FindingUUIDs[0])
+		if n := len(spec.FindingUUIDs); n > 1 {
+			body.Meta.Name = fmt.Sprintf("Exception for %d findings", n)
+		}
+	}
+	body.Meta.Description = spec.Description
+	body.Spec.PolicyType = PolicyTypeException
+	body.Spec.Rule = exceptionRule(spec.FindingUUIDs)
+	body.Spec.QueryStatements = []string{exceptionQuery}
+	body.Spec.ResourceKinds = []string{"Finding"}
+	body.Spec.Exception.Reason = spec.Reason
+	if !spec.Expiration.IsZero() {
+		expiry := spec.Expiration.UTC()
+		body.Spec.Exception.ExpirationTime = &expiry
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal exception policy: %w", err)
+	}
+
+	fullURL := fmt.Sprintf("%s/namespaces/%s/policies", c.baseURL, c.namespace)
+	resp, err := c.doWithRetry(ctx, "policies", 0, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", fullURL, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Request-Timeout", "60")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to create exception policy: %w", newAPIError("policies", resp))
+	}
+
+	var policy Policy
+	if err := json.NewDecoder(resp.Body).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &policy, nil
+}
+
+// DismissFinding excepts a single finding for reason, with no expiry
+func (c *Client) DismissFinding(ctx context.Context, token, uuid string, reason ExceptionReason) (*Policy, error) {
+	return c.CreateException(ctx, token, ExceptionSpec{Reason: reason, FindingUUIDs: []string{uuid}})
+}
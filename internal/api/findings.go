@@ -1,11 +1,17 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Finding represents a security finding from Endor Labs
@@ -15,21 +21,72 @@ type Finding struct {
 		Description string `json:"description"`
 		Name        string `json:"name"`
 		ParentUUID  string `json:"parent_uuid"`
+		CreateTime  string `json:"create_time,omitempty"`
 	} `json:"meta"`
+	// Context is the scan context the finding was found in, e.g. the main branch or a CI run
+	Context struct {
+		Type string `json:"type,omitempty"`
+		ID   string `json:"id,omitempty"`
+	} `json:"context"`
 	Spec struct {
-		Approximation               bool              `json:"approximation"`
-		DependencyFilePath          []string          `json:"dependency_file_paths"`
-		Ecosystem                   string            `json:"ecosystem"`
-		Explanation                 string            `json:"explanation"`
-		FindingCategories           []string          `json:"finding_categories"`
-		FindingTags                 []string          `json:"finding_tags"`
-		Level                       string            `json:"level"`
+		Approximation      bool              `json:"approximation"`
+		DependencyFilePath []string          `json:"dependency_file_paths"`
+		Ecosystem          string            `json:"ecosystem"`
+		Explanation        string            `json:"explanation"`
+		FindingCategories  []FindingCategory `json:"finding_categories"`
+		FindingMetadata    struct {
+			Vulnerability *Vulnerability `json:"vulnerability,omitempty"`
+		} `json:"finding_metadata"`
+		FindingTags                 []FindingTag      `json:"finding_tags"`
+		Level                       FindingLevel      `json:"level"`
 		LocationUrls                map[string]string `json:"location_urls"`
 		ProjectUUID                 string            `json:"project_uuid"`
+		ProposedVersion             string            `json:"proposed_version"`
 		Relationship                string            `json:"relationship"`
+		Remediation                 string            `json:"remediation,omitempty"`
+		RemediationAction           string            `json:"remediation_action,omitempty"`
 		Summary                     string            `json:"summary"`
+		TargetDependencyName        string            `json:"target_dependency_name"`
 		TargetDependencyPackageName string            `json:"target_dependency_package_name"`
+		TargetDependencyVersion     string            `json:"target_dependency_version"`
 	} `json:"spec"`
+
+	// Namespace is the namespace the finding belongs to, from its tenant
+	// metadata; a child namespace when the listing traversed into it
+	Namespace string `json:"namespace,omitempty"`
+	// Project is filled in client-side when project resolution is enabled
+	Project *ProjectInfo `json:"project,omitempty"`
+	// Owners is filled in client-side from a CODEOWNERS file
+	Owners []string `json:"owners,omitempty"`
+	// Enrichments holds data added by custom enrichers, keyed by enricher name
+	Enrichments map[string]json.RawMessage `json:"enrichments,omitempty"`
+	// Contexts lists the contexts the finding was found in, set client-side
+	// when findings are fetched from several contexts
+	Contexts []string `json:"contexts,omitempty"`
+	// CorrelationID is set client-side when the same vulnerability and package
+	// are found in other projects with the same repository origin
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// Occurrences counts the findings deduplicated into this one, itself
+	// included; 0 when findings were not deduplicated
+	Occurrences int `json:"occurrences,omitempty"`
+	// Duplicates are the findings deduplicated into this one
+	Duplicates []Occurrence `json:"duplicates,omitempty"`
+	// Extra holds the fields requested by ListOptions.FieldMask that the
+	// model does not cover, keyed by field path, e.g. "spec.remediation"
+	Extra map[string]json.RawMessage `json:"extra,omitempty"`
+}
+
+// Occurrence is a finding merged into another by deduplication
+type Occurrence struct {
+	UUID        string `json:"uuid"`
+	Namespace   string `json:"namespace,omitempty"`
+	ProjectUUID string `json:"project_uuid,omitempty"`
+}
+
+// ProjectInfo is the resolved project a finding belongs to
+type ProjectInfo struct {
+	Name    string `json:"name"`
+	RepoURL string `json:"repo_url,omitempty"`
 }
 
 // FindingsListResponse represents the actual API response structure
@@ -43,186 +100,408 @@ type FindingsListResponse struct {
 	} `json:"list"`
 }
 
-// GetFindings retrieves all findings for a specific project
-func (c *Client) GetFindings(token, projectUUID string) ([]Finding, error) {
-	var allFindings []Finding
-	pageSize := 100
-	pageCount := 0
-	var nextPageID string
-
-	for {
-		pageCount++
-		findings, newNextPageID, _, err := c.getFindingsPage(token, projectUUID, pageSize, nextPageID)
-		if err != nil {
-			return nil, err
-		}
-
-		log.Printf("Page %d: Found %d findings", pageCount, len(findings))
-
-		allFindings = append(allFindings, findings...)
-
-		// Update nextPageID for the next iteration
-		nextPageID = newNextPageID
-
-		// Break if no next_page_id (means no more pages) - exactly like Python script
-		if nextPageID == "" {
-			log.Printf("No more pages to fetch. Total pages: %d", pageCount)
-			break
-		}
-
-		log.Printf("Next Page ID: %s", nextPageID)
+// GetFindings retrieves all findings for a specific project that match filter.
+// When the listing hits the page cap (see ListOptions.MaxPages), the findings
+// fetched so far are returned with a *TruncatedError.
+func (c *Client) GetFindings(ctx context.Context, token, projectUUID, filter string) ([]Finding, error) {
+	return c.listFindings(ctx, token, projectFilter(projectUUID, filter))
+}
 
-		// Safety check to prevent infinite loops
-		if pageCount > 100 {
-			log.Printf("Safety limit reached: %d pages. Stopping pagination.", pageCount)
-			break
-		}
+// FindingsPageFunc receives the findings of each page as it is fetched;
+// returning an error stops the listing with that error
+type FindingsPageFunc func(findings []Finding) error
+
+// StreamFindings calls fn with the findings of each page matching filter as
+// the page arrives, without keeping earlier pages in memory. An empty
+// projectUUID streams the findings of every project. Findings repeated on
+// later pages are dropped, as with GetFindings.
+func (c *Client) StreamFindings(ctx context.Context, token, projectUUID, filter string, fn FindingsPageFunc) error {
+	if projectUUID != "" {
+		filter = projectFilter(projectUUID, filter)
 	}
-
-	return allFindings, nil
+	return c.walkFindings(ctx, token, filter, fn)
 }
 
-// getFindingsPage retrieves a single page of findings
-func (c *Client) getFindingsPage(token, projectUUID string, pageSize int, pageID string) ([]Finding, string, bool, error) {
-	baseURL := fmt.Sprintf("%s/namespaces/%s/findings", BaseURL, c.namespace)
-
-	// Create query parameters using the exact working filter from endorctl
-	params := url.Values{}
-
-	// Exact filter from the working endorctl command
-	complexFilter := fmt.Sprintf(`spec.project_uuid==%s and context.type == "CONTEXT_TYPE_MAIN" and (spec.level in ["FINDING_LEVEL_CRITICAL"] and spec.finding_tags not contains ["FINDING_TAGS_EXCEPTION"] and spec.finding_categories contains ["FINDING_CATEGORY_VULNERABILITY"] and (spec.finding_tags contains ["FINDING_TAGS_POTENTIALLY_REACHABLE_FUNCTION","FINDING_TAGS_REACHABLE_FUNCTION"] and spec.finding_tags contains ["FINDING_TAGS_REACHABLE_DEPENDENCY"] and spec.finding_tags contains ["FINDING_TAGS_FIX_AVAILABLE"] and spec.finding_tags contains ["FINDING_TAGS_NORMAL"]) and spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 0.01)`, projectUUID)
-
-	params.Set("list_parameters.filter", complexFilter)
-	// Use the exact field mask from the working endorctl command
-	params.Set("list_parameters.mask", "meta.description,meta.name,meta.parent_uuid,spec.approximation,spec.dependency_file_paths,spec.ecosystem,spec.explanation,spec.finding_categories,spec.finding_tags,spec.level,spec.location_urls,spec.project_uuid,spec.relationship,spec.summary,spec.target_dependency_package_name")
-	params.Set("list_parameters.page_size", fmt.Sprintf("%d", pageSize))
-	params.Set("list_parameters.traverse", "true") // Enable searching through child namespaces
+// projectFilter limits filter to the findings of one project
+func projectFilter(projectUUID, filter string) string {
+	return andFilter(fmt.Sprintf("spec.project_uuid==%s", projectUUID), filter)
+}
 
-	// Add page_id for pagination if provided
-	if pageID != "" {
-		params.Set("list_parameters.page_id", pageID)
+// andFilter joins clause and the caller's filter with "and", parenthesizing
+// the filter so an "or" in it cannot escape the clause
+func andFilter(clause, filter string) string {
+	if filter == "" {
+		return clause
 	}
+	return fmt.Sprintf("%s and (%s)", clause, filter)
+}
 
-	// Add the query string to the URL
-	fullURL := baseURL + "?" + params.Encode()
+// GetFindingsForAllProjects retrieves findings for all projects (without project_uuid filter)
+func (c *Client) GetFindingsForAllProjects(ctx context.Context, token, filter string) ([]Finding, error) {
+	return c.listFindings(ctx, token, filter)
+}
 
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to create request: %w", err)
-	}
+// DefaultConcurrency is the number of projects fetched in parallel by GetFindingsForProjects
+const DefaultConcurrency = 4
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Request-Timeout", "600")
+// ProjectFindings holds the findings fetched for one project, or the error that prevented it
+type ProjectFindings struct {
+	ProjectUUID string
+	Findings    []Finding
+	Err         error
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to send request: %w", err)
+// GetFindingsForProjects fetches findings for many projects in parallel using at most
+// concurrency workers. A failing project does not stop the others; its error is
+// reported in its result. Results are returned in the order of projectUUIDs.
+func (c *Client) GetFindingsForProjects(ctx context.Context, token string, projectUUIDs []string, filter string, concurrency int) []ProjectFindings {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", false, fmt.Errorf("failed to fetch findings with status: %d", resp.StatusCode)
+	results := make([]ProjectFindings, len(projectUUIDs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(projectUUIDs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				findings, err := c.GetFindings(ctx, token, projectUUIDs[i], filter)
+				results[i] = ProjectFindings{
+					ProjectUUID: projectUUIDs[i],
+					Findings:    findings,
+					Err:         err,
+				}
+			}
+		}()
 	}
 
-	var findingsResp FindingsListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&findingsResp); err != nil {
-		return nil, "", false, fmt.Errorf("failed to decode response: %w", err)
+	for i := range projectUUIDs {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
 
-	// Check if there are more pages by looking at next_page_id
-	hasMore := findingsResp.List.Response.NextPageID != ""
-
-	return findingsResp.List.Objects, findingsResp.List.Response.NextPageID, hasMore, nil
+	return results
 }
 
-// GetFindingsForAllProjects retrieves findings for all projects (without project_uuid filter)
-func (c *Client) GetFindingsForAllProjects(token string) ([]Finding, error) {
+// listFindings collects every page of findings matching filter. A listing
+// stopped by the page cap returns its findings with a *TruncatedError.
+func (c *Client) listFindings(ctx context.Context, token, filter string) ([]Finding, error) {
 	var allFindings []Finding
-	pageSize := 100
+	err := c.walkFindings(ctx, token, filter, func(findings []Finding) error {
+		allFindings = append(allFindings, findings...)
+		return nil
+	})
+	var truncated *TruncatedError
+	if errors.As(err, &truncated) {
+		return allFindings, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	return allFindings, nil
+}
+
+// walkFindings walks every page of findings matching filter, handing each to
+// fn. Findings repeated on later pages are dropped, and a page ID seen before
+// fails the listing rather than looping over the same pages. With a checkpoint,
+// every page is recorded, and a listing found in it is replayed and continues
+// after its last recorded page.
+func (c *Client) walkFindings(ctx context.Context, token, filter string, fn FindingsPageFunc) error {
+	pageSize := c.pageSize
+	if limit := c.list.Limit; limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
 	pageCount := 0
 	var nextPageID string
+	pages := newPageTracker("findings")
+	seen := make(map[string]bool)
+	duplicates := 0
+	listed := 0
+
+	// A listing recorded in the checkpoint continues after its last page
+	key := c.checkpointKey(filter)
+	if saved, ok := c.checkpoint.resume(key); ok {
+		for _, f := range saved.findings {
+			seen[f.UUID] = true
+		}
+		listed = len(saved.findings)
+		c.reportProgress(listed)
+		if err := fn(saved.findings); err != nil {
+			return err
+		}
+		if saved.done {
+			slog.Info("Resumed a completed listing from the checkpoint", "findings", listed)
+			return nil
+		}
+		slog.Info("Resuming a listing from the checkpoint", "after_page", saved.pages, "findings", listed)
+		pageCount, nextPageID = saved.pages, saved.nextPageID
+		if err := pages.next(nextPageID); err != nil {
+			return err
+		}
+	}
+
+	// Once pages are numbered by their tokens, later pages are prefetched
+	var prefetch *pagePrefetcher
+	defer func() {
+		if prefetch != nil {
+			prefetch.stop()
+		}
+	}()
 
 	for {
 		pageCount++
-		findings, newNextPageID, _, err := c.getFindingsPageForAllProjects(token, pageSize, nextPageID)
+		var page findingsPage
+		var err error
+		if prefetch != nil {
+			page, err = prefetch.get(pageCount)
+		} else {
+			page, err = c.getFindingsPage(ctx, token, filter, pageCount, pageSize, nextPageID, 0)
+		}
 		if err != nil {
-			return nil, err
+			return err
+		}
+		findings := page.Findings
+
+		slog.Debug("Fetched findings page", "page", pageCount, "findings", len(findings))
+
+		unique := findings[:0]
+		for _, f := range findings {
+			if f.UUID != "" && seen[f.UUID] {
+				duplicates++
+				continue
+			}
+			seen[f.UUID] = true
+			unique = append(unique, f)
+		}
+		limited := c.list.Limit > 0 && listed+len(unique) >= c.list.Limit
+		if limited {
+			unique = unique[:c.list.Limit-listed]
+		}
+		listed += len(unique)
+		c.reportProgress(len(unique))
+		// A listing stopped by the page cap stays open, to resume with a higher cap
+		done := limited || page.NextPageID == ""
+		c.checkpoint.record(key, pageCount, page.NextPageID, done, unique)
+		if err := fn(unique); err != nil {
+			return err
+		}
+		if limited {
+			slog.Info("Findings limit reached", "limit", c.list.Limit, "page", pageCount)
+			break
 		}
-
-		log.Printf("Page %d: Found %d findings", pageCount, len(findings))
-
-		allFindings = append(allFindings, findings...)
 
 		// Update nextPageID for the next iteration
-		nextPageID = newNextPageID
+		nextPageID = page.NextPageID
+
+		// Tokens numbering the pages make the next pages known in advance
+		numbered := page.NextPageToken == pageCount+1
+		switch {
+		case prefetch == nil && numbered && c.list.Prefetch > 0 && nextPageID != "":
+			slog.Debug("Prefetching pages", "ahead", c.list.Prefetch)
+			prefetch = newPagePrefetcher(ctx, c.list.Prefetch, pageCount+1, func(ctx context.Context, n int) (findingsPage, error) {
+				return c.getFindingsPage(ctx, token, filter, n, pageSize, "", n)
+			})
+		case prefetch != nil && !numbered && nextPageID != "":
+			slog.Debug("Page broke the page token sequence, fetching the remaining pages one at a time", "page", pageCount)
+			prefetch.stop()
+			prefetch = nil
+		}
 
 		// Break if no next_page_id (means no more pages) - exactly like Python script
 		if nextPageID == "" {
-			log.Printf("No more pages to fetch. Total pages: %d", pageCount)
+			slog.Debug("No more pages to fetch", "pages", pageCount)
 			break
 		}
+		if err := pages.next(nextPageID); err != nil {
+			return err
+		}
 
-		log.Printf("Next Page ID: %s", nextPageID)
-
-		// Safety check to prevent infinite loops
-		if pageCount > 100 {
-			log.Printf("Safety limit reached: %d pages. Stopping pagination.", pageCount)
-			break
+		slog.Debug("Next page", "next_page_id", nextPageID)
+
+		// The page cap stops runaway listings, reporting them as incomplete
+		if max := c.list.pageCap(); max > 0 && pageCount >= max {
+			slog.Warn("Page cap reached, stopping pagination", "pages", pageCount)
+			c.warnings.record(Warning{
+				Code:     WarningPaginationStopped,
+				Message:  fmt.Sprintf("stopped after %d pages, later findings are missing", pageCount),
+				Resource: "findings",
+				Page:     pageCount,
+			})
+			return &TruncatedError{Resource: "findings", Pages: pageCount}
 		}
 	}
 
-	return allFindings, nil
+	if duplicates > 0 {
+		c.warnings.record(Warning{
+			Code:     WarningDuplicateObject,
+			Message:  fmt.Sprintf("dropped %d findings repeated across pages", duplicates),
+			Resource: "findings",
+		})
+	}
+	return nil
 }
 
-// getFindingsPageForAllProjects retrieves a single page of findings for all projects
-func (c *Client) getFindingsPageForAllProjects(token string, pageSize int, pageID string) ([]Finding, string, bool, error) {
-	baseURL := fmt.Sprintf("%s/namespaces/%s/findings", BaseURL, c.namespace)
+// reportProgress tells the progress function, if any, of a page of findings
+func (c *Client) reportProgress(findings int) {
+	if c.progress != nil {
+		c.progress(findings)
+	}
+}
 
-	// Create query parameters using the same filter but WITHOUT project_uuid
-	params := url.Values{}
+// findingsMask is the field mask from the working endorctl command, plus the UUID,
+// namespace, creation time, context, dependency version, remediation and vulnerability
+// metadata fields
+var findingsMask = "uuid,tenant_meta.namespace,meta.description,meta.name,meta.parent_uuid,meta.create_time,context.type,context.id,spec.approximation,spec.dependency_file_paths,spec.ecosystem,spec.explanation,spec.finding_categories,spec.finding_tags,spec.level,spec.location_urls,spec.project_uuid,spec.proposed_version,spec.relationship,spec.remediation,spec.remediation_action,spec.summary,spec.target_dependency_name,spec.target_dependency_package_name,spec.target_dependency_version," + strings.Join(vulnerabilityMask, ",")
+
+// findingsPage is one page of findings with the cursors of the next page
+type findingsPage struct {
+	Findings      []Finding
+	NextPageID    string
+	NextPageToken int
+}
 
-	// Filter for all projects (removed spec.project_uuid requirement) - updated to include both CRITICAL and HIGH
-	complexFilter := `context.type == "CONTEXT_TYPE_MAIN" and (spec.level in ["FINDING_LEVEL_CRITICAL","FINDING_LEVEL_HIGH"] and spec.finding_tags not contains ["FINDING_TAGS_EXCEPTION"] and spec.finding_categories contains ["FINDING_CATEGORY_VULNERABILITY"] and (spec.finding_tags contains ["FINDING_TAGS_POTENTIALLY_REACHABLE_FUNCTION","FINDING_TAGS_REACHABLE_FUNCTION"] and spec.finding_tags contains ["FINDING_TAGS_REACHABLE_DEPENDENCY"] and spec.finding_tags contains ["FINDING_TAGS_FIX_AVAILABLE"] and spec.finding_tags contains ["FINDING_TAGS_NORMAL"]) and spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 0.01)`
+// getFindingsPage retrieves a single page of findings, the one after pageID,
+// or else the one numbered pageToken
+func (c *Client) getFindingsPage(ctx context.Context, token, filter string, page, pageSize int, pageID string, pageToken int) (findingsPage, error) {
+	baseURL := fmt.Sprintf("%s/namespaces/%s/findings", c.baseURL, c.namespace)
 
-	params.Set("list_parameters.filter", complexFilter)
-	// Use the exact field mask from the working endorctl command
-	params.Set("list_parameters.mask", "meta.description,meta.name,meta.parent_uuid,spec.approximation,spec.dependency_file_paths,spec.ecosystem,spec.explanation,spec.finding_categories,spec.finding_tags,spec.level,spec.location_urls,spec.project_uuid,spec.relationship,spec.summary,spec.target_dependency_package_name")
+	params := url.Values{}
+
+	if filter != "" {
+		params.Set("list_parameters.filter", filter)
+	}
+	params.Set("list_parameters.mask", c.list.fieldMask())
 	params.Set("list_parameters.page_size", fmt.Sprintf("%d", pageSize))
-	params.Set("list_parameters.traverse", "true") // Enable searching through child namespaces
+	if order, ok := sortOrders[c.list.Sort]; ok {
+		params.Set("list_parameters.sort.path", order.path)
+		if order.desc {
+			params.Set("list_parameters.sort.order", "SORT_ENTRY_ORDER_DESC")
+		}
+	}
+	if !c.list.NoTraverse {
+		params.Set("list_parameters.traverse", "true") // Enable searching through child namespaces
+	}
 
 	// Add page_id for pagination if provided (this should be the next_page_id from previous response)
 	if pageID != "" {
 		params.Set("list_parameters.page_id", pageID)
+	} else if pageToken > 0 {
+		params.Set("list_parameters.page_token", strconv.Itoa(pageToken))
 	}
 
 	// Add the query string to the URL
 	fullURL := baseURL + "?" + params.Encode()
 
-	req, err := http.NewRequest("GET", fullURL, nil)
-	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Request-Timeout", "600")
+	fetchStart := time.Now()
+	resp, err := c.doWithRetry(ctx, "findings", page, func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", fullURL, nil)
+		if err != nil {
+			return nil, err
+		}
 
-	resp, err := c.httpClient.Do(req)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Request-Timeout", "600")
+		return req, nil
+	})
 	if err != nil {
-		return nil, "", false, fmt.Errorf("failed to send request: %w", err)
+		return findingsPage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", false, fmt.Errorf("failed to fetch findings with status: %d", resp.StatusCode)
+		return findingsPage{}, fmt.Errorf("failed to fetch findings: %w", newAPIError("findings", resp))
 	}
 
-	var findingsResp FindingsListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&findingsResp); err != nil {
-		return nil, "", false, fmt.Errorf("failed to decode response: %w", err)
+	fetchDuration := time.Since(fetchStart)
+
+	body := &countingReader{r: resp.Body}
+	decodeStart := time.Now()
+	// Objects are decoded one by one so a malformed finding is skipped rather than
+	// failing the whole page
+	var findingsResp listResponse[json.RawMessage]
+	if err := json.NewDecoder(body).Decode(&findingsResp); err != nil {
+		return findingsPage{}, fmt.Errorf("failed to decode response: %w", err)
 	}
+	findings := c.decodeFindings(findingsResp.List.Objects, page)
+	c.telemetry.record(PageStats{
+		Endpoint:       "findings",
+		Page:           page,
+		PageSize:       pageSize,
+		Objects:        len(findingsResp.List.Objects),
+		Bytes:          body.n,
+		FetchDuration:  fetchDuration,
+		DecodeDuration: time.Since(decodeStart),
+	})
+
+	return findingsPage{
+		Findings:      findings,
+		NextPageID:    findingsResp.List.Response.NextPageID,
+		NextPageToken: findingsResp.List.Response.NextPageToken,
+	}, nil
+}
 
-	// Check if there are more pages by looking at next_page_id
-	hasMore := findingsResp.List.Response.NextPageID != ""
+// decodeFindings decodes the raw findings of a page, recording a warning for each
+// finding that is skipped or lacks fields the tool relies on
+func (c *Client) decodeFindings(objects []json.RawMessage, page int) []Finding {
+	extra := c.list.extraFields()
+	findings := make([]Finding, 0, len(objects))
+	for i, raw := range objects {
+		// The namespace comes from tenant_meta, which the model leaves out
+		var decoded struct {
+			Finding
+			TenantMeta struct {
+				Namespace string `json:"namespace"`
+			} `json:"tenant_meta"`
+		}
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			c.warnings.record(Warning{
+				Code:     WarningMalformedFinding,
+				Message:  fmt.Sprintf("skipped object %d: %v", i, err),
+				Resource: "findings",
+				Page:     page,
+			})
+			continue
+		}
+		f := decoded.Finding
+		f.Namespace = decoded.TenantMeta.Namespace
+		if f.Namespace == "" {
+			f.Namespace = c.namespace
+		}
+		if f.UUID == "" {
+			c.warnings.record(Warning{
+				Code:     WarningMalformedFinding,
+				Message:  fmt.Sprintf("skipped object %d without a uuid", i),
+				Resource: "findings",
+				Page:     page,
+			})
+			continue
+		}
 
-	return findingsResp.List.Objects, findingsResp.List.Response.NextPageID, hasMore, nil
+		var missing []string
+		if f.Spec.Level == "" {
+			missing = append(missing, "spec.level")
+		}
+		if f.Spec.ProjectUUID == "" {
+			missing = append(missing, "spec.project_uuid")
+		}
+		if len(missing) > 0 {
+			c.warnings.record(Warning{
+				Code:     WarningMissingField,
+				Message:  "missing " + strings.Join(missing, ", "),
+				Resource: "findings",
+				UUID:     f.UUID,
+				Page:     page,
+			})
+		}
+
+		if len(extra) > 0 {
+			f.Extra = extraFields(raw, extra)
+		}
+		findings = append(findings, f)
+	}
+	return findings
 }
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// groupResponse is the envelope of a list request made with
+// list_parameters.group. Each group is keyed by the JSON list of the
+// aggregation paths and their values, e.g.
+// [{"key":"spec.level","value":"FINDING_LEVEL_CRITICAL"}].
+type groupResponse struct {
+	GroupResponse struct {
+		Groups map[string]struct {
+			AggregationCount struct {
+				Count int `json:"count"`
+			} `json:"aggregation_count"`
+		} `json:"groups"`
+	} `json:"group_response"`
+}
+
+// groupKey is one aggregation path of a group and its value
+type groupKey struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// FindingGroup is the findings sharing the values of the fields grouped by
+type FindingGroup struct {
+	// Values maps each field grouped by to the value of the group's
+	// findings, "" for findings without it
+	Values map[string]string `json:"values"`
+	Count  int               `json:"count"`
+}
+
+// GroupFindings returns the findings matching filter in the projects with the
+// given UUIDs, or in every project when there are none, grouped by the fields
+// of ListOptions.GroupBy. The API groups them, so only a count per group is
+// transferred rather than every finding. A finding whose field is a list,
+// such as spec.finding_categories, counts in a group per value. Groups are
+// sorted by count, largest first.
+func (c *Client) GroupFindings(ctx context.Context, token string, projectUUIDs []string, filter string) ([]FindingGroup, error) {
+	if len(c.list.GroupBy) == 0 {
+		return nil, errors.New("no fields to group findings by")
+	}
+	return c.groupFindings(ctx, token, projectUUIDs, filter, c.list.GroupBy)
+}
+
+// groupFindings groups the findings of projectUUIDs, or of every project,
+// that match filter by the fields at paths
+func (c *Client) groupFindings(ctx context.Context, token string, projectUUIDs []string, filter string, paths []string) ([]FindingGroup, error) {
+	params := c.countParams(projectUUIDs, filter)
+	params.Set("list_parameters.group.aggregation_paths", strings.Join(paths, ","))
+
+	var resp groupResponse
+	if err := c.getFindingsAggregate(ctx, token, params, &resp); err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	var groups []FindingGroup
+	for key, group := range resp.GroupResponse.Groups {
+		var keys []groupKey
+		if err := json.Unmarshal([]byte(key), &keys); err != nil {
+			return nil, fmt.Errorf("failed to decode group %q: %w", key, err)
+		}
+		values := make(map[string][]string, len(paths))
+		for _, k := range keys {
+			v, err := groupValues(k.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode group %q: %w", key, err)
+			}
+			values[k.Key] = v
+		}
+		// Groups of list values split into a group per combination, which
+		// may meet groups the API returned separately
+		for _, combo := range combinations(paths, values) {
+			id := strings.Join(combo, "\x00")
+			i, ok := index[id]
+			if !ok {
+				i = len(groups)
+				index[id] = i
+				g := FindingGroup{Values: make(map[string]string, len(paths))}
+				for j, path := range paths {
+					g.Values[path] = combo[j]
+				}
+				groups = append(groups, g)
+			}
+			groups[i].Count += group.AggregationCount.Count
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		for _, path := range paths {
+			if a, b := groups[i].Values[path], groups[j].Values[path]; a != b {
+				return a < b
+			}
+		}
+		return false
+	})
+	return groups, nil
+}
+
+// combinations returns every choice of one value per path, in path order; a
+// path without values takes ""
+func combinations(paths []string, values map[string][]string) [][]string {
+	combos := [][]string{{}}
+	for _, path := range paths {
+		vs := values[path]
+		if len(vs) == 0 {
+			vs = []string{""}
+		}
+		next := make([][]string, 0, len(combos)*len(vs))
+		for _, combo := range combos {
+			for _, v := range vs {
+				next = append(next, append(append([]string{}, combo...), v))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// groupValues decodes the value of a group key: a string, a list of strings,
+// or null for objects without the field
+func groupValues(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return []string{""}, nil
+	}
+	var values []string
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return []string{""}, nil
+		}
+		return values, nil
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	if s, ok := value.(string); ok {
+		return []string{s}, nil
+	}
+	return []string{string(raw)}, nil
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxPages is the page cap of listings without ListOptions.MaxPages
+const DefaultMaxPages = 100
+
+// TruncatedError reports a listing stopped by the page cap while pages were
+// left, so the objects listed are incomplete
+type TruncatedError struct {
+	Resource string
+	Pages    int
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("stopped listing %s at the page cap (%d) with more pages left; raise the cap to list them all", e.Resource, e.Pages)
+}
+
+// listResponse is the envelope shared by Endor list endpoints
+type listResponse[T any] struct {
+	List struct {
+		Objects  []T `json:"objects"`
+		Response struct {
+			NextPageID    string `json:"next_page_id"`
+			NextPageToken int    `json:"next_page_token"`
+		} `json:"response"`
+	} `json:"list"`
+}
+
+// listAll fetches every page of resource in the client's namespace.
+// params holds the list parameters; page_id is managed here.
+func listAll[T any](ctx context.Context, c *Client, token, resource string, params url.Values) ([]T, error) {
+	var all []T
+	var pageID string
+	pages := newPageTracker(resource)
+
+	for page := 1; ; page++ {
+		objects, nextPageID, err := listPage[T](ctx, c, token, resource, page, params, pageID)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, objects...)
+
+		if nextPageID == "" {
+			break
+		}
+		if err := pages.next(nextPageID); err != nil {
+			return nil, err
+		}
+		pageID = nextPageID
+
+		if max := c.list.pageCap(); max > 0 && page >= max {
+			return all, &TruncatedError{Resource: resource, Pages: page}
+		}
+	}
+
+	return all, nil
+}
+
+// pageTracker detects a list endpoint handing out a page ID it returned
+// before, which would otherwise make the client fetch the same pages forever
+type pageTracker struct {
+	resource string
+	seen     map[string]bool
+}
+
+func newPageTracker(resource string) *pageTracker {
+	return &pageTracker{resource: resource, seen: make(map[string]bool)}
+}
+
+// next records the page ID about to be requested and fails if it was requested before
+func (t *pageTracker) next(pageID string) error {
+	if t.seen[pageID] {
+		return fmt.Errorf("failed to list %s: the API returned page ID %q twice, pagination would never end", t.resource, pageID)
+	}
+	t.seen[pageID] = true
+	return nil
+}
+
+// listPage fetches a single page of resource
+func listPage[T any](ctx context.Context, c *Client, token, resource string, page int, params url.Values, pageID string) ([]T, string, error) {
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	pageSize, err := strconv.Atoi(query.Get("list_parameters.page_size"))
+	if err != nil {
+		pageSize = c.pageSize
+		query.Set("list_parameters.page_size", strconv.Itoa(pageSize))
+	}
+	if pageID != "" {
+		query.Set("list_parameters.page_id", pageID)
+	}
+
+	fullURL := fmt.Sprintf("%s/namespaces/%s/%s?%s", c.baseURL, c.namespace, resource, query.Encode())
+
+	fetchStart := time.Now()
+	resp, err := c.doWithRetry(ctx, resource, page, func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", fullURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Request-Timeout", "60")
+		return req, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", resource, newAPIError(resource, resp))
+	}
+
+	fetchDuration := time.Since(fetchStart)
+
+	body := &countingReader{r: resp.Body}
+	decodeStart := time.Now()
+	var listResp listResponse[T]
+	if err := json.NewDecoder(body).Decode(&listResp); err != nil {
+		return nil, "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	c.telemetry.record(PageStats{
+		Endpoint:       resource,
+		Page:           page,
+		PageSize:       pageSize,
+		Objects:        len(listResp.List.Objects),
+		Bytes:          body.n,
+		FetchDuration:  fetchDuration,
+		DecodeDuration: time.Since(decodeStart),
+	})
+
+	return listResp.List.Objects, listResp.List.Response.NextPageID, nil
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ListOptions tune the findings list requests of a client
+type ListOptions struct {
+	// FieldMask lists the finding fields the API returns, replacing the
+	// default mask. If any field starts with +, the listed fields are added to
+	// the default mask instead. uuid, spec.level and spec.project_uuid are
+	// always requested, since findings are checked by them. Fields the
+	// Finding model does not cover are kept in Finding.Extra.
+	FieldMask []string
+	// Sort orders the findings server-side by one of SortKeys; empty keeps
+	// the API's order
+	Sort string
+	// Limit stops a listing once it has this many findings (0 is unlimited)
+	Limit int
+	// NoTraverse lists the findings of the client's namespace only, leaving
+	// out its child namespaces
+	NoTraverse bool
+	// MaxPages caps the pages of each listing: 0 uses DefaultMaxPages and a
+	// negative value removes the cap. A listing stopped by the cap returns
+	// what it fetched with a *TruncatedError rather than as if complete.
+	MaxPages int
+	// Prefetch fetches up to this many pages ahead concurrently while the
+	// current page is processed, once the API answers with page tokens that
+	// number the pages. 0 fetches one page at a time.
+	Prefetch int
+	// GroupBy lists the finding fields GroupFindings groups by, e.g.
+	// spec.target_dependency_package_name; listings ignore it
+	GroupBy []string
+}
+
+// Sort keys of findings lists
+const (
+	SortLevel   = "level"
+	SortEPSS    = "epss"
+	SortPackage = "package"
+	SortCreated = "created"
+)
+
+// SortKeys lists the supported sort keys
+var SortKeys = []string{SortLevel, SortEPSS, SortPackage, SortCreated}
+
+// sortOrder is how a sort key is sent to the API and applied client-side
+type sortOrder struct {
+	path string
+	desc bool
+	// less orders findings the same way, for merging sorted lists
+	less func(a, b Finding) bool
+}
+
+// sortOrders orders the riskiest, or newest, findings first
+var sortOrders = map[string]sortOrder{
+	SortLevel: {"spec.level", true, func(a, b Finding) bool { return a.Spec.Level.Rank() > b.Spec.Level.Rank() }},
+	SortEPSS: {"spec.finding_metadata.vulnerability.spec.epss_score.probability_score", true, func(a, b Finding) bool {
+		return epssProbability(a) > epssProbability(b)
+	}},
+	SortPackage: {"spec.target_dependency_package_name", false, func(a, b Finding) bool {
+		return a.Spec.TargetDependencyPackageName < b.Spec.TargetDependencyPackageName
+	}},
+	SortCreated: {"meta.create_time", true, func(a, b Finding) bool { return a.Meta.CreateTime > b.Meta.CreateTime }},
+}
+
+// Validate checks the sort key, limit, prefetch and group fields
+func (o ListOptions) Validate() error {
+	if _, ok := sortOrders[o.Sort]; o.Sort != "" && !ok {
+		return fmt.Errorf("unsupported sort %q (expected %s)", o.Sort, strings.Join(SortKeys, ", "))
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("invalid limit %d (expected a positive number, or 0 for no limit)", o.Limit)
+	}
+	if o.Prefetch < 0 {
+		return fmt.Errorf("invalid prefetch %d (expected a positive number, or 0 to fetch pages one at a time)", o.Prefetch)
+	}
+	for _, path := range o.GroupBy {
+		if path == "" || strings.ContainsAny(path, ", ") {
+			return fmt.Errorf("invalid group field %q (expected a field path such as spec.level)", path)
+		}
+	}
+	return nil
+}
+
+// ApplyTo sorts findings merged from several listings the way the API sorts
+// each of them, and truncates them to the limit
+func (o ListOptions) ApplyTo(findings []Finding) []Finding {
+	if order, ok := sortOrders[o.Sort]; ok {
+		sort.SliceStable(findings, func(i, j int) bool { return order.less(findings[i], findings[j]) })
+	}
+	if o.Limit > 0 && len(findings) > o.Limit {
+		findings = findings[:o.Limit]
+	}
+	return findings
+}
+
+// pageCap returns the page cap of a listing, 0 for none
+func (o ListOptions) pageCap() int {
+	switch {
+	case o.MaxPages == 0:
+		return DefaultMaxPages
+	case o.MaxPages < 0:
+		return 0
+	}
+	return o.MaxPages
+}
+
+// ListOptions returns the options of the client's findings list requests
+func (c *Client) ListOptions() ListOptions {
+	return c.list
+}
+
+func epssProbability(f Finding) float64 {
+	if e := f.EPSS(); e != nil {
+		return e.ProbabilityScore
+	}
+	return 0
+}
+
+// WithListOptions sets the options of the client's findings list requests
+func WithListOptions(opts ListOptions) Option {
+	return func(c *Client) { c.list = opts }
+}
+
+// requiredFindingsFields are requested whatever the field mask
+var requiredFindingsFields = []string{"uuid", "spec.level", "spec.project_uuid"}
+
+// DefaultFindingsFields returns the fields requested without a FieldMask
+func DefaultFindingsFields() []string {
+	return strings.Split(findingsMask, ",")
+}
+
+// fieldMask returns the list_parameters.mask of the findings list requests
+func (o ListOptions) fieldMask() string {
+	if len(o.FieldMask) == 0 {
+		return findingsMask
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	add := func(field string) {
+		if field != "" && !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	for _, f := range o.FieldMask {
+		if strings.HasPrefix(strings.TrimSpace(f), "+") {
+			for _, d := range DefaultFindingsFields() {
+				add(d)
+			}
+			break
+		}
+	}
+	for _, f := range requiredFindingsFields {
+		add(f)
+	}
+	for _, f := range o.FieldMask {
+		add(strings.TrimPrefix(strings.TrimSpace(f), "+"))
+	}
+	return strings.Join(fields, ",")
+}
+
+// extraFields returns the fields of the mask that are not in the default
+// mask, and so not decoded into the Finding model
+func (o ListOptions) extraFields() []string {
+	if len(o.FieldMask) == 0 {
+		return nil
+	}
+	known := make(map[string]bool)
+	for _, f := range DefaultFindingsFields() {
+		known[f] = true
+	}
+	var extra []string
+	for _, f := range strings.Split(o.fieldMask(), ",") {
+		if !known[f] {
+			extra = append(extra, f)
+		}
+	}
+	return extra
+}
+
+// extraFields extracts the values at the given dotted paths of a raw finding;
+// paths the finding lacks are left out
+func extraFields(raw json.RawMessage, paths []string) map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage)
+	for _, path := range paths {
+		value := raw
+		for _, key := range strings.Split(path, ".") {
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(value, &obj); err != nil {
+				value = nil
+				break
+			}
+			value = obj[key]
+			if value == nil {
+				break
+			}
+		}
+		if value != nil {
+			values[path] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
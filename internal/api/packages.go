@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PackageVersion is a package version of a project's inventory, such as the
+// package built from one manifest, with the dependencies resolved for it
+type PackageVersion struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		// Name is the package version, e.g. npm://payments@1.4.0
+		Name       string `json:"name"`
+		CreateTime string `json:"create_time,omitempty"`
+	} `json:"meta"`
+	Spec struct {
+		Ecosystem   string `json:"ecosystem"`
+		ProjectUUID string `json:"project_uuid"`
+		// RelativePath is the manifest the package version was found in
+		RelativePath         string `json:"relative_path,omitempty"`
+		ResolvedDependencies struct {
+			Dependencies []ResolvedDependency `json:"dependencies"`
+		} `json:"resolved_dependencies"`
+	} `json:"spec"`
+}
+
+// ResolvedDependency is one dependency resolved for a package version
+type ResolvedDependency struct {
+	// Name is the dependency package version, e.g. npm://lodash@4.17.21
+	Name   string `json:"name"`
+	Public bool   `json:"public,omitempty"`
+}
+
+// PackageName returns the name of the package version without its version,
+// e.g. npm://payments
+func (p PackageVersion) PackageName() string {
+	name, _ := splitPackageVersion(p.Meta.Name)
+	return name
+}
+
+// Version returns the version of the package version, e.g. 1.4.0
+func (p PackageVersion) Version() string {
+	_, version := splitPackageVersion(p.Meta.Name)
+	return version
+}
+
+// splitPackageVersion splits ecosystem://name@version at its last @, leaving
+// the @ of scoped npm packages such as npm://@acme/ui@1.0.0 in the name
+func splitPackageVersion(s string) (name, version string) {
+	if i := strings.LastIndex(s, "@"); i > 0 && !strings.HasSuffix(s[:i], "/") {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// packageVersionMask lists the package version fields requested
+const packageVersionMask = "uuid,meta.name,meta.create_time,spec.ecosystem,spec.project_uuid,spec.relative_path,spec.resolved_dependencies.dependencies"
+
+// ListPackageVersions retrieves every package version of a project with its
+// resolved dependencies
+func (c *Client) ListPackageVersions(ctx context.Context, token, projectUUID string) ([]PackageVersion, error) {
+	params := url.Values{}
+	params.Set("list_parameters.filter", fmt.Sprintf("spec.project_uuid==%q", projectUUID))
+	params.Set("list_parameters.mask", packageVersionMask)
+	params.Set("list_parameters.traverse", "true")
+
+	return listAll[PackageVersion](ctx, c, token, "package-versions", params)
+}
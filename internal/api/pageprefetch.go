@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// pageFetchFunc fetches the page numbered n
+type pageFetchFunc func(ctx context.Context, n int) (findingsPage, error)
+
+// pagePrefetcher fetches numbered pages ahead of the page being processed, so
+// the requests for later pages overlap with decoding and handling earlier ones.
+// Pages are handed out in order whatever order they arrive in.
+type pagePrefetcher struct {
+	ctx   context.Context
+	fetch pageFetchFunc
+	ahead int
+	// next is the first page not requested yet
+	next    int
+	pending map[int]chan pageResult
+	wg      sync.WaitGroup
+}
+
+// pageResult is the outcome of fetching one page
+type pageResult struct {
+	page findingsPage
+	err  error
+}
+
+// newPagePrefetcher returns a prefetcher that fetches pages from first on,
+// at most ahead of them at a time
+func newPagePrefetcher(ctx context.Context, ahead, first int, fetch pageFetchFunc) *pagePrefetcher {
+	return &pagePrefetcher{
+		ctx:     ctx,
+		fetch:   fetch,
+		ahead:   ahead,
+		next:    first,
+		pending: make(map[int]chan pageResult),
+	}
+}
+
+// get returns page n, first requesting the pages up to ahead of it
+func (p *pagePrefetcher) get(n int) (findingsPage, error) {
+	for ; p.next < n+p.ahead; p.next++ {
+		ch := make(chan pageResult, 1)
+		p.pending[p.next] = ch
+		p.wg.Add(1)
+		go func(n int) {
+			defer p.wg.Done()
+			page, err := p.fetch(p.ctx, n)
+			ch <- pageResult{page, err}
+		}(p.next)
+	}
+
+	ch := p.pending[n]
+	delete(p.pending, n)
+	r := <-ch
+	return r.page, r.err
+}
+
+// stop waits for the pages still being fetched and discards them. Pages past
+// the last one come back empty, so waiting keeps the fetch report complete
+// without recording them as cancelled.
+func (p *pagePrefetcher) stop() {
+	p.wg.Wait()
+}
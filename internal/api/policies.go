@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// PolicyTypeException is the policy type of exception (suppression) policies
+const PolicyTypeException = "POLICY_TYPE_EXCEPTION"
+
+// Policy represents an Endor Labs policy
+type Policy struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		Name        string    `json:"name"`
+		Description string    `json:"description"`
+		CreateTime  time.Time `json:"create_time"`
+		CreatedBy   string    `json:"created_by"`
+		UpdateTime  time.Time `json:"update_time"`
+		UpdatedBy   string    `json:"updated_by"`
+	} `json:"meta"`
+	Spec struct {
+		PolicyType string `json:"policy_type"`
+		Disable    bool   `json:"disable"`
+		Exception  struct {
+			Reason         string     `json:"reason"`
+			ExpirationTime *time.Time `json:"expiration_time"`
+		} `json:"exception"`
+	} `json:"spec"`
+	TenantMeta struct {
+		Namespace string `json:"namespace"`
+	} `json:"tenant_meta"`
+}
+
+// ListExceptionPolicies retrieves every exception policy in the namespace and its children
+func (c *Client) ListExceptionPolicies(ctx context.Context, token string) ([]Policy, error) {
+	params := url.Values{}
+	params.Set("list_parameters.filter", "spec.policy_type=="+PolicyTypeException)
+	params.Set("list_parameters.mask", "uuid,meta.name,meta.description,meta.create_time,meta.created_by,meta.update_time,meta.updated_by,spec.policy_type,spec.disable,spec.exception,tenant_meta.namespace")
+	params.Set("list_parameters.traverse", "true")
+
+	return listAll[Policy](ctx, c, token, "policies", params)
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Project represents an Endor Labs project
+type Project struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		Name        string            `json:"name"`
+		Description string            `json:"description,omitempty"`
+		Tags        []string          `json:"tags,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+		CreateTime  string            `json:"create_time,omitempty"`
+		UpdateTime  string            `json:"update_time,omitempty"`
+	} `json:"meta"`
+	Spec struct {
+		PlatformSource string `json:"platform_source,omitempty"`
+		Git            struct {
+			HTTPCloneURL string `json:"http_clone_url"`
+			WebURL       string `json:"web_url,omitempty"`
+			FullName     string `json:"full_name,omitempty"`
+		} `json:"git"`
+	} `json:"spec"`
+	TenantMeta struct {
+		Namespace string `json:"namespace"`
+	} `json:"tenant_meta"`
+}
+
+// ProjectsListResponse represents the list projects API response structure
+type ProjectsListResponse struct {
+	List struct {
+		Objects  []Project `json:"objects"`
+		Response struct {
+			NextPageID string `json:"next_page_id"`
+		} `json:"response"`
+	} `json:"list"`
+}
+
+// DefaultProjectMask is the field mask used when listing projects
+const DefaultProjectMask = "uuid,meta.name,spec.git.http_clone_url,tenant_meta.namespace"
+
+// ProjectListOptions narrows a project listing
+type ProjectListOptions struct {
+	// Name matches projects whose name contains this value
+	Name string
+	// RepoURL matches projects whose git clone URL contains this value
+	RepoURL string
+	// Filter is an extra raw Endor filter expression
+	Filter string
+	// Mask overrides DefaultProjectMask; "*" returns every field
+	Mask string
+	// PageSize overrides the client's page size when set
+	PageSize int
+}
+
+// filter combines the options into a single filter expression
+func (o ProjectListOptions) filter() string {
+	var clauses []string
+	if o.Name != "" {
+		clauses = append(clauses, fmt.Sprintf("meta.name matches %q", regexp.QuoteMeta(o.Name)))
+	}
+	if o.RepoURL != "" {
+		clauses = append(clauses, fmt.Sprintf("spec.git.http_clone_url matches %q", regexp.QuoteMeta(o.RepoURL)))
+	}
+	if o.Filter != "" {
+		clauses = append(clauses, "("+o.Filter+")")
+	}
+	return strings.Join(clauses, " and ")
+}
+
+// ListProjects retrieves every project in the namespace and its children that
+// matches opts
+func (c *Client) ListProjects(ctx context.Context, token string, opts ProjectListOptions) ([]Project, error) {
+	params := url.Values{}
+	if f := opts.filter(); f != "" {
+		params.Set("list_parameters.filter", f)
+	}
+	switch opts.Mask {
+	case "":
+		params.Set("list_parameters.mask", DefaultProjectMask)
+	case "*":
+		// No mask returns the full object
+	default:
+		params.Set("list_parameters.mask", opts.Mask)
+	}
+	if opts.PageSize > 0 {
+		params.Set("list_parameters.page_size", strconv.Itoa(opts.PageSize))
+	}
+	params.Set("list_parameters.traverse", "true") // Projects may live in child namespaces
+
+	return listAll[Project](ctx, c, token, "projects", params)
+}
+
+// GetProject retrieves a single project by UUID, searching child namespaces too
+func (c *Client) GetProject(ctx context.Context, token, uuid string) (*Project, error) {
+	projects, err := c.ListProjects(ctx, token, ProjectListOptions{
+		Filter: fmt.Sprintf("uuid==%q", uuid),
+		Mask:   "*",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("project %s not found", uuid)
+	}
+	return &projects[0], nil
+}
+
+// FindProjectByRepo returns the project whose git clone URL matches repo. The
+// scheme, user info, ".git" suffix and letter case are ignored, so
+// "github.com/org/repo" matches "https://github.com/org/repo.git".
+func (c *Client) FindProjectByRepo(ctx context.Context, token, repo string) (*Project, error) {
+	want := NormalizeRepoURL(repo)
+	if want == "" {
+		return nil, fmt.Errorf("invalid repository %q", repo)
+	}
+
+	// Narrow the listing server-side by repository name, then compare exactly
+	name := strings.TrimSuffix(path.Base(strings.TrimRight(strings.TrimSpace(repo), "/")), ".git")
+	candidates, err := c.ListProjects(ctx, token, ProjectListOptions{RepoURL: name})
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Project
+	for _, p := range candidates {
+		if NormalizeRepoURL(p.Spec.Git.HTTPCloneURL) == want {
+			matches = append(matches, p)
+		}
+	}
+
+	return singleProject(matches, "repository "+repo)
+}
+
+// FindProjectByName returns the project with exactly the given name
+func (c *Client) FindProjectByName(ctx context.Context, token, name string) (*Project, error) {
+	candidates, err := c.ListProjects(ctx, token, ProjectListOptions{Filter: fmt.Sprintf("meta.name==%q", name)})
+	if err != nil {
+		return nil, err
+	}
+
+	return singleProject(candidates, "name "+name)
+}
+
+// singleProject returns the only project in matches, or an error describing
+// why there is not exactly one
+func singleProject(matches []Project, what string) (*Project, error) {
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no project found for %s", what)
+	case 1:
+		return &matches[0], nil
+	default:
+		uuids := make([]string, len(matches))
+		for i, p := range matches {
+			uuids[i] = p.UUID
+		}
+		return nil, fmt.Errorf("%d projects found for %s (%s); pass a project UUID instead", len(matches), what, strings.Join(uuids, ", "))
+	}
+}
+
+// NormalizeRepoURL reduces a repository URL to a lower-case "host/path" form
+func NormalizeRepoURL(repo string) string {
+	s := strings.TrimSpace(repo)
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	} else if at := strings.Index(s, "@"); at >= 0 && strings.Contains(s[at:], ":") {
+		// scp-like syntax: git@github.com:org/repo.git
+		s = strings.Replace(s[at+1:], ":", "/", 1)
+	}
+	if at := strings.Index(s, "@"); at >= 0 && at < strings.Index(s+"/", "/") {
+		s = s[at+1:]
+	}
+	s = strings.TrimSuffix(strings.TrimRight(s, "/"), ".git")
+	return strings.ToLower(s)
+}
+
+// projectLookupBatchSize is the number of project UUIDs resolved per request
+const projectLookupBatchSize = 50
+
+// ProjectCache resolves project UUIDs to projects, remembering every lookup
+type ProjectCache struct {
+	client   *Client
+	mu       sync.Mutex
+	projects map[string]Project
+}
+
+// NewProjectCache creates an empty project cache backed by client
+func NewProjectCache(client *Client) *ProjectCache {
+	return &ProjectCache{
+		client:   client,
+		projects: make(map[string]Project),
+	}
+}
+
+// Resolve returns the projects for the given UUIDs, fetching any that are not cached yet.
+// Unknown UUIDs are simply missing from the returned map.
+func (pc *ProjectCache) Resolve(ctx context.Context, token string, uuids []string) (map[string]Project, error) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, id := range uuids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, ok := pc.projects[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	for start := 0; start < len(missing); start += projectLookupBatchSize {
+		end := start + projectLookupBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+
+		projects, err := pc.client.lookupProjects(ctx, token, missing[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			pc.projects[p.UUID] = p
+		}
+	}
+
+	resolved := make(map[string]Project, len(seen))
+	for id := range seen {
+		if p, ok := pc.projects[id]; ok {
+			resolved[id] = p
+		}
+	}
+
+	return resolved, nil
+}
+
+// lookupProjects fetches the projects with the given UUIDs in a single request
+func (c *Client) lookupProjects(ctx context.Context, token string, uuids []string) ([]Project, error) {
+	quoted := make([]string, len(uuids))
+	for i, id := range uuids {
+		quoted[i] = fmt.Sprintf("%q", id)
+	}
+
+	return c.ListProjects(ctx, token, ProjectListOptions{
+		Filter:   fmt.Sprintf("uuid in [%s]", strings.Join(quoted, ",")),
+		PageSize: len(uuids),
+	})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how many requests are sent per
+// second. Clients sharing a limiter share its budget, so a sweep across many
+// projects or namespaces stays under the API's rate limits as a whole. It is
+// safe for concurrent use.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	waited time.Duration
+	now    func() time.Time
+}
+
+// NewRateLimiter allows rps requests per second on average and bursts of up to
+// burst requests. A burst below 1 is raised to 1. It returns nil, which allows
+// every request, when rps is not positive.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	b := math.Max(float64(burst), 1)
+	return &RateLimiter{rate: rps, burst: b, tokens: b, now: time.Now}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	// Take the token now, going into debt if none is left, so concurrent
+	// callers queue up in order instead of racing for the next token
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		l.waited += delay
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the token back for the callers queued behind
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Waited returns the total time requests have been held back so far
+func (l *RateLimiter) Waited() time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.waited
+}
+
+// WithRateLimit limits the client to rps requests per second with bursts of up
+// to burst requests; rps 0 removes the limit
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) { c.limiter = NewRateLimiter(rps, burst) }
+}
+
+// WithRateLimiter makes the client share limiter with other clients; nil
+// removes the limit
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) { c.limiter = limiter }
+}
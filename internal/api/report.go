@@ -0,0 +1,73 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// FetchReport records how every API request of a run went, so operators can tell
+// API flakiness apart from real changes in the data between runs
+type FetchReport struct {
+	TotalRequests  int             `json:"total_requests"`
+	TotalRetries   int             `json:"total_retries"`
+	FailedRequests int             `json:"failed_requests"`
+	Requests       []RequestReport `json:"requests"`
+}
+
+// RequestReport describes a single logical request (one page or endpoint call)
+type RequestReport struct {
+	Endpoint  string        `json:"endpoint"`
+	Page      int           `json:"page,omitempty"`
+	Attempts  int           `json:"attempts"`
+	Retries   int           `json:"retries"`
+	Succeeded bool          `json:"succeeded"`
+	Status    int           `json:"status,omitempty"`
+	Errors    []string      `json:"errors,omitempty"`
+	Duration  time.Duration `json:"duration_ns"`
+}
+
+// reportRecorder collects request reports safely across goroutines
+type reportRecorder struct {
+	mu       sync.Mutex
+	requests []RequestReport
+}
+
+func (r *reportRecorder) record(rr RequestReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, rr)
+}
+
+func (r *reportRecorder) snapshot() FetchReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := FetchReport{
+		Requests: make([]RequestReport, len(r.requests)),
+	}
+	copy(report.Requests, r.requests)
+
+	for _, rr := range r.requests {
+		report.TotalRequests++
+		report.TotalRetries += rr.Retries
+		if !rr.Succeeded {
+			report.FailedRequests++
+		}
+	}
+
+	return report
+}
+
+// FetchReport returns a snapshot of every request made by the client so far
+func (c *Client) FetchReport() FetchReport {
+	return c.report.snapshot()
+}
+
+// Add adds the requests of other, such as the report of another client of
+// the same run, to the report
+func (r *FetchReport) Add(other FetchReport) {
+	r.TotalRequests += other.TotalRequests
+	r.TotalRetries += other.TotalRetries
+	r.FailedRequests += other.FailedRequests
+	r.Requests = append(r.Requests, other.Requests...)
+}
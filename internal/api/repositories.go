@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Repository is the source repository of a project
+type Repository struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		Name string `json:"name"`
+		// ParentUUID is the project of the repository
+		ParentUUID string `json:"parent_uuid"`
+		CreateTime string `json:"create_time,omitempty"`
+	} `json:"meta"`
+	Spec struct {
+		HTTPCloneURL   string `json:"http_clone_url,omitempty"`
+		DefaultBranch  string `json:"default_branch,omitempty"`
+		PlatformSource string `json:"platform_source,omitempty"`
+	} `json:"spec"`
+	TenantMeta struct {
+		Namespace string `json:"namespace"`
+	} `json:"tenant_meta"`
+}
+
+// RepositoryVersion is a scanned branch, tag or commit of a project's
+// repository. Findings of a version share its project and scan context.
+type RepositoryVersion struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		// Name is the ref scanned, e.g. main or refs/pull/42/head
+		Name string `json:"name"`
+		// ParentUUID is the project of the repository
+		ParentUUID string `json:"parent_uuid"`
+		CreateTime string `json:"create_time,omitempty"`
+	} `json:"meta"`
+	// Context is the scan context of the version, e.g. the main branch or a ref
+	Context struct {
+		Type string `json:"type,omitempty"`
+		ID   string `json:"id,omitempty"`
+	} `json:"context"`
+	Spec struct {
+		Version struct {
+			Ref string `json:"ref,omitempty"`
+			SHA string `json:"sha,omitempty"`
+		} `json:"version"`
+		LastCommitDate time.Time `json:"last_commit_date"`
+		// ScanObject is the outcome of the version's last scan
+		ScanObject struct {
+			Status   string    `json:"status,omitempty"`
+			ScanTime time.Time `json:"scan_time"`
+		} `json:"scan_object"`
+	} `json:"spec"`
+	TenantMeta struct {
+		Namespace string `json:"namespace"`
+	} `json:"tenant_meta"`
+}
+
+// Ref returns the ref of the version, falling back to its name
+func (v RepositoryVersion) Ref() string {
+	return firstNonEmpty(v.Spec.Version.Ref, v.Meta.Name)
+}
+
+// ShortSHA returns the first 12 characters of the version's commit SHA
+func (v RepositoryVersion) ShortSHA() string {
+	if len(v.Spec.Version.SHA) > 12 {
+		return v.Spec.Version.SHA[:12]
+	}
+	return v.Spec.Version.SHA
+}
+
+// Contains reports whether f was found in the version: in its project and
+// scan context
+func (v RepositoryVersion) Contains(f Finding) bool {
+	return f.Spec.ProjectUUID == v.Meta.ParentUUID && f.Context.Type == v.Context.Type && f.Context.ID == v.Context.ID
+}
+
+// repositoryMask and repositoryVersionMask list the fields requested
+const (
+	repositoryMask        = "uuid,meta.name,meta.parent_uuid,meta.create_time,spec.http_clone_url,spec.default_branch,spec.platform_source,tenant_meta.namespace"
+	repositoryVersionMask = "uuid,meta.name,meta.parent_uuid,meta.create_time,context.type,context.id," +
+		"spec.version.ref,spec.version.sha,spec.last_commit_date,spec.scan_object.status,spec.scan_object.scan_time,tenant_meta.namespace"
+)
+
+// ListRepositories retrieves the repositories of the projects with the given
+// UUIDs, or of every project when there are none, sorted by name
+func (c *Client) ListRepositories(ctx context.Context, token string, projectUUIDs []string) ([]Repository, error) {
+	params := parentParams(projectUUIDs)
+	params.Set("list_parameters.mask", repositoryMask)
+
+	repos, err := listAll[Repository](ctx, c, token, "repositories", params)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(repos, func(i, j int) bool { return repos[i].Meta.Name < repos[j].Meta.Name })
+	return repos, nil
+}
+
+// ListRepositoryVersions retrieves the scanned versions of the repositories
+// of the projects with the given UUIDs, or of every project when there are
+// none. Each project's main context comes first, then the most recently
+// scanned versions.
+func (c *Client) ListRepositoryVersions(ctx context.Context, token string, projectUUIDs []string) ([]RepositoryVersion, error) {
+	params := parentParams(projectUUIDs)
+	params.Set("list_parameters.mask", repositoryVersionMask)
+
+	versions, err := listAll[RepositoryVersion](ctx, c, token, "repository-versions", params)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		if a.Meta.ParentUUID != b.Meta.ParentUUID {
+			return a.Meta.ParentUUID < b.Meta.ParentUUID
+		}
+		if am, bm := a.Context.Type == "CONTEXT_TYPE_MAIN", b.Context.Type == "CONTEXT_TYPE_MAIN"; am != bm {
+			return am
+		}
+		return a.Spec.ScanObject.ScanTime.After(b.Spec.ScanObject.ScanTime)
+	})
+	return versions, nil
+}
+
+// CountFindingsByVersion returns how many findings each of versions has,
+// keyed by version UUID, from the API's group aggregation rather than by
+// listing them
+func (c *Client) CountFindingsByVersion(ctx context.Context, token string, versions []RepositoryVersion) (map[string]int, error) {
+	projects := make(map[string]bool)
+	var projectUUIDs []string
+	for _, v := range versions {
+		if !projects[v.Meta.ParentUUID] {
+			projects[v.Meta.ParentUUID] = true
+			projectUUIDs = append(projectUUIDs, v.Meta.ParentUUID)
+		}
+	}
+	counts := make(map[string]int, len(versions))
+	if len(projectUUIDs) == 0 {
+		return counts, nil
+	}
+
+	paths := []string{"spec.project_uuid", "context.type", "context.id"}
+	groups, err := c.groupFindings(ctx, token, projectUUIDs, "", paths)
+	if err != nil {
+		return nil, err
+	}
+	byContext := make(map[[3]string]int, len(groups))
+	for _, g := range groups {
+		byContext[[3]string{g.Values[paths[0]], g.Values[paths[1]], g.Values[paths[2]]}] += g.Count
+	}
+	for _, v := range versions {
+		counts[v.UUID] = byContext[[3]string{v.Meta.ParentUUID, v.Context.Type, v.Context.ID}]
+	}
+	return counts, nil
+}
+
+// parentParams returns the list parameters selecting the objects of the
+// projects with the given UUIDs, or of every project, in every namespace
+func parentParams(projectUUIDs []string) url.Values {
+	params := url.Values{}
+	switch len(projectUUIDs) {
+	case 0:
+	case 1:
+		params.Set("list_parameters.filter", fmt.Sprintf("meta.parent_uuid==%q", projectUUIDs[0]))
+	default:
+		quoted := make([]string, len(projectUUIDs))
+		for i, id := range projectUUIDs {
+			quoted[i] = fmt.Sprintf("%q", id)
+		}
+		params.Set("list_parameters.filter", fmt.Sprintf("meta.parent_uuid in [%s]", strings.Join(quoted, ",")))
+	}
+	params.Set("list_parameters.traverse", "true")
+	return params
+}
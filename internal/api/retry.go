@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultMaxRetries is the number of times a failed request is retried
+const DefaultMaxRetries = 2
+
+// Default backoff bounds
+const (
+	DefaultRetryBaseDelay = time.Second
+	DefaultRetryMaxDelay  = 30 * time.Second
+)
+
+// maxRetryAfter caps how long a Retry-After header can make the client wait
+const maxRetryAfter = 5 * time.Minute
+
+// RetryPolicy controls how failed requests are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt (0 disables retries)
+	MaxRetries int
+	// BaseDelay is the delay before the first retry; it doubles on every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used by new clients
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultRetryBaseDelay,
+		MaxDelay:   DefaultRetryMaxDelay,
+	}
+}
+
+// WithRetry replaces the client's retry policy. Zero delays fall back to the defaults.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		if p.MaxRetries < 0 {
+			p.MaxRetries = 0
+		}
+		if p.BaseDelay <= 0 {
+			p.BaseDelay = DefaultRetryBaseDelay
+		}
+		if p.MaxDelay <= 0 {
+			p.MaxDelay = DefaultRetryMaxDelay
+		}
+		if p.MaxDelay < p.BaseDelay {
+			p.MaxDelay = p.BaseDelay
+		}
+		c.retry = p
+	}
+}
+
+// HeaderIdempotencyKey marks a POST as safe to send again, so it is retried
+// like a GET
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// newIdempotencyKey returns a key for the attempts of one request
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	crand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// retryable reports whether req may be sent again after a failure: reads,
+// and writes carrying an idempotency key. A write may have been committed
+// before a 5xx or a dropped connection, and sending it again would repeat it.
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	}
+	return req.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+// doWithRetry sends the request built by newReq, retrying transport errors,
+// 429 and 5xx responses until the retry budget is spent; requests that are
+// not retryable are sent once. Retries back off
+// exponentially with jitter, or wait as long as a Retry-After header asks.
+// Each call is recorded in the client's fetch report under endpoint and page.
+func (c *Client) doWithRetry(ctx context.Context, endpoint string, page int, newReq func() (*http.Request, error)) (*http.Response, error) {
+	rr := RequestReport{
+		Endpoint: endpoint,
+		Page:     page,
+	}
+	start := time.Now()
+	ctx, endSpan := c.startSpan(ctx, endpoint, page)
+	defer func() {
+		rr.Duration = time.Since(start)
+		c.report.record(rr)
+		endSpan(rr)
+	}()
+
+	for {
+		if err := c.limiter.Wait(ctx); err != nil {
+			rr.Errors = append(rr.Errors, err.Error())
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		rr.Attempts++
+
+		req, err := newReq()
+		if err != nil {
+			rr.Errors = append(rr.Errors, err.Error())
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req = req.WithContext(ctx)
+		req.Header.Set("User-Agent", c.userAgent)
+		c.setAcceptEncoding(req)
+		injectTraceContext(ctx, req.Header)
+		c.logRequest(req, rr.Attempts)
+		sent := time.Now()
+		resp, err := c.httpClient.Do(req)
+		c.logResponse(req, resp, err, time.Since(sent))
+		if err == nil {
+			if err = decompress(resp); err != nil {
+				resp.Body.Close()
+				resp = nil
+			}
+		}
+		switch {
+		case err != nil:
+			rr.Errors = append(rr.Errors, err.Error())
+		case isRetryableStatus(resp.StatusCode):
+			rr.Status = resp.StatusCode
+			rr.Errors = append(rr.Errors, fmt.Sprintf("status %d", resp.StatusCode))
+		default:
+			rr.Status = resp.StatusCode
+			rr.Succeeded = resp.StatusCode == http.StatusOK
+			return resp, nil
+		}
+
+		if rr.Retries >= c.retry.MaxRetries || ctx.Err() != nil || !retryable(req) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to send request: %w", err)
+			}
+			return resp, nil
+		}
+
+		delay := c.retry.backoff(rr.Retries)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = after
+			}
+			resp.Body.Close()
+		}
+
+		rr.Retries++
+		select {
+		case <-ctx.Done():
+			rr.Errors = append(rr.Errors, ctx.Err().Error())
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns the delay before retry number n (starting at 0): the base
+// delay doubled n times, capped at MaxDelay, with "equal jitter" so the actual
+// wait is between half and all of that value
+func (p RetryPolicy) backoff(n int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < n && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(header); err == nil {
+		delay = t.Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+	return delay, true
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
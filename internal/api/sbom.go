@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SBOM kinds
+const (
+	SBOMCycloneDX = "cyclonedx"
+	SBOMSPDX      = "spdx"
+)
+
+// SBOM encodings
+const (
+	SBOMFormatJSON = "json"
+	SBOMFormatXML  = "xml"
+)
+
+// SBOMExportOptions selects the SBOM to export
+type SBOMExportOptions struct {
+	// PackageVersionUUID is the package version the SBOM describes
+	PackageVersionUUID string
+	// Kind is SBOMCycloneDX or SBOMSPDX
+	Kind string
+	// Format is SBOMFormatJSON or SBOMFormatXML; SPDX only supports JSON
+	Format string
+}
+
+// Validate checks that the kind and format are supported together
+func (o SBOMExportOptions) Validate() error {
+	switch o.Kind {
+	case SBOMCycloneDX, SBOMSPDX:
+	default:
+		return fmt.Errorf("unsupported SBOM kind %q (expected %s or %s)", o.Kind, SBOMCycloneDX, SBOMSPDX)
+	}
+	switch o.Format {
+	case SBOMFormatJSON:
+	case SBOMFormatXML:
+		if o.Kind == SBOMSPDX {
+			return fmt.Errorf("SPDX SBOMs can only be exported as %s", SBOMFormatJSON)
+		}
+	default:
+		return fmt.Errorf("unsupported SBOM format %q (expected %s or %s)", o.Format, SBOMFormatJSON, SBOMFormatXML)
+	}
+	return nil
+}
+
+// sbomExportRequest is the body of an SBOM export request
+type sbomExportRequest struct {
+	Meta struct {
+		Name string `json:"name"`
+	} `json:"meta"`
+	Spec struct {
+		Kind               string `json:"kind"`
+		Format             string `json:"format"`
+		ComponentType      string `json:"component_type"`
+		PackageVersionUUID string `json:"package_version_uuid"`
+	} `json:"spec"`
+}
+
+// ExportSBOM asks the API to generate the SBOM of a package version and returns
+// the document exactly as generated
+func (c *Client) ExportSBOM(ctx context.Context, token string, opts SBOMExportOptions) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var body sbomExportRequest
+	body.Meta.Name = fmt.Sprintf("SBOM export for %s", opts.PackageVersionUUID)
+	body.Spec.Kind = "SBOM_KIND_" + strings.ToUpper(opts.Kind)
+	body.Spec.Format = "FORMAT_" + strings.ToUpper(opts.Format)
+	body.Spec.ComponentType = "COMPONENT_TYPE_APPLICATION"
+	body.Spec.PackageVersionUUID = opts.PackageVersionUUID
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SBOM export request: %w", err)
+	}
+
+	fullURL := fmt.Sprintf("%s/namespaces/%s/sbom-export", c.baseURL, c.namespace)
+	// Generating an SBOM changes nothing, so it is safe to retry
+	key := newIdempotencyKey()
+	resp, err := c.doWithRetry(ctx, "sbom-export", 0, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", fullURL, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Request-Timeout", "600")
+		req.Header.Set(HeaderIdempotencyKey, key)
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to export SBOM: %w", newAPIError("sbom-export", resp))
+	}
+
+	var exportResp struct {
+		Spec struct {
+			Data string `json:"data"`
+		} `json:"spec"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&exportResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if exportResp.Spec.Data == "" {
+		return nil, fmt.Errorf("no SBOM received in response")
+	}
+
+	return []byte(exportResp.Spec.Data), nil
+}
+
+// PackageVersionRef identifies a package version of a project
+type PackageVersionRef struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		Name string `json:"name"`
+	} `json:"meta"`
+}
+
+// FindPackageVersion returns the package version of a project named name, e.g.
+// npm://my-app@1.0.0. name may be empty when the project has a single package version.
+func (c *Client) FindPackageVersion(ctx context.Context, token, projectUUID, name string) (*PackageVersionRef, error) {
+	filter := fmt.Sprintf("spec.project_uuid==%q", projectUUID)
+	if name != "" {
+		filter += fmt.Sprintf(" and meta.name==%q", name)
+	}
+
+	params := url.Values{}
+	params.Set("list_parameters.filter", filter)
+	params.Set("list_parameters.mask", "uuid,meta.name")
+	params.Set("list_parameters.traverse", "true")
+
+	versions, err := listAll[PackageVersionRef](ctx, c, token, "package-versions", params)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(versions) {
+	case 0:
+		if name != "" {
+			return nil, fmt.Errorf("package version %s not found in project %s", name, projectUUID)
+		}
+		return nil, fmt.Errorf("no package versions found in project %s", projectUUID)
+	case 1:
+		return &versions[0], nil
+	default:
+		names := make([]string, len(versions))
+		for i, v := range versions {
+			names[i] = v.Meta.Name
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("project %s has %d package versions, choose one of: %s", projectUUID, len(versions), strings.Join(names, ", "))
+	}
+}
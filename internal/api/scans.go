@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// ScanStatus is the outcome of a scan, e.g. STATUS_SUCCESS
+type ScanStatus string
+
+// Scan statuses
+const (
+	ScanSuccess        ScanStatus = "STATUS_SUCCESS"
+	ScanPartialSuccess ScanStatus = "STATUS_PARTIAL_SUCCESS"
+	ScanFailure        ScanStatus = "STATUS_FAILURE"
+	ScanRunning        ScanStatus = "STATUS_RUNNING"
+)
+
+// ScanStatusPrefix is the prefix shared by every scan status
+const ScanStatusPrefix = "STATUS_"
+
+// Short returns the status without its prefix in lower case, e.g. "success"
+func (s ScanStatus) Short() string { return short(string(s), ScanStatusPrefix) }
+
+// ScanResult is one scan of a project, the run that produced its findings
+type ScanResult struct {
+	UUID string `json:"uuid"`
+	Meta struct {
+		CreateTime string `json:"create_time,omitempty"`
+		// ParentUUID is the scanned project
+		ParentUUID string `json:"parent_uuid"`
+	} `json:"meta"`
+	// Context is what was scanned, e.g. the main branch or a CI run
+	Context struct {
+		Type string `json:"type,omitempty"`
+		ID   string `json:"id,omitempty"`
+	} `json:"context"`
+	Spec struct {
+		Status ScanStatus `json:"status"`
+		// Type is the kind of scan, e.g. SCAN_TYPE_ENDORCTL
+		Type      string    `json:"type,omitempty"`
+		StartTime time.Time `json:"start_time"`
+		EndTime   time.Time `json:"end_time"`
+		// Versions maps each scanner that ran to its version, e.g. endorctl
+		Versions map[string]string `json:"versions,omitempty"`
+		ExitCode int               `json:"exit_code,omitempty"`
+		// Errors are the errors the scan reported
+		Errors []string `json:"errors,omitempty"`
+	} `json:"spec"`
+}
+
+// Duration returns how long the scan ran, or 0 if it has not ended
+func (s ScanResult) Duration() time.Duration {
+	if s.Spec.StartTime.IsZero() || s.Spec.EndTime.IsZero() {
+		return 0
+	}
+	return s.Spec.EndTime.Sub(s.Spec.StartTime)
+}
+
+// Succeeded reports whether the scan completed, fully or partially
+func (s ScanResult) Succeeded() bool {
+	return s.Spec.Status == ScanSuccess || s.Spec.Status == ScanPartialSuccess
+}
+
+// scanResultMask lists the scan result fields requested
+const scanResultMask = "uuid,meta.create_time,meta.parent_uuid,context.type,context.id," +
+	"spec.status,spec.type,spec.start_time,spec.end_time,spec.versions,spec.exit_code,spec.errors"
+
+// ListScanResults retrieves the scan history of a project, newest scan first
+func (c *Client) ListScanResults(ctx context.Context, token, projectUUID string) ([]ScanResult, error) {
+	params := url.Values{}
+	params.Set("list_parameters.filter", fmt.Sprintf("meta.parent_uuid==%q", projectUUID))
+	params.Set("list_parameters.mask", scanResultMask)
+	params.Set("list_parameters.traverse", "true")
+
+	scans, err := listAll[ScanResult](ctx, c, token, "scan-results", params)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].Spec.StartTime.After(scans[j].Spec.StartTime) })
+	return scans, nil
+}
+
+// LastSuccessfulScan returns the newest scan of scans that completed, or nil
+func LastSuccessfulScan(scans []ScanResult) *ScanResult {
+	var last *ScanResult
+	for i := range scans {
+		if scans[i].Succeeded() && (last == nil || scans[i].Spec.StartTime.After(last.Spec.StartTime)) {
+			last = &scans[i]
+		}
+	}
+	return last
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// PageStats describes the transfer and decoding of a single page
+type PageStats struct {
+	Endpoint       string        `json:"endpoint"`
+	Page           int           `json:"page"`
+	PageSize       int           `json:"page_size"`
+	Objects        int           `json:"objects"`
+	Bytes          int64         `json:"bytes"`
+	FetchDuration  time.Duration `json:"fetch_duration_ns"`
+	DecodeDuration time.Duration `json:"decode_duration_ns"`
+	HeapAlloc      uint64        `json:"heap_alloc_bytes"`
+}
+
+// telemetryRecorder collects page statistics safely across goroutines
+type telemetryRecorder struct {
+	mu    sync.Mutex
+	pages []PageStats
+}
+
+func (t *telemetryRecorder) record(ps PageStats) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	ps.HeapAlloc = mem.HeapAlloc
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pages = append(t.pages, ps)
+}
+
+// PageTelemetry returns statistics for every page fetched by the client so far
+func (c *Client) PageTelemetry() []PageStats {
+	c.telemetry.mu.Lock()
+	defer c.telemetry.mu.Unlock()
+
+	pages := make([]PageStats, len(c.telemetry.pages))
+	copy(pages, c.telemetry.pages)
+	return pages
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the client's spans and metrics
+const instrumentationName = "github.com/endor-labs/findings-api/internal/api"
+
+// RequestDurationMetric is the histogram of API call durations, in seconds
+const RequestDurationMetric = "endorlabs.client.request.duration"
+
+// WithTracerProvider makes the client record a span per API call with tp. By
+// default the global provider is used, so nothing is recorded unless the
+// application installs one with otel.SetTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) { c.tracerProvider = tp }
+}
+
+// WithMeterProvider makes the client record its latency histogram with mp
+// instead of the global provider
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *Client) { c.meterProvider = mp }
+}
+
+// setupTelemetry creates the tracer and instruments from the configured
+// providers, falling back to the global ones
+func (c *Client) setupTelemetry() {
+	if c.tracerProvider == nil {
+		c.tracerProvider = otel.GetTracerProvider()
+	}
+	if c.meterProvider == nil {
+		c.meterProvider = otel.GetMeterProvider()
+	}
+	c.tracer = c.tracerProvider.Tracer(instrumentationName)
+
+	duration, err := c.meterProvider.Meter(instrumentationName).Float64Histogram(RequestDurationMetric,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of Endor Labs API calls, including retries"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	c.duration = duration
+}
+
+// startSpan starts the span of one API call, which covers all of its
+// attempts. The returned function ends it with the outcome recorded in rr.
+func (c *Client) startSpan(ctx context.Context, endpoint string, page int) (context.Context, func(rr RequestReport)) {
+	attrs := []attribute.KeyValue{
+		attribute.String("endorlabs.namespace", c.namespace),
+		attribute.String("endorlabs.endpoint", endpoint),
+	}
+	if page > 0 {
+		attrs = append(attrs, attribute.Int("endorlabs.page", page))
+	}
+	ctx, span := c.tracer.Start(ctx, "endorlabs "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, func(rr RequestReport) {
+		span.SetAttributes(
+			attribute.Int("endorlabs.attempts", rr.Attempts),
+			attribute.Int("endorlabs.retries", rr.Retries),
+		)
+		if rr.Status != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", rr.Status))
+		}
+		if !rr.Succeeded {
+			span.SetStatus(codes.Error, strings.Join(rr.Errors, "; "))
+		}
+		span.End()
+
+		if c.duration != nil {
+			c.duration.Record(ctx, rr.Duration.Seconds(), metric.WithAttributes(
+				attribute.String("endorlabs.endpoint", endpoint),
+				attribute.Int("http.response.status_code", rr.Status),
+			))
+		}
+	}
+}
+
+// injectTraceContext adds the trace context of ctx to header, so the API's
+// traces can be joined with the caller's
+func injectTraceContext(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
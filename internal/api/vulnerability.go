@@ -0,0 +1,224 @@
+package api
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/version"
+)
+
+// Vulnerability is the advisory a vulnerability finding refers to, as found in
+// spec.finding_metadata.vulnerability
+type Vulnerability struct {
+	Meta struct {
+		// Name is the advisory ID, e.g. GHSA-xxxx-xxxx-xxxx or CVE-2024-1234
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	} `json:"meta"`
+	Spec struct {
+		Aliases        []string          `json:"aliases,omitempty"`
+		Summary        string            `json:"summary,omitempty"`
+		Published      string            `json:"published,omitempty"`
+		Modified       string            `json:"modified,omitempty"`
+		CVSSV3Severity *CVSSSeverity     `json:"cvss_v3_severity,omitempty"`
+		CVSSV2Severity *CVSSSeverity     `json:"cvss_v2_severity,omitempty"`
+		EPSSScore      *EPSSScore        `json:"epss_score,omitempty"`
+		Affected       []AffectedPackage `json:"affected,omitempty"`
+		// DatabaseSpecific holds advisory database fields, such as the CWEs
+		// GitHub advisories are classified under
+		DatabaseSpecific struct {
+			CWEIDs []string `json:"cwe_ids,omitempty"`
+		} `json:"database_specific"`
+	} `json:"spec"`
+}
+
+// CVSSSeverity is a CVSS score with its vector
+type CVSSSeverity struct {
+	Level  string  `json:"level,omitempty"`
+	Score  float64 `json:"score"`
+	Vector string  `json:"vector,omitempty"`
+}
+
+// EPSSScore is the exploit prediction score of a vulnerability
+type EPSSScore struct {
+	ProbabilityScore float64 `json:"probability_score"`
+	PercentileScore  float64 `json:"percentile_score"`
+}
+
+// AffectedPackage lists the versions of a package a vulnerability affects
+type AffectedPackage struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem,omitempty"`
+		Name      string `json:"name,omitempty"`
+	} `json:"package"`
+	Ranges   []VersionRange `json:"ranges,omitempty"`
+	Versions []string       `json:"versions,omitempty"`
+}
+
+// VersionRange is an OSV-style affected range made of introduced/fixed events
+type VersionRange struct {
+	Type   string       `json:"type,omitempty"`
+	Events []RangeEvent `json:"events,omitempty"`
+}
+
+// RangeEvent is one boundary of a VersionRange
+type RangeEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// vulnerabilityMask lists the vulnerability fields requested with findings
+var vulnerabilityMask = []string{
+	"spec.finding_metadata.vulnerability.meta.name",
+	"spec.finding_metadata.vulnerability.meta.description",
+	"spec.finding_metadata.vulnerability.spec.aliases",
+	"spec.finding_metadata.vulnerability.spec.summary",
+	"spec.finding_metadata.vulnerability.spec.published",
+	"spec.finding_metadata.vulnerability.spec.modified",
+	"spec.finding_metadata.vulnerability.spec.cvss_v3_severity",
+	"spec.finding_metadata.vulnerability.spec.cvss_v2_severity",
+	"spec.finding_metadata.vulnerability.spec.epss_score",
+	"spec.finding_metadata.vulnerability.spec.affected",
+	"spec.finding_metadata.vulnerability.spec.database_specific",
+}
+
+// Vulnerability returns the finding's advisory, or nil for non-vulnerability findings
+func (f Finding) Vulnerability() *Vulnerability {
+	return f.Spec.FindingMetadata.Vulnerability
+}
+
+// VulnerabilityIDs returns the advisory ID and its aliases, CVE IDs first
+func (f Finding) VulnerabilityIDs() []string {
+	v := f.Vulnerability()
+	if v == nil {
+		return nil
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range append([]string{v.Meta.Name}, v.Spec.Aliases...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return strings.HasPrefix(ids[i], "CVE-") && !strings.HasPrefix(ids[j], "CVE-")
+	})
+	return ids
+}
+
+// CVE returns the finding's first CVE ID, or "" when it has none
+func (f Finding) CVE() string {
+	for _, id := range f.VulnerabilityIDs() {
+		if strings.HasPrefix(id, "CVE-") {
+			return id
+		}
+	}
+	return ""
+}
+
+// CWEs returns the IDs of the weaknesses the advisory is classified under, e.g. CWE-79
+func (f Finding) CWEs() []string {
+	if v := f.Vulnerability(); v != nil {
+		return v.Spec.DatabaseSpecific.CWEIDs
+	}
+	return nil
+}
+
+// CVSS returns the CVSS v3 severity, falling back to v2, or nil when unknown
+func (f Finding) CVSS() *CVSSSeverity {
+	v := f.Vulnerability()
+	if v == nil {
+		return nil
+	}
+	if v.Spec.CVSSV3Severity != nil {
+		return v.Spec.CVSSV3Severity
+	}
+	return v.Spec.CVSSV2Severity
+}
+
+// EPSS returns the EPSS probability score, or nil when unknown
+func (f Finding) EPSS() *EPSSScore {
+	if v := f.Vulnerability(); v != nil {
+		return v.Spec.EPSSScore
+	}
+	return nil
+}
+
+// FixedVersions returns every version the advisory lists as fixing it
+func (f Finding) FixedVersions() []string {
+	var fixed []string
+	for _, r := range f.affectedRanges() {
+		for _, e := range r.Events {
+			if e.Fixed != "" {
+				fixed = append(fixed, e.Fixed)
+			}
+		}
+	}
+	return fixed
+}
+
+// UpgradeTarget returns the version to upgrade the finding's dependency to:
+// the proposed version, or else the lowest fixed version above the current
+// one. It returns "" when no upgrade resolves the finding.
+func (f Finding) UpgradeTarget() string {
+	if f.Spec.ProposedVersion != "" {
+		return f.Spec.ProposedVersion
+	}
+	target := ""
+	for _, fixed := range f.FixedVersions() {
+		if version.Compare(fixed, f.Spec.TargetDependencyVersion) > 0 && (target == "" || version.Compare(fixed, target) < 0) {
+			target = fixed
+		}
+	}
+	return target
+}
+
+// AffectedRanges renders the affected ranges, e.g. ">=1.0.0 <1.2.3"
+func (f Finding) AffectedRanges() []string {
+	var ranges []string
+	for _, r := range f.affectedRanges() {
+		var bounds []string
+		for _, e := range r.Events {
+			switch {
+			case e.Introduced != "" && e.Introduced != "0":
+				bounds = append(bounds, ">="+e.Introduced)
+			case e.Fixed != "":
+				bounds = append(bounds, "<"+e.Fixed)
+			case e.LastAffected != "":
+				bounds = append(bounds, "<="+e.LastAffected)
+			case e.Limit != "":
+				bounds = append(bounds, "<"+e.Limit)
+			}
+		}
+		if len(bounds) == 0 {
+			bounds = []string{"*"}
+		}
+		ranges = append(ranges, strings.Join(bounds, " "))
+	}
+	return ranges
+}
+
+// affectedRanges returns the ranges of the affected entries matching the
+// finding's dependency, or of every entry when none match by name
+func (f Finding) affectedRanges() []VersionRange {
+	v := f.Vulnerability()
+	if v == nil {
+		return nil
+	}
+
+	var matched, all []VersionRange
+	for _, a := range v.Spec.Affected {
+		all = append(all, a.Ranges...)
+		if a.Package.Name != "" && a.Package.Name == f.Spec.TargetDependencyName {
+			matched = append(matched, a.Ranges...)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+	return all
+}
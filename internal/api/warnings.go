@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Warning codes
+const (
+	// WarningMalformedFinding means a finding could not be decoded and was skipped
+	WarningMalformedFinding = "malformed_finding"
+	// WarningMissingField means an object lacks a field the tool relies on
+	WarningMissingField = "missing_field"
+	// WarningProjectFailed means findings for one project could not be fetched
+	WarningProjectFailed = "project_failed"
+	// WarningNamespaceFailed means findings of one namespace could not be fetched
+	WarningNamespaceFailed = "namespace_failed"
+	// WarningEnrichmentFailed means findings could not be fully enriched
+	WarningEnrichmentFailed = "enrichment_failed"
+	// WarningDuplicateObject means a list page repeated objects already received;
+	// the repeats were dropped
+	WarningDuplicateObject = "duplicate_object"
+	// WarningPaginationStopped means a list hit the page cap and is incomplete
+	WarningPaginationStopped = "pagination_stopped"
+)
+
+// Warning is a non-fatal issue encountered while fetching or processing data.
+// Warnings are collected rather than logged so automation can inspect them.
+type Warning struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Resource string `json:"resource,omitempty"`
+	UUID     string `json:"uuid,omitempty"`
+	Page     int    `json:"page,omitempty"`
+}
+
+func (w Warning) String() string {
+	if w.UUID != "" {
+		return fmt.Sprintf("%s: %s (%s %s)", w.Code, w.Message, w.Resource, w.UUID)
+	}
+	return fmt.Sprintf("%s: %s", w.Code, w.Message)
+}
+
+// LogValue logs a warning as a group of its set fields
+func (w Warning) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("code", w.Code), slog.String("message", w.Message)}
+	if w.Resource != "" {
+		attrs = append(attrs, slog.String("resource", w.Resource))
+	}
+	if w.UUID != "" {
+		attrs = append(attrs, slog.String("uuid", w.UUID))
+	}
+	if w.Page > 0 {
+		attrs = append(attrs, slog.Int("page", w.Page))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// warningRecorder collects warnings safely across goroutines
+type warningRecorder struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+func (r *warningRecorder) record(w Warning) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warnings = append(r.warnings, w)
+}
+
+// Warnings returns every warning recorded by the client so far
+func (c *Client) Warnings() []Warning {
+	c.warnings.mu.Lock()
+	defer c.warnings.mu.Unlock()
+
+	warnings := make([]Warning, len(c.warnings.warnings))
+	copy(warnings, c.warnings.warnings)
+	return warnings
+}
+
+// AddWarning records a warning raised by code built on top of the client, such as
+// post-processing of fetched data, alongside the client's own warnings
+func (c *Client) AddWarning(w Warning) {
+	c.warnings.record(w)
+}
@@ -0,0 +1,15 @@
+package buildinfo
+
+// Build metadata, set at link time by release builds:
+//
+//	go build -ldflags "-X github.com/endor-labs/findings-api/internal/buildinfo.Version=v1.2.3"
+var (
+	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown"
+)
+
+// String returns a one-line description of the build
+func String() string {
+	return Version + " (commit " + Commit + ", built " + Date + ")"
+}
@@ -0,0 +1,281 @@
+// Package bundle loads enrichment datasets from a local directory, so findings
+// can be enriched with the CISA KEV catalog, EPSS scores and CWE names in
+// environments that cannot reach the internet. Download builds the directory
+// on a connected machine.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File names of the datasets in a bundle directory
+const (
+	KEVFile  = "kev.json"
+	EPSSFile = "epss.csv.gz"
+	CWEFile  = "cwe.csv"
+)
+
+// Sources the datasets are downloaded from
+const (
+	KEVURL  = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+	EPSSURL = "https://epss.cyentia.com/epss_scores-current.csv.gz"
+	CWEURL  = "https://cwe.mitre.org/data/csv/1000.csv.zip"
+)
+
+// KEVEntry is a vulnerability in the CISA Known Exploited Vulnerabilities catalog
+type KEVEntry struct {
+	CVE                string `json:"cve"`
+	Name               string `json:"name,omitempty"`
+	DateAdded          string `json:"date_added,omitempty"`
+	DueDate            string `json:"due_date,omitempty"`
+	RequiredAction     string `json:"required_action,omitempty"`
+	RansomwareCampaign string `json:"known_ransomware_campaign_use,omitempty"`
+}
+
+// EPSSEntry is a CVE's exploit prediction score on the snapshot date
+type EPSSEntry struct {
+	Score      float64 `json:"score"`
+	Percentile float64 `json:"percentile"`
+	Date       string  `json:"date,omitempty"`
+}
+
+// CWEEntry names a weakness, e.g. CWE-79
+type CWEEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Bundle holds the datasets found in a bundle directory, keyed by CVE or CWE ID
+type Bundle struct {
+	KEV  map[string]KEVEntry
+	EPSS map[string]EPSSEntry
+	CWE  map[string]CWEEntry
+}
+
+// Load reads the datasets in dir. Each dataset is optional, but a directory
+// holding none of them is an error.
+func Load(dir string) (*Bundle, error) {
+	b := &Bundle{}
+	found := 0
+
+	if f, err := os.Open(filepath.Join(dir, KEVFile)); err == nil {
+		defer f.Close()
+		if b.KEV, err = parseKEV(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", KEVFile, err)
+		}
+		found++
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if f, err := os.Open(filepath.Join(dir, EPSSFile)); err == nil {
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EPSSFile, err)
+		}
+		if b.EPSS, err = parseEPSS(gz); err != nil {
+			return nil, fmt.Errorf("%s: %w", EPSSFile, err)
+		}
+		found++
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if f, err := os.Open(filepath.Join(dir, CWEFile)); err == nil {
+		defer f.Close()
+		if b.CWE, err = parseCWE(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", CWEFile, err)
+		}
+		found++
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if found == 0 {
+		return nil, fmt.Errorf("no enrichment data in %s (expected %s, %s or %s)", dir, KEVFile, EPSSFile, CWEFile)
+	}
+	return b, nil
+}
+
+// Summary describes the loaded datasets for logs
+func (b *Bundle) Summary() string {
+	return fmt.Sprintf("%d KEV entries, %d EPSS scores, %d CWEs", len(b.KEV), len(b.EPSS), len(b.CWE))
+}
+
+// parseKEV reads the CISA KEV catalog JSON feed
+func parseKEV(r io.Reader) (map[string]KEVEntry, error) {
+	var catalog struct {
+		Vulnerabilities []struct {
+			CVEID                      string `json:"cveID"`
+			VulnerabilityName          string `json:"vulnerabilityName"`
+			DateAdded                  string `json:"dateAdded"`
+			DueDate                    string `json:"dueDate"`
+			RequiredAction             string `json:"requiredAction"`
+			KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.NewDecoder(r).Decode(&catalog); err != nil {
+		return nil, err
+	}
+
+	kev := make(map[string]KEVEntry, len(catalog.Vulnerabilities))
+	for _, v := range catalog.Vulnerabilities {
+		kev[v.CVEID] = KEVEntry{
+			CVE:                v.CVEID,
+			Name:               v.VulnerabilityName,
+			DateAdded:          v.DateAdded,
+			DueDate:            v.DueDate,
+			RequiredAction:     v.RequiredAction,
+			RansomwareCampaign: v.KnownRansomwareCampaignUse,
+		}
+	}
+	return kev, nil
+}
+
+// parseEPSS reads a FIRST EPSS scores CSV. The first line is a comment with the
+// model version and score date, followed by a cve,epss,percentile header.
+func parseEPSS(r io.Reader) (map[string]EPSSEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	epss := make(map[string]EPSSEntry)
+	var date string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) > 0 && strings.HasPrefix(record[0], "#") {
+			// #model_version:v2023.03.01,score_date:2024-01-01T00:00:00+0000
+			for _, field := range record {
+				if v, ok := strings.CutPrefix(field, "score_date:"); ok {
+					date, _, _ = strings.Cut(v, "T")
+				}
+			}
+			continue
+		}
+		if len(record) < 3 || record[0] == "cve" {
+			continue
+		}
+
+		score, err1 := strconv.ParseFloat(record[1], 64)
+		percentile, err2 := strconv.ParseFloat(record[2], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		epss[record[0]] = EPSSEntry{Score: score, Percentile: percentile, Date: date}
+	}
+	return epss, nil
+}
+
+// parseCWE reads MITRE's CWE CSV, whose first two columns are the numeric ID and the name
+func parseCWE(r io.Reader) (map[string]CWEEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	cwe := make(map[string]CWEEntry)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			continue
+		}
+		if _, err := strconv.Atoi(record[0]); err != nil {
+			continue // header
+		}
+		id := "CWE-" + record[0]
+		cwe[id] = CWEEntry{ID: id, Name: record[1]}
+	}
+	return cwe, nil
+}
+
+// Download fetches the current datasets into dir, creating it if needed
+func Download(ctx context.Context, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+
+	kev, err := fetch(ctx, KEVURL)
+	if err != nil {
+		return fmt.Errorf("failed to download KEV catalog: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, KEVFile), kev, 0o644); err != nil {
+		return err
+	}
+
+	epss, err := fetch(ctx, EPSSURL)
+	if err != nil {
+		return fmt.Errorf("failed to download EPSS scores: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, EPSSFile), epss, 0o644); err != nil {
+		return err
+	}
+
+	cweZip, err := fetch(ctx, CWEURL)
+	if err != nil {
+		return fmt.Errorf("failed to download CWE list: %w", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(cweZip), int64(len(cweZip)))
+	if err != nil {
+		return fmt.Errorf("failed to open CWE archive: %w", err)
+	}
+	for _, zf := range zr.File {
+		if filepath.Ext(zf.Name) != ".csv" {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, CWEFile), data, 0o644)
+	}
+	return errors.New("no CSV file in the CWE archive")
+}
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s failed with status: %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,87 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// EPSSAPIURL is FIRST's EPSS API, which returns the current scores of given CVEs
+const EPSSAPIURL = "https://api.first.org/data/v1/epss"
+
+// epssBatchSize is how many CVEs are asked for per EPSS API request
+const epssBatchSize = 100
+
+// Live fetches the current KEV catalog and EPSS scores, for runs with network
+// access that enrich findings without a bundle
+type Live struct {
+	// KEVURL is the KEV catalog feed (default KEVURL)
+	KEVURL string
+	// EPSSAPIURL is the EPSS API (default EPSSAPIURL)
+	EPSSAPIURL string
+}
+
+// FetchKEV downloads the KEV catalog
+func (l Live) FetchKEV(ctx context.Context) (map[string]KEVEntry, error) {
+	data, err := fetch(ctx, firstNonEmpty(l.KEVURL, KEVURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download KEV catalog: %w", err)
+	}
+	kev, err := parseKEV(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse KEV catalog: %w", err)
+	}
+	return kev, nil
+}
+
+// FetchEPSS returns the current EPSS scores of cves. CVEs without a score are
+// left out.
+func (l Live) FetchEPSS(ctx context.Context, cves []string) (map[string]EPSSEntry, error) {
+	epss := make(map[string]EPSSEntry, len(cves))
+	for start := 0; start < len(cves); start += epssBatchSize {
+		batch := cves[start:min(start+epssBatchSize, len(cves))]
+		q := url.Values{}
+		q.Set("cve", strings.Join(batch, ","))
+		// The API pages at 100 by default, the size of a batch
+		q.Set("limit", strconv.Itoa(epssBatchSize))
+		data, err := fetch(ctx, firstNonEmpty(l.EPSSAPIURL, EPSSAPIURL)+"?"+q.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch EPSS scores: %w", err)
+		}
+
+		// Scores are strings in the API response
+		var resp struct {
+			Data []struct {
+				CVE        string `json:"cve"`
+				EPSS       string `json:"epss"`
+				Percentile string `json:"percentile"`
+				Date       string `json:"date"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("failed to parse EPSS scores: %w", err)
+		}
+		for _, d := range resp.Data {
+			score, err1 := strconv.ParseFloat(d.EPSS, 64)
+			percentile, err2 := strconv.ParseFloat(d.Percentile, 64)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			epss[d.CVE] = EPSSEntry{Score: score, Percentile: percentile, Date: d.Date}
+		}
+	}
+	return epss, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
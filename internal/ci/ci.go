@@ -0,0 +1,155 @@
+// Package ci recognizes well-known CI systems from their environment variables
+// and reads the repository, commit and pull request of the running build.
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Providers
+const (
+	GitHubActions = "github-actions"
+	GitLabCI      = "gitlab-ci"
+	Jenkins       = "jenkins"
+)
+
+// Env describes the build the tool is running in
+type Env struct {
+	Provider string `json:"provider"`
+	// RepoURL is the repository's web URL, e.g. https://github.com/acme/app
+	RepoURL   string `json:"repo_url,omitempty"`
+	CommitSHA string `json:"commit_sha,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	// PRNumber is the pull or merge request number, when the build is for one
+	PRNumber string `json:"pr_number,omitempty"`
+	// BuildURL links to the build's page in the CI system
+	BuildURL string `json:"build_url,omitempty"`
+}
+
+// Detect returns the CI environment described by getenv, usually os.Getenv.
+// ok is false outside a recognized CI system.
+func Detect(getenv func(string) string) (env Env, ok bool) {
+	switch {
+	case getenv("GITHUB_ACTIONS") == "true":
+		server := firstNonEmpty(getenv("GITHUB_SERVER_URL"), "https://github.com")
+		env = Env{
+			Provider:  GitHubActions,
+			RepoURL:   joinURL(server, getenv("GITHUB_REPOSITORY")),
+			CommitSHA: getenv("GITHUB_SHA"),
+			Branch:    firstNonEmpty(getenv("GITHUB_HEAD_REF"), getenv("GITHUB_REF_NAME")),
+		}
+		// Pull request builds run on refs/pull/<number>/merge
+		if m := githubPRRef.FindStringSubmatch(getenv("GITHUB_REF")); m != nil {
+			env.PRNumber = m[1]
+		}
+		if env.RepoURL != "" && getenv("GITHUB_RUN_ID") != "" {
+			env.BuildURL = env.RepoURL + "/actions/runs/" + getenv("GITHUB_RUN_ID")
+		}
+		return env, true
+
+	case getenv("GITLAB_CI") == "true":
+		return Env{
+			Provider:  GitLabCI,
+			RepoURL:   getenv("CI_PROJECT_URL"),
+			CommitSHA: getenv("CI_COMMIT_SHA"),
+			Branch:    firstNonEmpty(getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"), getenv("CI_COMMIT_REF_NAME")),
+			PRNumber:  getenv("CI_MERGE_REQUEST_IID"),
+			BuildURL:  getenv("CI_PIPELINE_URL"),
+		}, true
+
+	case getenv("JENKINS_URL") != "":
+		return Env{
+			Provider:  Jenkins,
+			RepoURL:   strings.TrimSuffix(firstNonEmpty(getenv("GIT_URL"), getenv("CHANGE_URL")), ".git"),
+			CommitSHA: getenv("GIT_COMMIT"),
+			Branch:    firstNonEmpty(getenv("CHANGE_BRANCH"), getenv("BRANCH_NAME"), getenv("GIT_BRANCH")),
+			PRNumber:  getenv("CHANGE_ID"),
+			BuildURL:  getenv("BUILD_URL"),
+		}, true
+	}
+	return Env{}, false
+}
+
+var githubPRRef = regexp.MustCompile(`^refs/pull/(\d+)/`)
+
+// String describes the build for logs
+func (e Env) String() string {
+	s := e.Provider
+	if e.RepoURL != "" {
+		s += " build of " + e.RepoURL
+	}
+	if e.PRNumber != "" {
+		s += " (pull request #" + e.PRNumber + ")"
+	} else if e.Branch != "" {
+		s += " (" + e.Branch + ")"
+	}
+	if len(e.CommitSHA) >= 7 {
+		s += " at " + e.CommitSHA[:7]
+	}
+	return s
+}
+
+// Annotate writes findings as annotations the CI system shows on the build,
+// e.g. GitHub Actions workflow commands. It writes nothing for providers
+// without an annotation syntax.
+func (e Env) Annotate(w io.Writer, findings []api.Finding) error {
+	if e.Provider != GitHubActions {
+		return nil
+	}
+	for _, f := range findings {
+		command := "warning"
+		if f.Spec.Level.AtLeast(api.LevelHigh) {
+			command = "error"
+		}
+
+		title := f.Spec.Level.Short() + ": " + f.Spec.TargetDependencyPackageName
+		if cve := f.CVE(); cve != "" {
+			title = cve + " in " + f.Spec.TargetDependencyPackageName
+		}
+		props := "title=" + escapeProperty(title)
+		if len(f.Spec.DependencyFilePath) > 0 {
+			props = "file=" + escapeProperty(f.Spec.DependencyFilePath[0]) + "," + props
+		}
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", command, props, escapeData(f.Meta.Description)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes a GitHub Actions workflow command message
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a GitHub Actions workflow command property value
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// StepSummaryPath returns the file GitHub Actions renders as the job summary, if any
+func StepSummaryPath() string {
+	return os.Getenv("GITHUB_STEP_SUMMARY")
+}
+
+func joinURL(base, path string) string {
+	if path == "" {
+		return ""
+	}
+	return strings.TrimRight(base, "/") + "/" + path
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
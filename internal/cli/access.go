@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// checkNamespaceAccess authenticates in namespace and reads one of its projects,
+// recording the outcome in report
+func (g *globalOptions) checkNamespaceAccess(ctx context.Context, namespace string, report *api.AccessReport) string {
+	client, err := g.newNamespaceClient(namespace)
+	if err == nil {
+		var token string
+		if token, err = g.token(ctx, client); err == nil {
+			err = client.CheckAccess(ctx, token)
+		}
+	}
+	return report.Record(namespace, err)
+}
+
+// logAccessReport logs the namespaces of report that could not be read
+func logAccessReport(report *api.AccessReport) {
+	failed := report.Failed()
+	if len(failed) == 0 {
+		return
+	}
+	slog.Warn("Some namespaces could not be read", "failed", len(failed), "namespaces", len(report.Namespaces()))
+	for _, a := range failed {
+		slog.Warn("Namespace could not be read", "namespace", a.Namespace, "status", a.Status)
+	}
+}
+
+// writeAccessReport prints every namespace of report with its access status
+func writeAccessReport(w io.Writer, report *api.AccessReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tACCESS\tERROR")
+	for _, a := range report.Namespaces() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", a.Namespace, a.Status, a.Error)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newAuthCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage authentication with the Endor Labs API",
+	}
+
+	cmd.AddCommand(newAuthTestCmd(g), newAuthLoginCmd(g), newAuthLogoutCmd(g))
+	return cmd
+}
+
+func newAuthTestCmd(g *globalOptions) *cobra.Command {
+	var namespaces []string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Check that the configured API key and secret can authenticate",
+		Long: `Check that the configured API key and secret can authenticate. With
+--namespaces, every listed namespace is checked for read access and an access
+report is printed; namespaces the credentials cannot read do not stop the
+others from being checked.`,
+		Example: `  findings-api auth test
+  findings-api auth test --namespaces acme.payments,acme.billing,acme.legacy`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// A cached token would not prove the credentials still work
+			g.NoCache = true
+			if len(namespaces) == 0 {
+				if _, _, err := g.authenticate(cmd.Context()); err != nil {
+					return err
+				}
+				fmt.Println("Authentication successful")
+				return nil
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+
+			report := api.NewAccessReport()
+			for _, ns := range namespaces {
+				if err := cmd.Context().Err(); err != nil {
+					return err
+				}
+				status := g.checkNamespaceAccess(cmd.Context(), ns, report)
+				slog.Info("Namespace access", "namespace", ns, "status", status)
+			}
+
+			var err error
+			if format == "json" {
+				err = printJSON(report.Namespaces())
+			} else {
+				err = writeAccessReport(os.Stdout, report)
+			}
+			if err != nil {
+				return err
+			}
+			if failed := report.Failed(); len(failed) > 0 {
+				return fmt.Errorf("%d of %d namespaces could not be read", len(failed), len(namespaces))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&namespaces, "namespaces", nil, "Check read access to each of these namespaces and print an access report")
+	cmd.Flags().StringVar(&format, "format", "table", "Access report format (table or json)")
+	return cmd
+}
+
+func newAuthLoginCmd(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "login",
+		Short: "Authenticate and cache the token for later commands",
+		Long: `Authenticate with the configured API key and secret and store the token in
+the token cache ($ENDOR_TOKEN_CACHE or ~/.endor/token.json). Later commands
+using the same key and namespace reuse it until it is about to expire.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+			token, err := client.Authenticate(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to get authentication token: %w", err)
+			}
+			if token.Expiry.IsZero() {
+				fmt.Println("Authentication successful, but the token has no known expiry and was not cached")
+				return nil
+			}
+
+			cache, err := openTokenCache()
+			if err != nil {
+				return err
+			}
+			if err := cache.Put(client.CacheKey(), token); err != nil {
+				return err
+			}
+			fmt.Printf("Logged in to namespace %s, token valid until %s\n", client.Namespace(), token.Expiry.Local().Format(time.RFC1123))
+			return nil
+		},
+	}
+}
+
+func newAuthLogoutCmd(g *globalOptions) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the cached token for the configured key and namespace",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache, err := openTokenCache()
+			if err != nil {
+				return err
+			}
+			if all {
+				if err := cache.Clear(); err != nil {
+					return err
+				}
+				fmt.Println("Removed all cached tokens")
+				return nil
+			}
+
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+			removed, err := cache.Delete(client.CacheKey())
+			if err != nil {
+				return err
+			}
+			if !removed {
+				fmt.Printf("No cached token for namespace %s\n", client.Namespace())
+				return nil
+			}
+			fmt.Printf("Removed the cached token for namespace %s\n", client.Namespace())
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Remove every cached token")
+	return cmd
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func newFindingsBrowseCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var uiURL string
+
+	cmd := &cobra.Command{
+		Use:   "browse",
+		Short: "Browse and triage findings in an interactive terminal UI",
+		Long: `Fetch the selected findings and browse them in the terminal: a list of
+findings, most severe first and colored by level, next to the details of the
+selected one.
+
+Keys:
+  ↑/↓, j/k          move through the list
+  PgUp/PgDn, g/G    move a page, or to the first or last finding
+  /                 filter as you type; Enter keeps the filter, Esc clears it
+  J/K               scroll the details
+  o, Enter          open the finding in the Endor Labs app
+  q, Ctrl-C         quit
+
+The filter keeps the findings that contain every word typed, in their
+advisory IDs, description, level, package, project, tags or categories.`,
+		Example: `  findings-api findings browse --project_uuid abc123-def456-ghi789
+  findings-api findings browse --all-projects --level critical,high --reachable-only`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !tui.IsTerminal(os.Stdin) || !tui.IsTerminal(os.Stdout) {
+				return errors.New("findings browse needs an interactive terminal; use findings list or export to print findings")
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if err := g.validateNamespaces(opts); err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			fetched, err := g.fetchSelected(cmd.Context(), opts, filter)
+			if err != nil {
+				return err
+			}
+			logFetchReport(fetched.Report)
+			logWarnings(fetched.Warnings)
+			if len(fetched.Findings) == 0 {
+				fmt.Printf("No findings for %s\n", opts.description())
+				return nil
+			}
+
+			uiURL = firstNonEmpty(uiURL, os.Getenv("ENDOR_UI_URL"))
+			namespace := g.namespace()
+			browser := tui.New(opts.description(), fetched.Findings, func(f api.Finding) string {
+				return output.FindingURL(uiURL, namespace, f)
+			})
+			return tui.Run(cmd.Context(), os.Stdin, os.Stdout, browser, tui.OpenURL)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().StringVar(&uiURL, "ui-url", "", "Endor Labs app URL that findings are opened in (default: $ENDOR_UI_URL or "+output.DefaultUIURL+")")
+	g.addNamespacesFlag(cmd.Flags())
+	return cmd
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/endor-labs/findings-api/internal/bundle"
+	"github.com/spf13/cobra"
+)
+
+func newBundleCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Manage enrichment bundles for air-gapped environments",
+	}
+	cmd.AddCommand(newBundleDownloadCmd(g), newBundleInfoCmd())
+	return cmd
+}
+
+func newBundleDownloadCmd(g *globalOptions) *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "download",
+		Short: "Download the current KEV catalog, EPSS scores and CWE list into a bundle directory",
+		Long: `Download the CISA KEV catalog, the FIRST EPSS scores and the MITRE CWE list
+into a directory on a connected machine. Copy the directory into the
+air-gapped environment and pass it to findings commands with --bundle.`,
+		Example: `  findings-api bundle download --dir ./endor-bundle`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := g.requireNetwork("downloading an enrichment bundle"); err != nil {
+				return err
+			}
+
+			slog.Info("Downloading enrichment data", "dir", dir)
+			if err := bundle.Download(cmd.Context(), dir); err != nil {
+				return err
+			}
+			b, err := bundle.Load(dir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Bundle written to %s: %s\n", dir, b.Summary())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "endor-bundle", "Bundle directory to write")
+	return cmd
+}
+
+func newBundleInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info <dir>",
+		Short: "Show what a bundle directory contains",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := bundle.Load(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(b.Summary())
+			return nil
+		},
+	}
+}
@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/diff"
+	"github.com/endor-labs/findings-api/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+func newFindingsCheckCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var policyPaths []string
+	var format, regoPackage string
+
+	cmd := &cobra.Command{
+		Use:   "check [snapshot]",
+		Short: "Check findings against the rules of YAML or Rego policies",
+		Long: `Evaluate the rules of policy files against the selected findings, or the
+findings of a snapshot written by findings export --format json or ndjson, and
+report each rule as passed or failed with the findings that violate it.
+--policy takes YAML rule files (.yaml or .yml) and Rego modules, or
+directories of them, and may be repeated.
+
+A rule matches the findings that meet every condition of its match section;
+it is violated when more findings match than its max_violations (default 0).
+The check exits with code 2 when a rule of severity error is violated, while
+violated warning rules are only reported:
+
+  rules:
+    - name: old-fixable-reachable-criticals
+      description: Reachable criticals with a fix must be fixed within 30 days
+      match:
+        levels: [critical]
+        reachable: true
+        fix_available: true
+        older_than: 30d
+    - name: exploitable-mediums
+      severity: warning
+      match:
+        levels: [medium]
+        epss_min: 0.1
+
+Match conditions are levels, categories, tags, ecosystems, projects (UUIDs),
+reachable, fix_available, older_than (30d or 12h), epss_min and cvss_min. The
+usual filter flags still select the findings fetched, so widen them, e.g. with
+--fix-available=false, when rules are meant to see every finding.
+
+Rego policies are evaluated by the embedded OPA with the findings as
+input.findings, in the JSON form of findings export. Every result of the deny
+and violation rules of --rego-package (default main, as with conftest) fails
+the check, and every result of warn is reported. A result is a message, or an
+object with a msg and the uuid of the finding it is about:
+
+  package main
+  import rego.v1
+
+  deny contains {"msg": msg, "uuid": f.uuid} if {
+    some f in input.findings
+    f.spec.level == "FINDING_LEVEL_CRITICAL"
+    "FINDING_TAGS_FIX_AVAILABLE" in f.spec.finding_tags
+    msg := sprintf("%s has a fix available", [f.meta.name])
+  }`,
+		Example: `  findings-api findings check --policy policy.yaml --all-projects --level critical,high,medium,low --fix-available=false
+  findings-api findings check --policy policy.yaml reports/findings.json --format json
+  findings-api findings check --policy policies/ --rego-package findings.ci reports/findings.json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected text or json)", format)
+			}
+			check, err := loadPolicies(cmd.Context(), policyPaths, regoPackage)
+			if err != nil {
+				return err
+			}
+
+			var findings []api.Finding
+			if len(args) == 1 {
+				if findings, err = diff.Load(args[0]); err != nil {
+					return err
+				}
+				slog.Info("Checking findings", "path", args[0], "findings", len(findings))
+			} else {
+				if err := opts.validate(); err != nil {
+					return err
+				}
+				if err := g.validateNamespaces(opts); err != nil {
+					return err
+				}
+				filter, err := opts.buildFilter()
+				if err != nil {
+					return err
+				}
+				fetched, err := g.fetchSelected(cmd.Context(), opts, filter)
+				if err != nil {
+					return err
+				}
+				logFetchReport(fetched.Report)
+				logWarnings(fetched.Warnings)
+				if len(fetched.ProjectErrors) > 0 {
+					// A gate must not pass on findings it could not see
+					return fmt.Errorf("failed to fetch findings of %d projects; not checking an incomplete set", len(fetched.ProjectErrors))
+				}
+				findings = fetched.Findings
+			}
+
+			result, err := check(findings)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				if err := printJSON(result); err != nil {
+					return err
+				}
+			} else if err := printPolicyResult(os.Stdout, result); err != nil {
+				return err
+			}
+
+			if failed := result.Failed(); len(failed) > 0 {
+				return &exitError{
+					code: exitFindingsFound,
+					err:  fmt.Errorf("policy check failed: %d of %d rules violated", len(failed), len(result.Rules)),
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&policyPaths, "policy", nil, "YAML policy files (.yaml, .yml) and Rego modules or directories of them; may be repeated")
+	cmd.Flags().StringVar(&regoPackage, "rego-package", policy.DefaultRegoPackage, "Package of the Rego policy whose deny, violation and warn rules are evaluated")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text or json)")
+	opts.addFlags(cmd.Flags())
+	g.addNamespacesFlag(cmd.Flags())
+	cmd.MarkFlagRequired("policy")
+	return cmd
+}
+
+// loadPolicies loads the YAML policies and the Rego modules at paths and
+// returns the function checking findings against all of them
+func loadPolicies(ctx context.Context, paths []string, regoPackage string) (func([]api.Finding) (policy.Result, error), error) {
+	var yamlPolicies []*policy.Policy
+	var regoPaths []string
+	for _, path := range paths {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			p, err := policy.Load(path)
+			if err != nil {
+				return nil, err
+			}
+			yamlPolicies = append(yamlPolicies, p)
+		default:
+			regoPaths = append(regoPaths, path)
+		}
+	}
+	var regoPolicy *policy.Rego
+	if len(regoPaths) > 0 {
+		var err error
+		if regoPolicy, err = policy.LoadRego(ctx, regoPaths, regoPackage); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(findings []api.Finding) (policy.Result, error) {
+		now := time.Now()
+		result := policy.Result{Passed: true, Findings: len(findings)}
+		for _, p := range yamlPolicies {
+			result = result.Merge(p.Evaluate(findings, now))
+		}
+		if regoPolicy != nil {
+			r, err := regoPolicy.Evaluate(ctx, findings)
+			if err != nil {
+				return result, err
+			}
+			result = result.Merge(r)
+		}
+		return result, nil
+	}, nil
+}
+
+// policyViolationColumns are the columns of violation tables; columns empty
+// for every violation of a rule are left out
+var policyViolationColumns = []struct {
+	header string
+	value  func(policy.Violation) string
+}{
+	{"MESSAGE", func(v policy.Violation) string { return v.Message }},
+	{"LEVEL", func(v policy.Violation) string { return v.Level.Short() }},
+	{"NAME", func(v policy.Violation) string { return v.Name }},
+	{"PACKAGE", func(v policy.Violation) string { return v.Package }},
+	{"CREATED", func(v policy.Violation) string { return v.CreateTime }},
+	{"PROJECT", func(v policy.Violation) string { return v.ProjectUUID }},
+	{"UUID", func(v policy.Violation) string { return v.UUID }},
+}
+
+// printPolicyResult writes the outcome of every rule, then the findings
+// violating each rule that did not pass
+func printPolicyResult(w io.Writer, result policy.Result) error {
+	verdict := "passed"
+	if !result.Passed {
+		verdict = "failed"
+	}
+	fmt.Fprintf(w, "Policy check %s: %d findings checked against %d rules\n\n", verdict, result.Findings, len(result.Rules))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESULT\tSEVERITY\tVIOLATIONS\tRULE")
+	for _, r := range result.Rules {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+			if r.Severity == policy.SeverityWarning {
+				status = "WARN"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n", status, r.Severity, len(r.Violations), r.MaxViolations, r.Name)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range result.Rules {
+		if r.Passed {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s", r.Name)
+		if r.Description != "" {
+			fmt.Fprintf(w, ": %s", r.Description)
+		}
+		fmt.Fprintln(w)
+		// Rego rules may report messages, with or without the finding they are about
+		var columns []string
+		var values []func(policy.Violation) string
+		for _, c := range policyViolationColumns {
+			for _, v := range r.Violations {
+				if c.value(v) != "" {
+					columns = append(columns, c.header)
+					values = append(values, c.value)
+					break
+				}
+			}
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  "+strings.Join(columns, "\t"))
+		for _, v := range r.Violations {
+			row := make([]string, len(values))
+			for i, value := range values {
+				row[i] = firstNonEmpty(value(v), "-")
+			}
+			fmt.Fprintln(tw, "  "+strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/pflag"
+)
+
+// noCheckpoint is the --checkpoint value that disables the checkpoint
+const noCheckpoint = "none"
+
+// checkpointFileName is the default checkpoint, next to the run manifest
+const checkpointFileName = "findings.checkpoint"
+
+// addCheckpointFlags registers --checkpoint and --resume on fs
+func (g *globalOptions) addCheckpointFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&g.Checkpoint, "checkpoint", "", `File recording each fetched page, so an interrupted fetch can be resumed (default: `+checkpointFileName+` next to --output; "none" disables it)`)
+	fs.BoolVar(&g.Resume, "resume", false, "Resume the interrupted fetch recorded in the checkpoint instead of starting from the first page")
+}
+
+// checkpointPath returns where the checkpoint goes: --checkpoint, or
+// findings.checkpoint in the directory of --output (the current directory
+// when unset or writing to stdout), inside --out-dir when given
+func (g *globalOptions) checkpointPath() string {
+	switch {
+	case strings.EqualFold(g.Checkpoint, noCheckpoint):
+		return ""
+	case g.Checkpoint != "":
+		return g.Checkpoint
+	case g.Output != "" && g.Output != stdoutPath:
+		return g.inOutDir(filepath.Join(filepath.Dir(g.Output), checkpointFileName))
+	default:
+		return g.inOutDir(checkpointFileName)
+	}
+}
+
+// openCheckpoint starts recording the fetch of the running command in the
+// checkpoint, continuing the one recorded there with --resume. Clients created
+// afterwards record their listings in it.
+func (g *globalOptions) openCheckpoint() error {
+	path := g.checkpointPath()
+	if path == "" {
+		if g.Resume {
+			return errors.New("--resume cannot be used with --checkpoint none")
+		}
+		return nil
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 && !g.Resume {
+		slog.Warn("Discarding the checkpoint of an interrupted fetch; pass --resume to continue it instead", "path", path)
+	}
+
+	cp, err := api.OpenCheckpoint(path, g.Resume)
+	if err != nil {
+		return err
+	}
+	if g.Resume {
+		if listings, findings := cp.Resumed(); listings > 0 {
+			slog.Info("Resuming from the checkpoint", "path", path, "listings", listings, "findings", findings)
+		} else {
+			slog.Info("No checkpoint to resume, starting from the first page", "path", path)
+		}
+	}
+	g.checkpoint = cp
+	return nil
+}
+
+// closeCheckpoint removes the checkpoint once the fetch completed, and keeps
+// it for --resume when the run failed. Findings failing --fail-on were
+// fetched completely, so they do not keep it.
+func (g *globalOptions) closeCheckpoint(err error) {
+	cp := g.checkpoint
+	if cp == nil {
+		return
+	}
+	g.checkpoint = nil
+
+	var exit *exitError
+	if err != nil && !(errors.As(err, &exit) && exit.code == exitFindingsFound) {
+		if cerr := cp.Close(); cerr != nil {
+			slog.Warn("Failed to close the checkpoint", "error", cerr)
+		}
+		slog.Info("Fetch progress saved; rerun with --resume to continue where it stopped", "path", cp.Path())
+		return
+	}
+	if err := cp.Remove(); err != nil {
+		slog.Warn("Failed to remove the checkpoint", "error", err)
+	}
+}
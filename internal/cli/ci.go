@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/ci"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// ciDefaultName is the base name of the files ci saves, before the extension
+const ciDefaultName = "endor-findings"
+
+func newCICmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var formats []string
+	var annotate bool
+	var textSpec string
+
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Check the current CI build's repository with zero configuration",
+		Long: `Detect GitHub Actions, GitLab CI or Jenkins from the environment and check the
+findings of the repository being built:
+
+  - the project is resolved from the build's repository URL
+  - findings are saved as SARIF to endor-findings.sarif
+  - on GitHub Actions, findings are annotated on the build and summarized
+    in the job summary
+  - the command exits with code 2 when findings at or above --fail-on
+    (default high) are found
+
+Any findings flag overrides the detected defaults.`,
+		Example: `  findings-api ci
+  findings-api ci --fail-on critical --format sarif,json`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			run := g.startRun(cmd, args)
+			defer func() { err = g.finishRun(cmd.Context(), run, err) }()
+
+			env, detected := ci.Detect(os.Getenv)
+			if detected {
+				slog.Info("Detected CI environment", "ci", env)
+				run.CI = &env
+			}
+			if !opts.AllProjects && len(opts.ProjectUUIDs) == 0 && len(opts.Repos) == 0 && len(opts.ProjectNames) == 0 {
+				if env.RepoURL == "" {
+					return errors.New("not running in a supported CI system (GitHub Actions, GitLab CI or Jenkins); pass --repo or --project_uuid")
+				}
+				opts.Repos = []string{env.RepoURL}
+			}
+			if strings.EqualFold(opts.FailOn, "none") {
+				opts.FailOn = ""
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			text, err := parseTextFlag(textSpec)
+			if err != nil {
+				return err
+			}
+			writers, err := newFormatWriters(formats, output.Options{Text: text})
+			if err != nil {
+				return err
+			}
+			if len(writers) > 1 && g.Output == stdoutPath {
+				return errors.New("several --format values cannot all be written to stdout")
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(cmd.Context(), client, token); err != nil {
+				return err
+			}
+
+			slog.Info("Fetching findings", "selection", opts.description())
+			result, err := g.fetch(cmd.Context(), opts, client, token, filter, api.NewProjectCache(client))
+			if err != nil {
+				return err
+			}
+			report := client.FetchReport()
+			logFetchReport(report)
+			warnings := client.Warnings()
+			logWarnings(warnings)
+			run.Selection, run.Filter = opts.description(), filter
+			run.SetFindings(result.Findings, len(warnings), len(result.ProjectErrors))
+
+			doc := output.NewDocument(result.Findings, opts.description(), report, result.ProjectErrors, warnings)
+			doc.Correlations = result.Correlations
+			filename := func(ext string) string {
+				return exportFilename(g.Output, ciDefaultName+"."+ext, ext, len(writers) > 1)
+			}
+			failed := 0
+			for _, r := range renderFormats(writers, doc, filename) {
+				if r.Err != nil {
+					failed++
+					err := fmt.Errorf("failed to save %s: %w", r.Format, r.Err)
+					slog.Error("Failed to save a format", "format", r.Format, "error", r.Err)
+					run.AddError(err)
+					continue
+				}
+				addRunOutput(run, r.Filename, r.Format)
+				if r.Filename != stdoutPath {
+					fmt.Fprintf(g.console(), "Findings saved to: %s\n", r.Filename)
+				}
+			}
+
+			if annotate && g.Output != stdoutPath {
+				if err := env.Annotate(os.Stdout, result.Findings); err != nil {
+					slog.Warn("Failed to write annotations", "error", err)
+				}
+			}
+			if path := ci.StepSummaryPath(); env.Provider == ci.GitHubActions && path != "" {
+				if err := appendStepSummary(path, opts.description(), result.Findings, opts.FailOn); err != nil {
+					slog.Warn("Failed to write the job summary", "error", err)
+				}
+			}
+
+			fmt.Fprintf(g.console(), "Found %d findings for %s\n", len(result.Findings), opts.description())
+			if failed > 0 {
+				return fmt.Errorf("failed to save findings in %d of %d formats", failed, len(writers))
+			}
+			return opts.checkFailOn(result.Findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().StringVar(&opts.FailOn, "fail-on", "high", `Exit with code 2 when findings at or above this level are found (critical, high, medium, low or "none")`)
+	cmd.Flags().StringSliceVar(&formats, "format", []string{"sarif"}, "Output format, or comma-separated formats: "+strings.Join(output.Formats, ", "))
+	cmd.Flags().StringVar(&textSpec, "text", output.TextFull, textUsage)
+	cmd.Flags().BoolVar(&annotate, "annotations", true, "Annotate findings on the build where the CI system supports it (GitHub Actions)")
+	return cmd
+}
+
+// appendStepSummary adds a Markdown summary of findings by level to the GitHub
+// Actions job summary file
+func appendStepSummary(path, desc string, findings []api.Finding, failOn string) error {
+	counts := make(map[api.FindingLevel]int)
+	for _, f := range findings {
+		counts[f.Spec.Level]++
+	}
+	levels := make([]api.FindingLevel, 0, len(counts))
+	for l := range counts {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Rank() > levels[j].Rank() })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Endor Labs findings\n\n%d findings for %s.\n\n", len(findings), desc)
+	if len(levels) > 0 {
+		sb.WriteString("| Level | Findings |\n| --- | ---: |\n")
+		for _, l := range levels {
+			fmt.Fprintf(&sb, "| %s | %d |\n", l.Short(), counts[l])
+		}
+		sb.WriteString("\n")
+	}
+	if failOn != "" {
+		fmt.Fprintf(&sb, "The build fails on findings at or above level **%s**.\n", failOn)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(sb.String()); err != nil {
+		return err
+	}
+	return f.Close()
+}
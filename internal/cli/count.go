@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/contexts"
+	"github.com/spf13/cobra"
+)
+
+// countBreakdowns maps the values of findings count --by to the finding
+// field the API groups by
+var countBreakdowns = map[string]string{
+	"level":    "spec.level",
+	"category": "spec.finding_categories",
+}
+
+// groupFieldAliases are the short names findings count --group accepts for
+// finding fields, besides their paths
+var groupFieldAliases = map[string]string{
+	"level":    "spec.level",
+	"category": "spec.finding_categories",
+	"package":  "spec.target_dependency_package_name",
+	"project":  "spec.project_uuid",
+}
+
+// findingsCount is the output of findings count
+type findingsCount struct {
+	Selection string `json:"selection"`
+	Filter    string `json:"filter,omitempty"`
+	Total     int    `json:"total"`
+	// Levels counts the findings of every level, by short level
+	Levels map[string]int `json:"levels,omitempty"`
+	// Categories counts the findings of each category found, by short
+	// category; a finding of several categories counts in each
+	Categories map[string]int `json:"categories,omitempty"`
+	// Groups are the counts of findings sharing the values of the GroupBy
+	// fields, largest first
+	GroupBy []string           `json:"group_by,omitempty"`
+	Groups  []api.FindingGroup `json:"groups,omitempty"`
+}
+
+func newFindingsCountCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var format string
+	var by []string
+
+	cmd := &cobra.Command{
+		Use:   "count",
+		Short: "Count findings by level and category without fetching them",
+		Long: `Count the findings matching the filter flags, in total and by level and
+category, from the API's count and group aggregation rather than by listing
+every finding, so counting a whole namespace takes a few requests.
+
+--group counts the findings per value of any finding fields instead, e.g.
+--group package or --group spec.target_dependency_package_name,spec.level,
+with the server grouping them, so a rollup of a namespace transfers a line
+per group rather than every finding.
+
+Filters applied to fetched findings, such as --kev-only or --min-risk, are
+not available; use findings list --group-by for them.`,
+		Example: `  findings-api findings count --all-projects --level critical
+  findings-api findings count --all-projects --level critical,high,medium,low --format json | jq .levels.critical
+  findings-api findings count --repo github.com/acme/payments --by level
+  findings-api findings count --all-projects --group package,level`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			for _, b := range by {
+				if _, ok := countBreakdowns[b]; !ok {
+					return fmt.Errorf("invalid --by %q (expected level or category)", b)
+				}
+			}
+			if len(g.List.GroupBy) > 0 {
+				if cmd.Flags().Changed("by") {
+					return errors.New("--group cannot be combined with --by")
+				}
+				by = nil
+				for i, field := range g.List.GroupBy {
+					if path, ok := groupFieldAliases[field]; ok {
+						g.List.GroupBy[i] = path
+					}
+				}
+			}
+			// Merged contexts are deduplicated after fetching, so the API cannot count them
+			if opts.Filter.RawFilter == "" {
+				specs, err := contexts.ParseList(opts.Filter.Contexts)
+				if err != nil {
+					return err
+				}
+				if len(specs) > 1 || specs[0].Latest() {
+					return fmt.Errorf("--context %s cannot be counted; count one context at a time", opts.Filter.Contexts)
+				}
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(cmd.Context(), client, token); err != nil {
+				return err
+			}
+			var projects []string
+			if !opts.AllProjects {
+				projects = opts.ProjectUUIDs
+			}
+			slog.Info("Counting findings", "selection", opts.description())
+
+			count := findingsCount{Selection: opts.description(), Filter: filter}
+			if count.Total, err = client.CountFindings(cmd.Context(), token, projects, filter); err != nil {
+				return err
+			}
+			if len(g.List.GroupBy) > 0 {
+				count.GroupBy = g.List.GroupBy
+				if count.Groups, err = client.GroupFindings(cmd.Context(), token, projects, filter); err != nil {
+					return err
+				}
+			}
+			for _, b := range by {
+				counts, err := client.CountFindingsBy(cmd.Context(), token, projects, filter, countBreakdowns[b])
+				if err != nil {
+					return err
+				}
+				switch b {
+				case "level":
+					count.Levels = make(map[string]int)
+					for _, level := range api.FindingLevels {
+						count.Levels[level.Short()] = 0
+					}
+					for level, n := range counts {
+						count.Levels[api.FindingLevel(level).Short()] += n
+					}
+				case "category":
+					count.Categories = make(map[string]int)
+					for category, n := range counts {
+						name := api.FindingCategory(category).Short()
+						if name == "" {
+							name = "(none)"
+						}
+						count.Categories[name] += n
+					}
+				}
+			}
+
+			if format == "json" {
+				return printJSON(count)
+			}
+			return writeCountTable(os.Stdout, count)
+		},
+	}
+
+	opts.addProjectFlags(cmd.Flags())
+	opts.addFilterFlags(cmd.Flags())
+	cmd.Flags().StringSliceVar(&by, "by", []string{"level", "category"}, "Break the total down by level, category or both (empty for the total only)")
+	cmd.Flags().StringSliceVar(&g.List.GroupBy, "group", nil, "Count findings per value of these fields, grouped by the server: level, category, package, project or field paths")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
+
+// writeCountTable prints the total and a table per breakdown, levels most
+// severe first and categories and groups most frequent first
+func writeCountTable(w io.Writer, count findingsCount) error {
+	fmt.Fprintf(w, "%d findings for %s\n", count.Total, count.Selection)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if count.Levels != nil {
+		fmt.Fprintln(tw, "\nLEVEL\tCOUNT")
+		for _, level := range api.FindingLevels {
+			fmt.Fprintf(tw, "%s\t%d\n", level.Short(), count.Levels[level.Short()])
+		}
+	}
+	if count.Categories != nil {
+		names := make([]string, 0, len(count.Categories))
+		for name := range count.Categories {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if a, b := count.Categories[names[i]], count.Categories[names[j]]; a != b {
+				return a > b
+			}
+			return names[i] < names[j]
+		})
+		fmt.Fprintln(tw, "\nCATEGORY\tCOUNT")
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s\t%d\n", name, count.Categories[name])
+		}
+	}
+	if count.Groups != nil {
+		fmt.Fprintln(tw)
+		for _, field := range count.GroupBy {
+			fmt.Fprintf(tw, "%s\t", strings.ToUpper(field[strings.LastIndex(field, ".")+1:]))
+		}
+		fmt.Fprintln(tw, "COUNT")
+		for _, group := range count.Groups {
+			for _, field := range count.GroupBy {
+				fmt.Fprintf(tw, "%s\t", groupValueLabel(field, group.Values[field]))
+			}
+			fmt.Fprintf(tw, "%d\n", group.Count)
+		}
+	}
+	return tw.Flush()
+}
+
+// groupValueLabel shortens levels and categories for the table and names
+// findings without the field
+func groupValueLabel(field, value string) string {
+	switch {
+	case value == "":
+		return "(none)"
+	case field == "spec.level":
+		return api.FindingLevel(value).Short()
+	case field == "spec.finding_categories":
+		return api.FindingCategory(value).Short()
+	}
+	return value
+}
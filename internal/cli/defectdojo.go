@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/defectdojo"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defectDojoOptions are the DefectDojo flags; unset flags fall back to the
+// profile's defectdojo section
+type defectDojoOptions struct {
+	URL             string
+	Product         string
+	ProductType     string
+	Engagement      string
+	ScanType        string
+	TestTitle       string
+	MinimumSeverity string
+	Tags            []string
+	CloseOld        bool
+	Reimport        bool
+}
+
+func newDefectDojoCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	do := &defectDojoOptions{}
+	var uiURL string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "defectdojo",
+		Short: "Import findings into DefectDojo as a scan of an engagement",
+		Long: `Import the selected findings into DefectDojo through its import-scan API, as a
+test of the given product and engagement. The product, engagement and product
+type are created when they do not exist yet.
+
+The default scan type, Generic Findings Import, carries each finding's UUID as
+the unique ID from the tool and its first advisory ID as the vulnerability ID,
+so DefectDojo deduplicates findings across imports and --reimport updates the
+existing test: findings no longer reported are closed, fixed findings that
+come back are reactivated and the rest are left untouched. --scan-type SARIF
+uploads the sarif report instead.
+
+Credentials come from the profile's defectdojo section or DEFECTDOJO_API_KEY.
+--dry-run prints the report without uploading it.`,
+		Example: `  findings-api integrations defectdojo --all-projects --defectdojo-url https://dojo.acme.com --product Payments --engagement "Endor Labs CI"
+  findings-api integrations defectdojo --repo github.com/acme/payments --product Payments --engagement main --reimport
+  findings-api integrations defectdojo --project_uuid abc123-def456-ghi789 --product Web --engagement main --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := g.requireNetwork("the DefectDojo integration"); err != nil {
+				return err
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if err := g.validateNamespaces(opts); err != nil {
+				return err
+			}
+			cfg := do.resolve(g, cmd.Flags())
+			var dc *defectdojo.Client
+			if !dryRun {
+				var err error
+				if dc, err = defectdojo.NewClient(cfg); err != nil {
+					return err
+				}
+				cfg = dc.Config()
+			} else {
+				cfg.ScanType = firstNonEmpty(cfg.ScanType, defectdojo.DefaultScanType)
+				if cfg.ScanType != defectdojo.ScanTypeGeneric && cfg.ScanType != defectdojo.ScanTypeSARIF {
+					return fmt.Errorf("unsupported scan type %q (expected %q)", cfg.ScanType, defectdojo.ScanTypes)
+				}
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			fetched, err := g.fetchSelected(cmd.Context(), opts, filter)
+			if err != nil {
+				return err
+			}
+			logFetchReport(fetched.Report)
+			logWarnings(fetched.Warnings)
+
+			uiURL = firstNonEmpty(uiURL, os.Getenv("ENDOR_UI_URL"))
+			report, err := defectDojoReport(cfg.ScanType, fetched, opts.description(), uiURL, g.namespace())
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				_, err := os.Stdout.Write(report)
+				return err
+			}
+
+			slog.Info("Importing findings into DefectDojo", "findings", len(fetched.Findings), "product", cfg.Product, "engagement", cfg.Engagement)
+			res, err := dc.Import(cmd.Context(), report, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := printImportResult(dc, res, len(fetched.Findings)); err != nil {
+				return err
+			}
+			return opts.checkFailOn(fetched.Findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	g.addNamespacesFlag(cmd.Flags())
+	cmd.Flags().StringVar(&do.URL, "defectdojo-url", "", "DefectDojo URL, e.g. https://dojo.acme.com (default: the profile's defectdojo.url or $DEFECTDOJO_URL)")
+	cmd.Flags().StringVar(&do.Product, "product", "", "Name of the DefectDojo product findings are imported into")
+	cmd.Flags().StringVar(&do.ProductType, "product-type", "", "Product type of a product that does not exist yet (default: "+defectdojo.DefaultProductType+")")
+	cmd.Flags().StringVar(&do.Engagement, "engagement", "", "Name of the engagement of the product the test is added to")
+	cmd.Flags().StringVar(&do.ScanType, "scan-type", "", fmt.Sprintf("DefectDojo scan type of the report: %q or %q (default: %q)", defectdojo.ScanTypeGeneric, defectdojo.ScanTypeSARIF, defectdojo.DefaultScanType))
+	cmd.Flags().StringVar(&do.TestTitle, "test-title", "", "Title of the imported test, which --reimport updates (default: "+defectdojo.DefaultTestTitle+")")
+	cmd.Flags().StringVar(&do.MinimumSeverity, "minimum-severity", "", "Lowest severity DefectDojo keeps: Info, Low, Medium, High or Critical")
+	cmd.Flags().StringSliceVar(&do.Tags, "test-tags", nil, "Tags set on the imported test")
+	cmd.Flags().BoolVar(&do.CloseOld, "close-old", false, "Close findings of earlier imports into the engagement that this import does not report")
+	cmd.Flags().BoolVar(&do.Reimport, "reimport", false, "Update the engagement's existing test with the same title instead of adding a test")
+	cmd.Flags().StringVar(&uiURL, "ui-url", "", "Endor Labs app URL that imported findings link to (default: $ENDOR_UI_URL or "+output.DefaultUIURL+")")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the report that would be imported without uploading it")
+	return cmd
+}
+
+// resolve merges the flags with the profile's defectdojo section and the environment
+func (do *defectDojoOptions) resolve(g *globalOptions, flags *pflag.FlagSet) defectdojo.Config {
+	p := g.profile.DefectDojo
+	cfg := defectdojo.Config{
+		URL:             firstNonEmpty(do.URL, os.ExpandEnv(p.URL), os.Getenv("DEFECTDOJO_URL")),
+		Token:           firstNonEmpty(os.ExpandEnv(p.Token), os.Getenv("DEFECTDOJO_API_KEY")),
+		Product:         firstNonEmpty(do.Product, p.Product),
+		ProductType:     firstNonEmpty(do.ProductType, p.ProductType),
+		Engagement:      firstNonEmpty(do.Engagement, p.Engagement),
+		ScanType:        firstNonEmpty(do.ScanType, p.ScanType),
+		TestTitle:       firstNonEmpty(do.TestTitle, p.TestTitle),
+		MinimumSeverity: firstNonEmpty(do.MinimumSeverity, p.MinimumSeverity),
+		Tags:            do.Tags,
+		CloseOld:        p.CloseOld,
+		Reimport:        p.Reimport,
+	}
+	if cfg.Tags == nil {
+		cfg.Tags = p.Tags
+	}
+	if flags.Changed("close-old") {
+		cfg.CloseOld = do.CloseOld
+	}
+	if flags.Changed("reimport") {
+		cfg.Reimport = do.Reimport
+	}
+	return cfg
+}
+
+// defectDojoReport renders the fetched findings in the format of scanType
+func defectDojoReport(scanType string, fetched fetchedFindings, description, uiURL, namespace string) ([]byte, error) {
+	if scanType != defectdojo.ScanTypeSARIF {
+		return defectdojo.GenericReport(fetched.Findings, func(f api.Finding) string {
+			return output.FindingURL(uiURL, namespace, f)
+		})
+	}
+
+	w, err := output.NewWriter("sarif", output.Options{})
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	doc := output.NewDocument(fetched.Findings, description, fetched.Report, fetched.ProjectErrors, fetched.Warnings)
+	if err := w.Write(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to render SARIF report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// printImportResult prints where findings were imported and what happened to them
+func printImportResult(dc *defectdojo.Client, res defectdojo.Result, findings int) error {
+	cfg := dc.Config()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Product:\t%s\n", cfg.Product)
+	fmt.Fprintf(tw, "Engagement:\t%s\n", cfg.Engagement)
+	if res.Test != 0 {
+		fmt.Fprintf(tw, "Test:\t%s\n", dc.TestURL(res.Test))
+	}
+	fmt.Fprintf(tw, "Findings:\t%d\n", findings)
+	if res.Created+res.Closed+res.Reactivated+res.Untouched > 0 {
+		fmt.Fprintf(tw, "Created:\t%d\n", res.Created)
+		fmt.Fprintf(tw, "Closed:\t%d\n", res.Closed)
+		fmt.Fprintf(tw, "Reactivated:\t%d\n", res.Reactivated)
+		fmt.Fprintf(tw, "Untouched:\t%d\n", res.Untouched)
+	}
+	return tw.Flush()
+}
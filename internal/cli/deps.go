@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newDepsCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Inspect project dependencies",
+	}
+
+	cmd.AddCommand(newDepsListCmd(g))
+	return cmd
+}
+
+func newDepsListCmd(g *globalOptions) *cobra.Command {
+	var projectUUID, repo, packageVersion, format string
+	var directOnly, tree bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the direct and transitive dependencies of a project",
+		Example: `  findings-api deps list --project_uuid abc123-def456-ghi789 --direct
+  findings-api deps list --repo github.com/acme/payments --tree --package-version npm://payments@1.4.0`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectUUID == "" && repo == "" {
+				return errors.New("either --project_uuid or --repo is required")
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+
+			ctx := cmd.Context()
+			client, token, err := g.authenticate(ctx)
+			if err != nil {
+				return err
+			}
+			if projectUUID == "" {
+				p, err := client.FindProjectByRepo(ctx, token, repo)
+				if err != nil {
+					return fmt.Errorf("failed to resolve --repo: %w", err)
+				}
+				projectUUID = p.UUID
+			}
+
+			if tree {
+				slog.Info("Fetching dependency graph", "project_uuid", projectUUID)
+				graph, err := client.GetDependencyGraph(ctx, token, projectUUID, packageVersion)
+				if err != nil {
+					return fmt.Errorf("failed to fetch dependency graph: %w", err)
+				}
+				if format == "json" {
+					return printJSON(graph)
+				}
+				return printDependencyTree(os.Stdout, graph)
+			}
+
+			slog.Info("Fetching dependencies", "project_uuid", projectUUID)
+			deps, err := client.ListDependencies(ctx, token, projectUUID, directOnly)
+			if err != nil {
+				return fmt.Errorf("failed to fetch dependencies: %w", err)
+			}
+			if format == "json" {
+				return printJSON(deps)
+			}
+			return printDependencies(os.Stdout, deps)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectUUID, "project_uuid", "", "UUID of the project")
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository URL of the project, e.g. github.com/org/repo")
+	cmd.Flags().BoolVar(&directOnly, "direct", false, "Only list direct dependencies")
+	cmd.Flags().BoolVar(&tree, "tree", false, "Render the dependency graph of a package version as a tree")
+	cmd.Flags().StringVar(&packageVersion, "package-version", "", "Package version to render with --tree, e.g. npm://my-app@1.0.0 (needed when the project has several)")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
+
+// printDependencies prints dependencies as a table sorted by importer and name
+func printDependencies(w io.Writer, deps []api.DependencyMetadata) error {
+	sort.Slice(deps, func(i, j int) bool {
+		a, b := deps[i], deps[j]
+		if a.Spec.ImporterData.PackageVersionName != b.Spec.ImporterData.PackageVersionName {
+			return a.Spec.ImporterData.PackageVersionName < b.Spec.ImporterData.PackageVersionName
+		}
+		return a.Meta.Name < b.Meta.Name
+	})
+
+	direct := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEPENDENCY\tRELATIONSHIP\tREACHABLE\tSCOPE\tIMPORTED BY")
+	for _, d := range deps {
+		if d.Spec.DependencyData.Direct {
+			direct++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Meta.Name, d.Relationship(), d.Spec.DependencyData.Reachable, d.Spec.DependencyData.Scope, d.Spec.ImporterData.PackageVersionName)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d dependencies (%d direct, %d transitive)\n", len(deps), direct, len(deps)-direct)
+	return nil
+}
+
+// printDependencyTree renders graph depth-first from its root. A package version
+// already expanded elsewhere is marked with (*) instead of being repeated, which
+// also stops cycles.
+func printDependencyTree(w io.Writer, graph *api.DependencyGraph) error {
+	fmt.Fprintln(w, graph.Root)
+
+	expanded := map[string]bool{graph.Root: true}
+	var walk func(node, prefix string) error
+	walk = func(node, prefix string) error {
+		children := append([]string(nil), graph.Edges[node]...)
+		sort.Strings(children)
+
+		for i, child := range children {
+			branch, indent := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, indent = "└── ", "    "
+			}
+
+			if expanded[child] {
+				if _, err := fmt.Fprintf(w, "%s%s%s (*)\n", prefix, branch, child); err != nil {
+					return err
+				}
+				continue
+			}
+			expanded[child] = true
+
+			if _, err := fmt.Fprintf(w, "%s%s%s\n", prefix, branch, child); err != nil {
+				return err
+			}
+			if err := walk(child, prefix+indent); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(graph.Root, ""); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d package versions; (*) = dependencies shown above\n", len(expanded)-1)
+	return nil
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/diff"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// diffColumns are the default table columns of findings diff
+const diffColumns = "level,cve,name,package,project_uuid"
+
+func newFindingsDiffCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var format, key, columnSpec, webhookURL string
+	var maxWidth int
+	var showUnchanged bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Report new, resolved and unchanged findings between two snapshots",
+		Long: `Compare two findings snapshots and report the findings that are new in the
+second, resolved since the first, and unchanged. Snapshots are files written by
+findings export --format json or ndjson, or printed by findings list --format json.
+
+Findings are matched by UUID, or with --key fingerprint by project,
+vulnerability and package name, which also matches findings recreated by a
+rescan. --fail-on only considers new findings, so a pull request can be gated
+on the findings it introduces. With --webhook-url, the new and resolved
+findings are also posted to a webhook, signed with HMAC-SHA256.`,
+		Example: `  findings-api findings diff last-week.json today.json
+  findings-api findings diff main.json pr.json --fail-on high --key fingerprint
+  findings-api findings diff old.ndjson new.ndjson --format json | jq '.resolved | length'
+  findings-api findings diff last-week.json today.json --webhook-url https://soar.acme.com/hooks/endor`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			if _, err := diff.KeyFunc(key); err != nil {
+				return err
+			}
+			columns, err := output.ParseColumns(columnSpec)
+			if err != nil {
+				return fmt.Errorf("invalid columns: %w", err)
+			}
+			hook, err := g.newWebhook(webhookURL)
+			if err != nil {
+				return err
+			}
+
+			before, err := diff.Load(args[0])
+			if err != nil {
+				return err
+			}
+			after, err := diff.Load(args[1])
+			if err != nil {
+				return err
+			}
+			result, err := diff.Compare(before, after, key)
+			if err != nil {
+				return err
+			}
+			slog.Info("Compared findings", "before", args[0], "before_findings", len(before), "after", args[1], "after_findings", len(after), "changes", result.Summary())
+
+			if format == "json" {
+				if err := printJSON(result); err != nil {
+					return err
+				}
+			} else if err := writeDiffTable(os.Stdout, result, columns, maxWidth, showUnchanged); err != nil {
+				return err
+			}
+			if hook != nil {
+				if err := postChanges(cmd.Context(), hook, "findings diff", result); err != nil {
+					return fmt.Errorf("failed to post changes to the webhook: %w", err)
+				}
+			}
+			return opts.checkFailOn(result.New)
+		},
+	}
+
+	opts.addFailOnFlag(cmd.Flags())
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	cmd.Flags().StringVar(&key, "key", diff.ByUUID, "Match findings by uuid or fingerprint (project, vulnerability and package)")
+	cmd.Flags().StringVar(&columnSpec, "columns", diffColumns, "Comma-separated columns of the table (see findings export --columns)")
+	cmd.Flags().IntVar(&maxWidth, "max-width", 40, "Truncate table values longer than this many characters (0 disables truncation)")
+	cmd.Flags().BoolVar(&showUnchanged, "show-unchanged", false, "List unchanged findings too, not just their count")
+	addWebhookFlag(cmd.Flags(), &webhookURL)
+	return cmd
+}
+
+// writeDiffTable prints the counts of result, then its new and resolved
+// findings, and its unchanged findings when showUnchanged is set, in one table
+// with a status column
+func writeDiffTable(w io.Writer, result diff.Result, columns []output.Column, maxWidth int, showUnchanged bool) error {
+	fmt.Fprintf(w, "%s\n", result.Summary())
+
+	type entry struct {
+		finding api.Finding
+		status  string
+	}
+	var entries []entry
+	for _, f := range result.New {
+		entries = append(entries, entry{f, diff.StatusNew})
+	}
+	for _, f := range result.Resolved {
+		entries = append(entries, entry{f, diff.StatusResolved})
+	}
+	if showUnchanged {
+		for _, f := range result.Unchanged {
+			entries = append(entries, entry{f, diff.StatusUnchanged})
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	findings := make([]api.Finding, len(entries))
+	statuses := make(map[string]string, len(entries))
+	for i, e := range entries {
+		findings[i] = e.finding
+		statuses[e.finding.UUID] = e.status
+	}
+	statusColumn := output.Column{Name: "status", Header: "Status", Value: func(f api.Finding) string { return statuses[f.UUID] }}
+
+	fmt.Fprintln(w)
+	return output.WriteTable(w, findings, append([]output.Column{statusColumn}, columns...), maxWidth)
+}
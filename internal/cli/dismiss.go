@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newFindingsDismissCmd(g *globalOptions) *cobra.Command {
+	var reasonFlag, comment, expires, name string
+	var each, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "dismiss <finding-uuid>... | -",
+		Short: "Dismiss findings by creating an exception policy for them",
+		Long: `Dismiss findings by creating an exception policy in the selected namespace,
+so triage decisions made outside Endor Labs are pushed back to it. Excepted
+findings get the exception tag and drop out of the default filter.
+
+One policy covers every given finding unless --each creates one per finding.
+Pass - to read finding UUIDs from stdin, one per line.`,
+		Example: `  findings-api findings dismiss 6650a1000000000000000001 --reason false-positive --comment "test fixture only"
+  findings-api findings export --repo github.com/acme/web --format ndjson -o - | jq -r .uuid | findings-api findings dismiss - --reason risk-accepted --expires 90d`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := g.requireNetwork("dismissing findings"); err != nil {
+				return err
+			}
+			reason, err := api.ParseExceptionReason(reasonFlag)
+			if err != nil {
+				return err
+			}
+			expiry, err := parseExpiry(expires, time.Now())
+			if err != nil {
+				return err
+			}
+			uuids, err := findingUUIDArgs(args, os.Stdin)
+			if err != nil {
+				return err
+			}
+
+			specs := []api.ExceptionSpec{{Name: name, Description: comment, Reason: reason, Expiration: expiry, FindingUUIDs: uuids}}
+			if each {
+				specs = specs[:0]
+				for _, uuid := range uuids {
+					specs = append(specs, api.ExceptionSpec{Name: name, Description: comment, Reason: reason, Expiration: expiry, FindingUUIDs: []string{uuid}})
+				}
+			}
+			for _, spec := range specs {
+				if err := spec.Validate(); err != nil {
+					return err
+				}
+			}
+
+			if dryRun {
+				for _, spec := range specs {
+					fmt.Fprintf(g.console(), "Would create a %s exception for %s%s\n", spec.Reason.Short(), strings.Join(spec.FindingUUIDs, ", "), describeExpiry(spec.Expiration))
+				}
+				return nil
+			}
+
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			failed := 0
+			for _, spec := range specs {
+				policy, err := client.CreateException(cmd.Context(), token, spec)
+				if err != nil {
+					failed++
+					slog.Error("Failed to create an exception policy", "error", err)
+					continue
+				}
+				fmt.Fprintf(g.console(), "Created exception policy %s (%s)%s\n", policy.UUID, policy.Meta.Name, describeExpiry(spec.Expiration))
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to create %d of %d exception policies", failed, len(specs))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&reasonFlag, "reason", "", "Why the findings are dismissed: false-positive, risk-accepted, in-triage or other")
+	cmd.Flags().StringVar(&comment, "comment", "", "Triage comment stored as the policy description")
+	cmd.Flags().StringVar(&expires, "expires", "", "When the exception expires: a date (2006-01-02) or a number of days such as 90d (default never)")
+	cmd.Flags().StringVar(&name, "name", "", "Policy name (default names the findings)")
+	cmd.Flags().BoolVar(&each, "each", false, "Create one exception policy per finding")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the exceptions that would be created without creating them")
+	cmd.MarkFlagRequired("reason")
+	return cmd
+}
+
+// findingUUIDArgs returns the finding UUIDs of args, reading them from stdin
+// for "-", without blanks or duplicates
+func findingUUIDArgs(args []string, stdin io.Reader) ([]string, error) {
+	var uuids []string
+	seen := make(map[string]bool)
+	add := func(uuid string) {
+		uuid = strings.TrimSpace(uuid)
+		if uuid != "" && !seen[uuid] {
+			seen[uuid] = true
+			uuids = append(uuids, uuid)
+		}
+	}
+	for _, arg := range args {
+		if arg != "-" {
+			add(arg)
+			continue
+		}
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			add(scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read finding UUIDs: %w", err)
+		}
+	}
+	if len(uuids) == 0 {
+		return nil, fmt.Errorf("no finding UUIDs given")
+	}
+	return uuids, nil
+}
+
+// parseExpiry parses --expires as a date or a number of days from now; empty
+// means the exception never expires
+func parseExpiry(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return time.Time{}, fmt.Errorf("invalid --expires %q (expected a date or a positive number of days such as 90d)", s)
+		}
+		return now.AddDate(0, 0, n), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --expires %q (expected a date or a positive number of days such as 90d)", s)
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("--expires %s is not in the future", s)
+	}
+	return t, nil
+}
+
+// describeExpiry returns ", expiring <date>" or "" for exceptions without expiry
+func describeExpiry(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return ", expiring " + t.Format("2006-01-02")
+}
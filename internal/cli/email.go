@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/endor-labs/findings-api/internal/email"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/spf13/pflag"
+)
+
+// emailOptions are the report email flags; the SMTP server and unset flags
+// fall back to the profile's email section and the environment
+type emailOptions struct {
+	Enabled bool
+	To      []string
+	Subject string
+}
+
+func (eo *emailOptions) addFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&eo.Enabled, "email", false, "Email the HTML report, with a Markdown text alternative, after saving the findings (SMTP settings from the profile's email section or $SMTP_HOST)")
+	fs.StringSliceVar(&eo.To, "email-to", nil, "Comma-separated report recipients (default: the profile's email.to)")
+	fs.StringVar(&eo.Subject, "email-subject", "", "Go template of the report subject, with .Title, .Total and .Counts by level (default: "+email.DefaultSubject+")")
+}
+
+// resolve merges the flags with the profile's email section and the
+// environment, and checks the subject template
+func (eo *emailOptions) resolve(g *globalOptions) (*email.Client, string, error) {
+	p := g.profile.Email
+	cfg := email.Config{
+		Host:     firstNonEmpty(os.ExpandEnv(p.Host), os.Getenv("SMTP_HOST")),
+		Port:     p.Port,
+		Username: firstNonEmpty(os.ExpandEnv(p.Username), os.Getenv("SMTP_USERNAME")),
+		Password: firstNonEmpty(os.ExpandEnv(p.Password), os.Getenv("SMTP_PASSWORD")),
+		TLS:      firstNonEmpty(p.TLS, os.Getenv("SMTP_TLS")),
+		From:     firstNonEmpty(p.From, os.Getenv("SMTP_FROM")),
+		To:       p.To,
+		Cc:       p.Cc,
+	}
+	if cfg.Port == 0 {
+		if s := os.Getenv("SMTP_PORT"); s != "" {
+			port, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid SMTP_PORT %q", s)
+			}
+			cfg.Port = port
+		}
+	}
+	if len(eo.To) > 0 {
+		cfg.To, cfg.Cc = eo.To, nil
+	}
+	client, err := email.NewClient(cfg)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid email settings: %w", err)
+	}
+
+	subject := firstNonEmpty(eo.Subject, p.Subject)
+	if _, err := email.RenderSubject(subject, email.SubjectData{}); err != nil {
+		return nil, "", err
+	}
+	return client, subject, nil
+}
+
+// sendReport emails doc as the html report, with the markdown report as its
+// plain-text alternative
+func sendReport(ctx context.Context, client *email.Client, subject string, doc *output.Document, opts output.Options) error {
+	// Custom templates are written for one of the formats, so both reports use the built-in ones
+	opts.Template = ""
+	render := func(format string) (string, error) {
+		w, err := output.NewWriter(format, opts)
+		if err != nil {
+			return "", err
+		}
+		var buf bytes.Buffer
+		if err := w.Write(&buf, doc); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	html, err := render("html")
+	if err != nil {
+		return err
+	}
+	text, err := render("markdown")
+	if err != nil {
+		return err
+	}
+
+	data := output.NewReportData(doc, opts.Theme.WithDefaults(), opts.UIURL, opts.Namespace)
+	subject, err = email.RenderSubject(subject, email.SubjectData{
+		Title:       data.Title,
+		Description: data.Description,
+		Timestamp:   data.Timestamp,
+		Total:       data.Total,
+		Counts:      data.Counts,
+	})
+	if err != nil {
+		return err
+	}
+	if err := client.Send(ctx, email.Message{Subject: subject, Text: text, HTML: html}); err != nil {
+		return fmt.Errorf("failed to email the report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+	"github.com/endor-labs/findings-api/internal/evidence"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/release"
+	"github.com/spf13/cobra"
+)
+
+// evidenceMetadata describes the run that produced an evidence bundle
+type evidenceMetadata struct {
+	Tool          string          `json:"tool"`
+	Version       string          `json:"version"`
+	Commit        string          `json:"commit"`
+	BuildDate     string          `json:"build_date"`
+	GeneratedAt   string          `json:"generated_at"`
+	Command       string          `json:"command"`
+	Namespace     string          `json:"namespace"`
+	Selection     string          `json:"selection"`
+	Filter        string          `json:"filter"`
+	TotalFindings int             `json:"total_findings"`
+	Signed        bool            `json:"signed"`
+	FetchReport   api.FetchReport `json:"fetch_report"`
+	Warnings      []api.Warning   `json:"warnings,omitempty"`
+	// SBOMErrors maps the projects whose SBOMs could not be exported to the error
+	SBOMErrors map[string]string `json:"sbom_errors,omitempty"`
+}
+
+// secretFlags are the flags whose values may hold credentials; they are
+// redacted from the command recorded in evidence metadata
+var secretFlags = map[string]bool{
+	"token-claim": true,
+	"header":      true,
+	"webhook-url": true,
+}
+
+// redactedCommand returns args as a command line with the values of
+// secretFlags and the passwords of URLs replaced
+func redactedCommand(args []string) string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "="); strings.HasPrefix(arg, "--") && secretFlags[name] {
+			if hasValue {
+				out = append(out, "--"+name+"=REDACTED")
+			} else {
+				out = append(out, arg)
+				if i+1 < len(args) {
+					out = append(out, "REDACTED")
+					i++
+				}
+			}
+			continue
+		}
+		if u, err := url.Parse(arg); err == nil && u.Scheme != "" && u.User != nil {
+			arg = u.Redacted()
+		}
+		out = append(out, arg)
+	}
+	return strings.Join(out, " ")
+}
+
+func newExportCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Produce audit artifacts",
+	}
+
+	cmd.AddCommand(
+		newExportEvidenceCmd(g),
+		newExportVerifyCmd(),
+	)
+	return cmd
+}
+
+func newExportEvidenceCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var themePath, keyFile, uiURL string
+	var noSBOM bool
+
+	cmd := &cobra.Command{
+		Use:   "evidence",
+		Short: "Save findings, an xlsx and HTML report and run metadata as a single signed zip",
+		Long: `Fetch findings and bundle everything an auditor asks for into one zip:
+
+  findings.json     the raw findings with the fetch report and warnings
+  findings.xlsx     the rendered, themed report
+  report.html       the same report as a web page linking to the app
+  sboms/            a CycloneDX SBOM of each package version of the projects
+  metadata.json     tool version, command, namespace, filter and timing
+  checksums.txt     SHA-256 of every file
+  checksums.txt.sig ed25519 signature of checksums.txt (with a signing key)
+
+The signing key is read from --signing-key-file or the ENDOR_EVIDENCE_SIGNING_KEY
+environment variable (base64 ed25519 private key, see "release keygen").
+The report is rendered as xlsx and HTML only; the bundle holds no PDF.
+Flags that may hold credentials, such as --token-claim, are redacted from the
+command recorded in metadata.json. --no-sbom leaves the SBOMs out.`,
+		Example: `  findings-api export evidence --repo github.com/acme/payments --signing-key-file evidence.key`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			run := g.startRun(cmd, args)
+			defer func() { err = g.finishRun(cmd.Context(), run, err) }()
+
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			theme, err := g.reportTheme(themePath)
+			if err != nil {
+				return err
+			}
+			key, err := loadSigningKey(keyFile, "ENDOR_EVIDENCE_SIGNING_KEY")
+			if err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+			columns, err := output.ParseColumns("")
+			if err != nil {
+				return err
+			}
+
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(cmd.Context(), client, token); err != nil {
+				return err
+			}
+
+			slog.Info("Fetching findings", "selection", opts.description())
+			result, err := g.fetch(cmd.Context(), opts, client, token, filter, api.NewProjectCache(client))
+			if err != nil {
+				return err
+			}
+			report := client.FetchReport()
+			logFetchReport(report)
+			warnings := client.Warnings()
+			logWarnings(warnings)
+			run.Selection, run.Filter = opts.description(), filter
+			run.SetFindings(result.Findings, len(warnings), len(result.ProjectErrors))
+
+			bundle := evidence.New()
+			doc := output.NewDocument(result.Findings, opts.description(), report, result.ProjectErrors, warnings)
+			doc.Correlations = result.Correlations
+			if err := bundle.AddJSON("findings.json", doc); err != nil {
+				return err
+			}
+
+			var xlsx bytes.Buffer
+			if err := output.WriteXLSX(&xlsx, result.Findings, columns, theme); err != nil {
+				return fmt.Errorf("failed to render report: %w", err)
+			}
+			bundle.Add("findings.xlsx", xlsx.Bytes())
+
+			html, err := output.NewWriter("html", output.Options{
+				Theme:     theme,
+				UIURL:     firstNonEmpty(uiURL, os.Getenv("ENDOR_UI_URL")),
+				Namespace: g.namespace(),
+			})
+			if err != nil {
+				return err
+			}
+			var page bytes.Buffer
+			if err := html.Write(&page, doc); err != nil {
+				return err
+			}
+			bundle.Add("report.html", page.Bytes())
+
+			var sbomErrors map[string]string
+			if !noSBOM {
+				sbomErrors = addEvidenceSBOMs(cmd.Context(), client, token, bundle, evidenceProjects(opts, result.Findings))
+			}
+
+			metadata := evidenceMetadata{
+				Tool:          release.BinaryName,
+				Version:       buildinfo.Version,
+				Commit:        buildinfo.Commit,
+				BuildDate:     buildinfo.Date,
+				GeneratedAt:   bundle.Created.UTC().Format(time.RFC3339),
+				Command:       redactedCommand(os.Args),
+				Namespace:     client.Namespace(),
+				Selection:     opts.description(),
+				Filter:        filter,
+				TotalFindings: len(result.Findings),
+				Signed:        key != nil,
+				FetchReport:   report,
+				Warnings:      warnings,
+				SBOMErrors:    sbomErrors,
+			}
+			if err := bundle.AddJSON("metadata.json", metadata); err != nil {
+				return err
+			}
+
+			filename := g.outputPath("evidence_" + strings.TrimPrefix(opts.defaultFilename("zip"), "findings_"))
+			file, err := createOutput(filename)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			if err := bundle.Write(file, key); err != nil {
+				return fmt.Errorf("failed to write evidence bundle: %w", err)
+			}
+			if err := file.Close(); err != nil {
+				return fmt.Errorf("failed to close file: %w", err)
+			}
+
+			addRunOutput(run, filename, "evidence")
+			if filename != stdoutPath {
+				fmt.Printf("Evidence bundle with %d findings saved to: %s\n", len(result.Findings), filename)
+			}
+			if key == nil {
+				fmt.Fprintln(os.Stderr, "Warning: no signing key configured, evidence bundle is unsigned")
+			}
+			return opts.checkFailOn(result.Findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	cmd.Flags().StringVar(&themePath, "theme", "", "JSON file with a report theme (company name, logo, colours, font), replacing the profile's theme")
+	cmd.Flags().StringVar(&keyFile, "signing-key-file", "", "File containing the base64 ed25519 signing key")
+	cmd.Flags().StringVar(&uiURL, "ui-url", "", "Endor Labs app URL that the html report links findings to (default: $ENDOR_UI_URL or "+output.DefaultUIURL+")")
+	cmd.Flags().BoolVar(&noSBOM, "no-sbom", false, "Leave the SBOMs of the projects out of the bundle")
+	return cmd
+}
+
+// evidenceProjects returns the projects whose SBOMs go in an evidence bundle:
+// the selected ones, or with --all-projects those with findings
+func evidenceProjects(opts *findingsOptions, findings []api.Finding) []string {
+	if !opts.AllProjects {
+		return opts.ProjectUUIDs
+	}
+	seen := make(map[string]bool)
+	var projects []string
+	for _, f := range findings {
+		if p := f.Spec.ProjectUUID; p != "" && !seen[p] {
+			seen[p] = true
+			projects = append(projects, p)
+		}
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// addEvidenceSBOMs adds a CycloneDX JSON SBOM of each package version of
+// projects to bundle under sboms/. A project whose SBOMs cannot be exported
+// is logged and returned with its error rather than failing the bundle.
+func addEvidenceSBOMs(ctx context.Context, client *api.Client, token string, bundle *evidence.Bundle, projects []string) map[string]string {
+	errs := make(map[string]string)
+	names := make(map[string]int)
+	for _, project := range projects {
+		versions, err := client.ListPackageVersions(ctx, token, project)
+		if err == nil && len(versions) == 0 {
+			err = fmt.Errorf("no package versions found in project %s", project)
+		}
+		for _, pv := range versions {
+			if err != nil {
+				break
+			}
+			slog.Info("Exporting SBOM", "package", pv.Meta.Name)
+			var sbom []byte
+			sbom, err = client.ExportSBOM(ctx, token, api.SBOMExportOptions{PackageVersionUUID: pv.UUID, Kind: api.SBOMCycloneDX, Format: api.SBOMFormatJSON})
+			if err == nil {
+				// Package versions of different ecosystems can share a slug
+				name := sbomSlug(pv.Meta.Name)
+				if names[name]++; names[name] > 1 {
+					name = fmt.Sprintf("%s-%d", name, names[name])
+				}
+				bundle.Add("sboms/"+name+".cyclonedx.json", sbom)
+			}
+		}
+		if err != nil {
+			slog.Warn("Failed to export SBOM", "project_uuid", project, "error", err)
+			errs[project] = err.Error()
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func newExportVerifyCmd() *cobra.Command {
+	var publicKey string
+
+	cmd := &cobra.Command{
+		Use:   "verify <bundle.zip>",
+		Short: "Verify the checksums and signature of an evidence bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var key ed25519.PublicKey
+			if publicKey != "" {
+				var err error
+				if key, err = release.ParsePublicKey(publicKey); err != nil {
+					return err
+				}
+			}
+
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open bundle: %w", err)
+			}
+			defer file.Close()
+			info, err := file.Stat()
+			if err != nil {
+				return fmt.Errorf("failed to open bundle: %w", err)
+			}
+
+			if err := evidence.Verify(file, info.Size(), key); err != nil {
+				return err
+			}
+			if key == nil {
+				fmt.Println("Evidence bundle checksums verified (signature not checked, pass --public-key)")
+			} else {
+				fmt.Println("Evidence bundle verified")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&publicKey, "public-key", "", "Base64 ed25519 public key")
+	return cmd
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// Exception statuses used in the expiry report
+const (
+	exceptionExpired  = "expired"
+	exceptionExpiring = "expiring"
+	exceptionActive   = "active"
+	exceptionNoExpiry = "no_expiry"
+	exceptionDisabled = "disabled"
+)
+
+// exceptionEntry is one exception policy in the expiry report
+type exceptionEntry struct {
+	UUID           string     `json:"uuid"`
+	Name           string     `json:"name"`
+	Namespace      string     `json:"namespace"`
+	Reason         string     `json:"reason"`
+	CreatedBy      string     `json:"created_by"`
+	CreateTime     time.Time  `json:"create_time"`
+	ExpirationTime *time.Time `json:"expiration_time,omitempty"`
+	DaysRemaining  *int       `json:"days_remaining,omitempty"`
+	Status         string     `json:"status"`
+}
+
+// exceptionsReport lists exception policies grouped by expiry status
+type exceptionsReport struct {
+	GeneratedAt    time.Time        `json:"generated_at"`
+	ExpiringWithin int              `json:"expiring_within_days"`
+	Counts         map[string]int   `json:"counts"`
+	Exceptions     []exceptionEntry `json:"exceptions"`
+}
+
+func newExceptionsCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exceptions",
+		Short: "Inspect exception policies",
+	}
+
+	var expiringWithin int
+	report := &cobra.Command{
+		Use:   "report",
+		Short: "Report exception policies that have expired, are about to expire, or never expire",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			slog.Info("Fetching exception policies")
+			policies, err := client.ListExceptionPolicies(cmd.Context(), token)
+			if err != nil {
+				return fmt.Errorf("failed to fetch exception policies: %w", err)
+			}
+
+			report := buildExceptionsReport(policies, time.Now(), expiringWithin)
+			if err := printExceptionsReport(g.console(), report); err != nil {
+				return fmt.Errorf("failed to print exceptions report: %w", err)
+			}
+
+			filename := g.outputPath(fmt.Sprintf("exceptions_report_%s.json", time.Now().Format("2006-01-02_15-04-05")))
+			if err := writeJSONFile(report, filename); err != nil {
+				return fmt.Errorf("failed to save exceptions report: %w", err)
+			}
+
+			if filename != stdoutPath {
+				fmt.Printf("\nExceptions report saved to: %s\n", filename)
+			}
+			return nil
+		},
+	}
+	report.Flags().IntVar(&expiringWithin, "expiring-within", 30, "Days ahead to flag exceptions as expiring")
+
+	cmd.AddCommand(report)
+	return cmd
+}
+
+// buildExceptionsReport classifies each exception policy as expired, expiring within
+// the given number of days, active, or without any expiry date
+func buildExceptionsReport(policies []api.Policy, now time.Time, days int) exceptionsReport {
+	report := exceptionsReport{
+		GeneratedAt:    now,
+		ExpiringWithin: days,
+		Counts:         make(map[string]int),
+	}
+
+	horizon := now.AddDate(0, 0, days)
+
+	for _, p := range policies {
+		entry := exceptionEntry{
+			UUID:           p.UUID,
+			Name:           p.Meta.Name,
+			Namespace:      p.TenantMeta.Namespace,
+			Reason:         p.Spec.Exception.Reason,
+			CreatedBy:      p.Meta.CreatedBy,
+			CreateTime:     p.Meta.CreateTime,
+			ExpirationTime: p.Spec.Exception.ExpirationTime,
+		}
+
+		expiry := p.Spec.Exception.ExpirationTime
+		switch {
+		case p.Spec.Disable:
+			entry.Status = exceptionDisabled
+		case expiry == nil || expiry.IsZero():
+			entry.Status = exceptionNoExpiry
+		case expiry.Before(now):
+			entry.Status = exceptionExpired
+		case expiry.Before(horizon):
+			entry.Status = exceptionExpiring
+		default:
+			entry.Status = exceptionActive
+		}
+
+		if expiry != nil && !expiry.IsZero() {
+			remaining := int(expiry.Sub(now).Hours() / 24)
+			entry.DaysRemaining = &remaining
+		}
+
+		report.Counts[entry.Status]++
+		report.Exceptions = append(report.Exceptions, entry)
+	}
+
+	// Most urgent first: expired, expiring, no expiry, active, disabled
+	order := map[string]int{
+		exceptionExpired:  0,
+		exceptionExpiring: 1,
+		exceptionNoExpiry: 2,
+		exceptionActive:   3,
+		exceptionDisabled: 4,
+	}
+	sort.SliceStable(report.Exceptions, func(i, j int) bool {
+		a, b := report.Exceptions[i], report.Exceptions[j]
+		if order[a.Status] != order[b.Status] {
+			return order[a.Status] < order[b.Status]
+		}
+		if a.ExpirationTime != nil && b.ExpirationTime != nil {
+			return a.ExpirationTime.Before(*b.ExpirationTime)
+		}
+		return a.Name < b.Name
+	})
+
+	return report
+}
+
+// printExceptionsReport writes the report as an aligned table
+func printExceptionsReport(w io.Writer, report exceptionsReport) error {
+	fmt.Fprintf(w, "Exceptions: %d expired, %d expiring within %d days, %d without expiry, %d active, %d disabled\n\n",
+		report.Counts[exceptionExpired], report.Counts[exceptionExpiring], report.ExpiringWithin,
+		report.Counts[exceptionNoExpiry], report.Counts[exceptionActive], report.Counts[exceptionDisabled])
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tEXPIRES\tDAYS\tCREATED BY\tNAME\tNAMESPACE")
+	for _, e := range report.Exceptions {
+		expires, days := "-", "-"
+		if e.ExpirationTime != nil {
+			expires = e.ExpirationTime.Format("2006-01-02")
+		}
+		if e.DaysRemaining != nil {
+			days = fmt.Sprintf("%d", *e.DaysRemaining)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Status, expires, days, e.CreatedBy, e.Name, e.Namespace)
+	}
+	return tw.Flush()
+}
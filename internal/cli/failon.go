@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/pflag"
+)
+
+// exitFindingsFound is the exit code used when --fail-on finds findings at or
+// above the threshold, so CI can tell a failed gate apart from a failed run
+const exitFindingsFound = 2
+
+// exitError is an error that makes the process exit with a specific code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// addFailOnFlag registers --fail-on on fs
+func (o *findingsOptions) addFailOnFlag(fs *pflag.FlagSet) {
+	fs.StringVar(&o.FailOn, "fail-on", "", "Exit with code 2 when findings at or above this level are found (critical, high, medium or low)")
+}
+
+// checkFailOn returns an exitError when any finding is at or above the --fail-on level
+func (o *findingsOptions) checkFailOn(findings []api.Finding) error {
+	if o.FailOn == "" {
+		return nil
+	}
+	threshold, err := api.ParseFindingLevel(o.FailOn)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for _, f := range findings {
+		if f.Spec.Level.AtLeast(threshold) {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	return &exitError{
+		code: exitFindingsFound,
+		err:  fmt.Errorf("%d findings at or above level %s", count, threshold.Short()),
+	}
+}
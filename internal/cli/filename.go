@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultFilenameTemplate names export files when neither --output nor
+// --filename-template is given
+const defaultFilenameTemplate = "findings_{{.Project}}_{{.Timestamp}}.{{.Ext}}"
+
+// filenameData are the variables of --filename-template
+type filenameData struct {
+	// Project is the project UUID, all_projects or N_projects for the
+	// selection, or the project's name with --split-by-project
+	Project   string
+	Namespace string
+	// Timestamp is when the run started, e.g. 2024-06-01_09-30-00
+	Timestamp string
+	// Format is the output format, e.g. markdown, and Ext its file
+	// extension, e.g. md
+	Format string
+	Ext    string
+}
+
+// filenameTemplate names the files of an export
+type filenameTemplate struct {
+	tmpl *template.Template
+	// data holds the variables shared by every file of the run
+	data filenameData
+	// formats maps the extensions of the requested formats to their names
+	formats map[string]string
+}
+
+// newFilenameTemplate parses text, or defaultFilenameTemplate when empty, for
+// the files of writers. The template is tried on every format up front, so a
+// template that fails or gives two formats the same file is rejected before
+// anything is fetched.
+func newFilenameTemplate(text, namespace string, writers []formatWriter) (*filenameTemplate, error) {
+	if text == "" {
+		text = defaultFilenameTemplate
+	}
+	tmpl, err := template.New("filename").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --filename-template: %w", err)
+	}
+	t := &filenameTemplate{
+		tmpl:    tmpl,
+		data:    filenameData{Namespace: namespace, Timestamp: time.Now().Format("2006-01-02_15-04-05")},
+		formats: make(map[string]string, len(writers)),
+	}
+
+	seen := make(map[string]string)
+	for _, fw := range writers {
+		ext := fw.Writer.Extension()
+		t.formats[ext] = fw.Format
+		name, err := t.execute("project", ext)
+		if err != nil {
+			return nil, err
+		}
+		if other, dup := seen[name]; dup {
+			return nil, fmt.Errorf("invalid --filename-template: %s and %s would both be saved to %s; add {{.Ext}} or {{.Format}}", other, fw.Format, name)
+		}
+		seen[name] = fw.Format
+	}
+	return t, nil
+}
+
+// execute renders the file name of the format with extension ext for project
+func (t *filenameTemplate) execute(project, ext string) (string, error) {
+	data := t.data
+	data.Project, data.Format, data.Ext = project, t.formats[ext], ext
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid --filename-template: %w", err)
+	}
+	name := strings.TrimSpace(buf.String())
+	if name == "" || strings.HasSuffix(name, "/") {
+		return "", errors.New("invalid --filename-template: it gives an empty file name")
+	}
+	return name, nil
+}
+
+// filename returns the file of the format with extension ext for project
+func (t *filenameTemplate) filename(project, ext string) string {
+	// Errors were ruled out when the template was tried on every format
+	name, _ := t.execute(fileSlug(project), ext)
+	return name
+}
+
+// makeParentDir creates the directory of filename if it does not exist, so
+// templates can sort files into directories; a failure is left to the write
+// of the file to report
+func makeParentDir(filename string) string {
+	if dir := filepath.Dir(filename); dir != "." && filename != stdoutPath {
+		_ = os.MkdirAll(dir, 0o755)
+	}
+	return filename
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/contexts"
+	"github.com/endor-labs/findings-api/internal/filter"
+)
+
+// filterOptions holds the command line flags that compose the findings filter
+type filterOptions struct {
+	Levels        string
+	Categories    string
+	Tags          string
+	EPSSMin       float64
+	ReachableOnly bool
+	FixAvailable  bool
+	RawFilter     string
+	// Contexts is a comma-separated list of context specs, e.g. main,ci-run
+	Contexts string
+	// CVSSMin and CVSSEnvironmental are applied after enrichment, not by the API
+	CVSSMin           float64
+	CVSSEnvironmental string
+}
+
+// buildFindingsFilter composes an Endor filter expression from the flag values.
+// Levels, categories and tags are validated against the known values so a typo
+// fails instead of silently matching nothing. When RawFilter is set it is
+// returned as-is in parentheses, so clauses joined to it with "and" apply to
+// the whole of it.
+func buildFindingsFilter(opts filterOptions) (string, error) {
+	if opts.RawFilter != "" {
+		return "(" + opts.RawFilter + ")", nil
+	}
+
+	levels, err := parseEnums(opts.Levels, api.ParseFindingLevel)
+	if err != nil {
+		return "", err
+	}
+	categories, err := parseEnums(opts.Categories, api.ParseFindingCategory)
+	if err != nil {
+		return "", err
+	}
+	tags, err := parseEnums(opts.Tags, api.ParseFindingTag)
+	if err != nil {
+		return "", err
+	}
+
+	specs, err := contexts.ParseList(opts.Contexts)
+	if err != nil {
+		return "", err
+	}
+
+	b := filter.New()
+	if len(specs) == 1 && specs[0].ID == "" {
+		b.Context(specs[0].Type)
+	} else {
+		b.Group(func(g *filter.Builder) {
+			for i, s := range specs {
+				if i > 0 {
+					g.Or()
+				}
+				if s.ID == "" {
+					g.Context(s.Type)
+					continue
+				}
+				g.Group(func(c *filter.Builder) { c.Context(s.Type).Equals("context.id", s.ID) })
+			}
+		})
+	}
+	b.NotTags(string(api.TagException))
+
+	if len(levels) > 0 {
+		b.Level(levels...)
+	}
+
+	if len(categories) > 0 {
+		b.Categories(categories...)
+	}
+
+	if opts.ReachableOnly {
+		b.Tags(string(api.TagPotentiallyReachableFunction), string(api.TagReachableFunction)).
+			Tags(string(api.TagReachableDependency))
+	}
+
+	if opts.FixAvailable {
+		b.Tags(string(api.TagFixAvailable))
+	}
+
+	// Each tag is required individually, matching the endorctl behaviour
+	for _, tag := range tags {
+		b.Tags(tag)
+	}
+
+	if opts.EPSSMin > 0 {
+		b.EPSSAtLeast(opts.EPSSMin)
+	}
+
+	return b.Build()
+}
+
+// parseEnums parses a comma-separated list with parse, returning the values in
+// their API form
+func parseEnums[T ~string](list string, parse func(string) (T, error)) ([]string, error) {
+	var values []string
+	for _, s := range filter.SplitList(list) {
+		v, err := parse(s)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(v))
+	}
+	return values, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// The default filters, as the API was queried before the filter flags
+const (
+	baselineProjectFilter     = `(context.type == "CONTEXT_TYPE_MAIN" and spec.finding_tags not contains ["FINDING_TAGS_EXCEPTION"] and spec.level in ["FINDING_LEVEL_CRITICAL"] and spec.finding_categories contains ["FINDING_CATEGORY_VULNERABILITY"] and spec.finding_tags contains ["FINDING_TAGS_POTENTIALLY_REACHABLE_FUNCTION","FINDING_TAGS_REACHABLE_FUNCTION"] and spec.finding_tags contains ["FINDING_TAGS_REACHABLE_DEPENDENCY"] and spec.finding_tags contains ["FINDING_TAGS_FIX_AVAILABLE"] and spec.finding_tags contains ["FINDING_TAGS_NORMAL"] and spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 0.01)`
+	baselineAllProjectsFilter = `(context.type == "CONTEXT_TYPE_MAIN" and spec.finding_tags not contains ["FINDING_TAGS_EXCEPTION"] and spec.level in ["FINDING_LEVEL_CRITICAL","FINDING_LEVEL_HIGH"] and spec.finding_categories contains ["FINDING_CATEGORY_VULNERABILITY"] and spec.finding_tags contains ["FINDING_TAGS_POTENTIALLY_REACHABLE_FUNCTION","FINDING_TAGS_REACHABLE_FUNCTION"] and spec.finding_tags contains ["FINDING_TAGS_REACHABLE_DEPENDENCY"] and spec.finding_tags contains ["FINDING_TAGS_FIX_AVAILABLE"] and spec.finding_tags contains ["FINDING_TAGS_NORMAL"] and spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 0.01)`
+)
+
+func TestDefaultFilter(t *testing.T) {
+	t.Setenv("ENDOR_BUNDLE", "")
+	for _, tt := range []struct {
+		name        string
+		allProjects bool
+		want        string
+	}{
+		{"single project", false, baselineProjectFilter},
+		{"all projects", true, baselineAllProjectsFilter},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &findingsOptions{AllProjects: tt.allProjects}
+			o.addFilterFlags(pflag.NewFlagSet("findings", pflag.ContinueOnError))
+			got, err := o.buildFilter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawFilterIsParenthesized(t *testing.T) {
+	got, err := buildFindingsFilter(filterOptions{RawFilter: `spec.level == "FINDING_LEVEL_LOW" or spec.level == "FINDING_LEVEL_HIGH"`, Levels: "critical"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `(spec.level == "FINDING_LEVEL_LOW" or spec.level == "FINDING_LEVEL_HIGH")`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,1139 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/endor-labs/findings-api/internal/aggregate"
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/bundle"
+	"github.com/endor-labs/findings-api/internal/codeowners"
+	"github.com/endor-labs/findings-api/internal/config"
+	"github.com/endor-labs/findings-api/internal/contexts"
+	"github.com/endor-labs/findings-api/internal/correlate"
+	"github.com/endor-labs/findings-api/internal/cvss"
+	"github.com/endor-labs/findings-api/internal/dedupe"
+	"github.com/endor-labs/findings-api/internal/diff"
+	"github.com/endor-labs/findings-api/internal/email"
+	"github.com/endor-labs/findings-api/internal/enrich"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/risk"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// findingsOptions holds the flags that select which findings to fetch
+type findingsOptions struct {
+	ProjectUUIDs    []string
+	Repos           []string
+	ProjectNames    []string
+	AllProjects     bool
+	ResolveProjects bool
+	Concurrency     int
+	CodeOwners      string
+	Enrich          []string
+	FailOn          string
+	Correlate       string
+	Bundle          string
+	ThreatIntel     bool
+	KEVOnly         bool
+	CVSS            bool
+	Risk            bool
+	RiskWeights     string
+	SortRisk        bool
+	MinRisk         float64
+	Dedupe          bool
+	DedupeKey       string
+	Filter          filterOptions
+
+	// owners is the parsed CodeOwners file, loaded by validate
+	owners *codeowners.Ruleset
+	// commands are the parsed Enrich commands
+	commands []enrich.Command
+	// bundle is the enrichment bundle loaded from Bundle, if any
+	bundle *bundle.Bundle
+	// threatIntel is the enricher of ThreatIntel, kept across fetches so its
+	// downloads are reused
+	threatIntel *enrich.ThreatIntel
+	// cvss is the CVSS enricher, set when scores are recomputed
+	cvss *enrich.CVSS
+	// risk scores findings, set when risk scores are asked for
+	risk *risk.Scorer
+	// dedupeKey is the parsed DedupeKey
+	dedupeKey dedupe.Key
+}
+
+// fetchResult is the outcome of fetching the selected findings
+type fetchResult struct {
+	Findings []api.Finding
+	// ProjectErrors maps project UUIDs that could not be fetched to their error
+	ProjectErrors map[string]string
+	// Correlations groups findings repeated across forks and mirrors, when requested
+	Correlations []correlate.Group
+}
+
+// addFlags registers the findings selection flags on fs
+func (o *findingsOptions) addFlags(fs *pflag.FlagSet) {
+	o.addProjectFlags(fs)
+	fs.BoolVar(&o.ResolveProjects, "resolve-projects", false, "Resolve project names and repository URLs for each finding")
+	fs.IntVar(&o.Concurrency, "concurrency", api.DefaultConcurrency, "Number of projects fetched in parallel when several are given")
+	fs.StringVar(&o.CodeOwners, "codeowners", "", "CODEOWNERS file, or repository checkout containing one, used to attribute findings to owners")
+	fs.StringArrayVar(&o.Enrich, "enrich", nil, `External enricher command, "[name=]program [args]" (repeatable, run in order)`)
+	fs.StringVar(&o.Bundle, "bundle", "", "Enrichment bundle directory adding KEV, EPSS and CWE data without network access (default: $ENDOR_BUNDLE)")
+	fs.BoolVar(&o.ThreatIntel, "threat-intel", false, "Add CISA KEV catalog membership and, where the API has no score, current FIRST EPSS scores to each finding")
+	fs.BoolVar(&o.KEVOnly, "kev-only", false, "Only keep findings of vulnerabilities in the CISA KEV catalog (needs --threat-intel or a --bundle)")
+	fs.BoolVar(&o.CVSS, "cvss", false, "Add each finding's parsed CVSS vector with recomputed base, temporal and environmental scores")
+	// Applied after enrichment, so not offered by serve with the other filter flags
+	fs.Float64Var(&o.Filter.CVSSMin, "min-cvss", 0, "Minimum CVSS score, recomputed with --cvss-environmental applied (0 disables the check)")
+	fs.StringVar(&o.Filter.CVSSEnvironmental, "cvss-environmental", "", "CVSS environmental metrics applied to every vector before scoring, e.g. CR:H/IR:H/MAV:L; implies --cvss")
+	fs.BoolVar(&o.Risk, "risk", false, "Add a risk score from 0 to 100 to each finding, combining severity, EPSS, reachability, fix availability and dependency depth")
+	fs.StringVar(&o.RiskWeights, "risk-weights", "", "Weights of the risk factors of --risk, --sort-risk and --min-risk, e.g. severity=0.5,epss=0.3 (default: the profile's risk weights)")
+	fs.BoolVar(&o.SortRisk, "sort-risk", false, "Sort findings by risk score, highest first; implies --risk")
+	fs.Float64Var(&o.MinRisk, "min-risk", 0, "Only keep findings with at least this risk score (0 disables the check); implies --risk")
+	fs.BoolVar(&o.Dedupe, "dedupe", false, "Collapse findings of the same issue, e.g. found in several child namespaces, into one with a count of occurrences")
+	fs.StringVar(&o.DedupeKey, "dedupe-key", dedupe.DefaultKey, "Fields identifying the same issue for --dedupe: "+strings.Join(dedupe.Fields, ", "))
+	fs.StringVar(&o.Correlate, "correlate", "", "Group findings repeated across projects sharing a repository origin: repo (mirrors) or name (forks); implies --resolve-projects")
+	o.addFilterFlags(fs)
+}
+
+// addProjectFlags registers the flags that select the projects on fs
+func (o *findingsOptions) addProjectFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&o.ProjectUUIDs, "project_uuid", nil, "UUID of a project to fetch findings for (repeat or comma-separate for several)")
+	fs.StringSliceVar(&o.Repos, "repo", nil, "Repository URL of a project to fetch findings for, e.g. github.com/org/repo (repeatable)")
+	fs.StringSliceVar(&o.ProjectNames, "project-name", nil, "Exact name of a project to fetch findings for (repeatable)")
+	fs.BoolVar(&o.AllProjects, "all-projects", false, "Fetch findings for all projects (ignores project_uuid)")
+}
+
+// addFilterFlags registers the flags that compose the findings filter on fs
+func (o *findingsOptions) addFilterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Filter.Levels, "level", "", "Comma-separated finding levels, e.g. critical,high (default: critical for a project, critical,high for all projects)")
+	fs.StringVar(&o.Filter.Categories, "categories", "vulnerability", "Comma-separated finding categories")
+	fs.StringVar(&o.Filter.Tags, "tags", "normal", "Comma-separated finding tags that must all be present")
+	fs.Float64Var(&o.Filter.EPSSMin, "epss-min", 0.01, "Minimum EPSS probability score (0 disables the check)")
+	fs.BoolVar(&o.Filter.ReachableOnly, "reachable-only", true, "Only include findings with reachable functions and dependencies")
+	fs.BoolVar(&o.Filter.FixAvailable, "fix-available", true, "Only include findings with a fix available")
+	fs.StringVar(&o.Filter.RawFilter, "raw-filter", "", "Raw Endor filter expression (overrides all other filter flags)")
+	fs.StringVar(&o.Filter.Contexts, "context", contexts.Default, "Comma-separated contexts to fetch from and merge: main, ref:<branch>, ci-run (latest run) or ci-run:<id>")
+}
+
+// validate checks that a project or all projects were selected, loads the
+// CODEOWNERS file and enrichment bundle if given and parses the enricher commands
+func (o *findingsOptions) validate() error {
+	if !o.AllProjects && len(o.ProjectUUIDs) == 0 && len(o.Repos) == 0 && len(o.ProjectNames) == 0 {
+		return errors.New("either --project_uuid, --repo, --project-name or --all-projects is required")
+	}
+	if o.FailOn != "" {
+		if _, err := api.ParseFindingLevel(o.FailOn); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+	}
+	if o.Correlate != "" {
+		if !correlate.ValidMode(o.Correlate) {
+			return fmt.Errorf("invalid --correlate mode %q (expected %s)", o.Correlate, strings.Join(correlate.Modes, " or "))
+		}
+		// Origins come from the projects' repository URLs
+		o.ResolveProjects = true
+	}
+	if o.CodeOwners != "" {
+		owners, err := codeowners.Load(o.CodeOwners)
+		if err != nil {
+			return err
+		}
+		o.owners = owners
+	}
+	if dir := firstNonEmpty(o.Bundle, os.Getenv("ENDOR_BUNDLE")); dir != "" {
+		b, err := bundle.Load(dir)
+		if err != nil {
+			return fmt.Errorf("failed to load enrichment bundle: %w", err)
+		}
+		slog.Info("Loaded enrichment bundle", "dir", dir, "contents", b.Summary())
+		o.bundle = b
+	}
+	if o.KEVOnly && !o.ThreatIntel && o.bundle == nil {
+		return errors.New("--kev-only needs KEV data from --threat-intel or a --bundle")
+	}
+	if o.ThreatIntel && o.threatIntel == nil {
+		o.threatIntel = &enrich.ThreatIntel{Source: bundle.Live{
+			KEVURL:     os.Getenv("ENDOR_KEV_URL"),
+			EPSSAPIURL: os.Getenv("ENDOR_EPSS_API_URL"),
+		}}
+	}
+	o.cvss = nil
+	if o.CVSS || o.Filter.CVSSMin > 0 || o.Filter.CVSSEnvironmental != "" {
+		env, err := cvss.ParseEnvironment(o.Filter.CVSSEnvironmental)
+		if err != nil {
+			return fmt.Errorf("invalid --cvss-environmental: %w", err)
+		}
+		o.cvss = &enrich.CVSS{Environment: env}
+	}
+	o.risk = nil
+	if o.Risk || o.SortRisk || o.MinRisk > 0 {
+		weights, err := risk.ParseWeights(o.RiskWeights)
+		if err != nil {
+			return fmt.Errorf("invalid --risk-weights: %w", err)
+		}
+		if o.risk, err = risk.NewScorer(weights); err != nil {
+			return fmt.Errorf("invalid --risk-weights: %w", err)
+		}
+	}
+	if o.Dedupe {
+		key, err := dedupe.ParseKey(o.DedupeKey)
+		if err != nil {
+			return fmt.Errorf("invalid --dedupe-key: %w", err)
+		}
+		o.dedupeKey = key
+	}
+	o.commands = nil
+	for _, spec := range o.Enrich {
+		c, err := enrich.ParseCommand(spec)
+		if err != nil {
+			return err
+		}
+		o.commands = append(o.commands, c)
+	}
+	return nil
+}
+
+// enrichers builds the enrichment pipeline: project resolution, CODEOWNERS
+// attribution, bundled and live KEV/EPSS/CWE data, CVSS scores, the --enrich
+// commands in the order given, then risk scores, which may build on them all
+func (o *findingsOptions) enrichers(cache *api.ProjectCache, token string) enrich.Pipeline {
+	var p enrich.Pipeline
+	if o.ResolveProjects {
+		p = append(p, enrich.Projects{Cache: cache, Token: token})
+	}
+	if o.owners != nil {
+		p = append(p, enrich.Owners{Rules: o.owners})
+	}
+	if o.bundle != nil {
+		p = append(p, enrich.Bundle{Data: o.bundle})
+	}
+	if o.threatIntel != nil {
+		p = append(p, o.threatIntel)
+	}
+	if o.cvss != nil {
+		p = append(p, *o.cvss)
+	}
+	for _, c := range o.commands {
+		p = append(p, c)
+	}
+	if o.risk != nil {
+		p = append(p, o.risk)
+	}
+	return p
+}
+
+// resolveProjects looks up the projects selected by --repo and --project-name
+// and adds their UUIDs to ProjectUUIDs
+func (o *findingsOptions) resolveProjects(ctx context.Context, client *api.Client, token string) error {
+	if o.AllProjects {
+		return nil
+	}
+
+	for _, repo := range o.Repos {
+		p, err := client.FindProjectByRepo(ctx, token, repo)
+		if err != nil {
+			return fmt.Errorf("failed to resolve --repo: %w", err)
+		}
+		slog.Info("Resolved repository to project", "repo", repo, "project_uuid", p.UUID)
+		o.ProjectUUIDs = append(o.ProjectUUIDs, p.UUID)
+	}
+	for _, name := range o.ProjectNames {
+		p, err := client.FindProjectByName(ctx, token, name)
+		if err != nil {
+			return fmt.Errorf("failed to resolve --project-name: %w", err)
+		}
+		slog.Info("Resolved project name", "name", name, "project_uuid", p.UUID)
+		o.ProjectUUIDs = append(o.ProjectUUIDs, p.UUID)
+	}
+	o.Repos, o.ProjectNames = nil, nil
+
+	return nil
+}
+
+// buildFilter composes the filter expression, defaulting the levels to critical
+// for a single project and critical,high for all projects
+func (o *findingsOptions) buildFilter() (string, error) {
+	opts := o.Filter
+	if opts.Levels == "" {
+		if o.AllProjects {
+			opts.Levels = "critical,high"
+		} else {
+			opts.Levels = "critical"
+		}
+	}
+
+	if o.epssPostFilter() {
+		// Applied by postFilter, to the scores enrichment adds too
+		opts.EPSSMin = 0
+	}
+
+	filter, err := buildFindingsFilter(opts)
+	if err != nil {
+		return "", fmt.Errorf("invalid filter: %w", err)
+	}
+	return filter, nil
+}
+
+// epssPostFilter reports whether --epss-min is applied after enrichment
+// instead of by the API, because KEV and EPSS enrichment is on and can add
+// scores the API has none for
+func (o *findingsOptions) epssPostFilter() bool {
+	enriched := o.ThreatIntel || firstNonEmpty(o.Bundle, os.Getenv("ENDOR_BUNDLE")) != ""
+	return enriched && o.Filter.EPSSMin > 0 && o.Filter.RawFilter == ""
+}
+
+// postFiltered reports whether postFilter drops any findings
+func (o *findingsOptions) postFiltered() bool {
+	return o.KEVOnly || o.epssPostFilter() || o.Filter.CVSSMin > 0 || o.MinRisk > 0
+}
+
+// postFilter returns the enriched findings that pass --kev-only, --min-cvss,
+// --min-risk and, when it is applied after enrichment, --epss-min. Findings the API tags
+// as exploited count as in the KEV catalog.
+func (o *findingsOptions) postFilter(findings []api.Finding) []api.Finding {
+	if !o.postFiltered() {
+		return findings
+	}
+	epss := o.epssPostFilter()
+	kept := findings[:0]
+	for _, f := range findings {
+		if o.KEVOnly && enrich.KEV(f) == nil && !f.HasTag(api.TagExploited) {
+			continue
+		}
+		if score, ok := enrich.EPSS(f); epss && (!ok || score < o.Filter.EPSSMin) {
+			continue
+		}
+		if score, ok := enrich.CVSSScore(f); o.Filter.CVSSMin > 0 && (!ok || score < o.Filter.CVSSMin) {
+			continue
+		}
+		if score, _ := risk.Of(f); score < o.MinRisk {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// dedupe collapses the findings of the same issue by --dedupe-key
+func (o *findingsOptions) dedupe(findings []api.Finding) []api.Finding {
+	unique := dedupe.Dedupe(findings, o.dedupeKey)
+	if len(unique) < len(findings) {
+		slog.Info("Deduplicated findings", "findings", len(findings), "unique", len(unique), "key", o.dedupeKey)
+	}
+	return unique
+}
+
+// description returns a human readable description of the selected findings
+func (o *findingsOptions) description() string {
+	var desc string
+	switch {
+	case o.AllProjects:
+		desc = "all projects"
+	case len(o.ProjectUUIDs) == 1:
+		desc = fmt.Sprintf("project %s", o.ProjectUUIDs[0])
+	default:
+		desc = fmt.Sprintf("%d projects", len(o.ProjectUUIDs))
+	}
+	if o.Filter.RawFilter != "" {
+		return desc
+	}
+	if specs, err := contexts.ParseList(o.Filter.Contexts); err == nil && (len(specs) > 1 || specs[0].Label != contexts.Default) {
+		desc += " in " + strings.Join(contexts.Labels(specs), ", ")
+	}
+	return desc
+}
+
+// filenameLabel returns the selection as file names give it: the project
+// UUID, all_projects or N_projects
+func (o *findingsOptions) filenameLabel() string {
+	switch {
+	case o.AllProjects:
+		return "all_projects"
+	case len(o.ProjectUUIDs) == 1:
+		return o.ProjectUUIDs[0]
+	default:
+		return fmt.Sprintf("%d_projects", len(o.ProjectUUIDs))
+	}
+}
+
+// defaultFilename returns the timestamped default output file name for ext
+func (o *findingsOptions) defaultFilename(ext string) string {
+	return fmt.Sprintf("findings_%s_%s.%s", o.filenameLabel(), time.Now().Format("2006-01-02_15-04-05"), ext)
+}
+
+// fetch retrieves the selected findings and runs the enrichment pipeline over them.
+// When several projects are selected, projects that fail are recorded in the result and
+// only an error for every project aborts the fetch.
+func (o *findingsOptions) fetch(ctx context.Context, client *api.Client, token, filter string, cache *api.ProjectCache) (fetchResult, error) {
+	var result fetchResult
+	var err error
+
+	switch {
+	case o.AllProjects:
+		result.Findings, err = client.GetFindingsForAllProjects(ctx, token, filter)
+	case len(o.ProjectUUIDs) == 1:
+		result.Findings, err = client.GetFindings(ctx, token, o.ProjectUUIDs[0], filter)
+	default:
+		result.ProjectErrors = make(map[string]string)
+		for _, pf := range client.GetFindingsForProjects(ctx, token, o.ProjectUUIDs, filter, o.Concurrency) {
+			if pf.Err != nil {
+				client.AddWarning(api.Warning{
+					Code:     api.WarningProjectFailed,
+					Message:  pf.Err.Error(),
+					Resource: "projects",
+					UUID:     pf.ProjectUUID,
+				})
+				result.ProjectErrors[pf.ProjectUUID] = pf.Err.Error()
+				continue
+			}
+			slog.Debug("Fetched project", "project_uuid", pf.ProjectUUID, "findings", len(pf.Findings))
+			result.Findings = append(result.Findings, pf.Findings...)
+		}
+		if len(result.ProjectErrors) == len(o.ProjectUUIDs) {
+			err = errors.New("every project failed")
+		}
+		// Each project is sorted and limited on its own
+		result.Findings = client.ListOptions().ApplyTo(result.Findings)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to fetch findings: %w", err)
+	}
+
+	// A raw filter selects its own contexts
+	if o.Filter.RawFilter == "" {
+		specs, err := contexts.ParseList(o.Filter.Contexts)
+		if err != nil {
+			return result, err
+		}
+		if len(specs) > 1 || specs[0].Latest() {
+			result.Findings = contexts.Merge(result.Findings, specs, diff.Fingerprint)
+		}
+	}
+
+	for _, w := range o.enrichers(cache, token).Run(ctx, result.Findings) {
+		client.AddWarning(w)
+	}
+	if fetched := len(result.Findings); o.postFiltered() {
+		result.Findings = o.postFilter(result.Findings)
+		slog.Info("Applied the KEV, EPSS, CVSS and risk filters", "kept", len(result.Findings), "fetched", fetched)
+	}
+	if o.Dedupe {
+		result.Findings = o.dedupe(result.Findings)
+	}
+	if o.SortRisk {
+		risk.Sort(result.Findings)
+	}
+
+	if o.Correlate != "" {
+		result.Correlations = correlate.Correlate(result.Findings, o.Correlate)
+		correlate.Annotate(result.Findings, result.Correlations)
+		slog.Info("Correlated across repository origins", "correlations", correlate.Summary(result.Correlations))
+	}
+
+	return result, nil
+}
+
+func newFindingsCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "findings",
+		Short: "Fetch, export and stream security findings",
+	}
+
+	cmd.AddCommand(
+		newFindingsListCmd(g),
+		newFindingsCountCmd(g),
+		newFindingsExportCmd(g),
+		newFindingsTailCmd(g),
+		newFindingsWatchCmd(g),
+		newFindingsNotifyCmd(g),
+		newFindingsLinksCmd(),
+		newFindingsDiffCmd(g),
+		newFindingsCheckCmd(g),
+		newFindingsDismissCmd(g),
+		newFindingsBrowseCmd(g),
+	)
+	cmd.PersistentFlags().StringSliceVar(&g.List.FieldMask, "fields", nil, "Comma-separated finding fields to request instead of the default mask; prefix a field with + to add it to the default mask")
+	cmd.PersistentFlags().StringVar(&g.List.Sort, "sort", "", "Sort findings server-side, riskiest or newest first: "+strings.Join(api.SortKeys, ", "))
+	cmd.PersistentFlags().IntVar(&g.List.Limit, "limit", 0, "Stop after this many findings, fetching no further pages (0 fetches them all)")
+	cmd.PersistentFlags().BoolVar(&g.List.NoTraverse, "no-traverse", false, "Only fetch findings of the namespace itself, not of its child namespaces")
+	cmd.PersistentFlags().IntVar(&g.List.MaxPages, "max-pages", api.DefaultMaxPages, "Fail a listing that has more than this many pages rather than return it incomplete (-1 removes the cap)")
+	cmd.PersistentFlags().IntVar(&g.List.Prefetch, "prefetch", 0, "Fetch up to this many pages concurrently ahead of the one being processed, when the API numbers its pages (0 fetches one page at a time)")
+
+	return cmd
+}
+
+// textUsage documents the --text flag of commands that render findings
+const textUsage = `Long text fields (summary, explanation, advisory description): full, paragraph, omit or a character count, optionally by format, e.g. "table=120,sarif=full"`
+
+// parseTextFlag parses the --text policies by format
+func parseTextFlag(spec string) (map[string]output.TextPolicy, error) {
+	policies, err := output.ParseTextPolicies(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --text: %w", err)
+	}
+	return policies, nil
+}
+
+func newFindingsListCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var format, columnSpec, textSpec, baseline, baselineKey, groupBy string
+	var maxWidth int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Print findings for a project or all projects",
+		Example: `  findings-api findings list --project_uuid abc123-def456-ghi789
+  findings-api findings list --all-projects --level critical,high
+  findings-api findings list --all-projects --columns level,cve,package,project_name --max-width 0
+  findings-api findings list --project_uuid abc123 --columns level,cve,explanation --max-width 0 --text 200
+  findings-api findings list --repo github.com/acme/payments --baseline main.json --fail-on high
+  findings-api findings list --all-projects --group-by package`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if err := g.validateNamespaces(opts); err != nil {
+				return err
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			if groupBy != "" {
+				if err := aggregate.Validate(groupBy); err != nil {
+					return err
+				}
+				if baseline != "" {
+					return errors.New("--group-by cannot be combined with --baseline")
+				}
+			}
+			columns, err := output.ParseColumns(columnSpec)
+			if err != nil {
+				return fmt.Errorf("invalid columns: %w", err)
+			}
+			text, err := parseTextFlag(textSpec)
+			if err != nil {
+				return err
+			}
+			var before []api.Finding
+			if baseline != "" {
+				if _, err := diff.KeyFunc(baselineKey); err != nil {
+					return err
+				}
+				if before, err = diff.Load(baseline); err != nil {
+					return err
+				}
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			fetched, err := g.fetchSelected(cmd.Context(), opts, filter)
+			if err != nil {
+				return err
+			}
+			logFetchReport(fetched.Report)
+			logWarnings(fetched.Warnings)
+			findings := fetched.Findings
+			shown := output.ApplyText(findings, output.TextPolicyFor(text, format))
+
+			// With a baseline, only the changes since it are shown and gated on
+			if baseline != "" {
+				changes, err := diff.Compare(output.ApplyText(before, output.TextPolicyFor(text, format)), shown, baselineKey)
+				if err != nil {
+					return err
+				}
+				slog.Info("Compared with the baseline", "baseline", baseline, "baseline_findings", len(before), "changes", changes.Summary())
+				if format == "json" {
+					if err := printJSON(changes); err != nil {
+						return err
+					}
+				} else if err := writeDiffTable(os.Stdout, changes, columns, maxWidth, false); err != nil {
+					return err
+				}
+				return opts.checkFailOn(changes.New)
+			}
+
+			if groupBy != "" {
+				groups, err := aggregate.By(findings, groupBy)
+				if err != nil {
+					return err
+				}
+				if format == "json" {
+					err = printJSON(groups)
+				} else {
+					fmt.Printf("Found %d findings in %d groups by %s for %s:\n\n", len(findings), len(groups), groupBy, opts.description())
+					err = writeGroupTable(os.Stdout, groups)
+				}
+				if err != nil {
+					return err
+				}
+				return opts.checkFailOn(findings)
+			}
+
+			if format == "json" {
+				if err := printJSON(shown); err != nil {
+					return err
+				}
+				return opts.checkFailOn(findings)
+			}
+
+			fmt.Printf("Found %d findings for %s:\n\n", len(findings), opts.description())
+			if len(findings) > 0 {
+				if err := output.WriteTable(os.Stdout, shown, columns, maxWidth); err != nil {
+					return err
+				}
+			}
+
+			return opts.checkFailOn(findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	cmd.Flags().StringVar(&columnSpec, "columns", "level,cve,name,package,project_uuid", "Comma-separated columns of the table (see findings export --columns)")
+	cmd.Flags().IntVar(&maxWidth, "max-width", 40, "Truncate table values longer than this many characters (0 disables truncation)")
+	cmd.Flags().StringVar(&textSpec, "text", "table=paragraph", textUsage)
+	cmd.Flags().StringVar(&baseline, "baseline", "", "Findings snapshot to compare with; only new and resolved findings are listed, and --fail-on only counts new ones")
+	cmd.Flags().StringVar(&baselineKey, "baseline-key", diff.ByUUID, "Match findings with the baseline by uuid or fingerprint")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Print one line per group of findings with counts and worst level: "+strings.Join(aggregate.Keys, ", "))
+	g.addNamespacesFlag(cmd.Flags())
+	return cmd
+}
+
+// writeGroupTable prints one row per group with its counts by level
+func writeGroupTable(w io.Writer, groups []aggregate.Group) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tCOUNT\tWORST\tCRITICAL\tHIGH\tMEDIUM\tLOW\tPROJECTS\tFIXED IN")
+	for _, g := range groups {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n", g.Key, g.Count, g.Worst.Short(),
+			g.Levels["critical"], g.Levels["high"], g.Levels["medium"], g.Levels["low"], g.Projects, strings.Join(g.FixedVersions, ", "))
+	}
+	return tw.Flush()
+}
+
+func newFindingsExportCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var formats []string
+	var columnSpec, themePath, textSpec, historyDB, templatePath, uiURL, filenameTemplateText string
+	var splitByOwner, splitByProject, record, noSave bool
+	var maxWidth int
+	mail := &emailOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Save findings as JSON, NDJSON, a table, CSV, XLSX, SARIF, or a Markdown or HTML report",
+		Example: `  findings-api findings export --project_uuid abc123-def456-ghi789
+  findings-api findings export --all-projects --format xlsx --columns uuid,name,level,package
+  findings-api findings export --all-projects --format json,csv,sarif -o reports/findings
+  findings-api findings export --repo github.com/acme/payments --format sarif -o - | gzip > results.sarif.gz
+  findings-api findings export --all-projects --record
+  findings-api findings export --all-projects --split-by-project --out-dir reports
+  findings-api findings export --all-projects --resume
+  findings-api findings export --repo github.com/acme/payments --format markdown -o - >> "$GITHUB_STEP_SUMMARY"
+  findings-api findings export --all-projects --format html --email --email-to secteam@acme.com`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			run := g.startRun(cmd, args)
+			defer func() { err = g.finishRun(cmd.Context(), run, err) }()
+
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if err := g.validateNamespaces(opts); err != nil {
+				return err
+			}
+			if splitByOwner && opts.CodeOwners == "" {
+				return errors.New("--split-by-owner requires --codeowners")
+			}
+			if splitByOwner && splitByProject {
+				return errors.New("--split-by-owner and --split-by-project cannot be combined")
+			}
+			split := ""
+			if splitByOwner {
+				split = "--split-by-owner"
+			} else if splitByProject {
+				split = "--split-by-project"
+				// Files are named by project name
+				opts.ResolveProjects = true
+			}
+			if split != "" && g.Output == stdoutPath {
+				return fmt.Errorf("%s cannot write to stdout", split)
+			}
+			if noSave {
+				switch {
+				case g.Output != "":
+					return errors.New("--no-save cannot be combined with --output")
+				case g.OutDir != "":
+					return errors.New("--no-save cannot be combined with --out-dir")
+				case filenameTemplateText != "":
+					return errors.New("--no-save cannot be combined with --filename-template")
+				case split != "":
+					return fmt.Errorf("--no-save cannot be combined with %s", split)
+				}
+			}
+			if filenameTemplateText != "" && g.Output != "" {
+				return errors.New("--filename-template cannot be combined with --output")
+			}
+			if g.OutDir != "" {
+				if g.Output == stdoutPath {
+					return errors.New("--out-dir cannot be combined with --output -")
+				}
+				if err := os.MkdirAll(g.OutDir, 0o755); err != nil {
+					return fmt.Errorf("failed to create --out-dir: %w", err)
+				}
+			}
+			columns, err := output.ParseColumns(columnSpec)
+			if err != nil {
+				return fmt.Errorf("invalid columns: %w", err)
+			}
+			theme, err := g.reportTheme(themePath)
+			if err != nil {
+				return err
+			}
+			text, err := parseTextFlag(textSpec)
+			if err != nil {
+				return err
+			}
+			outputOpts := output.Options{
+				Columns:        columns,
+				Theme:          theme,
+				MaxColumnWidth: maxWidth,
+				Text:           text,
+				Template:       templatePath,
+				UIURL:          firstNonEmpty(uiURL, os.Getenv("ENDOR_UI_URL")),
+				Namespace:      g.namespace(),
+			}
+			writers, err := newFormatWriters(formats, outputOpts)
+			if err != nil {
+				return err
+			}
+			var mailer *email.Client
+			var subject string
+			if mail.Enabled {
+				if err := g.requireNetwork("emailing the report"); err != nil {
+					return err
+				}
+				if mailer, subject, err = mail.resolve(g); err != nil {
+					return err
+				}
+			}
+			if len(writers) > 1 && g.Output == stdoutPath {
+				return errors.New("several --format values cannot all be written to stdout")
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			if err := g.openCheckpoint(); err != nil {
+				return err
+			}
+			defer func() { g.closeCheckpoint(err) }()
+
+			names, err := newFilenameTemplate(filenameTemplateText, g.namespace(), writers)
+			if err != nil {
+				return err
+			}
+			// projectFilename names the files of project, or of the selection
+			// as a whole when project is empty
+			projectFilename := func(project, ext string) string {
+				if g.Output != "" {
+					name := exportFilename(g.Output, "", ext, len(writers) > 1)
+					if project != "" {
+						name = labeledFilename(name, project)
+					}
+					return g.inOutDir(name)
+				}
+				return makeParentDir(g.inOutDir(names.filename(firstNonEmpty(project, opts.filenameLabel()), ext)))
+			}
+			filename := func(ext string) string { return projectFilename("", ext) }
+
+			if len(writers) == 1 && writers[0].Format == "ndjson" && !noSave {
+				blocker := opts.streamBlocker(g, split, record, mail.Enabled)
+				if blocker == "" {
+					client, token, err := g.authenticate(cmd.Context())
+					if err != nil {
+						return err
+					}
+					if err := opts.resolveProjects(cmd.Context(), client, token); err != nil {
+						return err
+					}
+					slog.Info("Streaming findings", "selection", opts.description())
+					defer g.trackProgress(cmd.Context(), client, token, opts, filter)()
+					return opts.streamExport(cmd.Context(), client, token, filter, writers[0], filename(writers[0].Writer.Extension()), g.console(), run)
+				}
+				slog.Info("Buffering findings before writing ndjson, as an option needs every finding first", "option", blocker)
+			}
+
+			result, err := g.fetchSelected(cmd.Context(), opts, filter)
+			if err != nil {
+				return err
+			}
+			findings := result.Findings
+
+			report := result.Report
+			logFetchReport(report)
+			warnings := result.Warnings
+			logWarnings(warnings)
+			run.Selection, run.Filter = opts.description(), filter
+			run.SetFindings(findings, len(warnings), len(result.ProjectErrors))
+			if record {
+				recordHistory(cmd.Context(), historyDB, run, findings)
+			}
+
+			console := g.console()
+			fmt.Fprintf(console, "Found %d findings for %s\n", len(findings), opts.description())
+
+			// save renders every format; a format that fails does not stop the others
+			save := func(findings []api.Finding, desc string, filename func(ext string) string) error {
+				doc := output.NewDocument(findings, desc, report, result.ProjectErrors, warnings)
+				doc.Correlations = result.Correlations
+
+				failed := 0
+				for _, r := range renderFormats(writers, doc, filename) {
+					if r.Err != nil {
+						failed++
+						err := fmt.Errorf("failed to save %s: %w", r.Format, r.Err)
+						slog.Error("Failed to save a format", "format", r.Format, "error", r.Err)
+						run.AddError(err)
+						continue
+					}
+					addRunOutput(run, r.Filename, r.Format)
+					if r.Filename != stdoutPath {
+						fmt.Fprintf(console, "Findings saved to: %s\n", r.Filename)
+					}
+				}
+				if failed > 0 {
+					return fmt.Errorf("failed to save findings in %d of %d formats", failed, len(writers))
+				}
+				return nil
+			}
+
+			switch {
+			case noSave:
+				slog.Info("Not saving the findings", "option", "--no-save")
+			case splitByOwner:
+				for _, group := range groupByOwner(findings) {
+					desc := fmt.Sprintf("%s owned by %s", opts.description(), group.Owner)
+					ownerFile := func(ext string) string { return labeledFilename(filename(ext), group.Owner) }
+					if err := save(group.Findings, desc, ownerFile); err != nil {
+						return err
+					}
+				}
+			case splitByProject:
+				for _, group := range groupByProject(findings) {
+					desc := fmt.Sprintf("project %s", group.Name)
+					projectFile := func(ext string) string { return projectFilename(group.Label, ext) }
+					if err := save(group.Findings, desc, projectFile); err != nil {
+						return err
+					}
+				}
+			default:
+				if err := save(findings, opts.description(), filename); err != nil {
+					return err
+				}
+			}
+
+			if mailer != nil {
+				doc := output.NewDocument(findings, opts.description(), report, result.ProjectErrors, warnings)
+				if err := sendReport(cmd.Context(), mailer, subject, doc, outputOpts); err != nil {
+					return err
+				}
+				fmt.Fprintf(console, "Report emailed to: %s\n", strings.Join(mailer.Recipients(), ", "))
+			}
+			return opts.checkFailOn(findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	cmd.Flags().StringSliceVar(&formats, "format", []string{"json"}, "Output format, or comma-separated formats rendered concurrently: "+strings.Join(output.Formats, ", "))
+	cmd.Flags().StringVar(&themePath, "theme", "", "JSON file with a report theme (company name, logo, colours, font), replacing the profile's theme")
+	cmd.Flags().BoolVar(&splitByOwner, "split-by-owner", false, "Write one file per CODEOWNERS owner (requires --codeowners)")
+	cmd.Flags().BoolVar(&splitByProject, "split-by-project", false, "Write one file per project, named by project name, or UUID when names repeat; implies --resolve-projects")
+	cmd.Flags().StringVar(&filenameTemplateText, "filename-template", "", "Go template naming the output files, with .Project, .Namespace, .Timestamp, .Format and .Ext (default: "+defaultFilenameTemplate+")")
+	cmd.Flags().BoolVar(&noSave, "no-save", false, "Fetch, record, email and check the findings without saving any file")
+	cmd.Flags().StringVar(&g.OutDir, "out-dir", "", "Directory the output files and run manifest are written to, created if needed (relative --output paths are inside it)")
+	cmd.Flags().StringVar(&columnSpec, "columns", "", "Comma-separated columns for table, csv and xlsx output (default: "+strings.Join(output.DefaultColumns, ",")+")")
+	cmd.Flags().IntVar(&maxWidth, "max-width", 0, "Truncate table values longer than this many characters (0 disables truncation)")
+	cmd.Flags().StringVar(&textSpec, "text", output.TextFull, textUsage)
+	cmd.Flags().StringVar(&templatePath, "template", "", "Go template file replacing the built-in markdown and html reports")
+	cmd.Flags().StringVar(&uiURL, "ui-url", "", "Endor Labs app URL that markdown, html and gitlab reports link findings to (default: $ENDOR_UI_URL or "+output.DefaultUIURL+")")
+	cmd.Flags().BoolVar(&record, "record", false, "Record the fetched findings in the local history (see the history command)")
+	cmd.Flags().StringVar(&historyDB, "history-db", "", historyDBUsage)
+	mail.addFlags(cmd.Flags())
+	g.addNamespacesFlag(cmd.Flags())
+	g.addCheckpointFlags(cmd.Flags())
+	return cmd
+}
+
+func newFindingsTailCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var interval, watchConfig time.Duration
+
+	cmd := &cobra.Command{
+		Use:     "tail",
+		Short:   "Poll for findings and stream newly observed ones to stdout as NDJSON",
+		Example: `  findings-api findings tail --all-projects --interval 10m | jq -r '.meta.name'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			token, err := g.token(ctx, client)
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(ctx, client, token); err != nil {
+				return err
+			}
+
+			cache := api.NewProjectCache(client)
+			warned := 0
+			fetch := func() ([]api.Finding, error) {
+				// Re-check the token on every poll so long-running tails survive token expiry
+				token, err := g.token(ctx, client)
+				if err != nil {
+					return nil, err
+				}
+				result, err := opts.fetch(ctx, client, token, filter, cache)
+
+				warnings := client.Warnings()
+				logWarnings(warnings[warned:])
+				warned = len(warnings)
+
+				return result.Findings, err
+			}
+
+			reload := pollReload{
+				files: g.configChanges(ctx, watchConfig),
+				apply: func(file *config.File) (time.Duration, bool, error) {
+					changed, err := g.reloadPollConfig(cmd, opts, file, &filter, &interval)
+					return interval, changed, err
+				},
+			}
+			return runTail(ctx, fetch, interval, reload, os.Stdout)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "Polling interval")
+	addWatchConfigFlag(cmd.Flags(), &watchConfig)
+	return cmd
+}
+
+// logFetchReport logs a summary of the fetch report when anything needed retrying or failed
+func logFetchReport(report api.FetchReport) {
+	if report.TotalRetries > 0 || report.FailedRequests > 0 {
+		slog.Info("Fetch report", "requests", report.TotalRequests, "retries", report.TotalRetries, "failed", report.FailedRequests)
+	}
+}
+
+// logWarnings logs a summary line and each recorded warning
+func logWarnings(warnings []api.Warning) {
+	if len(warnings) == 0 {
+		return
+	}
+	slog.Warn("The fetch recorded warnings", "count", len(warnings))
+	for _, w := range warnings {
+		slog.Warn("Fetch warning", "warning", w)
+	}
+}
+
+// unownedGroup is the owner name used for findings no CODEOWNERS rule covers
+const unownedGroup = "unowned"
+
+// ownerGroup is the set of findings attributed to one owner
+type ownerGroup struct {
+	Owner    string
+	Findings []api.Finding
+}
+
+// groupByOwner groups findings by owner, sorted by owner name. A finding with
+// several owners appears in each of their groups.
+func groupByOwner(findings []api.Finding) []ownerGroup {
+	byOwner := make(map[string][]api.Finding)
+	for _, f := range findings {
+		owners := f.Owners
+		if len(owners) == 0 {
+			owners = []string{unownedGroup}
+		}
+		for _, o := range owners {
+			byOwner[o] = append(byOwner[o], f)
+		}
+	}
+
+	groups := make([]ownerGroup, 0, len(byOwner))
+	for owner, fs := range byOwner {
+		groups = append(groups, ownerGroup{Owner: owner, Findings: fs})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Owner < groups[j].Owner })
+	return groups
+}
+
+// projectGroup is the findings of one project with the label its files are named by
+type projectGroup struct {
+	UUID     string
+	Name     string
+	Label    string
+	Findings []api.Finding
+}
+
+// groupByProject groups findings by project, sorted by label. Projects are
+// labelled by name, or by UUID when the name is unknown or shared by another
+// project, so that every project gets its own file.
+func groupByProject(findings []api.Finding) []projectGroup {
+	index := make(map[string]int)
+	var groups []projectGroup
+	for _, f := range findings {
+		i, ok := index[f.Spec.ProjectUUID]
+		if !ok {
+			i = len(groups)
+			index[f.Spec.ProjectUUID] = i
+			name := f.Spec.ProjectUUID
+			if f.Project != nil && f.Project.Name != "" {
+				name = f.Project.Name
+			}
+			groups = append(groups, projectGroup{UUID: f.Spec.ProjectUUID, Name: name})
+		}
+		groups[i].Findings = append(groups[i].Findings, f)
+	}
+
+	names := make(map[string]int)
+	for _, g := range groups {
+		names[fileSlug(g.Name)]++
+	}
+	for i, g := range groups {
+		groups[i].Label = g.Name
+		if g.Name == g.UUID || names[fileSlug(g.Name)] > 1 {
+			groups[i].Label = firstNonEmpty(g.UUID, "no-project")
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Label < groups[j].Label })
+	return groups
+}
+
+// labeledFilename inserts a file-name-safe form of label, such as an owner or a
+// project name, before the extension of filename
+func labeledFilename(filename, label string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "_" + fileSlug(label) + ext
+}
+
+// fileSlug replaces the characters of s that are unsafe in file names
+func fileSlug(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, strings.TrimPrefix(s, "@"))
+}
+
+// saveFindings renders doc with writer into filename, or stdout for "-"
+func saveFindings(writer output.Writer, doc *output.Document, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := writer.Write(file, doc); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	return nil
+}
+
+// formatWriter is the writer of one requested output format
+type formatWriter struct {
+	Format string
+	Writer output.Writer
+}
+
+// newFormatWriters returns a writer for each format, ignoring repeats
+func newFormatWriters(formats []string, opts output.Options) ([]formatWriter, error) {
+	var writers []formatWriter
+	seen := make(map[string]bool)
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+
+		w, err := output.NewWriter(f, opts)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, formatWriter{Format: f, Writer: w})
+	}
+	if len(writers) == 0 {
+		return nil, errors.New("at least one --format is required")
+	}
+	return writers, nil
+}
+
+// formatResult is the outcome of rendering one format
+type formatResult struct {
+	Format   string
+	Filename string
+	Err      error
+}
+
+// renderFormats saves doc in every format at once, into the file filename
+// returns for the format's extension. Results are in the order of writers.
+func renderFormats(writers []formatWriter, doc *output.Document, filename func(ext string) string) []formatResult {
+	results := make([]formatResult, len(writers))
+	var wg sync.WaitGroup
+	for i, fw := range writers {
+		wg.Add(1)
+		go func(i int, fw formatWriter) {
+			defer wg.Done()
+			name := filename(fw.Writer.Extension())
+			results[i] = formatResult{Format: fw.Format, Filename: name, Err: saveFindings(fw.Writer, doc, name)}
+		}(i, fw)
+	}
+	wg.Wait()
+	return results
+}
+
+// exportFilename returns the file an export in the format with extension ext
+// is saved to. A single format goes to --output as given; with several, --output
+// names the files and each format's extension replaces its own.
+func exportFilename(outputFlag, defaultName, ext string, multi bool) string {
+	if outputFlag == "" {
+		return defaultName
+	}
+	if !multi || outputFlag == stdoutPath {
+		return outputFlag
+	}
+	return strings.TrimSuffix(outputFlag, filepath.Ext(outputFlag)) + "." + ext
+}
@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/ghissues"
+	"github.com/endor-labs/findings-api/internal/links"
+	"github.com/endor-labs/findings-api/internal/scm"
+	"github.com/endor-labs/findings-api/internal/tickets"
+	"github.com/spf13/cobra"
+)
+
+// githubOptions are the GitHub issue flags; unset flags fall back to the
+// profile's github section and the environment
+type githubOptions struct {
+	Repo                string
+	APIURL              string
+	Label               string
+	Labels              []string
+	GroupBy             string
+	SummaryTemplate     string
+	DescriptionTemplate string
+}
+
+// closeComment is posted on issues closed because their findings are gone
+const closeComment = "Endor Labs no longer reports the findings tracked by this issue, so it is being closed. It is reopened if they come back."
+
+func newGitHubIssuesCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	gho := &githubOptions{}
+	var linksFile string
+	var dryRun, closeResolved bool
+
+	cmd := &cobra.Command{
+		Use:   "github",
+		Short: "Open a GitHub issue per finding or per vulnerable package, and close resolved ones",
+		Long: `Open a GitHub issue for each finding, or for each vulnerable package of a
+project with --group-by package, update the issues opened by earlier runs, and
+close them when their findings are no longer reported.
+
+Issues embed the UUIDs of the findings they track in hidden markers in their
+body, so reruns on other machines find them through the search API and do not
+open duplicates. Each issue is labeled with the managed label (default
+endor-labs) and a severity label such as severity:critical.
+
+Only open issues with the managed label are closed, and only when all of their
+findings belong to the selected projects, so keep the filter the same between
+runs: findings dropped by a narrower filter count as resolved.
+
+The token comes from the profile's github section, GITHUB_TOKEN or GH_TOKEN,
+and the repository defaults to GITHUB_REPOSITORY inside GitHub Actions.`,
+		Example: `  findings-api integrations github --repo github.com/acme/payments --github-repo acme/payments
+  findings-api integrations github --all-projects --github-repo acme/security --group-by package --labels security --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := g.requireNetwork("the GitHub integration"); err != nil {
+				return err
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			cfg, groupBy, templates, err := gho.resolve(g)
+			if err != nil {
+				return err
+			}
+			gc, err := ghissues.NewClient(cfg)
+			if err != nil {
+				return err
+			}
+			store, err := openLinks(linksFile)
+			if err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			client, token, err := g.authenticate(ctx)
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(ctx, client, token); err != nil {
+				return err
+			}
+
+			slog.Info("Fetching findings", "selection", opts.description())
+			result, err := g.fetch(ctx, opts, client, token, filter, api.NewProjectCache(client))
+			if err != nil {
+				return err
+			}
+			logFetchReport(client.FetchReport())
+			logWarnings(client.Warnings())
+
+			ts, err := tickets.Group(result.Findings, groupBy)
+			if err != nil {
+				return err
+			}
+
+			results := make([]syncResult, 0, len(ts))
+			failed := 0
+			for _, t := range ts {
+				res := syncGitHubTicket(ctx, gc, store, templates, t, dryRun)
+				if res.Error != "" {
+					failed++
+				}
+				results = append(results, res)
+			}
+
+			if closeResolved {
+				closed, err := closeResolvedIssues(ctx, gc, opts, result, dryRun)
+				if err != nil {
+					return err
+				}
+				for _, res := range closed {
+					if res.Error != "" {
+						failed++
+					}
+					results = append(results, res)
+				}
+			}
+
+			if err := printSyncResults(results); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d issues could not be synced", failed, len(results))
+			}
+			return opts.checkFailOn(result.Findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	cmd.Flags().StringVar(&gho.Repo, "github-repo", "", "Repository issues are opened in, owner/name (default: the profile's github.repo or $GITHUB_REPOSITORY)")
+	cmd.Flags().StringVar(&gho.APIURL, "github-api-url", "", "REST API root of a GitHub Enterprise Server, e.g. https://github.acme.com/api/v3")
+	cmd.Flags().StringVar(&gho.Label, "label", "", "Label marking the issues this integration manages (default: "+ghissues.DefaultLabel+")")
+	cmd.Flags().StringSliceVar(&gho.Labels, "labels", nil, "Extra labels set on the issues")
+	cmd.Flags().StringVar(&gho.GroupBy, "group-by", "", "Open an issue per finding or per package (default: finding)")
+	cmd.Flags().StringVar(&gho.SummaryTemplate, "summary-template", "", "Go template for issue titles")
+	cmd.Flags().StringVar(&gho.DescriptionTemplate, "description-template", "", "Go template for issue bodies")
+	cmd.Flags().StringVar(&linksFile, "links-file", "", "Link store file (default: $ENDOR_LINKS_FILE or ~/.endor/links.json)")
+	cmd.Flags().BoolVar(&closeResolved, "close-resolved", true, "Close managed issues whose findings are no longer reported")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show which issues would be opened, updated or closed without changing GitHub")
+	return cmd
+}
+
+// resolve merges the flags with the profile's github section and the environment
+func (gho *githubOptions) resolve(g *globalOptions) (ghissues.Config, string, *tickets.Templates, error) {
+	p := g.profile.GitHub
+	sc := scm.FromEnv(scm.GitHub)
+	sc.APIURL = firstNonEmpty(gho.APIURL, p.APIURL, sc.APIURL)
+	sc.Token = firstNonEmpty(os.ExpandEnv(p.Token), sc.Token)
+
+	cfg := ghissues.Config{
+		SCM:    sc,
+		Repo:   firstNonEmpty(gho.Repo, p.Repo, os.Getenv("GITHUB_REPOSITORY")),
+		Label:  firstNonEmpty(gho.Label, p.Label),
+		Labels: gho.Labels,
+	}
+	if cfg.Labels == nil {
+		cfg.Labels = p.Labels
+	}
+	if cfg.Repo == "" {
+		return ghissues.Config{}, "", nil, errors.New("a repository is required (--github-repo owner/name)")
+	}
+
+	groupBy := strings.ToLower(firstNonEmpty(gho.GroupBy, p.GroupBy, tickets.ByFinding))
+	if groupBy != tickets.ByFinding && groupBy != tickets.ByPackage {
+		return ghissues.Config{}, "", nil, fmt.Errorf("invalid --group-by %q (expected %s)", groupBy, strings.Join(tickets.Modes, " or "))
+	}
+
+	templates, err := tickets.ParseTemplates(
+		firstNonEmpty(gho.SummaryTemplate, p.SummaryTemplate),
+		firstNonEmpty(gho.DescriptionTemplate, p.DescriptionTemplate),
+	)
+	if err != nil {
+		return ghissues.Config{}, "", nil, err
+	}
+	return cfg, groupBy, templates, nil
+}
+
+// syncGitHubTicket opens or updates the issue of t and links its findings to it
+func syncGitHubTicket(ctx context.Context, gc *ghissues.Client, store links.Store, templates *tickets.Templates, t tickets.Ticket, dryRun bool) syncResult {
+	res := syncResult{Ticket: t.ID, Findings: len(t.Findings)}
+	fail := func(err error) syncResult {
+		res.Action = "failed"
+		res.Error = err.Error()
+		return res
+	}
+
+	title, body, err := templates.Render(t)
+	if err != nil {
+		return fail(err)
+	}
+	fields := ghissues.Fields{Title: title, Body: body, Severity: t.Findings[0].Spec.Level.Short()}
+	for _, f := range t.Findings {
+		fields.Findings = append(fields.Findings, ghissues.Marker{Finding: f.UUID, Project: f.Spec.ProjectUUID})
+	}
+
+	issue, found, err := findGitHubIssue(ctx, gc, store, t)
+	if err != nil {
+		return fail(err)
+	}
+
+	switch {
+	case dryRun && found && issue.State == "closed":
+		res.Action, res.Issue = "would reopen", issue.Ref(gc.Repo())
+		return res
+	case dryRun && found:
+		res.Action, res.Issue = "would update", issue.Ref(gc.Repo())
+		return res
+	case dryRun:
+		res.Action = "would open"
+		return res
+	case found:
+		res.Action = "updated"
+		if issue.State == "closed" {
+			res.Action = "reopened"
+		}
+		if issue, err = gc.Update(ctx, issue, fields); err != nil {
+			return fail(err)
+		}
+	default:
+		if issue, err = gc.Create(ctx, fields); err != nil {
+			return fail(err)
+		}
+		res.Action = "opened"
+	}
+	res.Issue = issue.Ref(gc.Repo())
+
+	for _, uuid := range t.UUIDs() {
+		if err := store.Set(uuid, links.Link{System: links.SystemGitHub, ID: res.Issue, URL: issue.URL}); err != nil {
+			return fail(fmt.Errorf("issue %s synced but not recorded: %w", res.Issue, err))
+		}
+	}
+	return res
+}
+
+// findGitHubIssue returns the issue already tracking any of t's findings: from
+// the link store first, then by searching issue bodies for the finding markers
+func findGitHubIssue(ctx context.Context, gc *ghissues.Client, store links.Store, t tickets.Ticket) (ghissues.Issue, bool, error) {
+	for _, uuid := range t.UUIDs() {
+		ls, err := store.Get(uuid)
+		if err != nil {
+			return ghissues.Issue{}, false, err
+		}
+		for _, l := range ls {
+			if l.System != links.SystemGitHub {
+				continue
+			}
+			// Links to issues in other repositories belong to other runs
+			repo, number, ok := strings.Cut(l.ID, "#")
+			if !ok || repo != gc.Repo() {
+				continue
+			}
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				continue
+			}
+			issue, err := gc.Get(ctx, n)
+			if err != nil {
+				return ghissues.Issue{}, false, err
+			}
+			return issue, true, nil
+		}
+	}
+	return gc.FindByUUID(ctx, t.Findings[0].UUID)
+}
+
+// closeResolvedIssues closes the open managed issues none of whose findings
+// were fetched. Issues tracking findings of projects outside the selection, or
+// of projects that could not be fetched, are left alone.
+func closeResolvedIssues(ctx context.Context, gc *ghissues.Client, opts *findingsOptions, result fetchResult, dryRun bool) ([]syncResult, error) {
+	issues, err := gc.ListOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool, len(result.Findings))
+	for _, f := range result.Findings {
+		current[f.UUID] = true
+	}
+	selected := make(map[string]bool, len(opts.ProjectUUIDs))
+	for _, uuid := range opts.ProjectUUIDs {
+		selected[uuid] = true
+	}
+	inScope := func(project string) bool {
+		if _, failed := result.ProjectErrors[project]; failed {
+			return false
+		}
+		return opts.AllProjects || selected[project]
+	}
+
+	var results []syncResult
+	for _, issue := range issues {
+		markers := ghissues.ParseMarkers(issue.Body)
+		if len(markers) == 0 {
+			continue
+		}
+		resolved := true
+		for _, m := range markers {
+			if current[m.Finding] || !inScope(m.Project) {
+				resolved = false
+				break
+			}
+		}
+		if !resolved {
+			continue
+		}
+
+		res := syncResult{Ticket: markers[0].Finding, Findings: len(markers), Issue: issue.Ref(gc.Repo())}
+		if dryRun {
+			res.Action = "would close"
+		} else if err := gc.Close(ctx, issue.Number, closeComment); err != nil {
+			res.Action, res.Error = "failed", err.Error()
+		} else {
+			res.Action = "closed"
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
@@ -0,0 +1,413 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/diff"
+	"github.com/endor-labs/findings-api/internal/manifest"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// historyDBUsage documents the --history-db flag
+const historyDBUsage = "Findings history database (default: $ENDOR_HISTORY_DB or ~/.endor/history.db)"
+
+// openHistory opens the history database at path, $ENDOR_HISTORY_DB or ~/.endor/history.db
+func openHistory(path string) (*store.Store, error) {
+	path = firstNonEmpty(path, os.Getenv("ENDOR_HISTORY_DB"))
+	if path == "" {
+		var err error
+		if path, err = store.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return store.Open(path)
+}
+
+// recordHistory saves the findings of run in the history database. A run that
+// cannot be recorded is a warning; it never fails an export that succeeded.
+func recordHistory(ctx context.Context, path string, run *manifest.Manifest, findings []api.Finding) {
+	s, err := openHistory(path)
+	if err != nil {
+		slog.Warn("Failed to record findings history", "error", err)
+		return
+	}
+	defer s.Close()
+
+	id, err := s.SaveRun(ctx, store.Run{
+		StartedAt: run.StartedAt,
+		Namespace: run.Namespace,
+		Selection: run.Selection,
+		Filter:    run.Filter,
+	}, findings)
+	if err != nil {
+		slog.Warn("Failed to record findings history", "error", err)
+		return
+	}
+	slog.Info("Recorded findings history", "run_id", id, "findings", len(findings))
+}
+
+// resolveRun returns the run ID given as an argument: a number, "latest", or
+// "latest~N" for the Nth run before the latest
+func resolveRun(ctx context.Context, s *store.Store, arg string) (int64, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return id, nil
+	}
+	rest, ok := strings.CutPrefix(arg, "latest")
+	if !ok {
+		return 0, fmt.Errorf("invalid run %q (expected a run ID, latest or latest~N)", arg)
+	}
+	offset := 0
+	if rest != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(rest, "~"))
+		if err != nil || !strings.HasPrefix(rest, "~") || n < 0 {
+			return 0, fmt.Errorf("invalid run %q (expected a run ID, latest or latest~N)", arg)
+		}
+		offset = n
+	}
+	return s.LatestRunID(ctx, offset)
+}
+
+func newHistoryCmd() *cobra.Command {
+	var dbPath string
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Query the findings recorded by past runs",
+		Long: `Query the local findings history. Runs are recorded by findings export
+--record into a SQLite database, so past runs can be listed, shown, compared
+and summarised without calling the API.
+
+Runs are given by ID, as latest, or as latest~N for the Nth run before the
+latest.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&dbPath, "history-db", "", historyDBUsage)
+	cmd.AddCommand(
+		newHistoryRunsCmd(&dbPath),
+		newHistoryShowCmd(&dbPath),
+		newHistoryDiffCmd(&dbPath),
+		newHistoryTrendCmd(&dbPath),
+		newHistoryFindingCmd(&dbPath),
+		newHistoryPruneCmd(&dbPath),
+	)
+	return cmd
+}
+
+func newHistoryRunsCmd(dbPath *string) *cobra.Command {
+	var format string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "runs",
+		Short: "List recorded runs, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			s, err := openHistory(*dbPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			runs, err := s.Runs(cmd.Context(), limit)
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				if runs == nil {
+					runs = []store.Run{}
+				}
+				return printJSON(runs)
+			}
+			if len(runs) == 0 {
+				fmt.Println("No runs recorded; record one with findings export --record")
+				return nil
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "RUN\tSTARTED\tNAMESPACE\tTOTAL\tCRITICAL\tHIGH\tMEDIUM\tLOW\tSELECTION")
+			for _, r := range runs {
+				fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n", r.ID, r.StartedAt.Local().Format(time.DateTime), r.Namespace,
+					r.Total, r.ByLevel["critical"], r.ByLevel["high"], r.ByLevel["medium"], r.ByLevel["low"], r.Selection)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Number of runs to list (0 lists them all)")
+	return cmd
+}
+
+func newHistoryShowCmd(dbPath *string) *cobra.Command {
+	var format, columnSpec string
+	var maxWidth int
+
+	cmd := &cobra.Command{
+		Use:   "show [run]",
+		Short: "Print the findings of a recorded run (default: latest)",
+		Long: `Print the findings of a recorded run. --format json prints a findings
+document that findings diff and other commands reading snapshots accept.`,
+		Example: `  findings-api history show
+  findings-api history show 12 --columns level,cve,package
+  findings-api history show latest~1 --format json > last-week.json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			columns, err := output.ParseColumns(columnSpec)
+			if err != nil {
+				return fmt.Errorf("invalid columns: %w", err)
+			}
+			s, err := openHistory(*dbPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			arg := "latest"
+			if len(args) == 1 {
+				arg = args[0]
+			}
+			id, err := resolveRun(cmd.Context(), s, arg)
+			if err != nil {
+				return err
+			}
+			run, err := s.Run(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			findings, err := s.Findings(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				doc := output.NewDocument(findings, run.Selection, api.FetchReport{}, nil, nil)
+				doc.Timestamp = run.StartedAt.Format(time.RFC3339)
+				return printJSON(doc)
+			}
+			fmt.Printf("Run %d, %s: %d findings for %s\n\n", run.ID, run.StartedAt.Local().Format(time.DateTime), run.Total, run.Selection)
+			return output.WriteTable(os.Stdout, findings, columns, maxWidth)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	cmd.Flags().StringVar(&columnSpec, "columns", "level,cve,name,package,project_uuid", "Comma-separated columns of the table (see findings export --columns)")
+	cmd.Flags().IntVar(&maxWidth, "max-width", 40, "Truncate table values longer than this many characters (0 disables truncation)")
+	return cmd
+}
+
+func newHistoryDiffCmd(dbPath *string) *cobra.Command {
+	opts := &findingsOptions{}
+	var format, key, columnSpec string
+	var maxWidth int
+	var showUnchanged bool
+
+	cmd := &cobra.Command{
+		Use:   "diff [old-run] [new-run]",
+		Short: "Report new, resolved and unchanged findings between two recorded runs",
+		Long: `Compare two recorded runs like findings diff compares two snapshots. Without
+arguments the latest run is compared with the one before it; with one
+argument, that run is compared with the latest.`,
+		Example: `  findings-api history diff
+  findings-api history diff 12
+  findings-api history diff latest~7 latest --key fingerprint --fail-on high`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			if _, err := diff.KeyFunc(key); err != nil {
+				return err
+			}
+			columns, err := output.ParseColumns(columnSpec)
+			if err != nil {
+				return fmt.Errorf("invalid columns: %w", err)
+			}
+			s, err := openHistory(*dbPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			runs := []string{"latest~1", "latest"}
+			copy(runs, args)
+			ids := make([]int64, 2)
+			findings := make([][]api.Finding, 2)
+			for i, arg := range runs {
+				if ids[i], err = resolveRun(cmd.Context(), s, arg); err != nil {
+					return err
+				}
+				if findings[i], err = s.Findings(cmd.Context(), ids[i]); err != nil {
+					return err
+				}
+			}
+
+			result, err := diff.Compare(findings[0], findings[1], key)
+			if err != nil {
+				return err
+			}
+			slog.Info("Compared history runs", "before", ids[0], "before_findings", len(findings[0]), "after", ids[1], "after_findings", len(findings[1]), "changes", result.Summary())
+
+			if format == "json" {
+				if err := printJSON(result); err != nil {
+					return err
+				}
+			} else if err := writeDiffTable(os.Stdout, result, columns, maxWidth, showUnchanged); err != nil {
+				return err
+			}
+			return opts.checkFailOn(result.New)
+		},
+	}
+
+	opts.addFailOnFlag(cmd.Flags())
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	cmd.Flags().StringVar(&key, "key", diff.ByUUID, "Match findings by uuid or fingerprint (project, vulnerability and package)")
+	cmd.Flags().StringVar(&columnSpec, "columns", diffColumns, "Comma-separated columns of the table (see findings export --columns)")
+	cmd.Flags().IntVar(&maxWidth, "max-width", 40, "Truncate table values longer than this many characters (0 disables truncation)")
+	cmd.Flags().BoolVar(&showUnchanged, "show-unchanged", false, "List unchanged findings too, not just their count")
+	return cmd
+}
+
+func newHistoryTrendCmd(dbPath *string) *cobra.Command {
+	var format string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "trend",
+		Short: "Show finding counts by level across recorded runs, oldest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			s, err := openHistory(*dbPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			runs, err := s.Runs(cmd.Context(), limit)
+			if err != nil {
+				return err
+			}
+			// Runs are newest first; a trend reads oldest first
+			for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+				runs[i], runs[j] = runs[j], runs[i]
+			}
+
+			if format == "json" {
+				if runs == nil {
+					runs = []store.Run{}
+				}
+				return printJSON(runs)
+			}
+			if len(runs) == 0 {
+				fmt.Println("No runs recorded; record one with findings export --record")
+				return nil
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "RUN\tSTARTED\tTOTAL\tCHANGE\tCRITICAL\tHIGH\tMEDIUM\tLOW")
+			for i, r := range runs {
+				change := ""
+				if i > 0 {
+					change = fmt.Sprintf("%+d", r.Total-runs[i-1].Total)
+				}
+				fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%d\t%d\t%d\t%d\n", r.ID, r.StartedAt.Local().Format(time.DateTime),
+					r.Total, change, r.ByLevel["critical"], r.ByLevel["high"], r.ByLevel["medium"], r.ByLevel["low"])
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Number of most recent runs to include (0 includes them all)")
+	return cmd
+}
+
+func newHistoryFindingCmd(dbPath *string) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "finding <finding-uuid>",
+		Short: "List the recorded runs a finding appeared in",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			s, err := openHistory(*dbPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			sightings, err := s.Sightings(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				if sightings == nil {
+					sightings = []store.Sighting{}
+				}
+				return printJSON(sightings)
+			}
+			if len(sightings) == 0 {
+				return fmt.Errorf("finding %s is not in any recorded run", args[0])
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "RUN\tSTARTED\tLEVEL\tVERSION")
+			for _, sg := range sightings {
+				fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", sg.RunID, sg.StartedAt.Local().Format(time.DateTime), sg.Level.Short(), sg.Version)
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
+
+func newHistoryPruneCmd(dbPath *string) *cobra.Command {
+	var keep int
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete all but the most recent recorded runs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keep < 1 {
+				return errors.New("--keep must be at least 1")
+			}
+			s, err := openHistory(*dbPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			n, err := s.Prune(cmd.Context(), keep)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %d runs, kept the %d most recent\n", n, keep)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&keep, "keep", 50, "Number of most recent runs to keep")
+	return cmd
+}
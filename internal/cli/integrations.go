@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newIntegrationsCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "integrations",
+		Short: "Sync findings to issue trackers, vulnerability management tools and SIEMs",
+	}
+
+	cmd.AddCommand(newJiraCmd(g))
+	cmd.AddCommand(newGitHubIssuesCmd(g))
+	cmd.AddCommand(newServiceNowCmd(g))
+	cmd.AddCommand(newDefectDojoCmd(g))
+	cmd.AddCommand(newSIEMCmd(g))
+	return cmd
+}
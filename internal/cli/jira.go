@@ -0,0 +1,254 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/jira"
+	"github.com/endor-labs/findings-api/internal/links"
+	"github.com/endor-labs/findings-api/internal/tickets"
+	"github.com/spf13/cobra"
+)
+
+// jiraOptions are the Jira flags; unset flags fall back to the profile's jira section
+type jiraOptions struct {
+	URL                 string
+	Project             string
+	IssueType           string
+	Labels              []string
+	GroupBy             string
+	UUIDField           string
+	SummaryTemplate     string
+	DescriptionTemplate string
+}
+
+// syncResult is what happened to one ticket
+type syncResult struct {
+	Ticket   string
+	Action   string
+	Issue    string
+	Findings int
+	Error    string
+}
+
+func newJiraCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	jo := &jiraOptions{}
+	var linksFile string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "jira",
+		Short: "Create or update a Jira issue per finding or per vulnerable package",
+		Long: `Create a Jira issue for each finding, or for each vulnerable package of a
+project with --group-by package, and update the issues created by earlier runs.
+
+Issues are matched to findings through the link store and, when --uuid-field
+names a custom text field, through the finding UUIDs stored in that field, so
+reruns on other machines do not open duplicates either.
+
+Credentials come from the profile's jira section or JIRA_EMAIL and
+JIRA_API_TOKEN; without an email the token is sent as a Data Center personal
+access token.`,
+		Example: `  findings-api integrations jira --all-projects --jira-url https://acme.atlassian.net --jira-project SEC
+  findings-api integrations jira --repo github.com/acme/payments --group-by package --labels endor,security --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := g.requireNetwork("the Jira integration"); err != nil {
+				return err
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			cfg, groupBy, templates, err := jo.resolve(g)
+			if err != nil {
+				return err
+			}
+			jc, err := jira.NewClient(cfg)
+			if err != nil {
+				return err
+			}
+			store, err := openLinks(linksFile)
+			if err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			client, token, err := g.authenticate(ctx)
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(ctx, client, token); err != nil {
+				return err
+			}
+
+			slog.Info("Fetching findings", "selection", opts.description())
+			result, err := g.fetch(ctx, opts, client, token, filter, api.NewProjectCache(client))
+			if err != nil {
+				return err
+			}
+			logFetchReport(client.FetchReport())
+			logWarnings(client.Warnings())
+
+			ts, err := tickets.Group(result.Findings, groupBy)
+			if err != nil {
+				return err
+			}
+
+			results := make([]syncResult, 0, len(ts))
+			failed := 0
+			for _, t := range ts {
+				res := syncJiraTicket(ctx, jc, store, templates, t, dryRun)
+				if res.Error != "" {
+					failed++
+				}
+				results = append(results, res)
+			}
+
+			if err := printSyncResults(results); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d issues could not be synced", failed, len(results))
+			}
+			return opts.checkFailOn(result.Findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	cmd.Flags().StringVar(&jo.URL, "jira-url", "", "Jira base URL, e.g. https://acme.atlassian.net (default: the profile's jira.url or $JIRA_URL)")
+	cmd.Flags().StringVar(&jo.Project, "jira-project", "", "Key of the Jira project issues are created in")
+	cmd.Flags().StringVar(&jo.IssueType, "issue-type", "", "Issue type of created issues (default: "+jira.DefaultIssueType+")")
+	cmd.Flags().StringSliceVar(&jo.Labels, "labels", nil, "Labels set on the issues")
+	cmd.Flags().StringVar(&jo.GroupBy, "group-by", "", "Open an issue per finding or per package (default: finding)")
+	cmd.Flags().StringVar(&jo.UUIDField, "uuid-field", "", "Custom text field storing the tracked finding UUIDs, e.g. customfield_10050")
+	cmd.Flags().StringVar(&jo.SummaryTemplate, "summary-template", "", "Go template for issue summaries")
+	cmd.Flags().StringVar(&jo.DescriptionTemplate, "description-template", "", "Go template for issue descriptions")
+	cmd.Flags().StringVar(&linksFile, "links-file", "", "Link store file (default: $ENDOR_LINKS_FILE or ~/.endor/links.json)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show which issues would be created or updated without changing Jira")
+	return cmd
+}
+
+// resolve merges the flags with the profile's jira section and the environment
+func (jo *jiraOptions) resolve(g *globalOptions) (jira.Config, string, *tickets.Templates, error) {
+	p := g.profile.Jira
+	cfg := jira.Config{
+		URL:       firstNonEmpty(jo.URL, os.ExpandEnv(p.URL), os.Getenv("JIRA_URL")),
+		Email:     firstNonEmpty(os.ExpandEnv(p.Email), os.Getenv("JIRA_EMAIL")),
+		Token:     firstNonEmpty(os.ExpandEnv(p.Token), os.Getenv("JIRA_API_TOKEN")),
+		Project:   firstNonEmpty(jo.Project, p.Project),
+		IssueType: firstNonEmpty(jo.IssueType, p.IssueType),
+		Labels:    jo.Labels,
+		UUIDField: firstNonEmpty(jo.UUIDField, p.UUIDField),
+	}
+	if cfg.Labels == nil {
+		cfg.Labels = p.Labels
+	}
+
+	groupBy := strings.ToLower(firstNonEmpty(jo.GroupBy, p.GroupBy, tickets.ByFinding))
+	if groupBy != tickets.ByFinding && groupBy != tickets.ByPackage {
+		return jira.Config{}, "", nil, fmt.Errorf("invalid --group-by %q (expected %s)", groupBy, strings.Join(tickets.Modes, " or "))
+	}
+
+	templates, err := tickets.ParseTemplates(
+		firstNonEmpty(jo.SummaryTemplate, p.SummaryTemplate),
+		firstNonEmpty(jo.DescriptionTemplate, p.DescriptionTemplate),
+	)
+	if err != nil {
+		return jira.Config{}, "", nil, err
+	}
+	return cfg, groupBy, templates, nil
+}
+
+// syncJiraTicket creates or updates the issue of t and links its findings to it
+func syncJiraTicket(ctx context.Context, jc *jira.Client, store links.Store, templates *tickets.Templates, t tickets.Ticket, dryRun bool) syncResult {
+	res := syncResult{Ticket: t.ID, Findings: len(t.Findings)}
+	fail := func(err error) syncResult {
+		res.Action = "failed"
+		res.Error = err.Error()
+		return res
+	}
+
+	summary, description, err := templates.Render(t)
+	if err != nil {
+		return fail(err)
+	}
+	fields := jira.Fields{Summary: summary, Description: description, UUIDs: t.UUIDs()}
+
+	issue, found, err := findJiraIssue(ctx, jc, store, t)
+	if err != nil {
+		return fail(err)
+	}
+
+	switch {
+	case dryRun && found:
+		res.Action, res.Issue = "would update", issue.Key
+		return res
+	case dryRun:
+		res.Action = "would create"
+		return res
+	case found:
+		if err := jc.Update(ctx, issue.Key, fields); err != nil {
+			return fail(err)
+		}
+		res.Action = "updated"
+	default:
+		if issue, err = jc.Create(ctx, fields); err != nil {
+			return fail(err)
+		}
+		res.Action = "created"
+	}
+	res.Issue = issue.Key
+
+	for _, uuid := range t.UUIDs() {
+		if err := store.Set(uuid, links.Link{System: links.SystemJira, ID: issue.Key, URL: issue.URL}); err != nil {
+			return fail(fmt.Errorf("issue %s synced but not recorded: %w", issue.Key, err))
+		}
+	}
+	return res
+}
+
+// findJiraIssue returns the issue already tracking any of t's findings: from the
+// link store first, then by searching the UUID field
+func findJiraIssue(ctx context.Context, jc *jira.Client, store links.Store, t tickets.Ticket) (jira.Issue, bool, error) {
+	for _, uuid := range t.UUIDs() {
+		ls, err := store.Get(uuid)
+		if err != nil {
+			return jira.Issue{}, false, err
+		}
+		for _, l := range ls {
+			if l.System == links.SystemJira {
+				return jira.Issue{Key: l.ID, URL: l.URL}, true, nil
+			}
+		}
+	}
+	return jc.FindByUUID(ctx, t.Findings[0].UUID)
+}
+
+// printSyncResults prints what happened to each ticket
+func printSyncResults(results []syncResult) error {
+	if len(results) == 0 {
+		fmt.Println("No findings to sync")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TICKET\tFINDINGS\tACTION\tISSUE")
+	for _, r := range results {
+		action := r.Action
+		if r.Error != "" {
+			action += ": " + r.Error
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", r.Ticket, r.Findings, action, r.Issue)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/links"
+	"github.com/spf13/cobra"
+)
+
+// openLinks opens the link store at path, $ENDOR_LINKS_FILE or ~/.endor/links.json
+func openLinks(path string) (links.Store, error) {
+	path = firstNonEmpty(path, os.Getenv("ENDOR_LINKS_FILE"))
+	if path == "" {
+		var err error
+		if path, err = links.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return links.OpenFile(path)
+}
+
+func newFindingsLinksCmd() *cobra.Command {
+	var linksFile, system, id, format string
+
+	cmd := &cobra.Command{
+		Use:   "links [finding-uuid]",
+		Short: "Show the tickets and issues linked to a finding",
+		Example: `  findings-api findings links 64f1c2e3a9b8d7e6f5a4b3c2
+  findings-api findings links --system jira --id SEC-123`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+			if (len(args) == 0) == (id == "") {
+				return errors.New("pass either a finding UUID or --system and --id")
+			}
+			if id != "" && system == "" {
+				return errors.New("--id requires --system")
+			}
+
+			store, err := openLinks(linksFile)
+			if err != nil {
+				return err
+			}
+
+			findingUUID := ""
+			if len(args) == 1 {
+				findingUUID = args[0]
+			} else {
+				var ok bool
+				findingUUID, ok, err = store.Find(system, id)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("no finding is linked to %s %s", system, id)
+				}
+			}
+
+			found, err := store.Get(findingUUID)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				return printJSON(struct {
+					Finding string       `json:"finding"`
+					Links   []links.Link `json:"links"`
+				}{findingUUID, found})
+			}
+
+			fmt.Printf("Finding %s\n\n", findingUUID)
+			if len(found) == 0 {
+				fmt.Println("No linked tickets or issues")
+				return nil
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "SYSTEM\tID\tURL\tUPDATED")
+			for _, l := range found {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", l.System, l.ID, l.URL, l.UpdatedAt.Local().Format(time.DateTime))
+			}
+			return tw.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&linksFile, "links-file", "", "Link store file (default: $ENDOR_LINKS_FILE or ~/.endor/links.json)")
+	cmd.Flags().StringVar(&system, "system", "", "Look up the finding by its ticket in this system (jira, github, servicenow)")
+	cmd.Flags().StringVar(&id, "id", "", "Ticket or issue ID to look up, with --system")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logLevels are the values of --log-level
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// newLogger returns a logger writing records of level and above to w as
+// logfmt-style text or JSON lines
+func newLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, ok := logLevels[strings.ToLower(level)]
+	if !ok {
+		return nil, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q (expected text or json)", format)
+}
+
+// setupLogging makes the --log-level and --log-format logger, or their
+// $ENDOR_LOG_LEVEL and $ENDOR_LOG_FORMAT defaults, the default logger of the
+// run, writing to stderr above the progress line. --debug lowers the level to
+// debug so API calls are logged.
+func (g *globalOptions) setupLogging() error {
+	level := firstNonEmpty(g.LogLevel, os.Getenv("ENDOR_LOG_LEVEL"), "info")
+	if g.Debug {
+		level = "debug"
+	}
+	logger, err := newLogger(g.progress.writer(os.Stderr), level, firstNonEmpty(g.LogFormat, os.Getenv("ENDOR_LOG_FORMAT"), "text"))
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
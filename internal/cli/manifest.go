@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+	"github.com/endor-labs/findings-api/internal/manifest"
+	"github.com/endor-labs/findings-api/internal/release"
+	"github.com/endor-labs/findings-api/internal/upload"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// noManifest is the --manifest value that disables the run manifest
+const noManifest = "none"
+
+// startRun begins the manifest of a command that produces artifacts, recording
+// the build, the namespace, the arguments and the flags set on the command line
+func (g *globalOptions) startRun(cmd *cobra.Command, args []string) *manifest.Manifest {
+	m := manifest.New(cmd.CommandPath())
+	m.Tool = release.BinaryName
+	m.Version = buildinfo.Version
+	m.Commit = buildinfo.Commit
+	m.BuildDate = buildinfo.Date
+	m.Args = args
+	m.Namespace = g.namespace()
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		m.Flags[f.Name] = f.Value.String()
+	})
+	return m
+}
+
+// finishRun records how the run ended, uploads its artifacts and manifest
+// when --upload is set, and writes the manifest. Artifacts are uploaded even
+// when the run failed, e.g. a --fail-on gate. It returns err, or the upload
+// error of a run that otherwise succeeded; a manifest that cannot be written
+// is only a warning.
+func (g *globalOptions) finishRun(ctx context.Context, m *manifest.Manifest, err error) error {
+	uploader, uploadErr := g.newUploader()
+	if uploader != nil {
+		uploadErr = uploadOutputs(ctx, uploader, m)
+	}
+	if uploadErr != nil {
+		if err == nil {
+			err = uploadErr
+		} else {
+			m.AddError(uploadErr)
+		}
+	}
+	m.Finish(err)
+
+	path := g.manifestPath()
+	if path == "" {
+		return err
+	}
+	if werr := m.Write(path); werr != nil {
+		slog.Warn("Failed to write the run manifest", "error", werr)
+		return err
+	}
+	slog.Info("Run manifest saved", "path", path)
+
+	if uploader != nil && uploadErr == nil {
+		url, uerr := uploader.Upload(ctx, path, upload.ObjectName(path))
+		switch {
+		case uerr != nil && err == nil:
+			return uerr
+		case uerr != nil:
+			slog.Warn("Failed to upload the run manifest", "error", uerr)
+		default:
+			slog.Info("Uploaded", "path", path, "url", url)
+		}
+	}
+	return err
+}
+
+// manifestPath returns where the run manifest goes: --manifest, or run.json in
+// the directory of --output (the current directory when unset), inside
+// --out-dir when given. Runs writing to stdout have no manifest unless
+// --manifest is given.
+func (g *globalOptions) manifestPath() string {
+	switch {
+	case strings.EqualFold(g.Manifest, noManifest):
+		return ""
+	case g.Manifest != "":
+		return g.Manifest
+	case g.Output == stdoutPath:
+		return ""
+	case g.Output != "":
+		return g.inOutDir(filepath.Join(filepath.Dir(g.Output), manifest.FileName))
+	default:
+		return g.inOutDir(manifest.FileName)
+	}
+}
+
+// addRunOutput records an artifact in the manifest, warning if it cannot be checksummed
+func addRunOutput(m *manifest.Manifest, path, format string) {
+	if err := m.AddOutput(path, format); err != nil {
+		slog.Warn("Failed to checksum an output", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/risk"
+	"github.com/spf13/pflag"
+)
+
+// fetchSelected authenticates and fetches the selected findings from the
+// command's namespace, or from each of --namespaces
+func (g *globalOptions) fetchSelected(ctx context.Context, opts *findingsOptions, filter string) (fetchedFindings, error) {
+	if len(g.Namespaces) > 0 {
+		return g.fetchNamespaces(ctx, opts, filter)
+	}
+
+	client, token, err := g.authenticate(ctx)
+	if err != nil {
+		return fetchedFindings{}, err
+	}
+	if err := opts.resolveProjects(ctx, client, token); err != nil {
+		return fetchedFindings{}, err
+	}
+	slog.Info("Fetching findings", "selection", opts.description())
+	result, err := g.fetch(ctx, opts, client, token, filter, api.NewProjectCache(client))
+	if err != nil {
+		return fetchedFindings{}, err
+	}
+	return fetchedFindings{fetchResult: result, Report: client.FetchReport(), Warnings: client.Warnings()}, nil
+}
+
+// validateNamespaces checks that --namespaces can be used with the selection
+func (g *globalOptions) validateNamespaces(opts *findingsOptions) error {
+	if len(g.Namespaces) == 0 {
+		return nil
+	}
+	if g.Namespace != "" {
+		return errors.New("--namespace and --namespaces cannot be combined")
+	}
+	// Projects belong to one namespace, so only all projects span several
+	if !opts.AllProjects {
+		return errors.New("--namespaces requires --all-projects")
+	}
+	return nil
+}
+
+// addNamespacesFlag registers --namespaces on fs
+func (g *globalOptions) addNamespacesFlag(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&g.Namespaces, "namespaces", nil, "Fetch from each of these namespaces instead of one, recording the namespace on every finding (requires --all-projects)")
+}
+
+// fetchedFindings is the outcome of fetching findings from one namespace or several
+type fetchedFindings struct {
+	fetchResult
+	Report   api.FetchReport
+	Warnings []api.Warning
+}
+
+// fetchNamespaces fetches the selected findings from each of --namespaces with
+// a client of its own. Namespaces that cannot be read are reported and do not
+// stop the others; only a failure of every namespace fails the fetch. Every
+// finding records the namespace it belongs to.
+func (g *globalOptions) fetchNamespaces(ctx context.Context, opts *findingsOptions, filter string) (fetchedFindings, error) {
+	var fetched fetchedFindings
+	access := api.NewAccessReport()
+	for _, ns := range g.Namespaces {
+		client, err := g.newNamespaceClient(ns)
+		if err != nil {
+			return fetched, err
+		}
+		var result fetchResult
+		token, err := g.token(ctx, client)
+		if err == nil {
+			slog.Info("Fetching findings", "selection", opts.description(), "namespace", ns)
+			result, err = g.fetch(ctx, opts, client, token, filter, api.NewProjectCache(client))
+		}
+		fetched.Report.Add(client.FetchReport())
+		fetched.Warnings = append(fetched.Warnings, client.Warnings()...)
+		access.Record(ns, err)
+		if err != nil {
+			fetched.Warnings = append(fetched.Warnings, api.Warning{
+				Code:     api.WarningNamespaceFailed,
+				Message:  err.Error(),
+				Resource: "namespaces",
+				UUID:     ns,
+			})
+			continue
+		}
+		slog.Info("Fetched namespace", "namespace", ns, "findings", len(result.Findings))
+		fetched.Findings = append(fetched.Findings, result.Findings...)
+		fetched.Correlations = append(fetched.Correlations, result.Correlations...)
+	}
+	logAccessReport(access)
+	if len(access.Failed()) == len(g.Namespaces) {
+		return fetched, fmt.Errorf("failed to fetch findings: none of the %d namespaces could be read", len(g.Namespaces))
+	}
+
+	// Each namespace is sorted and limited on its own
+	fetched.Findings = g.List.ApplyTo(fetched.Findings)
+	if opts.Dedupe {
+		// Issues repeated across the namespaces, once each was deduplicated
+		fetched.Findings = opts.dedupe(fetched.Findings)
+	}
+	if opts.SortRisk {
+		risk.Sort(fetched.Findings)
+	}
+	return fetched, nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+func newFindingsNotifyCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Send findings to the notification sinks chosen by the profile's routing rules",
+		Example: `  findings-api findings notify --all-projects --level critical,high,medium
+  findings-api findings notify --repo github.com/acme/payments --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			router, err := notify.FromConfig(g.profile.Notifications)
+			if err != nil {
+				return fmt.Errorf("invalid notifications config: %w", err)
+			}
+			if len(router.Routes) == 0 {
+				return errors.New("no notification routes configured in the profile")
+			}
+			if remote := router.RemoteSinks(); len(remote) > 0 && !dryRun {
+				if err := g.requireNetwork("sending to sinks " + strings.Join(remote, ", ")); err != nil {
+					return err
+				}
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(cmd.Context(), client, token); err != nil {
+				return err
+			}
+
+			slog.Info("Fetching findings", "selection", opts.description())
+			result, err := g.fetch(cmd.Context(), opts, client, token, filter, api.NewProjectCache(client))
+			if err != nil {
+				return err
+			}
+			logFetchReport(client.FetchReport())
+			logWarnings(client.Warnings())
+
+			if dryRun {
+				plan := router.Plan(result.Findings)
+				results := make([]notify.Result, 0, len(plan))
+				for sink, findings := range plan {
+					results = append(results, notify.Result{Sink: sink, Findings: len(findings)})
+				}
+				sort.Slice(results, func(i, j int) bool { return results[i].Sink < results[j].Sink })
+				fmt.Printf("Dry run: %d findings would be routed as follows\n\n", len(result.Findings))
+				return printNotifyResults(results)
+			}
+
+			results := router.Deliver(cmd.Context(), result.Findings)
+			if err := printNotifyResults(results); err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, r := range results {
+				if r.Error != "" {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d sinks failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show how many findings each sink would receive without sending anything")
+	return cmd
+}
+
+// printNotifyResults prints the findings sent to each sink and any failure
+func printNotifyResults(results []notify.Result) error {
+	if len(results) == 0 {
+		fmt.Println("No findings matched a route")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SINK\tFINDINGS\tSTATUS")
+	for _, r := range results {
+		status := "ok"
+		if r.Error != "" {
+			status = "failed: " + r.Error
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", r.Sink, r.Findings, status)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newPackagesCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packages",
+		Short: "Inspect the package inventory of projects",
+	}
+
+	cmd.AddCommand(newPackagesListCmd(g))
+	return cmd
+}
+
+func newPackagesListCmd(g *globalOptions) *cobra.Command {
+	var projectUUID, repo, format string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the package versions of a project with their resolved dependencies",
+		Example: `  findings-api packages list --repo github.com/acme/payments
+  findings-api packages list --project_uuid abc123-def456-ghi789 --format json | jq -r '.[].spec.resolved_dependencies.dependencies[].name'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectUUID == "" && repo == "" {
+				return errors.New("either --project_uuid or --repo is required")
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+
+			ctx := cmd.Context()
+			client, token, err := g.authenticate(ctx)
+			if err != nil {
+				return err
+			}
+			if projectUUID == "" {
+				p, err := client.FindProjectByRepo(ctx, token, repo)
+				if err != nil {
+					return fmt.Errorf("failed to resolve --repo: %w", err)
+				}
+				projectUUID = p.UUID
+			}
+
+			slog.Info("Fetching package versions", "project_uuid", projectUUID)
+			versions, err := client.ListPackageVersions(ctx, token, projectUUID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch package versions: %w", err)
+			}
+			if format == "json" {
+				return printJSON(versions)
+			}
+			return printPackageVersions(os.Stdout, versions)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectUUID, "project_uuid", "", "UUID of the project")
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository URL of the project, e.g. github.com/org/repo")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
+
+// printPackageVersions prints package versions as a table sorted by name
+func printPackageVersions(w io.Writer, versions []api.PackageVersion) error {
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Meta.Name < versions[j].Meta.Name })
+
+	deps := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tVERSION\tECOSYSTEM\tMANIFEST\tDEPENDENCIES")
+	for _, v := range versions {
+		n := len(v.Spec.ResolvedDependencies.Dependencies)
+		deps += n
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", v.PackageName(), v.Version(), strings.TrimPrefix(v.Spec.Ecosystem, "ECOSYSTEM_"), v.Spec.RelativePath, n)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d package versions, %d resolved dependencies\n", len(versions), deps)
+	return nil
+}
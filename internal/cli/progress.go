@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// progressInterval is how often the progress line is redrawn at most
+const progressInterval = 100 * time.Millisecond
+
+// progressBar draws the progress of a findings fetch on the last line of a
+// terminal: the pages and findings fetched so far and, once the findings are
+// counted, the share fetched and an estimate of the time left. Logs and
+// console output written through it clear the line first and redraw it after,
+// so they do not run into it. A nil progressBar draws nothing.
+type progressBar struct {
+	mu sync.Mutex
+	w  io.Writer
+	// active is set between begin and finish; pages fetched outside a fetch,
+	// e.g. by a poll, are not drawn
+	active   bool
+	start    time.Time
+	pages    int
+	findings int
+	// total is the number of findings the fetch is expected to list, 0 when unknown
+	total int
+	// drawn is the line on the terminal, and drawnAt when it was drawn
+	drawn   string
+	drawnAt time.Time
+}
+
+func newProgressBar(w io.Writer) *progressBar {
+	return &progressBar{w: w}
+}
+
+// begin starts drawing a fetch of total findings, 0 when unknown
+func (p *progressBar) begin(total int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active, p.start, p.pages, p.findings, p.total = true, time.Now(), 0, 0, total
+}
+
+// page records a page of findings; it is the client's api.ProgressFunc
+func (p *progressBar) page(findings int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.active {
+		return
+	}
+	p.pages++
+	p.findings += findings
+	if now := time.Now(); p.drawn == "" || now.Sub(p.drawnAt) >= progressInterval {
+		p.draw(p.line(now))
+	}
+}
+
+// finish clears the line and stops drawing until the next begin
+func (p *progressBar) finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active = false
+	p.clear()
+}
+
+// line describes the progress at now, e.g. "Fetching findings: 12 pages,
+// 1200 of 5000 findings (24%), about 1m20s left"
+func (p *progressBar) line(now time.Time) string {
+	if p.total <= 0 {
+		return fmt.Sprintf("Fetching findings: %d pages, %d findings", p.pages, p.findings)
+	}
+	// A count taken before the fetch can fall behind findings added since
+	done := min(p.findings, p.total)
+	line := fmt.Sprintf("Fetching findings: %d pages, %d of %d findings (%d%%)", p.pages, p.findings, p.total, done*100/p.total)
+	if elapsed := now.Sub(p.start); done > 0 && done < p.total {
+		if left := time.Duration(float64(elapsed) / float64(done) * float64(p.total-done)); left >= time.Second {
+			line += fmt.Sprintf(", about %s left", left.Round(time.Second))
+		}
+	}
+	return line
+}
+
+// draw replaces the line on the terminal with line
+func (p *progressBar) draw(line string) {
+	fmt.Fprintf(p.w, "\r\033[K%s", line)
+	p.drawn, p.drawnAt = line, time.Now()
+}
+
+// clear removes the line from the terminal
+func (p *progressBar) clear() {
+	if p.drawn != "" {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.drawn = ""
+	}
+}
+
+// writer returns a writer to w that keeps the progress line below what it
+// writes, for output sharing the terminal with it; w itself when p is nil
+func (p *progressBar) writer(w io.Writer) io.Writer {
+	if p == nil {
+		return w
+	}
+	return progressWriter{p: p, w: w}
+}
+
+type progressWriter struct {
+	p *progressBar
+	w io.Writer
+}
+
+func (pw progressWriter) Write(b []byte) (int, error) {
+	p := pw.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	drawn := p.drawn != ""
+	p.clear()
+	n, err := pw.w.Write(b)
+	if drawn {
+		p.draw(p.line(time.Now()))
+	}
+	return n, err
+}
+
+// trackProgress draws the progress of fetching the findings of opts, counting
+// them first for the estimate of the time left. The returned func clears the
+// progress line and must be called once the fetch is over.
+func (g *globalOptions) trackProgress(ctx context.Context, client *api.Client, token string, opts *findingsOptions, filter string) func() {
+	if g.progress == nil {
+		return func() {}
+	}
+	var projects []string
+	if !opts.AllProjects {
+		projects = opts.ProjectUUIDs
+	}
+	total, err := client.CountFindings(ctx, token, projects, filter)
+	if err != nil {
+		slog.Debug("Failed to count the findings, fetching without an estimate", "error", err)
+		total = 0
+	}
+	// A limit stops a single listing early
+	if limit := g.List.Limit; limit > 0 && len(projects) <= 1 && limit < total {
+		total = limit
+	}
+	g.progress.begin(total)
+	return g.progress.finish
+}
+
+// fetch fetches the findings of opts with client, drawing the progress
+func (g *globalOptions) fetch(ctx context.Context, opts *findingsOptions, client *api.Client, token, filter string, cache *api.ProjectCache) (fetchResult, error) {
+	defer g.trackProgress(ctx, client, token, opts, filter)()
+	return opts.fetch(ctx, client, token, filter, cache)
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newProjectsCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "projects",
+		Short: "Work with Endor Labs projects",
+	}
+
+	cmd.AddCommand(newProjectsListCmd(g))
+	cmd.AddCommand(newProjectsGetCmd(g))
+	return cmd
+}
+
+func newProjectsListCmd(g *globalOptions) *cobra.Command {
+	var opts api.ProjectListOptions
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List projects with their UUIDs and repository URLs",
+		Example: `  findings-api projects list --name payments
+  findings-api projects list --repo github.com/acme/ --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			slog.Info("Fetching projects")
+			projects, err := client.ListProjects(cmd.Context(), token, opts)
+			if err != nil {
+				return fmt.Errorf("failed to fetch projects: %w", err)
+			}
+
+			if format == "json" {
+				return printJSON(projects)
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "UUID\tNAME\tREPOSITORY\tNAMESPACE")
+			for _, p := range projects {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.UUID, p.Meta.Name, p.Spec.Git.HTTPCloneURL, p.TenantMeta.Namespace)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+
+			fmt.Printf("\n%d projects\n", len(projects))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Only projects whose name contains this value")
+	cmd.Flags().StringVar(&opts.RepoURL, "repo", "", "Only projects whose git URL contains this value")
+	cmd.Flags().StringVar(&opts.Filter, "filter", "", "Additional raw Endor filter expression")
+	cmd.Flags().StringVar(&opts.Mask, "mask", "", `Field mask to request ("*" for all fields)`)
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
+
+func newProjectsGetCmd(g *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <uuid>",
+		Short: "Show a single project as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			project, err := client.GetProject(cmd.Context(), token, args[0])
+			if err != nil {
+				return fmt.Errorf("failed to fetch project: %w", err)
+			}
+			return printJSON(project)
+		},
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/release"
+	"github.com/spf13/cobra"
+)
+
+func newReleaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Build, sign and verify release binaries",
+	}
+
+	cmd.AddCommand(
+		newReleaseBuildCmd(),
+		newReleaseKeygenCmd(),
+		newReleaseVerifyCmd(),
+	)
+	return cmd
+}
+
+func newReleaseBuildCmd() *cobra.Command {
+	var version, outDir, targetSpec, keyFile string
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Cross-compile versioned binaries with checksums for every supported platform",
+		Long: `Build a binary for each target platform into --out-dir, write checksums.txt in
+sha256sum format and, when a signing key is given, sign it into checksums.txt.sig.
+
+The signing key is read from --signing-key-file or the ENDOR_RELEASE_SIGNING_KEY
+environment variable (base64 ed25519 private key, see "release keygen").
+Must be run from the repository root.`,
+		Example: `  findings-api release build --version v1.2.0 --signing-key-file release.key`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if version == "" {
+				return errors.New("--version is required")
+			}
+			targets, err := release.ParseTargets(targetSpec)
+			if err != nil {
+				return err
+			}
+
+			opts := release.Options{
+				Version: version,
+				Commit:  gitCommit(),
+				Date:    time.Now().UTC().Format(time.RFC3339),
+				OutDir:  outDir,
+				Targets: targets,
+				Log:     os.Stderr,
+			}
+
+			if opts.SigningKey, err = loadSigningKey(keyFile, "ENDOR_RELEASE_SIGNING_KEY"); err != nil {
+				return err
+			}
+
+			artifacts, err := release.Build(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			for _, a := range artifacts {
+				fmt.Printf("%s  %s\n", a.SHA256, a.Path)
+			}
+			if opts.SigningKey == nil {
+				fmt.Fprintln(os.Stderr, "Warning: no signing key configured, checksums are unsigned")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&version, "version", "", "Release version, e.g. v1.2.0")
+	cmd.Flags().StringVar(&outDir, "out-dir", "dist", "Directory for the built binaries")
+	cmd.Flags().StringVar(&targetSpec, "targets", "", "Comma-separated os/arch targets (default: linux, darwin and windows on amd64 and arm64)")
+	cmd.Flags().StringVar(&keyFile, "signing-key-file", "", "File containing the base64 ed25519 signing key")
+	return cmd
+}
+
+func newReleaseKeygenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate an ed25519 key pair for signing releases",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pub, priv, err := release.GenerateKey()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Public key:  %s\n", pub)
+			fmt.Printf("Private key: %s\n", priv)
+			return nil
+		},
+	}
+}
+
+func newReleaseVerifyCmd() *cobra.Command {
+	var dir, publicKey string
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the checksums signature and every binary checksum in a release directory",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := release.ParsePublicKey(publicKey)
+			if err != nil {
+				return err
+			}
+			if err := release.Verify(dir, key); err != nil {
+				return err
+			}
+			fmt.Println("Release verified")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "dist", "Release directory")
+	cmd.Flags().StringVar(&publicKey, "public-key", "", "Base64 ed25519 public key")
+	return cmd
+}
+
+// loadSigningKey reads a base64 ed25519 private key from keyFile, or from the
+// environment variable envVar when no file is given. It returns nil when neither is set.
+func loadSigningKey(keyFile, envVar string) (ed25519.PrivateKey, error) {
+	keyData := os.Getenv(envVar)
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		keyData = string(data)
+	}
+	if keyData == "" {
+		return nil, nil
+	}
+	return release.ParsePrivateKey(keyData)
+}
+
+// gitCommit returns the current short commit hash, or "unknown"
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configChanges checks the configuration file every interval and sends its
+// contents each time they change, until ctx is cancelled. It returns nil, a
+// channel that never receives, when reloading is off or no file is in use.
+func (g *globalOptions) configChanges(ctx context.Context, every time.Duration) <-chan *config.File {
+	if every <= 0 || g.configPath == "" {
+		return nil
+	}
+	files := make(chan *config.File)
+	go config.Watch(ctx, g.configPath, every, func(file *config.File) error {
+		select {
+		case files <- file:
+		case <-ctx.Done():
+		}
+		return nil
+	}, func(err error) {
+		slog.Warn("Keeping the previous configuration", "error", err)
+	})
+	return files
+}
+
+// addWatchConfigFlag adds the --watch-config flag of long-running commands
+func addWatchConfigFlag(fs *pflag.FlagSet, every *time.Duration) {
+	fs.DurationVar(every, "watch-config", config.DefaultWatchInterval, "How often the configuration file is checked for changes to apply (0 disables reloading)")
+}
+
+// pollReload applies configuration changes to a polling command between its
+// polls, so they never race with a fetch in progress
+type pollReload struct {
+	files <-chan *config.File
+	// apply applies a changed file and returns the new polling interval and
+	// whether the findings selected changed
+	apply func(*config.File) (interval time.Duration, changed bool, err error)
+}
+
+// wait blocks until the next tick of ticker, applying configuration changes
+// meanwhile and resetting ticker when the interval changes. It returns false
+// when ctx is cancelled, and whether a change altered the findings selected,
+// after which the next poll must be a new baseline.
+func (r pollReload) wait(ctx context.Context, ticker *time.Ticker, interval *time.Duration) (ok, changed bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, changed
+		case <-ticker.C:
+			return true, changed
+		case file := <-r.files:
+			next, selection, err := r.apply(file)
+			if err != nil {
+				slog.Warn("Keeping the previous configuration", "error", err)
+				continue
+			}
+			if next != *interval {
+				*interval = next
+				ticker.Reset(next)
+			}
+			changed = changed || selection
+			slog.Info("Configuration reloaded", "interval", *interval, "filter_changed", selection)
+		}
+	}
+}
+
+// reloadPollConfig applies the filters and schedule of a changed configuration
+// file to the options of findings watch or tail, updating filter and interval.
+// It reports whether the findings selected changed. Nothing changes when the
+// file is invalid.
+func (g *globalOptions) reloadPollConfig(cmd *cobra.Command, opts *findingsOptions, file *config.File, filter *string, interval *time.Duration) (changed bool, err error) {
+	profile, err := g.selectProfile(cmd, file)
+	if err != nil {
+		return false, err
+	}
+
+	prevFilter, prevInterval := opts.Filter, *interval
+	defer func() {
+		if err != nil {
+			opts.Filter, *interval = prevFilter, prevInterval
+			// Rebuild what validate derives from the filters
+			opts.validate()
+		}
+	}()
+	if err := applyProfileFilters(cmd.Flags(), profile.Filters); err != nil {
+		return false, err
+	}
+	if err := applyProfileSchedule(cmd.Flags(), profile.Schedule); err != nil {
+		return false, err
+	}
+	if *interval <= 0 {
+		return false, fmt.Errorf("invalid schedule interval %s", *interval)
+	}
+	if err := opts.validate(); err != nil {
+		return false, err
+	}
+	next, err := opts.buildFilter()
+	if err != nil {
+		return false, err
+	}
+
+	changed = next != *filter || opts.Filter != prevFilter
+	*filter = next
+	return changed, nil
+}
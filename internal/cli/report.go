@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/policy"
+	"github.com/endor-labs/findings-api/internal/trend"
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarise findings for dashboards and management reports",
+	}
+
+	cmd.AddCommand(
+		newReportOrgCmd(g),
+		newReportTrendCmd(),
+	)
+	return cmd
+}
+
+func newReportOrgCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var format, themePath, templatePath, uiURL string
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "org",
+		Short: "Write an executive summary of the findings of every project",
+		Long: `Fetch the findings of every project and roll them up into an executive
+summary: findings by level, the top vulnerable dependencies across the
+organization and each project's findings with its worst level, most severe
+projects first.
+
+The summary is written as Markdown or HTML, or as JSON for other tools, to
+--output or org_summary_<timestamp>.<ext>. --template replaces the built-in
+Markdown or HTML template, executed with the summary's Title, Description,
+Timestamp, Total, Counts, Levels, Projects, TopDependencies and Theme.`,
+		Example: `  findings-api report org
+  findings-api report org --format html --theme theme.json -o summary.html
+  findings-api report org --level critical,high,medium --top 20 -o - >> "$GITHUB_STEP_SUMMARY"
+  findings-api report org --namespaces acme.payments,acme.billing --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			run := g.startRun(cmd, args)
+			defer func() { err = g.finishRun(cmd.Context(), run, err) }()
+
+			ext, ok := map[string]string{"markdown": "md", "html": "html", "json": "json"}[format]
+			if !ok {
+				return fmt.Errorf("unsupported format %q (expected markdown, html or json)", format)
+			}
+			if top < 1 {
+				return errors.New("--top must be at least 1")
+			}
+			theme, err := g.reportTheme(themePath)
+			if err != nil {
+				return err
+			}
+			// An org summary covers every project, listed by name
+			opts.AllProjects, opts.ResolveProjects = true, true
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if err := g.validateNamespaces(opts); err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			fetched, err := g.fetchSelected(cmd.Context(), opts, filter)
+			if err != nil {
+				return err
+			}
+			logFetchReport(fetched.Report)
+			logWarnings(fetched.Warnings)
+			run.Selection, run.Filter = opts.description(), filter
+			run.SetFindings(fetched.Findings, len(fetched.Warnings), len(fetched.ProjectErrors))
+
+			summary := output.NewOrgSummary(fetched.Findings, opts.description(), time.Now().Format(time.RFC3339), top,
+				theme, firstNonEmpty(uiURL, os.Getenv("ENDOR_UI_URL")), g.namespace())
+			filename := g.outputPath(fmt.Sprintf("org_summary_%s.%s", time.Now().Format("2006-01-02_15-04-05"), ext))
+			if format == "json" {
+				err = writeJSONFile(summary, filename)
+			} else {
+				err = writeOrgSummary(summary, format, templatePath, filename)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to save org summary: %w", err)
+			}
+			addRunOutput(run, filename, format)
+
+			console := g.console()
+			fmt.Fprintf(console, "Summarised %d findings in %d projects\n", summary.Total, len(summary.Projects))
+			if filename != stdoutPath {
+				fmt.Fprintf(console, "Org summary saved to: %s\n", filename)
+			}
+			return nil
+		},
+	}
+
+	opts.addFilterFlags(cmd.Flags())
+	g.addNamespacesFlag(cmd.Flags())
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format (markdown, html or json)")
+	cmd.Flags().IntVar(&top, "top", output.DefaultTopDependencies, "Number of vulnerable dependencies listed")
+	cmd.Flags().StringVar(&themePath, "theme", "", "JSON file with a report theme (company name, logo, colours, font), replacing the profile's theme")
+	cmd.Flags().StringVar(&templatePath, "template", "", "Go template file replacing the built-in markdown or html summary")
+	cmd.Flags().StringVar(&uiURL, "ui-url", "", "Endor Labs app URL that projects link to (default: $ENDOR_UI_URL or "+output.DefaultUIURL+")")
+	return cmd
+}
+
+// writeOrgSummary renders summary as markdown or html to filename, or stdout for "-"
+func writeOrgSummary(summary output.OrgSummary, format, templatePath, filename string) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return output.WriteOrgSummary(file, summary, format, templatePath)
+}
+
+func newReportTrendCmd() *cobra.Command {
+	var dbPath, sinceSpec, format string
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "trend",
+		Short: "Report open, opened and resolved findings and time to remediate across recorded runs",
+		Long: `Report how findings changed over the runs recorded by findings export
+--record: open findings by level per run, findings opened and resolved since
+the run before, and the mean time to remediate, for the organization and per
+project.
+
+Findings are matched across runs by UUID. A finding is resolved by the first
+run that no longer records it, and its time to remediate runs from the first
+run that recorded it. Runs before --since are read too, so the first run of
+the window has opened and resolved counts.
+
+--format csv writes one row per run, for the organization (empty
+project_uuid) and then per project, ready for a spreadsheet chart; --format
+json writes the same series with the time to remediate by level.`,
+		Example: `  findings-api report trend
+  findings-api report trend --since 30d --top 20
+  findings-api report trend --since 90d --format csv > trend.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" && format != "csv" {
+				return fmt.Errorf("unsupported format %q (expected table, json or csv)", format)
+			}
+			age, err := policy.ParseAge(sinceSpec)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			var since time.Time
+			if age > 0 {
+				since = time.Now().Add(-age)
+			}
+
+			s, err := openHistory(dbPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			recorded, err := s.Runs(cmd.Context(), 0)
+			if err != nil {
+				return err
+			}
+			// Runs are newest first; a trend reads oldest first
+			runs := make([]trend.Run, len(recorded))
+			for i, r := range recorded {
+				entries, err := s.Entries(cmd.Context(), r.ID)
+				if err != nil {
+					return err
+				}
+				runs[len(recorded)-1-i] = trend.Run{ID: r.ID, StartedAt: r.StartedAt, Entries: entries}
+			}
+			report := trend.Compute(runs, since)
+
+			switch format {
+			case "json":
+				return printJSON(report)
+			case "csv":
+				return writeTrendCSV(os.Stdout, report)
+			}
+			if len(report.Points) == 0 {
+				fmt.Println("No runs recorded in the window; record one with findings export --record")
+				return nil
+			}
+			return writeTrendTable(os.Stdout, report, top)
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "history-db", "", historyDBUsage)
+	cmd.Flags().StringVar(&sinceSpec, "since", "90d", "Report the runs of this period, as days such as 90d or a duration such as 12h (0 reports every run)")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table, json or csv)")
+	cmd.Flags().IntVar(&top, "top", 10, "Projects listed in the table, those with the most open findings first (0 lists them all)")
+	return cmd
+}
+
+// writeTrendTable prints the organization's series, its time to remediate
+// and a summary of the top projects
+func writeTrendTable(w io.Writer, report trend.Report, top int) error {
+	first := report.Points[0]
+	fmt.Fprintf(w, "Findings trend over %d runs since %s\n\n", len(report.Points), first.StartedAt.Local().Format(time.DateTime))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tSTARTED\tOPEN\tCRITICAL\tHIGH\tMEDIUM\tLOW\tOPENED\tRESOLVED")
+	for _, p := range report.Points {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", p.RunID, p.StartedAt.Local().Format(time.DateTime),
+			p.Open.Total, p.Open.Critical, p.Open.High, p.Open.Medium, p.Open.Low, p.Opened.Total, p.Resolved.Total)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nMean time to remediate: %s\n", formatMTTR(report.Remediation))
+
+	if len(report.Projects) == 0 {
+		return nil
+	}
+	projects := report.Projects
+	if top > 0 && len(projects) > top {
+		projects = projects[:top]
+	}
+	fmt.Fprintf(w, "\nProjects (%d of %d):\n\n", len(projects), len(report.Projects))
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROJECT\tOPEN\tCHANGE\tCRITICAL\tHIGH\tOPENED\tRESOLVED\tMTTR (DAYS)")
+	for _, p := range projects {
+		last := p.Last()
+		opened, resolved := trend.Totals(p.Points)
+		mttr := "-"
+		if p.Remediation.Resolved > 0 {
+			mttr = strconv.FormatFloat(p.Remediation.MeanDays, 'f', 1, 64)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%+d\t%d\t%d\t%d\t%d\t%s\n", p.ProjectUUID, last.Open.Total, last.Open.Total-p.Points[0].Open.Total,
+			last.Open.Critical, last.Open.High, opened.Total, resolved.Total, mttr)
+	}
+	return tw.Flush()
+}
+
+// formatMTTR describes a time to remediate, e.g. "4.5 days over 12 resolved
+// findings (critical 2.0, high 5.1)"
+func formatMTTR(r trend.Remediation) string {
+	if r.Resolved == 0 {
+		return "no findings resolved"
+	}
+	var levels []string
+	for _, level := range []string{"critical", "high", "medium", "low"} {
+		if m, ok := r.ByLevel[level]; ok {
+			levels = append(levels, fmt.Sprintf("%s %.1f", level, m.MeanDays))
+		}
+	}
+	return fmt.Sprintf("%.1f days over %d resolved findings (%s)", r.MeanDays, r.Resolved, strings.Join(levels, ", "))
+}
+
+// writeTrendCSV writes one row per run of the organization, then of each project
+func writeTrendCSV(w io.Writer, report trend.Report) error {
+	cw := csv.NewWriter(w)
+	header := []string{"project_uuid", "run_id", "started_at"}
+	for _, series := range []string{"open", "opened", "resolved"} {
+		for _, level := range []string{"total", "critical", "high", "medium", "low"} {
+			header = append(header, series+"_"+level)
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	write := func(project string, points []trend.Point) error {
+		for _, p := range points {
+			row := []string{project, strconv.FormatInt(p.RunID, 10), p.StartedAt.UTC().Format(time.RFC3339)}
+			for _, c := range []trend.Counts{p.Open, p.Opened, p.Resolved} {
+				for _, n := range []int{c.Total, c.Critical, c.High, c.Medium, c.Low} {
+					row = append(row, strconv.Itoa(n))
+				}
+			}
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := write("", report.Points); err != nil {
+		return err
+	}
+	for _, p := range report.Projects {
+		if err := write(p.ProjectUUID, p.Points); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
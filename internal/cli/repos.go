@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newReposCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repos",
+		Short: "Inspect the repositories of projects and their scanned branches and commits",
+	}
+
+	cmd.AddCommand(newReposListCmd(g))
+	return cmd
+}
+
+// repoListing is a repository and its scanned versions, as repos list
+// prints them
+type repoListing struct {
+	api.Repository
+	Versions []api.RepositoryVersion `json:"versions"`
+	// Findings counts the findings of each version by version UUID, with
+	// --count-findings
+	Findings map[string]int `json:"findings,omitempty"`
+}
+
+func newReposListCmd(g *globalOptions) *cobra.Command {
+	var projectUUID, repo, format string
+	var countFindings bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List repositories with their scanned branches, commit SHAs and scan contexts",
+		Long: `List the repositories of a project, or of every project, with the versions
+Endor Labs scanned: the branch or ref, the commit SHA, the scan context and
+when it was scanned. Findings carry the scan context they were found in, so
+the context ties each finding to a branch and commit.
+
+--count-findings adds the number of findings of each version, counted by the
+API in a single request.`,
+		Example: `  findings-api repos list
+  findings-api repos list --repo github.com/acme/payments --count-findings
+  findings-api repos list --project_uuid abc123-def456-ghi789 --format json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+
+			ctx := cmd.Context()
+			client, token, err := g.authenticate(ctx)
+			if err != nil {
+				return err
+			}
+			if projectUUID == "" && repo != "" {
+				p, err := client.FindProjectByRepo(ctx, token, repo)
+				if err != nil {
+					return fmt.Errorf("failed to resolve --repo: %w", err)
+				}
+				projectUUID = p.UUID
+			}
+			var projects []string
+			if projectUUID != "" {
+				projects = []string{projectUUID}
+			}
+
+			slog.Info("Fetching repositories", "project_uuid", projectUUID)
+			repos, err := client.ListRepositories(ctx, token, projects)
+			if err != nil {
+				return fmt.Errorf("failed to fetch repositories: %w", err)
+			}
+			versions, err := client.ListRepositoryVersions(ctx, token, projects)
+			if err != nil {
+				return fmt.Errorf("failed to fetch repository versions: %w", err)
+			}
+			var counts map[string]int
+			if countFindings {
+				if counts, err = client.CountFindingsByVersion(ctx, token, versions); err != nil {
+					return fmt.Errorf("failed to count findings: %w", err)
+				}
+			}
+
+			listings := groupRepoVersions(repos, versions, counts)
+			if format == "json" {
+				return printJSON(listings)
+			}
+			return printRepos(os.Stdout, listings, countFindings)
+		},
+	}
+
+	cmd.Flags().StringVar(&projectUUID, "project_uuid", "", "UUID of the project (default: every project)")
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository URL of the project, e.g. github.com/org/repo")
+	cmd.Flags().BoolVar(&countFindings, "count-findings", false, "Count the findings of each version")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
+
+// groupRepoVersions pairs each repository with the versions of its project.
+// Versions of a project without a repository listed get one named after the
+// project UUID, so none are left out.
+func groupRepoVersions(repos []api.Repository, versions []api.RepositoryVersion, counts map[string]int) []repoListing {
+	listings := make([]repoListing, 0, len(repos))
+	index := make(map[string]int, len(repos))
+	for _, r := range repos {
+		index[r.Meta.ParentUUID] = len(listings)
+		listings = append(listings, repoListing{Repository: r, Versions: []api.RepositoryVersion{}})
+	}
+	for _, v := range versions {
+		i, ok := index[v.Meta.ParentUUID]
+		if !ok {
+			var r api.Repository
+			r.Meta.Name, r.Meta.ParentUUID = v.Meta.ParentUUID, v.Meta.ParentUUID
+			i = len(listings)
+			index[v.Meta.ParentUUID] = i
+			listings = append(listings, repoListing{Repository: r})
+		}
+		l := &listings[i]
+		l.Versions = append(l.Versions, v)
+		if counts != nil {
+			if l.Findings == nil {
+				l.Findings = make(map[string]int)
+			}
+			l.Findings[v.UUID] = counts[v.UUID]
+		}
+	}
+	return listings
+}
+
+// printRepos prints a line per repository version, and one for each
+// repository without any
+func printRepos(w io.Writer, listings []repoListing, countFindings bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "REPOSITORY\tREF\tSHA\tCONTEXT\tLAST COMMIT\tSCANNED"
+	if countFindings {
+		header += "\tFINDINGS"
+	}
+	fmt.Fprintln(tw, header)
+	versions := 0
+	for _, l := range listings {
+		if len(l.Versions) == 0 {
+			fmt.Fprintf(tw, "%s\t\t\t\t\t", l.Meta.Name)
+			if countFindings {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprintln(tw)
+			continue
+		}
+		for _, v := range l.Versions {
+			versions++
+			ref := v.Ref()
+			if ref != "" && ref == l.Spec.DefaultBranch {
+				ref += " (default)"
+			}
+			scanned := strings.ToLower(strings.TrimPrefix(v.Context.Type, "CONTEXT_TYPE_"))
+			if v.Context.ID != "" {
+				scanned += ":" + v.Context.ID
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s", l.Meta.Name, ref, v.ShortSHA(), scanned,
+				localTime(v.Spec.LastCommitDate), localTime(v.Spec.ScanObject.ScanTime))
+			if countFindings {
+				fmt.Fprintf(tw, "\t%d", l.Findings[v.UUID])
+			}
+			fmt.Fprintln(tw)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d repositories, %d versions\n", len(listings), versions)
+	return nil
+}
+
+// localTime formats t in local time to the minute, or "" when unset
+func localTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
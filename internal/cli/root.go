@@ -0,0 +1,610 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+	"github.com/endor-labs/findings-api/internal/config"
+	"github.com/endor-labs/findings-api/internal/risk"
+	"github.com/endor-labs/findings-api/internal/tokencache"
+	"github.com/endor-labs/findings-api/internal/tui"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// globalOptions holds the flags shared by every command
+type globalOptions struct {
+	ConfigPath string
+	Profile    string
+	Namespace  string
+	APIURL     string
+	Output     string
+	Retries    int
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff between retries
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	PageSize       int
+	Verbose        bool
+	AuthPath       string
+	TokenAudience  string
+	TokenClaims    map[string]string
+	// Offline disables every network call except to the Endor Labs API
+	Offline bool
+	// NoCache skips the on-disk token cache
+	NoCache bool
+	// Debug logs every API request and response to stderr
+	Debug bool
+	// LogLevel and LogFormat configure the logger of the run; see setupLogging
+	LogLevel  string
+	LogFormat string
+	// Quiet hides the progress of findings fetches
+	Quiet bool
+	// NoCompression requests uncompressed API responses
+	NoCompression bool
+	// Manifest is where artifact-producing runs write run.json
+	Manifest string
+	// Preset is the shared preset of default filters, overriding the profile's
+	Preset string
+	// Upload is where artifact-producing runs upload their artifacts, encrypted
+	// with UploadSSE and UploadKMSKey
+	Upload       string
+	UploadSSE    string
+	UploadKMSKey string
+	// RateLimit caps API requests per second across every client of the run
+	// (0 is unlimited), allowing bursts of RateBurst requests
+	RateLimit float64
+	RateBurst int
+	// List tunes the findings list requests; set by the findings command flags
+	List api.ListOptions
+	// Namespaces are fetched from one after another instead of the single
+	// namespace, by the commands that support it
+	Namespaces []string
+	// Checkpoint records the pages fetched, and Resume continues the fetch
+	// recorded in it; see openCheckpoint
+	Checkpoint string
+	Resume     bool
+	// OutDir is the directory that relative output files and the run manifest
+	// are written to, by the commands that support it
+	OutDir string
+
+	// profile is the configuration profile selected for the running command
+	profile config.Profile
+	// configPath is the configuration file in use, when one was found or given
+	configPath string
+	// client is the client created for the running command, if any
+	client *api.Client
+	// checkpoint records the listings of clients created while it is open
+	checkpoint *api.Checkpoint
+	// limiter is shared by every client of the run; see rateLimiter
+	limiter     *api.RateLimiter
+	limiterOnce sync.Once
+	// progress draws the progress of findings fetches on stderr; nil unless
+	// stderr is a terminal and --quiet is not given
+	progress *progressBar
+}
+
+// NewRootCmd builds the command tree
+func NewRootCmd() *cobra.Command {
+	g := &globalOptions{}
+
+	root := &cobra.Command{
+		Use:           "findings-api",
+		Short:         "Fetch security findings and related data from the Endor Labs API",
+		Version:       buildinfo.String(),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Load .env file automatically (like Python)
+			envErr := godotenv.Load()
+			// The progress line would run into results written to the same terminal
+			if !g.Quiet && tui.IsTerminal(os.Stderr) && !(g.Output == stdoutPath && tui.IsTerminal(os.Stdout)) {
+				g.progress = newProgressBar(os.Stderr)
+			}
+			if err := g.setupLogging(); err != nil {
+				return err
+			}
+			if errors.Is(envErr, fs.ErrNotExist) {
+				slog.Debug("No .env file found")
+			} else if envErr != nil {
+				slog.Warn("Failed to load the .env file", "error", envErr)
+			}
+			if err := g.loadProfile(cmd); err != nil {
+				return err
+			}
+			if on, _ := cmd.Flags().GetBool("threat-intel"); on {
+				if err := g.requireNetwork("fetching KEV and EPSS data (--threat-intel)"); err != nil {
+					return err
+				}
+			}
+			// Reject a bad upload destination before the run, not after it
+			_, err := g.newUploader()
+			return err
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			if g.Verbose && g.client != nil {
+				printPageTelemetry(os.Stderr, g.client.PageTelemetry())
+			}
+			if waited := g.limiter.Waited(); g.Verbose && waited > 0 {
+				fmt.Fprintf(os.Stderr, "Rate limit held requests back for %s in total\n", waited.Round(time.Millisecond))
+			}
+		},
+	}
+
+	root.PersistentFlags().StringVar(&g.ConfigPath, "config", "", "Configuration file (default: $ENDOR_CONFIG or ~/.endor/config.yaml)")
+	root.PersistentFlags().StringVar(&g.Profile, "profile", "", "Configuration profile to use (default: $ENDOR_PROFILE or the file's default_profile)")
+	root.PersistentFlags().StringVar(&g.Namespace, "namespace", "", "Endor Labs namespace (default: $ENDOR_API_NAMESPACE)")
+	root.PersistentFlags().StringVar(&g.APIURL, "api-url", "", "Endor Labs API base URL, e.g. for staging or regional tenants (default: $ENDOR_API_URL, the profile's base_url or "+api.BaseURL+")")
+	root.PersistentFlags().StringVarP(&g.Output, "output", "o", "", `Output file path, or "-" for stdout (default: a timestamped file in the current directory)`)
+	root.PersistentFlags().IntVar(&g.Retries, "retries", api.DefaultMaxRetries, "Number of times each failed API request is retried")
+	root.PersistentFlags().IntVar(&g.PageSize, "page-size", api.DefaultPageSize, "Number of objects requested per API page")
+	root.PersistentFlags().Float64Var(&g.RateLimit, "rate-limit", 0, "Maximum API requests per second across the run, 0 for no limit (default: $ENDOR_RATE_LIMIT)")
+	root.PersistentFlags().IntVar(&g.RateBurst, "rate-burst", 5, "Requests that may be sent at once before --rate-limit applies")
+	root.PersistentFlags().BoolVarP(&g.Verbose, "verbose", "v", false, "Print per-page size, timing and memory telemetry at the end of the run")
+	root.PersistentFlags().StringVar(&g.AuthPath, "auth-path", "", "Auth endpoint path or absolute URL (default: $ENDOR_AUTH_PATH or "+api.DefaultAuthPath+")")
+	root.PersistentFlags().StringVar(&g.TokenAudience, "token-audience", "", "Audience to request for the token (default: $ENDOR_TOKEN_AUDIENCE)")
+	root.PersistentFlags().StringToStringVar(&g.TokenClaims, "token-claim", nil, "Extra key=value field for the auth request (repeatable)")
+	root.PersistentFlags().DurationVar(&g.RetryBaseDelay, "retry-base-delay", api.DefaultRetryBaseDelay, "Delay before the first retry; doubles on every retry")
+	root.PersistentFlags().DurationVar(&g.RetryMaxDelay, "retry-max-delay", api.DefaultRetryMaxDelay, "Maximum delay between retries")
+	root.PersistentFlags().StringVar(&g.Manifest, "manifest", "", `Run manifest path for commands that save artifacts (default: run.json next to --output; "none" disables it)`)
+	root.PersistentFlags().StringVar(&g.Upload, "upload", "", "Upload the artifacts and manifest of the run to s3://bucket/prefix, gs://bucket/prefix or az://account/container/prefix (default: $ENDOR_UPLOAD or the profile's upload.url)")
+	root.PersistentFlags().StringVar(&g.UploadSSE, "upload-sse", "", "S3 server-side encryption of uploads: AES256 or aws:kms (default: the bucket's)")
+	root.PersistentFlags().StringVar(&g.UploadKMSKey, "upload-kms-key", "", "KMS key of uploads: an S3 KMS key ID or ARN, a Cloud KMS key name or an Azure encryption scope")
+	root.PersistentFlags().StringVar(&g.Preset, "preset", "", "Shared preset of default filters: a file path or an http(s), s3 or gs URL (default: $ENDOR_PRESET or the profile's preset)")
+	root.PersistentFlags().BoolVar(&g.Debug, "debug", false, "Log each API request's URL, headers (secrets redacted), status and timing to stderr; implies --log-level debug")
+	root.PersistentFlags().StringVar(&g.LogLevel, "log-level", "", "Log level: debug, info, warn or error (default: $ENDOR_LOG_LEVEL or info)")
+	root.PersistentFlags().StringVar(&g.LogFormat, "log-format", "", "Log format: text or json (default: $ENDOR_LOG_FORMAT or text)")
+	root.PersistentFlags().BoolVarP(&g.Quiet, "quiet", "q", false, "Do not draw the progress of findings fetches on stderr")
+	root.PersistentFlags().BoolVar(&g.NoCompression, "no-compression", false, "Request uncompressed API responses instead of gzip, e.g. when debugging")
+	root.PersistentFlags().BoolVar(&g.NoCache, "no-cache", false, "Always authenticate instead of reusing a cached token")
+	root.PersistentFlags().BoolVar(&g.Offline, "offline", false, "Air-gapped mode: never contact services other than the Endor Labs API (default: $ENDOR_OFFLINE)")
+
+	root.AddCommand(
+		newFindingsCmd(g),
+		newProjectsCmd(g),
+		newDepsCmd(g),
+		newPackagesCmd(g),
+		newScansCmd(g),
+		newReposCmd(g),
+		newSBOMCmd(g),
+		newExceptionsCmd(g),
+		newExportCmd(g),
+		newSimulateUpgradeCmd(g),
+		newAuthCmd(g),
+		newServeCmd(g),
+		newBundleCmd(g),
+		newIntegrationsCmd(g),
+		newCICmd(g),
+		newHistoryCmd(),
+		newReportCmd(g),
+		newReleaseCmd(),
+		newVersionCmd(),
+	)
+
+	return root
+}
+
+// Execute runs the root command and exits non-zero on failure
+func Execute() {
+	// Cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := NewRootCmd().ExecuteContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+		}
+
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		os.Exit(1)
+	}
+}
+
+// errorHint suggests how to fix common API errors
+func errorHint(err error) string {
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		return "check ENDOR_API_KEY and ENDOR_API_SECRET (or the profile's api_key and api_secret), and that the key has not expired"
+	case errors.Is(err, api.ErrForbidden):
+		return "the API key lacks permission for this namespace or resource; check --namespace and the key's role"
+	case errors.Is(err, api.ErrRateLimited):
+		return "the API is rate limiting requests; retry later, or raise --retries and --retry-max-delay"
+	case errors.Is(err, api.ErrNotFound):
+		return "check that the namespace and any UUIDs exist"
+	case errors.Is(err, api.ErrBadRequest):
+		return "the API rejected the request; check --raw-filter and other filter flags"
+	}
+	return ""
+}
+
+// loadProfile reads the configuration file and selects the profile for the
+// running command. The profile's filters become the defaults of any filter
+// flags the command has and the user did not set.
+func (g *globalOptions) loadProfile(cmd *cobra.Command) error {
+	path := firstNonEmpty(g.ConfigPath, os.Getenv("ENDOR_CONFIG"))
+	required := path != ""
+	if path == "" {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return nil
+		}
+	}
+
+	file, err := config.Load(path, required)
+	if err != nil {
+		return err
+	}
+	g.configPath = path
+	g.profile, err = g.selectProfile(cmd, file)
+	if err != nil {
+		return err
+	}
+
+	if err := applyProfileFilters(cmd.Flags(), g.profile.Filters); err != nil {
+		return err
+	}
+	if err := applyProfileSchedule(cmd.Flags(), g.profile.Schedule); err != nil {
+		return err
+	}
+	// The profile's risk weights are the default of --risk-weights
+	if flag := cmd.Flags().Lookup("risk-weights"); flag != nil && !flag.Changed && len(g.profile.Risk.Weights) > 0 {
+		if err := flag.Value.Set(risk.FormatWeights(g.profile.Risk.Weights)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectProfile returns the profile of file chosen by --profile or the
+// environment. For commands with filter flags, the filters of the shared preset
+// fill in those the profile leaves unset.
+func (g *globalOptions) selectProfile(cmd *cobra.Command, file *config.File) (config.Profile, error) {
+	profile, err := file.Profile(firstNonEmpty(g.Profile, os.Getenv("ENDOR_PROFILE")))
+	if err != nil {
+		return config.Profile{}, err
+	}
+
+	source := firstNonEmpty(g.Preset, os.Getenv("ENDOR_PRESET"), profile.Preset)
+	if source == "" || !hasFilterFlags(cmd.Flags()) {
+		return profile, nil
+	}
+	opts := config.PresetOptions{Offline: g.offline(), Token: os.Getenv("ENDOR_PRESET_TOKEN")}
+	opts.CacheDir, _ = config.DefaultPresetCacheDir()
+	preset, err := config.LoadPreset(cmd.Context(), source, opts)
+	if err != nil {
+		return config.Profile{}, err
+	}
+	namespace := firstNonEmpty(g.Namespace, os.Getenv("ENDOR_API_NAMESPACE"), profile.Namespace)
+	profile.Filters = preset.For(namespace).Override(profile.Filters)
+	return profile, nil
+}
+
+// hasFilterFlags reports whether fs has any flag a profile's filters can set
+func hasFilterFlags(fs *pflag.FlagSet) bool {
+	for _, name := range profileFilterFlags {
+		if fs.Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProfileFilters makes the profile's filters the values of the matching
+// flags in fs the user did not set. Other unset flags are reset to their
+// built-in defaults, so filters can be applied again when the profile changes.
+func applyProfileFilters(fs *pflag.FlagSet, filters config.Filters) error {
+	defaults := filters.FlagDefaults()
+	for _, name := range profileFilterFlags {
+		flag := fs.Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		value, ok := defaults[name]
+		if !ok {
+			value = flag.DefValue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s in profile filters: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// profileFilterFlags are the flags a profile's filters can set
+var profileFilterFlags = []string{"level", "categories", "tags", "epss-min", "reachable-only", "fix-available", "raw-filter", "context", "fail-on", "min-cvss", "cvss-environmental"}
+
+// applyProfileSchedule makes the profile's schedule the values of the matching
+// flags in fs the user did not set, resetting the others like
+// applyProfileFilters
+func applyProfileSchedule(fs *pflag.FlagSet, schedule config.Schedule) error {
+	defaults := schedule.FlagDefaults()
+	for _, name := range profileScheduleFlags {
+		flag := fs.Lookup(name)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		value, ok := defaults[name]
+		if !ok {
+			value = flag.DefValue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid %s in profile schedule: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// profileScheduleFlags are the flags a profile's schedule can set
+var profileScheduleFlags = []string{"interval", "refresh-interval", "refresh-jitter", "max-staleness", "min-fetch-gap"}
+
+// newClient creates an API client from the global flags, the environment and
+// the selected profile, in that order of precedence
+func (g *globalOptions) newClient() (*api.Client, error) {
+	client, err := g.newNamespaceClient(g.namespace())
+	if err != nil {
+		return nil, err
+	}
+	g.client = client
+
+	return client, nil
+}
+
+// offline reports whether air-gapped mode is on, by --offline or ENDOR_OFFLINE
+func (g *globalOptions) offline() bool {
+	if g.Offline {
+		return true
+	}
+	on, _ := strconv.ParseBool(os.Getenv("ENDOR_OFFLINE"))
+	return on
+}
+
+// requireNetwork fails in air-gapped mode; what names the service that would be contacted
+func (g *globalOptions) requireNetwork(what string) error {
+	if g.offline() {
+		return fmt.Errorf("%s is disabled in offline mode (--offline or ENDOR_OFFLINE)", what)
+	}
+	return nil
+}
+
+// namespace returns the namespace selected by the flags, the environment or the profile
+func (g *globalOptions) namespace() string {
+	return firstNonEmpty(g.Namespace, os.Getenv("ENDOR_API_NAMESPACE"), g.profile.Namespace)
+}
+
+// newNamespaceClient creates an API client for namespace, configured like newClient.
+// Unlike newClient it does not become the command's client, so long-running
+// commands can create one per namespace or per refresh.
+func (g *globalOptions) newNamespaceClient(namespace string) (*api.Client, error) {
+	return g.newProfileClient(g.profile, namespace)
+}
+
+// newProfileClient creates an API client for namespace from the global flags,
+// the environment and profile, for commands that reload their profile
+func (g *globalOptions) newProfileClient(profile config.Profile, namespace string) (*api.Client, error) {
+	apiKey := firstNonEmpty(os.Getenv("ENDOR_API_KEY"), profile.APIKey)
+	apiSecret := firstNonEmpty(os.Getenv("ENDOR_API_SECRET"), profile.APISecret)
+
+	if apiKey == "" || apiSecret == "" || namespace == "" {
+		return nil, fmt.Errorf("please set the ENDOR_API_KEY, ENDOR_API_SECRET and ENDOR_API_NAMESPACE environment variables (or --namespace), or configure a profile")
+	}
+
+	baseURL := firstNonEmpty(g.APIURL, os.Getenv("ENDOR_API_URL"), profile.BaseURL)
+	if baseURL != "" {
+		if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid API URL %q (expected an absolute http or https URL)", baseURL)
+		}
+	}
+
+	limiter, err := g.rateLimiter()
+	if err != nil {
+		return nil, err
+	}
+	if err := g.List.Validate(); err != nil {
+		return nil, err
+	}
+	opts := []api.Option{
+		api.WithCredentials(apiKey, apiSecret),
+		api.WithNamespace(namespace),
+		api.WithBaseURL(baseURL),
+		api.WithRetry(api.RetryPolicy{
+			MaxRetries: g.Retries,
+			BaseDelay:  g.RetryBaseDelay,
+			MaxDelay:   g.RetryMaxDelay,
+		}),
+		api.WithPageSize(g.PageSize),
+		api.WithListOptions(g.List),
+		api.WithRateLimiter(limiter),
+		api.WithCompression(!g.NoCompression),
+		api.WithAuthConfig(api.AuthConfig{
+			Path:     firstNonEmpty(g.AuthPath, os.Getenv("ENDOR_AUTH_PATH"), profile.AuthPath),
+			Audience: firstNonEmpty(g.TokenAudience, os.Getenv("ENDOR_TOKEN_AUDIENCE"), profile.TokenAudience),
+			Claims:   g.TokenClaims,
+		}),
+	}
+	if g.checkpoint != nil {
+		opts = append(opts, api.WithCheckpoint(g.checkpoint))
+	}
+	if g.Debug {
+		opts = append(opts, api.WithLogger(slog.Default()))
+	}
+	if g.progress != nil {
+		opts = append(opts, api.WithProgress(g.progress.page))
+	}
+
+	return api.NewClient(opts...), nil
+}
+
+// rateLimiter returns the limiter shared by every client of the run, set by
+// --rate-limit or ENDOR_RATE_LIMIT; nil when requests are not limited
+func (g *globalOptions) rateLimiter() (*api.RateLimiter, error) {
+	rps := g.RateLimit
+	if rps == 0 {
+		if env := os.Getenv("ENDOR_RATE_LIMIT"); env != "" {
+			var err error
+			if rps, err = strconv.ParseFloat(env, 64); err != nil {
+				return nil, fmt.Errorf("invalid ENDOR_RATE_LIMIT %q: %w", env, err)
+			}
+		}
+	}
+	if rps < 0 {
+		return nil, fmt.Errorf("invalid rate limit %g (expected requests per second, or 0 for no limit)", rps)
+	}
+	g.limiterOnce.Do(func() {
+		g.limiter = api.NewRateLimiter(rps, g.RateBurst)
+	})
+	return g.limiter, nil
+}
+
+// authenticate creates a client and fetches an authentication token
+func (g *globalOptions) authenticate(ctx context.Context) (*api.Client, string, error) {
+	client, err := g.newClient()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := g.token(ctx, client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	slog.Info("Authenticated with the Endor Labs API")
+	return client, token, nil
+}
+
+// openTokenCache opens the token cache at $ENDOR_TOKEN_CACHE or ~/.endor/token.json
+func openTokenCache() (*tokencache.Cache, error) {
+	path := os.Getenv("ENDOR_TOKEN_CACHE")
+	if path == "" {
+		var err error
+		if path, err = tokencache.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return tokencache.Open(path)
+}
+
+// token returns a valid token for client, from the token cache when possible.
+// A cache that cannot be read or written only costs an extra auth request.
+func (g *globalOptions) token(ctx context.Context, client *api.Client) (string, error) {
+	var cache *tokencache.Cache
+	if !g.NoCache {
+		var err error
+		if cache, err = openTokenCache(); err != nil {
+			slog.Warn("Token cache unavailable", "error", err)
+		} else if t, ok := cache.Get(client.CacheKey()); ok {
+			return t.Value, nil
+		}
+	}
+
+	t, err := client.Authenticate(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get authentication token: %w", err)
+	}
+	if cache != nil {
+		if err := cache.Put(client.CacheKey(), t); err != nil {
+			slog.Warn("Failed to cache the token", "error", err)
+		}
+	}
+	return t.Value, nil
+}
+
+// stdoutPath is the --output value that writes results to stdout
+const stdoutPath = "-"
+
+// outputPath returns the --output path, or defaultName when it is not set
+func (g *globalOptions) outputPath(defaultName string) string {
+	if g.Output != "" {
+		return g.Output
+	}
+	return defaultName
+}
+
+// inOutDir returns path inside --out-dir when one is set and path is relative
+func (g *globalOptions) inOutDir(path string) string {
+	if g.OutDir == "" || path == stdoutPath || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(g.OutDir, path)
+}
+
+// console returns where human-readable progress and summaries are printed:
+// stderr when results are written to stdout, so they can be piped cleanly
+func (g *globalOptions) console() io.Writer {
+	if g.Output == stdoutPath {
+		return g.progress.writer(os.Stderr)
+	}
+	return g.progress.writer(os.Stdout)
+}
+
+// nopCloser wraps stdout so it is not closed like an output file
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// createOutput creates filename for writing, or returns stdout for "-"
+func createOutput(filename string) (io.WriteCloser, error) {
+	if filename == stdoutPath {
+		return nopCloser{os.Stdout}, nil
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return file, nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// writeJSONFile writes v to filename, or stdout for "-", as indented JSON
+func writeJSONFile(v any, filename string) error {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(jsonData, '\n')); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	return file.Close()
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newSBOMCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sbom",
+		Short: "Export software bills of materials",
+	}
+
+	cmd.AddCommand(newSBOMExportCmd(g))
+	return cmd
+}
+
+func newSBOMExportCmd(g *globalOptions) *cobra.Command {
+	var projectUUID, repo, packageVersion string
+	opts := api.SBOMExportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Save a project's SBOM as CycloneDX or SPDX",
+		Example: `  findings-api sbom export --project_uuid abc123-def456-ghi789 --format cyclonedx
+  findings-api sbom export --repo github.com/acme/payments --format spdx -o payments.spdx.json
+  findings-api sbom export --repo github.com/acme/payments --format cyclonedx --encoding xml --package-version npm://payments@1.4.0`,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			run := g.startRun(cmd, args)
+			defer func() { err = g.finishRun(cmd.Context(), run, err) }()
+
+			if projectUUID == "" && repo == "" {
+				return errors.New("either --project_uuid or --repo is required")
+			}
+			opts.Kind = strings.ToLower(opts.Kind)
+			opts.Format = strings.ToLower(opts.Format)
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			client, token, err := g.authenticate(ctx)
+			if err != nil {
+				return err
+			}
+			if projectUUID == "" {
+				p, err := client.FindProjectByRepo(ctx, token, repo)
+				if err != nil {
+					return fmt.Errorf("failed to resolve --repo: %w", err)
+				}
+				projectUUID = p.UUID
+			}
+
+			pv, err := client.FindPackageVersion(ctx, token, projectUUID, packageVersion)
+			if err != nil {
+				return fmt.Errorf("failed to find package version: %w", err)
+			}
+			opts.PackageVersionUUID = pv.UUID
+			run.Selection = pv.Meta.Name
+
+			slog.Info("Exporting SBOM", "kind", opts.Kind, "package", pv.Meta.Name)
+			sbom, err := client.ExportSBOM(ctx, token, opts)
+			if err != nil {
+				return fmt.Errorf("failed to export SBOM: %w", err)
+			}
+
+			filename := g.outputPath(sbomFilename(pv.Meta.Name, opts))
+			file, err := createOutput(filename)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			if _, err := file.Write(sbom); err != nil {
+				return fmt.Errorf("failed to write SBOM: %w", err)
+			}
+			if err := file.Close(); err != nil {
+				return fmt.Errorf("failed to close file: %w", err)
+			}
+
+			addRunOutput(run, filename, opts.Kind+"-"+opts.Format)
+			if filename != stdoutPath {
+				fmt.Fprintf(g.console(), "SBOM saved to: %s\n", filename)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&projectUUID, "project_uuid", "", "UUID of the project")
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository URL of the project, e.g. github.com/org/repo")
+	cmd.Flags().StringVar(&packageVersion, "package-version", "", "Package version to export, e.g. npm://my-app@1.0.0 (needed when the project has several)")
+	cmd.Flags().StringVar(&opts.Kind, "format", api.SBOMCycloneDX, "SBOM standard: cyclonedx or spdx")
+	cmd.Flags().StringVar(&opts.Format, "encoding", api.SBOMFormatJSON, "Document encoding: json or xml (xml is CycloneDX only)")
+	return cmd
+}
+
+// sbomFilename returns the timestamped default file name for the SBOM of packageVersion
+func sbomFilename(packageVersion string, opts api.SBOMExportOptions) string {
+	timestamp := time.Now().Format("2006-01-02_15-04-05")
+	return fmt.Sprintf("sbom_%s_%s.%s.%s", sbomSlug(packageVersion), timestamp, opts.Kind, opts.Format)
+}
+
+// sbomSlug names the SBOM of packageVersion in file names:
+// npm://@acme/payments@1.4.0 becomes payments-1.4.0
+func sbomSlug(packageVersion string) string {
+	name := path.Base(packageVersion[strings.Index(packageVersion, "://")+1:])
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newScansCmd(g *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scans",
+		Short: "Inspect the scan history of projects",
+	}
+
+	cmd.AddCommand(newScansListCmd(g))
+	return cmd
+}
+
+func newScansListCmd(g *globalOptions) *cobra.Command {
+	var projectUUID, repo, format string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the scans of a project, newest first, with their status and scanner versions",
+		Long: `List the scans of a project, newest first, with their status, duration,
+scanner versions and errors, and when it was last scanned successfully, to
+confirm findings are current before trusting them.`,
+		Example: `  findings-api scans list --repo github.com/acme/payments
+  findings-api scans list --project_uuid abc123-def456-ghi789 --limit 0 --format json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if projectUUID == "" && repo == "" {
+				return errors.New("either --project_uuid or --repo is required")
+			}
+			if format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q (expected table or json)", format)
+			}
+
+			ctx := cmd.Context()
+			client, token, err := g.authenticate(ctx)
+			if err != nil {
+				return err
+			}
+			if projectUUID == "" {
+				p, err := client.FindProjectByRepo(ctx, token, repo)
+				if err != nil {
+					return fmt.Errorf("failed to resolve --repo: %w", err)
+				}
+				projectUUID = p.UUID
+			}
+
+			slog.Info("Fetching scan history", "project_uuid", projectUUID)
+			scans, err := client.ListScanResults(ctx, token, projectUUID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch scan results: %w", err)
+			}
+			last := api.LastSuccessfulScan(scans)
+			if limit > 0 && len(scans) > limit {
+				scans = scans[:limit]
+			}
+			if format == "json" {
+				return printJSON(scans)
+			}
+			return printScans(os.Stdout, scans, last, time.Now())
+		},
+	}
+
+	cmd.Flags().StringVar(&projectUUID, "project_uuid", "", "UUID of the project")
+	cmd.Flags().StringVar(&repo, "repo", "", "Repository URL of the project, e.g. github.com/org/repo")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Number of most recent scans to list (0 lists them all)")
+	cmd.Flags().StringVar(&format, "format", "table", "Output format (table or json)")
+	return cmd
+}
+
+// printScans prints scans as a table followed by when the project was last
+// scanned successfully
+func printScans(w io.Writer, scans []api.ScanResult, last *api.ScanResult, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STARTED\tDURATION\tSTATUS\tTYPE\tCONTEXT\tVERSIONS\tERRORS")
+	for _, s := range scans {
+		started := ""
+		if !s.Spec.StartTime.IsZero() {
+			started = s.Spec.StartTime.Local().Format("2006-01-02 15:04")
+		}
+		duration := ""
+		if d := s.Duration(); d > 0 {
+			duration = d.Round(time.Second).String()
+		}
+		scanned := strings.TrimPrefix(s.Context.Type, "CONTEXT_TYPE_")
+		if s.Context.ID != "" {
+			scanned += ":" + s.Context.ID
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", started, duration, s.Spec.Status.Short(),
+			strings.ToLower(strings.TrimPrefix(s.Spec.Type, "SCAN_TYPE_")), strings.ToLower(scanned), scanVersions(s.Spec.Versions), strings.Join(s.Spec.Errors, "; "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if last == nil {
+		fmt.Fprintf(w, "\n%d scans; no successful scan found\n", len(scans))
+		return nil
+	}
+	fmt.Fprintf(w, "\n%d scans; last successful scan %s (%s ago)\n", len(scans),
+		last.Spec.StartTime.Local().Format("2006-01-02 15:04"), now.Sub(last.Spec.StartTime).Round(time.Minute))
+	return nil
+}
+
+// scanVersions returns the scanner versions as name=version pairs sorted by name
+func scanVersions(versions map[string]string) string {
+	pairs := make([]string, 0, len(versions))
+	for name, version := range versions {
+		pairs = append(pairs, name+"="+version)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,506 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/config"
+	"github.com/endor-labs/findings-api/internal/grpcapi"
+	"github.com/endor-labs/findings-api/internal/metrics"
+	"github.com/endor-labs/findings-api/internal/prefetch"
+	"github.com/spf13/cobra"
+)
+
+// serveOptions holds the flags of the serve command
+type serveOptions struct {
+	Addr            string
+	GRPCAddr        string
+	Namespaces      []string
+	RefreshInterval time.Duration
+	Jitter          float64
+	MaxStaleness    time.Duration
+	MinGap          time.Duration
+	FullRefresh     time.Duration
+	WatchConfig     time.Duration
+	Findings        findingsOptions
+}
+
+// serveSettings are the parts of the configuration a running server reloads
+type serveSettings struct {
+	profile config.Profile
+	filter  string
+}
+
+// serveState holds the current settings, swapped as a whole on reload, and
+// whether each served namespace could be read on its last refresh
+type serveState struct {
+	mu       sync.Mutex
+	settings serveSettings
+	access   *api.AccessReport
+	metrics  *metrics.Collector
+}
+
+func (s *serveState) get() serveSettings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.settings
+}
+
+func (s *serveState) set(settings serveSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings = settings
+}
+
+// findingsResponse is the body of GET /findings and GET /projects/{uuid}/findings
+type findingsResponse struct {
+	Namespace string             `json:"namespace"`
+	Project   string             `json:"project_uuid,omitempty"`
+	Freshness prefetch.Freshness `json:"freshness"`
+	Count     int                `json:"count"`
+	Findings  []api.Finding      `json:"findings"`
+}
+
+func newServeCmd(g *globalOptions) *cobra.Command {
+	opts := &serveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve findings over HTTP, refreshing them in the background",
+		Example: `  findings-api serve --addr :8080 --namespaces acme.payments,acme.billing
+  curl localhost:8080/findings?namespace=acme.payments
+  curl localhost:8080/projects/6650a0000000000000000001/findings?namespace=acme.payments
+  findings-api serve --addr :8080 --grpc-addr :9090`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// A server always fetches every project of its namespaces
+			opts.Findings.AllProjects = true
+			filter, err := opts.Findings.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			namespaces := opts.Namespaces
+			if len(namespaces) == 0 {
+				namespaces = []string{g.namespace()}
+			}
+			for _, ns := range namespaces {
+				// Fail early on missing credentials rather than on every refresh
+				if _, err := g.newNamespaceClient(ns); err != nil {
+					return err
+				}
+			}
+
+			state := &serveState{settings: serveSettings{profile: g.profile, filter: filter}, access: api.NewAccessReport(), metrics: metrics.New()}
+			fetch := recordServeMetrics(newServeFetch(g, state, opts.FullRefresh), state.metrics)
+			refresher := prefetch.New(trackServeAccess(fetch, state.access), opts.schedule())
+			for _, ns := range namespaces {
+				refresher.Track(ns)
+			}
+			go refresher.Run(ctx)
+
+			if opts.WatchConfig > 0 && g.configPath != "" {
+				go config.Watch(ctx, g.configPath, opts.WatchConfig, func(file *config.File) error {
+					return reloadServeConfig(g, cmd, opts, state, refresher, file)
+				}, func(err error) {
+					slog.Warn("Keeping the previous configuration", "error", err)
+				})
+			}
+
+			defaultNamespace := func() string {
+				if len(opts.Namespaces) > 0 {
+					return opts.Namespaces[0]
+				}
+				return profileNamespace(g, state.get().profile)
+			}
+			if opts.GRPCAddr != "" {
+				if err := serveGRPC(ctx, opts.GRPCAddr, grpcapi.NewService(refresher, defaultNamespace, newServeProjects(g, state))); err != nil {
+					return err
+				}
+			}
+
+			server := &http.Server{
+				Addr:              opts.Addr,
+				Handler:           newServeHandler(refresher, state.access, state.metrics, defaultNamespace),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				server.Shutdown(shutdownCtx)
+			}()
+
+			slog.Info("Serving findings", "namespaces", len(namespaces), "addr", opts.Addr, "refresh", opts.RefreshInterval)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("server failed: %w", err)
+			}
+			logAccessReport(state.access)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Addr, "addr", ":8080", "Address to listen on")
+	cmd.Flags().StringVar(&opts.GRPCAddr, "grpc-addr", "", "Address to serve the gRPC findings API on too, e.g. :9090")
+	cmd.Flags().StringSliceVar(&opts.Namespaces, "namespaces", nil, "Namespaces to keep fresh (default: the --namespace in use)")
+	cmd.Flags().DurationVar(&opts.RefreshInterval, "refresh-interval", 5*time.Minute, "Target time between refreshes of each namespace")
+	cmd.Flags().Float64Var(&opts.Jitter, "refresh-jitter", 0.2, "Fraction of the refresh interval each refresh is randomly moved by (0 to 1)")
+	cmd.Flags().DurationVar(&opts.MaxStaleness, "max-staleness", 0, "Age after which a namespace's data is reported as stale (default: twice the refresh interval)")
+	cmd.Flags().DurationVar(&opts.MinGap, "min-fetch-gap", 10*time.Second, "Minimum time between the start of any two refreshes")
+	cmd.Flags().DurationVar(&opts.FullRefresh, "full-refresh", time.Hour, "How often a namespace is fully refetched instead of fetching only updated findings")
+	addWatchConfigFlag(cmd.Flags(), &opts.WatchConfig)
+	opts.Findings.addFilterFlags(cmd.Flags())
+	return cmd
+}
+
+// schedule returns the refresh schedule set by the flags
+func (o *serveOptions) schedule() prefetch.Options {
+	return prefetch.Options{
+		Interval:     o.RefreshInterval,
+		Jitter:       o.Jitter,
+		MaxStaleness: o.MaxStaleness,
+		MinGap:       o.MinGap,
+	}
+}
+
+// serveGRPC serves svc over gRPC on addr until ctx is done
+func serveGRPC(ctx context.Context, addr string, svc *grpcapi.Service) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to serve gRPC: %w", err)
+	}
+	server := grpcapi.NewServer(svc)
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			slog.Warn("gRPC server failed", "error", err)
+		}
+	}()
+	slog.Info("Serving gRPC findings API", "addr", listener.Addr().String())
+	return nil
+}
+
+// newServeProjects returns the project listing of the gRPC API, which asks the
+// API with the current profile's credentials as projects are not kept in memory
+func newServeProjects(g *globalOptions, state *serveState) grpcapi.ProjectsFunc {
+	return func(ctx context.Context, namespace, name string) ([]api.Project, error) {
+		client, err := g.newProfileClient(state.get().profile, namespace)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { state.metrics.ObserveRequests(client.FetchReport().Requests) }()
+		token, err := g.token(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		return client.ListProjects(ctx, token, api.ProjectListOptions{Name: name, Mask: "*"})
+	}
+}
+
+// newServeFetch returns the refresh function for serve. Between full refreshes
+// only findings updated since the previous fetch are requested and merged into
+// the previous data; a periodic full refresh drops findings that were resolved.
+func newServeFetch(g *globalOptions, state *serveState, fullRefresh time.Duration) prefetch.FetchFunc[[]api.Finding] {
+	var mu sync.Mutex
+	lastFull := make(map[string]time.Time)
+	lastFilter := make(map[string]string)
+
+	return func(ctx context.Context, namespace string, prev []api.Finding, since time.Time) ([]api.Finding, error) {
+		settings := state.get()
+		filter := settings.filter
+
+		// A fresh client per refresh keeps the fetch report and warnings from
+		// growing for the lifetime of the server
+		client, err := g.newProfileClient(settings.profile, namespace)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { state.metrics.ObserveRequests(client.FetchReport().Requests) }()
+		token, err := g.token(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		mu.Lock()
+		// Data fetched with a previous filter must be replaced as a whole
+		full := since.IsZero() || start.Sub(lastFull[namespace]) >= fullRefresh || lastFilter[namespace] != filter
+		mu.Unlock()
+
+		if full {
+			findings, err := client.GetFindingsForAllProjects(ctx, token, filter)
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			lastFull[namespace] = start
+			lastFilter[namespace] = filter
+			mu.Unlock()
+			slog.Info("Refreshed findings", "namespace", namespace, "findings", len(findings))
+			return findings, nil
+		}
+
+		updatedFilter := fmt.Sprintf("meta.update_time >= date(%s)", since.UTC().Format(time.RFC3339))
+		if filter != "" {
+			updatedFilter = "(" + filter + ") and " + updatedFilter
+		}
+		updated, err := client.GetFindingsForAllProjects(ctx, token, updatedFilter)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Refreshed updated findings", "namespace", namespace, "updated", len(updated))
+		return mergeFindings(prev, updated), nil
+	}
+}
+
+// reloadServeConfig validates a changed configuration file and applies its
+// credentials, namespace, filters and schedule to the running server. Nothing
+// changes when the file is invalid.
+func reloadServeConfig(g *globalOptions, cmd *cobra.Command, opts *serveOptions, state *serveState, refresher *prefetch.Refresher[[]api.Finding], file *config.File) error {
+	profile, err := g.selectProfile(cmd, file)
+	if err != nil {
+		return err
+	}
+
+	prevFilter, prevSchedule := opts.Findings.Filter, opts.schedule()
+	restore := func() {
+		opts.Findings.Filter = prevFilter
+		opts.RefreshInterval, opts.Jitter = prevSchedule.Interval, prevSchedule.Jitter
+		opts.MaxStaleness, opts.MinGap = prevSchedule.MaxStaleness, prevSchedule.MinGap
+	}
+	if err := applyProfileFilters(cmd.Flags(), profile.Filters); err != nil {
+		restore()
+		return err
+	}
+	if err := applyProfileSchedule(cmd.Flags(), profile.Schedule); err != nil {
+		restore()
+		return err
+	}
+	filter, err := opts.Findings.buildFilter()
+	if err != nil {
+		restore()
+		return err
+	}
+
+	// Without --namespaces the server follows the profile's namespace
+	prevNamespace := profileNamespace(g, state.get().profile)
+	namespace := profileNamespace(g, profile)
+	if _, err := g.newProfileClient(profile, namespace); err != nil {
+		restore()
+		return err
+	}
+
+	state.set(serveSettings{profile: profile, filter: filter})
+	if schedule := opts.schedule(); schedule != prevSchedule {
+		refresher.SetOptions(schedule)
+		slog.Info("Refresh schedule changed", "refresh", schedule.Interval, "min_fetch_gap", schedule.MinGap)
+	}
+	if len(opts.Namespaces) == 0 && namespace != prevNamespace {
+		refresher.Track(namespace)
+		refresher.Untrack(prevNamespace)
+		state.access.Forget(prevNamespace)
+		state.metrics.Forget(prevNamespace)
+		slog.Info("Configuration reloaded", "namespace", namespace)
+		return nil
+	}
+	slog.Info("Configuration reloaded")
+	return nil
+}
+
+// profileNamespace returns the namespace selected by the flags, the environment or profile
+func profileNamespace(g *globalOptions, profile config.Profile) string {
+	return firstNonEmpty(g.Namespace, os.Getenv("ENDOR_API_NAMESPACE"), profile.Namespace)
+}
+
+// mergeFindings returns prev with each finding in updated replacing the finding
+// with the same UUID, or appended when it is new
+func mergeFindings(prev, updated []api.Finding) []api.Finding {
+	index := make(map[string]int, len(prev))
+	merged := make([]api.Finding, len(prev), len(prev)+len(updated))
+	copy(merged, prev)
+	for i, f := range merged {
+		index[f.UUID] = i
+	}
+	for _, f := range updated {
+		if i, ok := index[f.UUID]; ok {
+			merged[i] = f
+			continue
+		}
+		index[f.UUID] = len(merged)
+		merged = append(merged, f)
+	}
+	return merged
+}
+
+// recordServeMetrics updates the findings gauges of a namespace after each
+// successful refresh of fetch
+func recordServeMetrics(fetch prefetch.FetchFunc[[]api.Finding], collector *metrics.Collector) prefetch.FetchFunc[[]api.Finding] {
+	return func(ctx context.Context, namespace string, prev []api.Finding, since time.Time) ([]api.Finding, error) {
+		findings, err := fetch(ctx, namespace, prev, since)
+		if err == nil {
+			collector.SetFindings(namespace, findings, time.Now())
+		}
+		return findings, err
+	}
+}
+
+// trackServeAccess records whether each refresh of fetch could read its
+// namespace. A namespace the credentials cannot read is logged when its access
+// changes and keeps being retried, without holding up the other namespaces.
+func trackServeAccess(fetch prefetch.FetchFunc[[]api.Finding], access *api.AccessReport) prefetch.FetchFunc[[]api.Finding] {
+	return func(ctx context.Context, namespace string, prev []api.Finding, since time.Time) ([]api.Finding, error) {
+		findings, err := fetch(ctx, namespace, prev, since)
+		if ctx.Err() != nil {
+			return findings, err
+		}
+		before, seen := access.Status(namespace)
+		after := access.Record(namespace, err)
+		switch {
+		case after == before:
+		case after != api.AccessOK:
+			slog.Warn("Namespace cannot be read; serving the other namespaces", "namespace", namespace, "status", after, "error", err)
+		case seen:
+			slog.Info("Namespace can be read again", "namespace", namespace)
+		}
+		return findings, err
+	}
+}
+
+// newServeHandler routes the serve endpoints. Requests without a namespace
+// parameter are answered for the namespace defaultNamespace returns.
+func newServeHandler(refresher *prefetch.Refresher[[]api.Finding], access *api.AccessReport, collector *metrics.Collector, defaultNamespace func() string) http.Handler {
+	mux := http.NewServeMux()
+
+	// snapshot returns the ready data of the request's namespace, or writes the
+	// error response and returns false
+	snapshot := func(w http.ResponseWriter, r *http.Request) (string, prefetch.Snapshot[[]api.Finding], bool) {
+		namespace := firstNonEmpty(r.URL.Query().Get("namespace"), defaultNamespace())
+		snap, ok := refresher.Get(namespace)
+		if !ok {
+			http.Error(w, fmt.Sprintf("namespace %q is not served", namespace), http.StatusNotFound)
+			return namespace, snap, false
+		}
+
+		f := snap.Freshness
+		w.Header().Set("X-Data-Stale", fmt.Sprint(f.Stale))
+		if !f.Ready {
+			w.Header().Set("Retry-After", "30")
+			writeServeJSON(w, http.StatusServiceUnavailable, f)
+			return namespace, snap, false
+		}
+		w.Header().Set("X-Data-Age", fmt.Sprint(int(f.Age.Seconds())))
+		return namespace, snap, true
+	}
+
+	mux.HandleFunc("/findings", func(w http.ResponseWriter, r *http.Request) {
+		namespace, snap, ok := snapshot(w, r)
+		if !ok {
+			return
+		}
+		writeServeJSON(w, http.StatusOK, findingsResponse{
+			Namespace: namespace,
+			Freshness: snap.Freshness,
+			Count:     len(snap.Data),
+			Findings:  snap.Data,
+		})
+	})
+
+	// Project findings are answered from the namespace's data, so dashboards
+	// polling many projects cost no API requests
+	mux.HandleFunc("/projects/", func(w http.ResponseWriter, r *http.Request) {
+		project, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/projects/"), "/")
+		if project == "" || rest != "findings" {
+			http.NotFound(w, r)
+			return
+		}
+		namespace, snap, ok := snapshot(w, r)
+		if !ok {
+			return
+		}
+		findings := []api.Finding{}
+		for _, f := range snap.Data {
+			if f.Spec.ProjectUUID == project {
+				findings = append(findings, f)
+			}
+		}
+		writeServeJSON(w, http.StatusOK, findingsResponse{
+			Namespace: namespace,
+			Project:   project,
+			Freshness: snap.Freshness,
+			Count:     len(findings),
+			Findings:  findings,
+		})
+	})
+
+	mux.HandleFunc("/freshness", func(w http.ResponseWriter, r *http.Request) {
+		writeServeJSON(w, http.StatusOK, refresher.Freshness())
+	})
+
+	mux.HandleFunc("/access", func(w http.ResponseWriter, r *http.Request) {
+		writeServeJSON(w, http.StatusOK, access.Namespaces())
+	})
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metrics.ContentType)
+		writeFreshnessMetrics(w, refresher.Freshness())
+		collector.Write(w)
+	})
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	return mux
+}
+
+// writeServeJSON writes v as the JSON body of a response with status
+func writeServeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Failed to write the response", "error", err)
+	}
+}
+
+// writeFreshnessMetrics writes the freshness of each namespace in the
+// Prometheus text exposition format
+func writeFreshnessMetrics(w io.Writer, all []prefetch.Freshness) {
+	metric := func(name, kind, help string, value func(prefetch.Freshness) float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, f := range all {
+			fmt.Fprintf(w, "%s{namespace=%q} %g\n", name, f.Key, value(f))
+		}
+	}
+	boolValue := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	metric("findings_api_data_age_seconds", "gauge", "Age of the data served for the namespace",
+		func(f prefetch.Freshness) float64 { return f.Age.Seconds() })
+	metric("findings_api_data_ready", "gauge", "Whether the namespace has been fetched at least once",
+		func(f prefetch.Freshness) float64 { return boolValue(f.Ready) })
+	metric("findings_api_data_stale", "gauge", "Whether the namespace's data is older than the maximum staleness",
+		func(f prefetch.Freshness) float64 { return boolValue(f.Stale) })
+	metric("findings_api_refreshes_total", "counter", "Successful refreshes of the namespace",
+		func(f prefetch.Freshness) float64 { return float64(f.Refreshes) })
+	metric("findings_api_refresh_failures_total", "counter", "Failed refreshes of the namespace",
+		func(f prefetch.Freshness) float64 { return float64(f.Failures) })
+}
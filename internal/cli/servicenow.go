@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/links"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/servicenow"
+	"github.com/endor-labs/findings-api/internal/tickets"
+	"github.com/spf13/cobra"
+)
+
+// serviceNowOptions are the ServiceNow flags; unset flags fall back to the
+// profile's servicenow section and the environment
+type serviceNowOptions struct {
+	URL                 string
+	RecordType          string
+	Table               string
+	AssignmentGroup     string
+	Category            string
+	MinLevel            string
+	SummaryTemplate     string
+	DescriptionTemplate string
+}
+
+func newServiceNowCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	so := &serviceNowOptions{}
+	var linksFile, uiURL string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "servicenow",
+		Short: "Create or update a ServiceNow incident or vulnerability record per critical finding",
+		Long: `Create a ServiceNow incident, or a Vulnerability Response vulnerable item with
+--record-type vulnerability, for each finding at or above --min-level
+(default critical), and update the records created by earlier runs.
+
+Records carry the finding UUID as their correlation ID, so reruns on any
+machine update the finding's active record instead of opening another one;
+a finding whose record was closed gets a new one if it comes back. The
+finding level sets the impact and urgency ServiceNow derives the priority
+from: critical findings are P1, high P2, medium P3 and low P4. Descriptions
+end with the finding's metadata: project, package, advisories, CVSS, EPSS,
+reachability, fix and a link to the finding in the Endor Labs app.
+
+Credentials come from the profile's servicenow section, or SERVICENOW_USERNAME
+and SERVICENOW_PASSWORD, or an OAuth token in SERVICENOW_TOKEN.`,
+		Example: `  findings-api integrations servicenow --all-projects --servicenow-url https://acme.service-now.com --assignment-group "App Security"
+  findings-api integrations servicenow --repo github.com/acme/payments --record-type vulnerability --min-level high --dry-run`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := g.requireNetwork("the ServiceNow integration"); err != nil {
+				return err
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if err := g.validateNamespaces(opts); err != nil {
+				return err
+			}
+			cfg, minLevel, templates, err := so.resolve(g)
+			if err != nil {
+				return err
+			}
+			sc, err := servicenow.NewClient(cfg)
+			if err != nil {
+				return err
+			}
+			store, err := openLinks(linksFile)
+			if err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			fetched, err := g.fetchSelected(ctx, opts, filter)
+			if err != nil {
+				return err
+			}
+			logFetchReport(fetched.Report)
+			logWarnings(fetched.Warnings)
+
+			var selected []api.Finding
+			for _, f := range fetched.Findings {
+				if f.Spec.Level.AtLeast(minLevel) {
+					selected = append(selected, f)
+				}
+			}
+			ts, err := tickets.Group(selected, tickets.ByFinding)
+			if err != nil {
+				return err
+			}
+
+			uiURL = firstNonEmpty(uiURL, os.Getenv("ENDOR_UI_URL"))
+			namespace := g.namespace()
+			results := make([]syncResult, 0, len(ts))
+			failed := 0
+			for _, t := range ts {
+				link := output.FindingURL(uiURL, namespace, t.Findings[0])
+				res := syncServiceNowRecord(ctx, sc, store, templates, t, link, dryRun)
+				if res.Error != "" {
+					failed++
+				}
+				results = append(results, res)
+			}
+
+			if err := printSyncResults(results); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d records could not be synced", failed, len(results))
+			}
+			return opts.checkFailOn(fetched.Findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	g.addNamespacesFlag(cmd.Flags())
+	cmd.Flags().StringVar(&so.URL, "servicenow-url", "", "ServiceNow instance URL, e.g. https://acme.service-now.com (default: the profile's servicenow.url or $SERVICENOW_URL)")
+	cmd.Flags().StringVar(&so.RecordType, "record-type", "", "Records to create: incident or vulnerability (default: incident)")
+	cmd.Flags().StringVar(&so.Table, "table", "", "Table records are created in (default: "+servicenow.IncidentTable+" or "+servicenow.VulnerabilityTable+")")
+	cmd.Flags().StringVar(&so.AssignmentGroup, "assignment-group", "", "Assignment group of the records, by name or sys_id")
+	cmd.Flags().StringVar(&so.Category, "category", "", "Category of the records")
+	cmd.Flags().StringVar(&so.MinLevel, "min-level", "", "Lowest finding level records are created for (default: critical)")
+	cmd.Flags().StringVar(&so.SummaryTemplate, "summary-template", "", "Go template for record short descriptions")
+	cmd.Flags().StringVar(&so.DescriptionTemplate, "description-template", "", "Go template for record descriptions, followed by the finding metadata")
+	cmd.Flags().StringVar(&uiURL, "ui-url", "", "Endor Labs app URL that records link findings to (default: $ENDOR_UI_URL or "+output.DefaultUIURL+")")
+	cmd.Flags().StringVar(&linksFile, "links-file", "", "Link store file (default: $ENDOR_LINKS_FILE or ~/.endor/links.json)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show which records would be created or updated without changing ServiceNow")
+	return cmd
+}
+
+// resolve merges the flags with the profile's servicenow section and the environment
+func (so *serviceNowOptions) resolve(g *globalOptions) (servicenow.Config, api.FindingLevel, *tickets.Templates, error) {
+	p := g.profile.ServiceNow
+	cfg := servicenow.Config{
+		URL:             firstNonEmpty(so.URL, os.ExpandEnv(p.URL), os.Getenv("SERVICENOW_URL")),
+		Username:        firstNonEmpty(os.ExpandEnv(p.Username), os.Getenv("SERVICENOW_USERNAME")),
+		Password:        firstNonEmpty(os.ExpandEnv(p.Password), os.Getenv("SERVICENOW_PASSWORD")),
+		Token:           firstNonEmpty(os.ExpandEnv(p.Token), os.Getenv("SERVICENOW_TOKEN")),
+		RecordType:      firstNonEmpty(so.RecordType, p.RecordType),
+		Table:           firstNonEmpty(so.Table, p.Table),
+		AssignmentGroup: firstNonEmpty(so.AssignmentGroup, p.AssignmentGroup),
+		Category:        firstNonEmpty(so.Category, p.Category),
+		Fields:          p.Fields,
+	}
+
+	minLevel, err := api.ParseFindingLevel(firstNonEmpty(so.MinLevel, p.MinLevel, "critical"))
+	if err != nil {
+		return servicenow.Config{}, "", nil, fmt.Errorf("invalid --min-level: %w", err)
+	}
+
+	templates, err := tickets.ParseTemplates(
+		firstNonEmpty(so.SummaryTemplate, p.SummaryTemplate),
+		firstNonEmpty(so.DescriptionTemplate, p.DescriptionTemplate),
+	)
+	if err != nil {
+		return servicenow.Config{}, "", nil, err
+	}
+	return cfg, minLevel, templates, nil
+}
+
+// syncServiceNowRecord creates or updates the record of t's finding and links
+// the finding to it
+func syncServiceNowRecord(ctx context.Context, sc *servicenow.Client, store links.Store, templates *tickets.Templates, t tickets.Ticket, link string, dryRun bool) syncResult {
+	res := syncResult{Ticket: t.ID, Findings: len(t.Findings)}
+	fail := func(err error) syncResult {
+		res.Action = "failed"
+		res.Error = err.Error()
+		return res
+	}
+
+	summary, description, err := templates.Render(t)
+	if err != nil {
+		return fail(err)
+	}
+	f := t.Findings[0]
+	fields := servicenow.RecordFields{
+		ShortDescription: summary,
+		Description:      description + "\n\n" + servicenow.Details(f, link),
+		CorrelationID:    f.UUID,
+		Level:            f.Spec.Level,
+	}
+
+	rec, found, err := sc.FindByCorrelationID(ctx, f.UUID)
+	if err != nil {
+		return fail(err)
+	}
+
+	switch {
+	case dryRun && found:
+		res.Action, res.Issue = "would update", rec.Number
+		return res
+	case dryRun:
+		res.Action = "would create (P" + servicenow.Priority(f.Spec.Level) + ")"
+		return res
+	case found:
+		if rec, err = sc.Update(ctx, rec.SysID, fields); err != nil {
+			return fail(err)
+		}
+		res.Action = "updated"
+	default:
+		if rec, err = sc.Create(ctx, fields); err != nil {
+			return fail(err)
+		}
+		res.Action = "created"
+	}
+	res.Issue = rec.Number
+
+	if err := store.Set(f.UUID, links.Link{System: links.SystemServiceNow, ID: rec.Number, URL: rec.URL}); err != nil {
+		return fail(fmt.Errorf("record %s synced but not recorded: %w", rec.Number, err))
+	}
+	return res
+}
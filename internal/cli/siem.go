@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/endor-labs/findings-api/internal/config"
+	"github.com/endor-labs/findings-api/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+// siemOptions are the SIEM flags; unset flags fall back to the profile's siem
+// section and the environment
+type siemOptions struct {
+	SplunkURL  string
+	Syslog     string
+	EventsURL  string
+	Index      string
+	Sourcetype string
+	Source     string
+	Host       string
+	AppName    string
+	Headers    map[string]string
+}
+
+func newSIEMCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	so := &siemOptions{}
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "siem",
+		Short: "Forward findings as events to Splunk, a syslog server or an HTTPS collector",
+		Long: `Send each finding as an event to a SIEM, so SOC teams can correlate Endor
+Labs findings with the rest of their security data. Events are flat JSON
+documents with the finding's UUID, project, level, package, advisories, CVSS,
+EPSS, reachability and fix.
+
+The destination is one of:
+  --splunk-url   a Splunk HTTP Event Collector, with the token in $SPLUNK_HEC_TOKEN
+  --syslog       a syslog server at udp://, tcp:// or tls://host:port, sent RFC 5424 messages
+  --events-url   any HTTPS endpoint, posted the events as NDJSON
+
+or the profile's siem section, which takes the same settings as a
+notification sink.`,
+		Example: `  findings-api integrations siem --all-projects --splunk-url https://splunk.acme.com:8088 --index security --sourcetype endor:finding
+  findings-api integrations siem --all-projects --syslog tls://siem.acme.com:6514
+  findings-api integrations siem --repo github.com/acme/payments --events-url https://intake.acme.com/endor --header "Authorization=Bearer $TOKEN"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if err := g.validateNamespaces(opts); err != nil {
+				return err
+			}
+			sink, err := so.resolve(g)
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err := g.requireNetwork("forwarding to a SIEM"); err != nil {
+					return err
+				}
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			ctx := cmd.Context()
+			fetched, err := g.fetchSelected(ctx, opts, filter)
+			if err != nil {
+				return err
+			}
+			logFetchReport(fetched.Report)
+			logWarnings(fetched.Warnings)
+
+			if dryRun {
+				fmt.Printf("Dry run: %d findings would be sent\n\n", len(fetched.Findings))
+				return printNotifyResults([]notify.Result{{Sink: sink.Name(), Findings: len(fetched.Findings)}})
+			}
+			if len(fetched.Findings) == 0 {
+				fmt.Println("No findings to send")
+				return opts.checkFailOn(fetched.Findings)
+			}
+
+			res := notify.Result{Sink: sink.Name(), Findings: len(fetched.Findings)}
+			if err := sink.Send(ctx, fetched.Findings); err != nil {
+				res.Error = err.Error()
+			}
+			if err := printNotifyResults([]notify.Result{res}); err != nil {
+				return err
+			}
+			if res.Error != "" {
+				return errors.New("forwarding to " + sink.Name() + " failed")
+			}
+			return opts.checkFailOn(fetched.Findings)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	opts.addFailOnFlag(cmd.Flags())
+	g.addNamespacesFlag(cmd.Flags())
+	cmd.Flags().StringVar(&so.SplunkURL, "splunk-url", "", "Splunk HTTP Event Collector URL, e.g. https://splunk.acme.com:8088")
+	cmd.Flags().StringVar(&so.Syslog, "syslog", "", "Syslog server address: udp://, tcp:// or tls://host:port")
+	cmd.Flags().StringVar(&so.EventsURL, "events-url", "", "HTTPS endpoint that receives the events as NDJSON")
+	cmd.Flags().StringVar(&so.Index, "index", "", "Splunk index of the events (default: the token's default index)")
+	cmd.Flags().StringVar(&so.Sourcetype, "sourcetype", "", "Splunk sourcetype of the events (default: "+notify.DefaultSourcetype+")")
+	cmd.Flags().StringVar(&so.Source, "source", "", "Splunk source of the events (default: "+notify.DefaultSource+")")
+	cmd.Flags().StringVar(&so.Host, "host", "", "Splunk host of the events, or the HOSTNAME of syslog messages (default: the local host name for syslog)")
+	cmd.Flags().StringVar(&so.AppName, "app-name", "", "APP-NAME of syslog messages (default: "+notify.DefaultAppName+")")
+	cmd.Flags().StringToStringVar(&so.Headers, "header", nil, "Header added to --events-url requests, as name=value (repeatable)")
+	cmd.MarkFlagsMutuallyExclusive("splunk-url", "syslog", "events-url")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show how many findings would be sent without sending anything")
+	return cmd
+}
+
+// resolve builds the sink from the flags, or the profile's siem section with
+// the flags overriding its settings
+func (so *siemOptions) resolve(g *globalOptions) (notify.Sink, error) {
+	sc := g.profile.SIEM
+	switch {
+	case so.SplunkURL != "":
+		sc = config.Sink{Type: notify.TypeSplunk, URL: so.SplunkURL, Token: sc.Token, Index: sc.Index, Sourcetype: sc.Sourcetype, Source: sc.Source, Host: sc.Host}
+	case so.Syslog != "":
+		sc = config.Sink{Type: notify.TypeSyslog, Address: so.Syslog, AppName: sc.AppName, Host: sc.Host}
+	case so.EventsURL != "":
+		sc = config.Sink{Type: notify.TypeEvents, URL: so.EventsURL, Headers: sc.Headers}
+	case sc.Type == "":
+		return nil, errors.New("no SIEM configured: set --splunk-url, --syslog, --events-url or the profile's siem section")
+	}
+
+	sc.Index = firstNonEmpty(so.Index, sc.Index)
+	sc.Sourcetype = firstNonEmpty(so.Sourcetype, sc.Sourcetype)
+	sc.Source = firstNonEmpty(so.Source, sc.Source)
+	sc.Host = firstNonEmpty(so.Host, sc.Host)
+	sc.AppName = firstNonEmpty(so.AppName, sc.AppName)
+	if len(so.Headers) > 0 {
+		headers := make(map[string]string, len(sc.Headers)+len(so.Headers))
+		for k, v := range sc.Headers {
+			headers[k] = v
+		}
+		for k, v := range so.Headers {
+			headers[k] = v
+		}
+		sc.Headers = headers
+	}
+	if sc.Type == notify.TypeSplunk {
+		sc.Token = firstNonEmpty(os.ExpandEnv(sc.Token), os.Getenv("SPLUNK_HEC_TOKEN"))
+	}
+
+	sink, err := notify.NewSink(sc.Type, sc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SIEM settings: %w", err)
+	}
+	return sink, nil
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/filter"
+	"github.com/endor-labs/findings-api/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// Outcomes of simulating an upgrade for a single finding
+const (
+	upgradeResolved  = "resolved"
+	upgradeRemaining = "remaining"
+	upgradeUnknown   = "unknown"
+)
+
+// upgradeOutcome is the simulated effect of an upgrade on one finding
+type upgradeOutcome struct {
+	UUID           string           `json:"uuid"`
+	Name           string           `json:"name"`
+	Level          api.FindingLevel `json:"level"`
+	ProjectUUID    string           `json:"project_uuid"`
+	CurrentVersion string           `json:"current_version"`
+	FixedVersion   string           `json:"fixed_version,omitempty"`
+	Outcome        string           `json:"outcome"`
+}
+
+// upgradeSimulation is the report produced by simulate-upgrade
+type upgradeSimulation struct {
+	Timestamp string           `json:"timestamp"`
+	Package   string           `json:"package"`
+	ToVersion string           `json:"to_version"`
+	Resolved  int              `json:"resolved"`
+	Remaining int              `json:"remaining"`
+	Unknown   int              `json:"unknown"`
+	Findings  []upgradeOutcome `json:"findings"`
+	Warnings  []api.Warning    `json:"warnings,omitempty"`
+}
+
+func newSimulateUpgradeCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var pkg, toVersion string
+
+	cmd := &cobra.Command{
+		Use:   "simulate-upgrade",
+		Short: "Report which findings an upgrade of a package would resolve",
+		Long: `Fetch the findings for a package and compare the target version against the
+fixed version Endor Labs proposes for each finding. Findings without a known
+fixed version are reported as unknown.`,
+		Example: `  findings-api simulate-upgrade --project_uuid abc123 --package lodash --to-version 4.17.21`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pkg == "" || toVersion == "" {
+				return errors.New("--package and --to-version are required")
+			}
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			base, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+			filterExpr, err := filter.New().
+				Where(base).
+				Equals("spec.target_dependency_name", pkg).
+				Build()
+			if err != nil {
+				return fmt.Errorf("invalid filter: %w", err)
+			}
+
+			client, token, err := g.authenticate(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(cmd.Context(), client, token); err != nil {
+				return err
+			}
+
+			slog.Info("Fetching findings", "package", pkg, "selection", opts.description())
+			result, err := g.fetch(cmd.Context(), opts, client, token, filterExpr, api.NewProjectCache(client))
+			if err != nil {
+				return err
+			}
+
+			sim := simulateUpgrade(result.Findings, pkg, toVersion)
+			sim.Warnings = client.Warnings()
+			logWarnings(sim.Warnings)
+			if err := printUpgradeSimulation(g.console(), sim); err != nil {
+				return err
+			}
+
+			filename := g.outputPath(fmt.Sprintf("upgrade_simulation_%s.json", time.Now().Format("2006-01-02_15-04-05")))
+			if err := writeJSONFile(sim, filename); err != nil {
+				return fmt.Errorf("failed to save simulation report: %w", err)
+			}
+
+			if filename != stdoutPath {
+				fmt.Printf("\nSimulation report saved to: %s\n", filename)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().StringVar(&pkg, "package", "", "Name of the dependency to upgrade")
+	cmd.Flags().StringVar(&toVersion, "to-version", "", "Version the dependency would be upgraded to")
+	return cmd
+}
+
+// simulateUpgrade decides for each finding whether upgrading to toVersion would resolve it
+func simulateUpgrade(findings []api.Finding, pkg, toVersion string) upgradeSimulation {
+	sim := upgradeSimulation{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Package:   pkg,
+		ToVersion: toVersion,
+	}
+
+	for _, f := range findings {
+		outcome := upgradeOutcome{
+			UUID:           f.UUID,
+			Name:           f.Meta.Description,
+			Level:          f.Spec.Level,
+			ProjectUUID:    f.Spec.ProjectUUID,
+			CurrentVersion: f.Spec.TargetDependencyVersion,
+			FixedVersion:   f.Spec.ProposedVersion,
+		}
+
+		switch {
+		case f.Spec.ProposedVersion == "":
+			outcome.Outcome = upgradeUnknown
+			sim.Unknown++
+		case version.Compare(toVersion, f.Spec.ProposedVersion) >= 0:
+			outcome.Outcome = upgradeResolved
+			sim.Resolved++
+		default:
+			outcome.Outcome = upgradeRemaining
+			sim.Remaining++
+		}
+
+		sim.Findings = append(sim.Findings, outcome)
+	}
+
+	return sim
+}
+
+// printUpgradeSimulation writes the simulation as a summary line and a table
+func printUpgradeSimulation(w io.Writer, sim upgradeSimulation) error {
+	fmt.Fprintf(w, "Upgrading %s to %s: %d findings resolved, %d remaining, %d unknown\n\n",
+		sim.Package, sim.ToVersion, sim.Resolved, sim.Remaining, sim.Unknown)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OUTCOME\tLEVEL\tCURRENT\tFIXED IN\tFINDING")
+	for _, o := range sim.Findings {
+		fixed := o.FixedVersion
+		if fixed == "" {
+			fixed = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", o.Outcome, o.Level, o.CurrentVersion, fixed, o.Name)
+	}
+	return tw.Flush()
+}
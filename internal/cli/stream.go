@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/contexts"
+	"github.com/endor-labs/findings-api/internal/manifest"
+	"github.com/endor-labs/findings-api/internal/output"
+)
+
+// streamBlocker returns the option that needs every finding before any can be
+// written, so the export cannot be streamed page by page, or "" if none does.
+// split is the --split-by flag in use, if any.
+func (o *findingsOptions) streamBlocker(g *globalOptions, split string, record, email bool) string {
+	switch {
+	case len(g.Namespaces) > 0:
+		return "--namespaces"
+	case split != "":
+		return split
+	case record:
+		return "--record"
+	case email:
+		return "--email"
+	case o.Correlate != "":
+		return "--correlate"
+	case o.SortRisk:
+		return "--sort-risk"
+	case o.Dedupe:
+		return "--dedupe"
+	}
+	if len(o.ProjectUUIDs) > 1 && (g.List.Sort != "" || g.List.Limit > 0) {
+		return "--sort or --limit with several projects"
+	}
+	if o.Filter.RawFilter == "" {
+		if specs, err := contexts.ParseList(o.Filter.Contexts); err == nil && (len(specs) > 1 || specs[0].Latest()) {
+			return "--context " + o.Filter.Contexts
+		}
+	}
+	return ""
+}
+
+// stream fetches the selected findings page by page, enriches each page and
+// hands it to emit as it arrives, one page at a time. As with fetch, projects
+// that fail are returned and only a failure of every project, or an error
+// from emit, fails the stream.
+func (o *findingsOptions) stream(ctx context.Context, client *api.Client, token, filter string, cache *api.ProjectCache, emit api.FindingsPageFunc) (map[string]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pipeline := o.enrichers(cache, token)
+	var mu sync.Mutex
+	var emitErr error
+	page := func(findings []api.Finding) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if emitErr != nil {
+			return emitErr
+		}
+		for _, w := range pipeline.Run(ctx, findings) {
+			client.AddWarning(w)
+		}
+		if findings = o.postFilter(findings); len(findings) == 0 {
+			return nil
+		}
+		if err := emit(findings); err != nil {
+			// Stop the other projects too; the listing error is reported as-is
+			emitErr = err
+			cancel()
+			return err
+		}
+		return nil
+	}
+
+	var projectUUIDs []string
+	switch {
+	case o.AllProjects:
+		projectUUIDs = []string{""}
+	default:
+		projectUUIDs = o.ProjectUUIDs
+	}
+	if len(projectUUIDs) == 1 {
+		if err := client.StreamFindings(ctx, token, projectUUIDs[0], filter, page); err != nil {
+			if emitErr != nil {
+				return nil, emitErr
+			}
+			return nil, fmt.Errorf("failed to fetch findings: %w", err)
+		}
+		return nil, nil
+	}
+
+	projectErrors := make(map[string]string)
+	concurrency := o.Concurrency
+	if concurrency < 1 {
+		concurrency = api.DefaultConcurrency
+	}
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(projectUUIDs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for uuid := range jobs {
+				err := client.StreamFindings(ctx, token, uuid, filter, page)
+				if err == nil || ctx.Err() != nil {
+					continue
+				}
+				client.AddWarning(api.Warning{
+					Code:     api.WarningProjectFailed,
+					Message:  err.Error(),
+					Resource: "projects",
+					UUID:     uuid,
+				})
+				mu.Lock()
+				projectErrors[uuid] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, uuid := range projectUUIDs {
+		jobs <- uuid
+	}
+	close(jobs)
+	wg.Wait()
+
+	if emitErr != nil {
+		return projectErrors, emitErr
+	}
+	if err := ctx.Err(); err != nil {
+		return projectErrors, fmt.Errorf("failed to fetch findings: %w", err)
+	}
+	if len(projectErrors) == len(projectUUIDs) {
+		return projectErrors, errors.New("failed to fetch findings: every project failed")
+	}
+	return projectErrors, nil
+}
+
+// streamExport writes the selected findings to filename with writer, page by
+// page as they are fetched, so large namespaces never sit in memory. The run
+// records the counts of what was written.
+func (o *findingsOptions) streamExport(ctx context.Context, client *api.Client, token, filter string, fw formatWriter, filename string, console io.Writer, run *manifest.Manifest) error {
+	file, err := createOutput(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	buf := bufio.NewWriter(file)
+
+	// Only the fields the run counts need are kept of each written finding
+	var written []api.Finding
+	projectErrors, err := o.stream(ctx, client, token, filter, api.NewProjectCache(client), func(findings []api.Finding) error {
+		if err := fw.Writer.Write(buf, &output.Document{Findings: findings}); err != nil {
+			return fmt.Errorf("failed to save %s: %w", fw.Format, err)
+		}
+		// Flush every page so downstream tools see the findings as they arrive
+		if err := buf.Flush(); err != nil {
+			return fmt.Errorf("failed to save %s: %w", fw.Format, err)
+		}
+		for _, f := range findings {
+			var slim api.Finding
+			slim.Spec.Level, slim.Spec.ProjectUUID = f.Spec.Level, f.Spec.ProjectUUID
+			written = append(written, slim)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	logFetchReport(client.FetchReport())
+	warnings := client.Warnings()
+	logWarnings(warnings)
+	run.Selection, run.Filter = o.description(), filter
+	run.SetFindings(written, len(warnings), len(projectErrors))
+	addRunOutput(run, filename, fw.Format)
+
+	fmt.Fprintf(console, "Streamed %d findings for %s\n", len(written), o.description())
+	if filename != stdoutPath {
+		fmt.Fprintf(console, "Findings saved to: %s\n", filename)
+	}
+	return o.checkFailOn(written)
+}
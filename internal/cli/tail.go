@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// fetchFunc fetches the current set of findings
+type fetchFunc func() ([]api.Finding, error)
+
+// runTail polls fetch every interval and writes each newly observed finding to w
+// as a single JSON line. The first poll only records the findings that already
+// exist so that the stream contains new findings exclusively, as does the first
+// poll after a configuration change alters the findings selected. Poll failures
+// are logged and retried on the next tick; runTail returns when ctx is
+// cancelled.
+func runTail(ctx context.Context, fetch fetchFunc, interval time.Duration, reload pollReload, w io.Writer) error {
+	seen := make(map[string]bool)
+	encoder := json.NewEncoder(w)
+	first := true
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		findings, err := fetch()
+		if err != nil {
+			slog.Warn("Poll failed", "error", err)
+		} else {
+			newCount := 0
+			for _, f := range findings {
+				if seen[f.UUID] {
+					continue
+				}
+				seen[f.UUID] = true
+
+				if first {
+					continue
+				}
+				if err := encoder.Encode(f); err != nil {
+					return fmt.Errorf("failed to write finding: %w", err)
+				}
+				newCount++
+			}
+
+			if first {
+				slog.Info("Tailing findings", "existing", len(seen), "interval", interval)
+				first = false
+			} else {
+				slog.Info("Poll complete", "new", newCount)
+			}
+		}
+
+		ok, changed := reload.wait(ctx, ticker, &interval)
+		if !ok {
+			return nil
+		}
+		if changed {
+			first = true
+		}
+	}
+}
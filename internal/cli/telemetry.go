@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// printPageTelemetry writes a table of per-page statistics followed by totals,
+// to help pick a --page-size that balances request count against payload size
+func printPageTelemetry(w io.Writer, pages []api.PageStats) {
+	if len(pages) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nPage telemetry:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ENDPOINT\tPAGE\tSIZE\tOBJECTS\tBYTES\tFETCH\tDECODE\tHEAP\t")
+
+	var totalBytes int64
+	var totalObjects int
+	var totalFetch, totalDecode time.Duration
+	var peakHeap uint64
+	for _, p := range pages {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t\n",
+			p.Endpoint, p.Page, p.PageSize, p.Objects, formatBytes(uint64(p.Bytes)),
+			p.FetchDuration.Round(time.Millisecond), p.DecodeDuration.Round(time.Millisecond), formatBytes(p.HeapAlloc))
+
+		totalBytes += p.Bytes
+		totalObjects += p.Objects
+		totalFetch += p.FetchDuration
+		totalDecode += p.DecodeDuration
+		if p.HeapAlloc > peakHeap {
+			peakHeap = p.HeapAlloc
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d pages, %d objects, %s transferred (avg %s/page", len(pages), totalObjects, formatBytes(uint64(totalBytes)), formatBytes(uint64(totalBytes)/uint64(len(pages))))
+	if totalObjects > 0 {
+		fmt.Fprintf(w, ", %s/object", formatBytes(uint64(totalBytes)/uint64(totalObjects)))
+	}
+	fmt.Fprintf(w, ")\nfetch %s, decode %s, peak heap %s\n", totalFetch.Round(time.Millisecond), totalDecode.Round(time.Millisecond), formatBytes(peakHeap))
+}
+
+// formatBytes renders a byte count with a binary unit suffix
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/endor-labs/findings-api/internal/output"
+)
+
+// reportTheme returns the theme of styled reports: the theme file given with
+// --theme, or else the profile's theme, with unset fields taken from the
+// default theme
+func (g *globalOptions) reportTheme(path string) (output.Theme, error) {
+	if path != "" {
+		return output.LoadTheme(path)
+	}
+
+	t := g.profile.Theme
+	theme := output.Theme{
+		CompanyName:  t.CompanyName,
+		LogoURL:      t.LogoURL,
+		PrimaryColor: t.PrimaryColor,
+		AccentColor:  t.AccentColor,
+		FontFamily:   t.FontFamily,
+	}.WithDefaults()
+	if err := theme.Validate(); err != nil {
+		return output.Theme{}, fmt.Errorf("invalid profile theme: %w", err)
+	}
+	return theme, nil
+}
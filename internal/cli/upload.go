@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/endor-labs/findings-api/internal/manifest"
+	"github.com/endor-labs/findings-api/internal/upload"
+)
+
+// newUploader returns the uploader of --upload, $ENDOR_UPLOAD or the profile's
+// upload section, or nil when artifacts are not uploaded
+func (g *globalOptions) newUploader() (*upload.Uploader, error) {
+	p := g.profile.Upload
+	dest := firstNonEmpty(g.Upload, os.Getenv("ENDOR_UPLOAD"), os.ExpandEnv(p.URL))
+	if dest == "" {
+		return nil, nil
+	}
+	if err := g.requireNetwork("--upload"); err != nil {
+		return nil, err
+	}
+	return upload.New(dest, upload.Options{
+		SSE:    firstNonEmpty(g.UploadSSE, p.SSE),
+		KMSKey: firstNonEmpty(g.UploadKMSKey, os.ExpandEnv(p.KMSKey)),
+	})
+}
+
+// uploadOutputs uploads every artifact of the run written to a file and
+// records where each went. It stops at the first failure.
+func uploadOutputs(ctx context.Context, u *upload.Uploader, m *manifest.Manifest) error {
+	for _, out := range m.Outputs {
+		if out.Path == stdoutPath {
+			continue
+		}
+		url, err := u.Upload(ctx, out.Path, upload.ObjectName(out.Path))
+		if err != nil {
+			return err
+		}
+		m.SetUploaded(out.Path, url)
+		slog.Info("Uploaded", "path", out.Path, "url", url)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+	"github.com/spf13/cobra"
+)
+
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of this tool",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(buildinfo.String())
+		},
+	}
+}
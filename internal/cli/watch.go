@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/config"
+	"github.com/endor-labs/findings-api/internal/diff"
+	"github.com/endor-labs/findings-api/internal/metrics"
+	"github.com/endor-labs/findings-api/internal/notify"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// watchEvent is one change reported by findings watch
+type watchEvent struct {
+	Event   string      `json:"event"`
+	Time    time.Time   `json:"time"`
+	Finding api.Finding `json:"finding"`
+}
+
+// watchFetchFunc fetches the current findings and the projects that failed
+type watchFetchFunc func() (fetchResult, error)
+
+// watcher polls for findings and reports what changed since the previous poll
+type watcher struct {
+	fetch    watchFetchFunc
+	interval time.Duration
+	key      string
+	// emit reports the changes of a poll; an error stops the watch
+	emit func(diff.Result) error
+	// statePath, when set, keeps the last poll so a restarted watch carries on
+	// where it stopped instead of starting from a new baseline
+	statePath string
+	// reload applies configuration changes between polls
+	reload pollReload
+
+	previous []api.Finding
+	primed   bool
+}
+
+// run polls until ctx is cancelled. Without a saved state the first poll only
+// records the baseline, as does the first poll after a configuration change
+// alters the findings selected. Poll failures are logged and retried on the
+// next tick.
+func (w *watcher) run(ctx context.Context) error {
+	if w.statePath != "" {
+		previous, err := diff.Load(w.statePath)
+		switch {
+		case err == nil:
+			w.previous, w.primed = previous, true
+			slog.Info("Resuming watch", "state", w.statePath, "findings", len(previous))
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("failed to read watch state: %w", err)
+		}
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.poll(); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		ok, changed := w.reload.wait(ctx, ticker, &w.interval)
+		if !ok {
+			return nil
+		}
+		if changed {
+			w.primed = false
+		}
+	}
+}
+
+// poll fetches once and reports the changes. Only errors emitting the changes
+// are returned; fetch errors are logged.
+func (w *watcher) poll() error {
+	result, err := w.fetch()
+	if err != nil {
+		slog.Warn("Poll failed", "error", err)
+		return nil
+	}
+	current := keepFailedProjects(result, w.previous)
+
+	if !w.primed {
+		w.previous, w.primed = current, true
+		slog.Info("Watching findings", "existing", len(current), "interval", w.interval)
+		w.saveState()
+		return nil
+	}
+
+	changes, err := diff.Compare(w.previous, current, w.key)
+	if err != nil {
+		return err
+	}
+	if err := w.emit(changes); err != nil {
+		return err
+	}
+	slog.Info("Poll complete", "new", len(changes.New), "resolved", len(changes.Resolved), "total", len(current))
+	w.previous = current
+	w.saveState()
+	return nil
+}
+
+// keepFailedProjects returns the fetched findings plus the previous findings
+// of projects that could not be fetched this time, so a project failing for
+// one poll does not report all of its findings as resolved
+func keepFailedProjects(result fetchResult, previous []api.Finding) []api.Finding {
+	if len(result.ProjectErrors) == 0 {
+		return result.Findings
+	}
+	current := append([]api.Finding(nil), result.Findings...)
+	for _, f := range previous {
+		if _, failed := result.ProjectErrors[f.Spec.ProjectUUID]; failed {
+			current = append(current, f)
+		}
+	}
+	slog.Warn("Some projects could not be fetched; their findings are carried over from the previous poll", "projects", len(result.ProjectErrors))
+	return current
+}
+
+// saveState replaces the state file with the last poll; failures are only a warning
+func (w *watcher) saveState() {
+	if w.statePath == "" {
+		return
+	}
+	doc := output.NewDocument(w.previous, "findings watch state", api.FetchReport{}, nil, nil)
+	data, err := json.Marshal(doc)
+	if err == nil {
+		tmp := w.statePath + ".tmp"
+		if err = os.MkdirAll(filepath.Dir(w.statePath), 0o755); err == nil {
+			if err = os.WriteFile(tmp, data, 0o644); err == nil {
+				err = os.Rename(tmp, w.statePath)
+			}
+		}
+	}
+	if err != nil {
+		slog.Warn("Failed to save the watch state", "error", err)
+	}
+}
+
+// newWatchEmitter returns the function printing the changes of a poll to w:
+// a line per change for text, or a JSON event per line for ndjson
+func newWatchEmitter(w io.Writer, format string) func(diff.Result) error {
+	return func(changes diff.Result) error {
+		now := time.Now().UTC()
+		for _, group := range []struct {
+			event    string
+			findings []api.Finding
+		}{{diff.StatusNew, changes.New}, {diff.StatusResolved, changes.Resolved}} {
+			for _, f := range group.findings {
+				var err error
+				if format == "ndjson" {
+					err = json.NewEncoder(w).Encode(watchEvent{Event: group.event, Time: now, Finding: f})
+				} else {
+					_, err = fmt.Fprintf(w, "%s  %-8s  %-8s  %s  %s  %s\n", now.Format(time.RFC3339), strings.ToUpper(group.event),
+						f.Spec.Level.Short(), firstNonEmpty(f.CVE(), f.Meta.Name), firstNonEmpty(f.Spec.TargetDependencyName, f.Spec.TargetDependencyPackageName), f.UUID)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to write change: %w", err)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// notifyNew sends the new findings of a poll through router; sink failures
+// are logged and do not stop the watch
+func notifyNew(ctx context.Context, router *notify.Router, changes diff.Result) {
+	if len(changes.New) == 0 {
+		return
+	}
+	for _, r := range router.Deliver(ctx, changes.New) {
+		if r.Error != "" {
+			slog.Warn("Failed to notify", "sink", r.Sink, "error", r.Error)
+		} else {
+			slog.Info("Notified of new findings", "sink", r.Sink, "findings", r.Findings)
+		}
+	}
+}
+
+// serveWatchMetrics serves collector on addr at /metrics until ctx is done
+func serveWatchMetrics(ctx context.Context, addr string, collector *metrics.Collector) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", collector)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("Metrics server failed", "error", err)
+		}
+	}()
+	slog.Info("Serving metrics", "addr", listener.Addr().String(), "path", "/metrics")
+	return nil
+}
+
+func newFindingsWatchCmd(g *globalOptions) *cobra.Command {
+	opts := &findingsOptions{}
+	var interval, watchConfig time.Duration
+	var key, format, statePath, metricsAddr, pushgateway, pushJob, webhookURL string
+	var notifyNewFindings bool
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Poll for findings and report the ones that are new or resolved since the previous poll",
+		Long: `Poll for findings every --interval and report the findings that appeared or
+were resolved since the previous poll, for running the tool as a lightweight
+monitor. The first poll records the baseline, unless --state holds the last
+poll of an earlier run. With --notify, new findings are also sent through the
+profile's notification routes, and with --webhook-url the new and resolved
+findings of each poll are posted to a webhook, signed with HMAC-SHA256.
+--metrics-addr serves Prometheus metrics, and --pushgateway pushes them after
+every poll.
+
+Findings of projects that fail to fetch are carried over from the previous
+poll, so a transient failure is not reported as every finding resolved.`,
+		Example: `  findings-api findings watch --all-projects --interval 15m
+  findings-api findings watch --repo github.com/acme/payments --format ndjson --notify --state /data/watch.json
+  ENDOR_WEBHOOK_SECRET=s3cret findings-api findings watch --all-projects --webhook-url https://soar.acme.com/hooks/endor`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.validate(); err != nil {
+				return err
+			}
+			if format != "text" && format != "ndjson" {
+				return fmt.Errorf("unsupported format %q (expected text or ndjson)", format)
+			}
+			if interval <= 0 {
+				return errors.New("--interval must be positive")
+			}
+			if _, err := diff.KeyFunc(key); err != nil {
+				return err
+			}
+			var router *notify.Router
+			if notifyNewFindings {
+				var err error
+				if router, err = notify.FromConfig(g.profile.Notifications); err != nil {
+					return fmt.Errorf("invalid notifications config: %w", err)
+				}
+				if len(router.Routes) == 0 {
+					return errors.New("--notify needs notification routes in the profile")
+				}
+				if remote := router.RemoteSinks(); len(remote) > 0 {
+					if err := g.requireNetwork("sending to sinks " + strings.Join(remote, ", ")); err != nil {
+						return err
+					}
+				}
+			}
+			if pushgateway != "" {
+				if err := g.requireNetwork("pushing metrics to " + pushgateway); err != nil {
+					return err
+				}
+			}
+			hook, err := g.newWebhook(webhookURL)
+			if err != nil {
+				return err
+			}
+			filter, err := opts.buildFilter()
+			if err != nil {
+				return err
+			}
+
+			client, err := g.newClient()
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			token, err := g.token(ctx, client)
+			if err != nil {
+				return err
+			}
+			if err := opts.resolveProjects(ctx, client, token); err != nil {
+				return err
+			}
+
+			collector := metrics.New()
+			if metricsAddr != "" {
+				if err := serveWatchMetrics(ctx, metricsAddr, collector); err != nil {
+					return err
+				}
+			}
+
+			cache := api.NewProjectCache(client)
+			warned, observed := 0, 0
+			emit := newWatchEmitter(os.Stdout, format)
+			w := &watcher{
+				interval:  interval,
+				key:       key,
+				statePath: statePath,
+				reload: pollReload{
+					files: g.configChanges(ctx, watchConfig),
+					apply: func(file *config.File) (time.Duration, bool, error) {
+						changed, err := g.reloadPollConfig(cmd, opts, file, &filter, &interval)
+						return interval, changed, err
+					},
+				},
+				fetch: func() (fetchResult, error) {
+					// Re-check the token on every poll so long-running watches survive token expiry
+					token, err := g.token(ctx, client)
+					if err != nil {
+						return fetchResult{}, err
+					}
+					result, err := opts.fetch(ctx, client, token, filter, cache)
+
+					warnings := client.Warnings()
+					logWarnings(warnings[warned:])
+					warned = len(warnings)
+
+					requests := client.FetchReport().Requests
+					collector.ObserveRequests(requests[observed:])
+					observed = len(requests)
+					if err == nil {
+						collector.SetFindings(client.Namespace(), result.Findings, time.Now())
+					}
+					if pushgateway != "" {
+						if err := collector.Push(ctx, pushgateway, pushJob); err != nil {
+							slog.Warn("Failed to push metrics", "pushgateway", pushgateway, "error", err)
+						}
+					}
+
+					return result, err
+				},
+				emit: func(changes diff.Result) error {
+					if err := emit(changes); err != nil {
+						return err
+					}
+					if router != nil {
+						notifyNew(ctx, router, changes)
+					}
+					if hook != nil {
+						// A failed delivery is not retried on the next poll, which reports only its own changes
+						if err := postChanges(ctx, hook, "findings watch", changes); err != nil {
+							slog.Warn("Failed to post changes to the webhook", "error", err)
+						}
+					}
+					return nil
+				},
+			}
+			return w.run(ctx)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().DurationVar(&interval, "interval", 15*time.Minute, "Polling interval")
+	cmd.Flags().StringVar(&key, "key", diff.ByUUID, "How findings of two polls are matched: uuid or fingerprint")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text or ndjson")
+	cmd.Flags().StringVar(&statePath, "state", "", "File keeping the last poll, so a restarted watch resumes instead of starting a new baseline")
+	cmd.Flags().BoolVar(&notifyNewFindings, "notify", false, "Send new findings through the profile's notification routes")
+	addWebhookFlag(cmd.Flags(), &webhookURL)
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on at /metrics, e.g. :9090")
+	cmd.Flags().StringVar(&pushgateway, "pushgateway", "", "Prometheus Pushgateway URL to push metrics to after every poll")
+	cmd.Flags().StringVar(&pushJob, "push-job", "findings_api", "Job name metrics are pushed under")
+	addWatchConfigFlag(cmd.Flags(), &watchConfig)
+	return cmd
+}
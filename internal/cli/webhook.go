@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/endor-labs/findings-api/internal/diff"
+	"github.com/endor-labs/findings-api/internal/webhook"
+	"github.com/spf13/pflag"
+)
+
+// webhookUsage documents --webhook-url
+const webhookUsage = "Webhook URL new and resolved findings are posted to, signed with the profile's webhook.secret or $ENDOR_WEBHOOK_SECRET (default: $ENDOR_WEBHOOK_URL or the profile's webhook.url)"
+
+func addWebhookFlag(fs *pflag.FlagSet, url *string) {
+	fs.StringVar(url, "webhook-url", "", webhookUsage)
+}
+
+// newWebhook returns the client of the webhook selected by url, the
+// environment or the profile, or nil when none is
+func (g *globalOptions) newWebhook(url string) (*webhook.Client, error) {
+	p := g.profile.Webhook
+	url = firstNonEmpty(url, os.Getenv("ENDOR_WEBHOOK_URL"), os.ExpandEnv(p.URL))
+	if url == "" {
+		return nil, nil
+	}
+	if err := g.requireNetwork("posting to the webhook"); err != nil {
+		return nil, err
+	}
+	headers := make(map[string]string, len(p.Headers))
+	for k, v := range p.Headers {
+		headers[k] = os.ExpandEnv(v)
+	}
+	return webhook.NewClient(webhook.Config{
+		URL:     url,
+		Secret:  firstNonEmpty(os.Getenv("ENDOR_WEBHOOK_SECRET"), os.ExpandEnv(p.Secret)),
+		Headers: headers,
+	})
+}
+
+// postChanges posts the new and resolved findings of changes, if there are any
+func postChanges(ctx context.Context, hook *webhook.Client, source string, changes diff.Result) error {
+	if len(changes.New) == 0 && len(changes.Resolved) == 0 {
+		return nil
+	}
+	if err := hook.Send(ctx, webhook.NewPayload(source, changes.New, changes.Resolved)); err != nil {
+		return err
+	}
+	slog.Info("Posted changes to the webhook", "new", len(changes.New), "resolved", len(changes.Resolved))
+	return nil
+}
@@ -0,0 +1,186 @@
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations are the places GitHub and GitLab look for a CODEOWNERS file, in order
+var Locations = []string{".github/CODEOWNERS", ".gitlab/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule assigns owners to the paths matching a pattern
+type Rule struct {
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// Ruleset is a parsed CODEOWNERS file
+type Ruleset struct {
+	Rules []Rule
+}
+
+// Load reads a CODEOWNERS file. If path is a directory, the file is looked up
+// in the standard locations beneath it.
+func Load(path string) (*Ruleset, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		dir := path
+		path = ""
+		for _, loc := range Locations {
+			candidate := filepath.Join(dir, filepath.FromSlash(loc))
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+		if path == "" {
+			return nil, fmt.Errorf("no CODEOWNERS file found in %s", dir)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CODEOWNERS: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads CODEOWNERS rules from r. Blank lines and comments are ignored.
+// GitLab section headers ("[Section] @owner", "^[Optional][2]") are understood:
+// their default owners apply to rules in the section that list none.
+func Parse(r io.Reader) (*Ruleset, error) {
+	rs := &Ruleset{}
+	var sectionOwners []string
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if header := strings.TrimPrefix(fields[0], "^"); strings.HasPrefix(header, "[") {
+			sectionOwners = sectionDefaultOwners(line)
+			continue
+		}
+
+		re, err := compile(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("CODEOWNERS line %d: %w", lineNo, err)
+		}
+		owners := fields[1:]
+		if len(owners) == 0 {
+			owners = sectionOwners
+		}
+		rs.Rules = append(rs.Rules, Rule{Pattern: fields[0], Owners: owners, re: re})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+
+	return rs, nil
+}
+
+// sectionDefaultOwners returns the owners listed after a GitLab section header
+// such as "[Section name][2] @owner"
+func sectionDefaultOwners(line string) []string {
+	rest := strings.TrimSpace(line)
+	// Skip the section name and optional approval count, both in brackets
+	for strings.HasPrefix(strings.TrimPrefix(rest, "^"), "[") {
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return nil
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return strings.Fields(rest)
+}
+
+// Owners returns the owners of path. As on GitHub, the last matching rule wins;
+// a matching rule without owners leaves the path unowned.
+func (rs *Ruleset) Owners(path string) []string {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(rs.Rules) - 1; i >= 0; i-- {
+		if rs.Rules[i].re.MatchString(path) {
+			return rs.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// OwnersOf returns the distinct owners of any of paths, in first-seen order
+func (rs *Ruleset) OwnersOf(paths []string) []string {
+	var owners []string
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		for _, o := range rs.Owners(p) {
+			if !seen[o] {
+				seen[o] = true
+				owners = append(owners, o)
+			}
+		}
+	}
+	return owners
+}
+
+// compile translates a gitignore-style CODEOWNERS pattern into a regular expression
+func compile(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	// Patterns with a slash other than a trailing one are relative to the repository root
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, fmt.Errorf("invalid pattern %q", pattern)
+	}
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(p, "/*"):
+		// "docs/*" owns the files directly in docs, not those in subdirectories
+		b.WriteString("$")
+	default:
+		// A pattern naming a directory owns everything beneath it
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfileName is used when neither a profile nor default_profile is set
+const DefaultProfileName = "default"
+
+// File is the contents of a configuration file
+type File struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile holds the connection settings and default filters for one tenant
+type Profile struct {
+	APIKey        string        `yaml:"api_key"`
+	APISecret     string        `yaml:"api_secret"`
+	Namespace     string        `yaml:"namespace"`
+	BaseURL       string        `yaml:"base_url"`
+	AuthPath      string        `yaml:"auth_path"`
+	TokenAudience string        `yaml:"token_audience"`
+	Filters       Filters       `yaml:"filters"`
+	Notifications Notifications `yaml:"notifications"`
+	Jira          Jira          `yaml:"jira"`
+	GitHub        GitHubIssues  `yaml:"github"`
+	DefectDojo    DefectDojo    `yaml:"defectdojo"`
+	ServiceNow    ServiceNow    `yaml:"servicenow"`
+	// SIEM is the sink integrations siem forwards to: a splunk, syslog or
+	// events sink
+	SIEM Sink `yaml:"siem"`
+	// Preset is a shared preset of default filters: a file path or an http(s),
+	// s3 or gs URL. The profile's own filters take precedence over it.
+	Preset string `yaml:"preset"`
+	Upload Upload `yaml:"upload"`
+	Email  Email  `yaml:"email"`
+	// Webhook receives the new and resolved findings of findings watch and
+	// findings diff
+	Webhook Webhook `yaml:"webhook"`
+	Risk    Risk    `yaml:"risk"`
+	// Schedule is the default polling and refresh schedule of findings watch,
+	// findings tail and serve
+	Schedule Schedule `yaml:"schedule"`
+	// Theme brands the styled reports of the profile unless --theme is given
+	Theme Theme `yaml:"theme"`
+}
+
+// Theme is a report theme: colours are #RRGGBB values
+type Theme struct {
+	CompanyName  string `yaml:"company_name"`
+	LogoURL      string `yaml:"logo_url"`
+	PrimaryColor string `yaml:"primary_color"`
+	AccentColor  string `yaml:"accent_color"`
+	FontFamily   string `yaml:"font_family"`
+}
+
+// Schedule configures how often long-running commands fetch findings.
+// Durations are written like 10m or 1h30m.
+type Schedule struct {
+	// Interval is the polling interval of watch and tail and the refresh
+	// interval of serve
+	Interval time.Duration `yaml:"interval"`
+	// Jitter is the fraction of the refresh interval serve moves each
+	// refresh by (0 to 1)
+	Jitter       *float64      `yaml:"jitter"`
+	MaxStaleness time.Duration `yaml:"max_staleness"`
+	MinGap       time.Duration `yaml:"min_fetch_gap"`
+}
+
+// Risk configures risk scoring
+type Risk struct {
+	// Weights replace the default weights of the risk factors they name, e.g.
+	// severity, epss, reachability, fix and depth; 0 leaves a factor out
+	Weights map[string]float64 `yaml:"weights"`
+}
+
+// Jira configures the Jira issue integration. Email and Token may reference
+// environment variables as $NAME or ${NAME}.
+type Jira struct {
+	URL string `yaml:"url"`
+	// Email is the Jira Cloud account for basic auth; when empty, Token is sent
+	// as a Data Center personal access token
+	Email     string   `yaml:"email"`
+	Token     string   `yaml:"token"`
+	Project   string   `yaml:"project"`
+	IssueType string   `yaml:"issue_type"`
+	Labels    []string `yaml:"labels"`
+	// GroupBy is "finding" for an issue per finding or "package" for an issue
+	// per vulnerable package of a project
+	GroupBy string `yaml:"group_by"`
+	// UUIDField is the custom text field, e.g. customfield_10050, that holds
+	// the finding UUIDs an issue tracks
+	UUIDField           string `yaml:"uuid_field"`
+	SummaryTemplate     string `yaml:"summary_template"`
+	DescriptionTemplate string `yaml:"description_template"`
+}
+
+// GitHubIssues configures the GitHub issue integration. Token may reference
+// environment variables as $NAME or ${NAME}.
+type GitHubIssues struct {
+	// Repo is the owner/name of the repository issues are opened in
+	Repo string `yaml:"repo"`
+	// APIURL is the REST API root of a GitHub Enterprise Server instance
+	APIURL string `yaml:"api_url"`
+	Token  string `yaml:"token"`
+	// Label marks the issues the integration manages and may close
+	Label               string   `yaml:"label"`
+	Labels              []string `yaml:"labels"`
+	GroupBy             string   `yaml:"group_by"`
+	SummaryTemplate     string   `yaml:"summary_template"`
+	DescriptionTemplate string   `yaml:"description_template"`
+}
+
+// DefectDojo configures the DefectDojo import integration. URL and Token may
+// reference environment variables as $NAME or ${NAME}.
+type DefectDojo struct {
+	URL         string `yaml:"url"`
+	Token       string `yaml:"token"`
+	Product     string `yaml:"product"`
+	ProductType string `yaml:"product_type"`
+	Engagement  string `yaml:"engagement"`
+	// ScanType is "Generic Findings Import" or "SARIF"
+	ScanType        string   `yaml:"scan_type"`
+	TestTitle       string   `yaml:"test_title"`
+	MinimumSeverity string   `yaml:"minimum_severity"`
+	Tags            []string `yaml:"tags"`
+	CloseOld        bool     `yaml:"close_old"`
+	Reimport        bool     `yaml:"reimport"`
+}
+
+// ServiceNow configures the ServiceNow integration. URL, Username, Password and
+// Token may reference environment variables as $NAME or ${NAME}.
+type ServiceNow struct {
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Token is an OAuth bearer token, used when no username is set
+	Token string `yaml:"token"`
+	// RecordType is "incident" or "vulnerability"; Table overrides the table
+	// records of that type are created in
+	RecordType      string `yaml:"record_type"`
+	Table           string `yaml:"table"`
+	AssignmentGroup string `yaml:"assignment_group"`
+	Category        string `yaml:"category"`
+	// MinLevel is the lowest level records are created for
+	MinLevel string `yaml:"min_level"`
+	// Fields are extra field values set on every record
+	Fields              map[string]string `yaml:"fields"`
+	SummaryTemplate     string            `yaml:"summary_template"`
+	DescriptionTemplate string            `yaml:"description_template"`
+}
+
+// Upload configures where the artifacts of each run are uploaded
+type Upload struct {
+	// URL is an s3://bucket/prefix, gs://bucket/prefix or
+	// az://account/container/prefix destination
+	URL string `yaml:"url"`
+	// SSE is the S3 server-side encryption, AES256 or aws:kms
+	SSE string `yaml:"sse"`
+	// KMSKey is an S3 KMS key, a Cloud KMS key name or an Azure encryption scope
+	KMSKey string `yaml:"kms_key"`
+}
+
+// Email configures the SMTP server and recipients of emailed reports. Username
+// and Password may reference environment variables as $NAME or ${NAME}.
+type Email struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// TLS is "starttls", "tls" or "none"
+	TLS  string   `yaml:"tls"`
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+	Cc   []string `yaml:"cc"`
+	// Subject is a Go template of the subject, with the report title and the
+	// finding counts by level
+	Subject string `yaml:"subject"`
+}
+
+// Webhook configures the webhook changes in findings are posted to. URL,
+// Secret and header values may reference environment variables.
+type Webhook struct {
+	URL string `yaml:"url"`
+	// Secret signs deliveries with HMAC-SHA256
+	Secret  string            `yaml:"secret"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Notifications configures where findings are sent and which findings go where
+type Notifications struct {
+	// Sinks are the notification channels, keyed by the name routes refer to
+	Sinks map[string]Sink `yaml:"sinks"`
+	// Routes send matching findings to sinks; a finding is delivered once to
+	// every sink of every route it matches
+	Routes []Route `yaml:"routes"`
+}
+
+// Sink is one notification channel. Type selects it and decides which of the
+// other fields apply.
+type Sink struct {
+	Type string `yaml:"type"`
+	// URL is the endpoint of webhook, splunk and events sinks
+	URL string `yaml:"url"`
+	// Headers are added to webhook and events requests; values may reference
+	// environment variables as $NAME or ${NAME}
+	Headers map[string]string `yaml:"headers"`
+	// RoutingKey is the PagerDuty Events API v2 integration key; it may
+	// reference an environment variable
+	RoutingKey string `yaml:"routing_key"`
+	// Path is the file file sinks append to
+	Path string `yaml:"path"`
+	// Token is the Splunk HTTP Event Collector token; it may reference an
+	// environment variable
+	Token string `yaml:"token"`
+	// Index, Sourcetype, Source and Host are the metadata of Splunk events;
+	// Host is also the HOSTNAME of syslog messages
+	Index      string `yaml:"index"`
+	Sourcetype string `yaml:"sourcetype"`
+	Source     string `yaml:"source"`
+	Host       string `yaml:"host"`
+	// Address is where syslog sinks send to: udp://, tcp:// or tls://host:port
+	Address string `yaml:"address"`
+	// AppName is the APP-NAME of syslog messages
+	AppName string `yaml:"app_name"`
+}
+
+// Route matches findings by level, category and tag. Empty lists match
+// everything; a finding matches when it has any of the listed values of
+// each non-empty list.
+type Route struct {
+	Name       string   `yaml:"name"`
+	Levels     []string `yaml:"levels"`
+	Categories []string `yaml:"categories"`
+	Tags       []string `yaml:"tags"`
+	Sinks      []string `yaml:"sinks"`
+}
+
+// Filters are default values for the findings filter flags. Unset fields leave
+// the built-in defaults in place.
+type Filters struct {
+	Level         string   `yaml:"level"`
+	Categories    string   `yaml:"categories"`
+	Tags          string   `yaml:"tags"`
+	EPSSMin       *float64 `yaml:"epss_min"`
+	ReachableOnly *bool    `yaml:"reachable_only"`
+	FixAvailable  *bool    `yaml:"fix_available"`
+	RawFilter     string   `yaml:"raw_filter"`
+	// Context is a comma-separated list of contexts, e.g. main,ci-run
+	Context string `yaml:"context"`
+	// FailOn is the default --fail-on gate of commands that have one
+	FailOn string `yaml:"fail_on"`
+	// CVSSMin is the minimum CVSS score, after CVSSEnvironmental is applied
+	CVSSMin *float64 `yaml:"cvss_min"`
+	// CVSSEnvironmental are CVSS environmental metrics applied to every
+	// finding's vector before scoring, e.g. CR:H/IR:H/MAV:L
+	CVSSEnvironmental string `yaml:"cvss_environmental"`
+}
+
+// DefaultPath returns ~/.endor/config.yaml
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".endor", "config.yaml"), nil
+}
+
+// Load reads the configuration file at path. A missing file is only an error
+// when required is set; otherwise an empty configuration is returned.
+func Load(path string, required bool) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Profile returns the named profile. An empty name selects default_profile, or
+// the "default" profile; when neither exists an empty profile is returned.
+func (f *File) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = f.DefaultProfile
+		if name == "" {
+			return f.Profiles[DefaultProfileName], nil
+		}
+	}
+
+	p, ok := f.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("profile %q not found (available: %v)", name, f.ProfileNames())
+	}
+	return p, nil
+}
+
+// ProfileNames returns the names of every profile in sorted order
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FlagDefaults returns the filters as flag names and values
+func (fl Filters) FlagDefaults() map[string]string {
+	defaults := make(map[string]string)
+	set := func(name, value string) {
+		if value != "" {
+			defaults[name] = value
+		}
+	}
+
+	set("level", fl.Level)
+	set("categories", fl.Categories)
+	set("tags", fl.Tags)
+	set("raw-filter", fl.RawFilter)
+	set("context", fl.Context)
+	set("fail-on", fl.FailOn)
+	set("cvss-environmental", fl.CVSSEnvironmental)
+	if fl.EPSSMin != nil {
+		set("epss-min", strconv.FormatFloat(*fl.EPSSMin, 'f', -1, 64))
+	}
+	if fl.CVSSMin != nil {
+		set("min-cvss", strconv.FormatFloat(*fl.CVSSMin, 'f', -1, 64))
+	}
+	if fl.ReachableOnly != nil {
+		set("reachable-only", strconv.FormatBool(*fl.ReachableOnly))
+	}
+	if fl.FixAvailable != nil {
+		set("fix-available", strconv.FormatBool(*fl.FixAvailable))
+	}
+	return defaults
+}
+
+// FlagDefaults returns the schedule as flag names and values. Interval sets
+// both --interval and --refresh-interval, as no command has both.
+func (s Schedule) FlagDefaults() map[string]string {
+	defaults := make(map[string]string)
+	set := func(name string, d time.Duration) {
+		if d > 0 {
+			defaults[name] = d.String()
+		}
+	}
+
+	set("interval", s.Interval)
+	set("refresh-interval", s.Interval)
+	set("max-staleness", s.MaxStaleness)
+	set("min-fetch-gap", s.MinGap)
+	if s.Jitter != nil {
+		defaults["refresh-jitter"] = strconv.FormatFloat(*s.Jitter, 'f', -1, 64)
+	}
+	return defaults
+}
+
+// Override returns fl with every field set in local replacing its value
+func (fl Filters) Override(local Filters) Filters {
+	str := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	str(&fl.Level, local.Level)
+	str(&fl.Categories, local.Categories)
+	str(&fl.Tags, local.Tags)
+	str(&fl.RawFilter, local.RawFilter)
+	str(&fl.Context, local.Context)
+	str(&fl.FailOn, local.FailOn)
+	str(&fl.CVSSEnvironmental, local.CVSSEnvironmental)
+	if local.EPSSMin != nil {
+		fl.EPSSMin = local.EPSSMin
+	}
+	if local.CVSSMin != nil {
+		fl.CVSSMin = local.CVSSMin
+	}
+	if local.ReachableOnly != nil {
+		fl.ReachableOnly = local.ReachableOnly
+	}
+	if local.FixAvailable != nil {
+		fl.FixAvailable = local.FixAvailable
+	}
+	return fl
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// presetTimeout bounds fetching a remote preset
+const presetTimeout = 15 * time.Second
+
+// Preset holds organization-wide default filters shared by many checkouts,
+// with optional overrides for individual namespaces
+type Preset struct {
+	Filters Filters `yaml:"filters"`
+	// Namespaces override Filters for the namespaces they are keyed by
+	Namespaces map[string]Filters `yaml:"namespaces"`
+}
+
+// For returns the preset's filters for namespace
+func (p Preset) For(namespace string) Filters {
+	return p.Filters.Override(p.Namespaces[namespace])
+}
+
+// PresetOptions configure LoadPreset
+type PresetOptions struct {
+	// CacheDir keeps the last copy of each remote preset, used when the
+	// source cannot be reached; empty disables the cache
+	CacheDir string
+	// Offline reads remote presets from the cache only
+	Offline bool
+	// Token is sent as a bearer token to http(s) sources, e.g. to read a
+	// preset from a private config repository
+	Token string
+}
+
+// DefaultPresetCacheDir returns ~/.endor/presets
+func DefaultPresetCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".endor", "presets"), nil
+}
+
+// LoadPreset reads the preset at source: a file path, a file, http or https
+// URL, or an s3://bucket/key or gs://bucket/object URL of a publicly readable
+// object. A remote preset that cannot be fetched falls back to its cached copy
+// with a warning.
+func LoadPreset(ctx context.Context, source string, opts PresetOptions) (Preset, error) {
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// A plain path, or a Windows path such as C:\config\preset.yaml
+		return readPresetFile(source)
+	}
+
+	var fetchURL string
+	switch strings.ToLower(u.Scheme) {
+	case "file":
+		return readPresetFile(u.Path)
+	case "http", "https":
+		fetchURL = source
+	case "s3":
+		fetchURL = fmt.Sprintf("https://%s.s3.amazonaws.com/%s", u.Host, strings.TrimPrefix(u.Path, "/"))
+	case "gs":
+		fetchURL = fmt.Sprintf("https://storage.googleapis.com/%s/%s", u.Host, strings.TrimPrefix(u.Path, "/"))
+	default:
+		return Preset{}, fmt.Errorf("unsupported preset source %q (expected a file path or an http, https, s3 or gs URL)", source)
+	}
+
+	cachePath := ""
+	if opts.CacheDir != "" {
+		sum := sha256.Sum256([]byte(source))
+		cachePath = filepath.Join(opts.CacheDir, hex.EncodeToString(sum[:8])+".yaml")
+	}
+
+	if opts.Offline {
+		if cachePath == "" {
+			return Preset{}, fmt.Errorf("preset %s cannot be fetched in offline mode", source)
+		}
+		p, err := readPresetFile(cachePath)
+		if errors.Is(err, os.ErrNotExist) {
+			return Preset{}, fmt.Errorf("preset %s cannot be fetched in offline mode and has not been cached", source)
+		}
+		return p, err
+	}
+
+	data, err := fetchPreset(ctx, fetchURL, opts.Token)
+	if err == nil {
+		var p Preset
+		if err = yaml.Unmarshal(data, &p); err == nil {
+			if cachePath != "" {
+				writePresetCache(cachePath, data)
+			}
+			return p, nil
+		}
+		err = fmt.Errorf("failed to parse preset %s: %w", source, err)
+	}
+	if cachePath == "" {
+		return Preset{}, err
+	}
+	cached, cacheErr := readPresetFile(cachePath)
+	if cacheErr != nil {
+		return Preset{}, err
+	}
+	slog.Warn("Using the cached preset", "error", err)
+	return cached, nil
+}
+
+func fetchPreset(ctx context.Context, fetchURL, token string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, presetTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fetchURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch preset: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch preset %s: %s", fetchURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch preset: %w", err)
+	}
+	return data, nil
+}
+
+func readPresetFile(path string) (Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Preset{}, fmt.Errorf("failed to read preset: %w", err)
+	}
+	var p Preset
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return Preset{}, fmt.Errorf("failed to parse preset %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// writePresetCache saves a fetched preset; a cache that cannot be written is only a warning
+func writePresetCache(path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		slog.Warn("Failed to cache the preset", "error", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		slog.Warn("Failed to cache the preset", "error", err)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultWatchInterval is how often Watch checks the file for changes
+const DefaultWatchInterval = 5 * time.Second
+
+// Watch checks the configuration file at path every interval and calls apply
+// with its new contents whenever they change, until ctx is cancelled. A file
+// that cannot be read or parsed, or that apply rejects, leaves the previous
+// configuration in effect: the error is passed to onError and the same
+// contents are not retried until the file changes again.
+func Watch(ctx context.Context, path string, interval time.Duration, apply func(*File) error, onError func(error)) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	last, _ := fileHash(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sum, data := fileHash(path)
+		if bytes.Equal(sum, last) {
+			continue
+		}
+		last = sum
+
+		if data == nil {
+			onError(fmt.Errorf("failed to read config %s", path))
+			continue
+		}
+		var f File
+		if err := yaml.Unmarshal(data, &f); err != nil {
+			onError(fmt.Errorf("failed to parse config %s: %w", path, err))
+			continue
+		}
+		if err := apply(&f); err != nil {
+			onError(fmt.Errorf("rejected config %s: %w", path, err))
+		}
+	}
+}
+
+// fileHash returns the SHA-256 of the file at path and its contents, or nil
+// values when it cannot be read
+func fileHash(path string) ([]byte, []byte) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	sum := sha256.Sum256(data)
+	return sum[:], data
+}
@@ -0,0 +1,189 @@
+// Package contexts selects the scan contexts findings are fetched from, such as
+// the main branch, another ref or a CI run, and merges findings fetched from
+// several of them, recording which contexts each finding was found in.
+package contexts
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Prefix of the context types accepted by the Endor API
+const Prefix = "CONTEXT_TYPE_"
+
+// Well-known context types
+const (
+	Main  = "CONTEXT_TYPE_MAIN"
+	Ref   = "CONTEXT_TYPE_REF"
+	CIRun = "CONTEXT_TYPE_CI_RUN"
+)
+
+// Default is the context findings are fetched from unless others are selected
+const Default = "main"
+
+// Spec selects one context
+type Spec struct {
+	// Label is the spec as given, e.g. main, ref:release-2.x or ci-run
+	Label string
+	// Type is the context type in API form, e.g. CONTEXT_TYPE_CI_RUN
+	Type string
+	// ID is the context ID, such as a branch name; empty matches every ID
+	ID string
+}
+
+// Latest reports whether s selects the most recent CI run rather than a given one
+func (s Spec) Latest() bool {
+	return s.Type == CIRun && s.ID == ""
+}
+
+// Matches reports whether f was found in the context s selects
+func (s Spec) Matches(f api.Finding) bool {
+	return f.Context.Type == s.Type && (s.ID == "" || f.Context.ID == s.ID)
+}
+
+// Parse parses a context spec: a type such as main, ref or ci-run, optionally
+// followed by a context ID, e.g. ref:release-2.x or ci-run:6650e3a8
+func Parse(s string) (Spec, error) {
+	label := strings.TrimSpace(s)
+	name, id, _ := strings.Cut(label, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Spec{}, fmt.Errorf("invalid context %q (expected a type such as main, ref:<branch> or ci-run)", s)
+	}
+
+	typ := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if !strings.HasPrefix(typ, Prefix) {
+		typ = Prefix + typ
+	}
+	if strings.ContainsAny(typ+id, "\"[]()") {
+		return Spec{}, fmt.Errorf("invalid character in context %q", s)
+	}
+	if typ == Ref && id == "" {
+		return Spec{}, fmt.Errorf("context %q needs a ref name, e.g. ref:main", s)
+	}
+	return Spec{Label: label, Type: typ, ID: strings.TrimSpace(id)}, nil
+}
+
+// ParseList parses a comma-separated list of specs; an empty list selects Default
+func ParseList(list string) ([]Spec, error) {
+	var specs []Spec
+	seen := make(map[string]bool)
+	for _, s := range strings.Split(list, ",") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		spec, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		if key := spec.Type + ":" + spec.ID; !seen[key] {
+			seen[key] = true
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) == 0 {
+		spec, _ := Parse(Default)
+		specs = []Spec{spec}
+	}
+	return specs, nil
+}
+
+// Merge narrows findings fetched from specs to what each spec selects, keeping
+// only the latest run of each project for a CI run spec without an ID. With
+// several specs, findings of the same vulnerability and package found in more
+// than one context are merged: the finding of the first spec listed is kept,
+// and every finding's Contexts lists the labels of the contexts it was found in.
+// key identifies the same finding across contexts.
+func Merge(findings []api.Finding, specs []Spec, key func(api.Finding) string) []api.Finding {
+	bySpec := make([][]api.Finding, len(specs))
+	for _, f := range findings {
+		// Findings without a context, e.g. from older snapshots, count as the first
+		if f.Context.Type == "" {
+			bySpec[0] = append(bySpec[0], f)
+			continue
+		}
+		for i, s := range specs {
+			if s.Matches(f) {
+				bySpec[i] = append(bySpec[i], f)
+				break
+			}
+		}
+	}
+	for i, s := range specs {
+		if s.Latest() {
+			bySpec[i] = latestRuns(bySpec[i])
+		}
+	}
+
+	if len(specs) == 1 {
+		return bySpec[0]
+	}
+
+	var merged []api.Finding
+	index := make(map[string]int)
+	counts := make([]string, len(specs))
+	shared := 0
+	for i, s := range specs {
+		counts[i] = fmt.Sprintf("%s %d", s.Label, len(bySpec[i]))
+		for _, f := range bySpec[i] {
+			k := key(f)
+			if j, ok := index[k]; ok {
+				if !contains(merged[j].Contexts, s.Label) {
+					if len(merged[j].Contexts) == 1 {
+						shared++
+					}
+					merged[j].Contexts = append(merged[j].Contexts, s.Label)
+				}
+				continue
+			}
+			f.Contexts = []string{s.Label}
+			index[k] = len(merged)
+			merged = append(merged, f)
+		}
+	}
+	slog.Info("Merged findings across contexts", "contexts", strings.Join(counts, ", "), "distinct", len(merged), "shared", shared)
+	return merged
+}
+
+// latestRuns keeps the findings of the most recent context of each project,
+// going by the findings' creation times
+func latestRuns(findings []api.Finding) []api.Finding {
+	// newest maps each project to its context with the latest finding
+	type run struct{ id, created string }
+	newest := make(map[string]run)
+	for _, f := range findings {
+		r, ok := newest[f.Spec.ProjectUUID]
+		if !ok || f.Meta.CreateTime > r.created || (f.Meta.CreateTime == r.created && f.Context.ID > r.id) {
+			newest[f.Spec.ProjectUUID] = run{id: f.Context.ID, created: f.Meta.CreateTime}
+		}
+	}
+
+	var kept []api.Finding
+	for _, f := range findings {
+		if newest[f.Spec.ProjectUUID].id == f.Context.ID {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// Labels returns the labels of specs
+func Labels(specs []Spec) []string {
+	labels := make([]string, len(specs))
+	for i, s := range specs {
+		labels[i] = s.Label
+	}
+	return labels
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+// Package correlate groups findings for the same vulnerability and package
+// across projects that share a repository origin, such as forks and mirrors, so
+// remediation can be tracked once per logical codebase.
+package correlate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Modes decide which projects share an origin
+const (
+	// ByRepo treats repositories with the same owner and name as one origin,
+	// whatever their host: mirrors, e.g. github.com/acme/app and ghe.acme.io/acme/app
+	ByRepo = "repo"
+	// ByName treats repositories with the same name as one origin, whatever
+	// their host and owner: forks, e.g. github.com/acme/app and github.com/alice/app
+	ByName = "name"
+)
+
+// Modes lists the supported modes
+var Modes = []string{ByRepo, ByName}
+
+// ValidMode reports whether mode is supported
+func ValidMode(mode string) bool {
+	return mode == ByRepo || mode == ByName
+}
+
+// Group is one vulnerability in one package found in several projects with the
+// same origin
+type Group struct {
+	ID            string           `json:"id"`
+	Origin        string           `json:"origin"`
+	Vulnerability string           `json:"vulnerability"`
+	Package       string           `json:"package"`
+	Level         api.FindingLevel `json:"level"`
+	Projects      []string         `json:"projects"`
+	Findings      []string         `json:"findings"`
+}
+
+// Origin reduces a repository URL to the origin it shares with its forks or
+// mirrors under mode, or "" when the URL is empty
+func Origin(repoURL, mode string) string {
+	repo := api.NormalizeRepoURL(repoURL)
+	if repo == "" {
+		return ""
+	}
+
+	// Drop the host, keeping owner/name (or group/subgroup/name on GitLab)
+	if i := strings.Index(repo, "/"); i >= 0 {
+		repo = repo[i+1:]
+	}
+	if mode == ByName {
+		return path.Base(repo)
+	}
+	return repo
+}
+
+// key returns what identifies f within an origin: its vulnerability and package.
+// ok is false when f cannot be correlated.
+func key(f api.Finding, mode string) (origin, vuln, pkg string, ok bool) {
+	if f.Project == nil {
+		return "", "", "", false
+	}
+	origin = Origin(f.Project.RepoURL, mode)
+
+	vuln = f.CVE()
+	if vuln == "" {
+		if ids := f.VulnerabilityIDs(); len(ids) > 0 {
+			vuln = ids[0]
+		} else {
+			vuln = f.Meta.Name
+		}
+	}
+	pkg = f.Spec.TargetDependencyPackageName
+
+	return origin, vuln, pkg, origin != "" && vuln != "" && pkg != ""
+}
+
+// Correlate groups findings sharing an origin, vulnerability and package.
+// Only groups spanning more than one project are returned, sorted by origin,
+// vulnerability and package. Findings need their Project resolved.
+func Correlate(findings []api.Finding, mode string) []Group {
+	byKey := make(map[string]*Group)
+	projects := make(map[string]map[string]bool)
+
+	for _, f := range findings {
+		origin, vuln, pkg, ok := key(f, mode)
+		if !ok {
+			continue
+		}
+		k := origin + "\x00" + vuln + "\x00" + pkg
+
+		g, exists := byKey[k]
+		if !exists {
+			sum := sha256.Sum256([]byte(mode + "\x00" + k))
+			g = &Group{
+				ID:            hex.EncodeToString(sum[:6]),
+				Origin:        origin,
+				Vulnerability: vuln,
+				Package:       pkg,
+			}
+			byKey[k] = g
+			projects[k] = make(map[string]bool)
+		}
+		if f.Spec.Level.Rank() > g.Level.Rank() {
+			g.Level = f.Spec.Level
+		}
+		g.Findings = append(g.Findings, f.UUID)
+		if !projects[k][f.Spec.ProjectUUID] {
+			projects[k][f.Spec.ProjectUUID] = true
+			g.Projects = append(g.Projects, f.Spec.ProjectUUID)
+		}
+	}
+
+	var groups []Group
+	for _, g := range byKey {
+		if len(g.Projects) > 1 {
+			sort.Strings(g.Projects)
+			groups = append(groups, *g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if a.Origin != b.Origin {
+			return a.Origin < b.Origin
+		}
+		if a.Vulnerability != b.Vulnerability {
+			return a.Vulnerability < b.Vulnerability
+		}
+		return a.Package < b.Package
+	})
+	return groups
+}
+
+// Summary describes groups for logs, e.g. "12 findings in 3 groups"
+func Summary(groups []Group) string {
+	n := 0
+	for _, g := range groups {
+		n += len(g.Findings)
+	}
+	return fmt.Sprintf("%d findings in %d groups", n, len(groups))
+}
+
+// Annotate sets the CorrelationID of every finding that belongs to one of groups
+func Annotate(findings []api.Finding, groups []Group) {
+	ids := make(map[string]string)
+	for _, g := range groups {
+		for _, uuid := range g.Findings {
+			ids[uuid] = g.ID
+		}
+	}
+	for i := range findings {
+		if id, ok := ids[findings[i].UUID]; ok {
+			findings[i].CorrelationID = id
+		}
+	}
+}
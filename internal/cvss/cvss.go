@@ -0,0 +1,314 @@
+// Package cvss parses CVSS v3.0, v3.1 and v4.0 vectors and computes their
+// base, temporal and environmental scores, so findings can be ranked and
+// filtered by more than their severity string, with scores adjusted to the
+// environment the software runs in.
+package cvss
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CVSS versions
+const (
+	Version30 = "3.0"
+	Version31 = "3.1"
+	Version40 = "4.0"
+)
+
+// metricDef is a metric of a version with its allowed values
+type metricDef struct {
+	values   []string
+	required bool
+}
+
+var (
+	v3Impact   = []string{"H", "L", "N"}
+	reqValues  = []string{"X", "H", "M", "L"}
+	v3Metrics  = map[string]metricDef{}
+	v4Metrics  = map[string]metricDef{}
+	v3Order    = []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A", "E", "RL", "RC", "CR", "IR", "AR", "MAV", "MAC", "MPR", "MUI", "MS", "MC", "MI", "MA"}
+	v4Order    = []string{"AV", "AC", "AT", "PR", "UI", "VC", "VI", "VA", "SC", "SI", "SA", "E", "CR", "IR", "AR", "MAV", "MAC", "MAT", "MPR", "MUI", "MVC", "MVI", "MVA", "MSC", "MSI", "MSA", "S", "AU", "R", "V", "RE", "U"}
+	v3Temporal = []string{"E", "RL", "RC"}
+)
+
+func init() {
+	required := func(m map[string]metricDef, name string, values ...string) {
+		m[name] = metricDef{values: values, required: true}
+	}
+	optional := func(m map[string]metricDef, name string, values ...string) {
+		m[name] = metricDef{values: append([]string{"X"}, values...)}
+	}
+
+	required(v3Metrics, "AV", "N", "A", "L", "P")
+	required(v3Metrics, "AC", "L", "H")
+	required(v3Metrics, "PR", "N", "L", "H")
+	required(v3Metrics, "UI", "N", "R")
+	required(v3Metrics, "S", "U", "C")
+	required(v3Metrics, "C", v3Impact...)
+	required(v3Metrics, "I", v3Impact...)
+	required(v3Metrics, "A", v3Impact...)
+	optional(v3Metrics, "E", "H", "F", "P", "U")
+	optional(v3Metrics, "RL", "U", "W", "T", "O")
+	optional(v3Metrics, "RC", "C", "R", "U")
+	for _, m := range []string{"CR", "IR", "AR"} {
+		v3Metrics[m] = metricDef{values: reqValues}
+	}
+	optional(v3Metrics, "MAV", "N", "A", "L", "P")
+	optional(v3Metrics, "MAC", "L", "H")
+	optional(v3Metrics, "MPR", "N", "L", "H")
+	optional(v3Metrics, "MUI", "N", "R")
+	optional(v3Metrics, "MS", "U", "C")
+	optional(v3Metrics, "MC", v3Impact...)
+	optional(v3Metrics, "MI", v3Impact...)
+	optional(v3Metrics, "MA", v3Impact...)
+
+	required(v4Metrics, "AV", "N", "A", "L", "P")
+	required(v4Metrics, "AC", "L", "H")
+	required(v4Metrics, "AT", "N", "P")
+	required(v4Metrics, "PR", "N", "L", "H")
+	required(v4Metrics, "UI", "N", "P", "A")
+	for _, m := range []string{"VC", "VI", "VA", "SC", "SI", "SA"} {
+		required(v4Metrics, m, v3Impact...)
+	}
+	optional(v4Metrics, "E", "A", "P", "U")
+	for _, m := range []string{"CR", "IR", "AR"} {
+		v4Metrics[m] = metricDef{values: reqValues}
+	}
+	optional(v4Metrics, "MAV", "N", "A", "L", "P")
+	optional(v4Metrics, "MAC", "L", "H")
+	optional(v4Metrics, "MAT", "N", "P")
+	optional(v4Metrics, "MPR", "N", "L", "H")
+	optional(v4Metrics, "MUI", "N", "P", "A")
+	for _, m := range []string{"MVC", "MVI", "MVA", "MSC"} {
+		optional(v4Metrics, m, v3Impact...)
+	}
+	// Safety impacts on subsequent systems
+	optional(v4Metrics, "MSI", "S", "H", "L", "N")
+	optional(v4Metrics, "MSA", "S", "H", "L", "N")
+	optional(v4Metrics, "S", "N", "P")
+	optional(v4Metrics, "AU", "N", "Y")
+	optional(v4Metrics, "R", "A", "U", "I")
+	optional(v4Metrics, "V", "D", "C")
+	optional(v4Metrics, "RE", "L", "M", "H")
+	optional(v4Metrics, "U", "Clear", "Green", "Amber", "Red")
+}
+
+// Vector is a parsed CVSS vector
+type Vector struct {
+	// Version is 3.0, 3.1 or 4.0
+	Version string
+	// Metrics maps metric abbreviations to their values, e.g. AV to N.
+	// Metrics left out of the vector are absent.
+	Metrics map[string]string
+}
+
+// Parse parses a CVSS v3.0, v3.1 or v4.0 vector such as
+// CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H
+func Parse(s string) (*Vector, error) {
+	prefix, rest, ok := strings.Cut(strings.TrimSpace(s), "/")
+	version, hasVersion := strings.CutPrefix(prefix, "CVSS:")
+	if !ok || !hasVersion {
+		return nil, fmt.Errorf("invalid CVSS vector %q: expected a CVSS:3.x or CVSS:4.0 prefix", s)
+	}
+	defs := metricsOf(version)
+	if defs == nil {
+		return nil, fmt.Errorf("invalid CVSS vector %q: unsupported version %s", s, version)
+	}
+
+	v := &Vector{Version: version, Metrics: make(map[string]string)}
+	if err := v.set(defs, rest); err != nil {
+		return nil, fmt.Errorf("invalid CVSS vector %q: %w", s, err)
+	}
+	var missing []string
+	for name, def := range defs {
+		if _, ok := v.Metrics[name]; def.required && !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("invalid CVSS vector %q: missing base metrics %s", s, strings.Join(missing, ", "))
+	}
+	return v, nil
+}
+
+// set parses metrics of the form AV:N/AC:L into v
+func (v *Vector) set(defs map[string]metricDef, metrics string) error {
+	for _, part := range strings.Split(metrics, "/") {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return fmt.Errorf("malformed metric %q", part)
+		}
+		def, known := defs[name]
+		if !known {
+			return fmt.Errorf("unknown metric %s", name)
+		}
+		if !contains(def.values, value) {
+			return fmt.Errorf("invalid value %s of metric %s (expected %s)", value, name, strings.Join(def.values, ", "))
+		}
+		if _, dup := v.Metrics[name]; dup {
+			return fmt.Errorf("metric %s is repeated", name)
+		}
+		v.Metrics[name] = value
+	}
+	return nil
+}
+
+// String returns the vector in the specification's metric order
+func (v *Vector) String() string {
+	order := v3Order
+	if v.Version == Version40 {
+		order = v4Order
+	}
+	parts := []string{"CVSS:" + v.Version}
+	for _, name := range order {
+		if value, ok := v.Metrics[name]; ok {
+			parts = append(parts, name+":"+value)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// Get returns the value of a metric, X (not defined) when it is absent
+func (v *Vector) Get(name string) string {
+	if value, ok := v.Metrics[name]; ok {
+		return value
+	}
+	return "X"
+}
+
+// has reports whether any of the metrics is defined
+func (v *Vector) has(names ...string) bool {
+	for _, name := range names {
+		if v.Get(name) != "X" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTemporal reports whether the vector defines temporal metrics, the threat
+// metric E of v4.0
+func (v *Vector) hasTemporal() bool {
+	if v.Version == Version40 {
+		return v.has("E")
+	}
+	return v.has(v3Temporal...)
+}
+
+// HasEnvironmental reports whether the vector defines environmental metrics
+func (v *Vector) HasEnvironmental() bool {
+	for name := range v.Metrics {
+		if isEnvironmental(name) && v.Metrics[name] != "X" {
+			return true
+		}
+	}
+	return false
+}
+
+// Environment is a set of environmental metrics, e.g. CR:H/IR:H/MAV:L, that
+// describes where the software runs: how much its confidentiality, integrity
+// and availability matter and how exposed it is
+type Environment map[string]string
+
+// ParseEnvironment parses environmental metrics of the form CR:H/MAV:L. Each
+// metric must be an environmental metric of CVSS v3.1 or v4.0.
+func ParseEnvironment(s string) (Environment, error) {
+	env := make(Environment)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return env, nil
+	}
+	for _, part := range strings.Split(s, "/") {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed environmental metric %q (expected NAME:VALUE)", part)
+		}
+		if !isEnvironmental(name) {
+			return nil, fmt.Errorf("%s is not an environmental metric", name)
+		}
+		d3, in3 := v3Metrics[name]
+		d4, in4 := v4Metrics[name]
+		if !(in3 && contains(d3.values, value)) && !(in4 && contains(d4.values, value)) {
+			return nil, fmt.Errorf("invalid value %s of environmental metric %s", value, name)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// String returns the metrics in a stable order
+func (e Environment) String() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ":" + e[name]
+	}
+	return strings.Join(parts, "/")
+}
+
+// Adjust returns a copy of v with the metrics of env that its version defines
+// replacing its own. Metrics of the other version, such as MS for a v4.0
+// vector, are left out.
+func (v *Vector) Adjust(env Environment) *Vector {
+	adjusted := &Vector{Version: v.Version, Metrics: make(map[string]string, len(v.Metrics)+len(env))}
+	for name, value := range v.Metrics {
+		adjusted.Metrics[name] = value
+	}
+	defs := metricsOf(v.Version)
+	for name, value := range env {
+		if def, ok := defs[name]; ok && contains(def.values, value) {
+			adjusted.Metrics[name] = value
+		}
+	}
+	return adjusted
+}
+
+// Severity returns the qualitative rating of a score: None, Low, Medium,
+// High or Critical
+func Severity(score float64) string {
+	switch {
+	case score >= 9:
+		return "Critical"
+	case score >= 7:
+		return "High"
+	case score >= 4:
+		return "Medium"
+	case score > 0:
+		return "Low"
+	}
+	return "None"
+}
+
+func metricsOf(version string) map[string]metricDef {
+	switch version {
+	case Version30, Version31:
+		return v3Metrics
+	case Version40:
+		return v4Metrics
+	}
+	return nil
+}
+
+func isEnvironmental(name string) bool {
+	switch name {
+	case "CR", "IR", "AR":
+		return true
+	}
+	return strings.HasPrefix(name, "M")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
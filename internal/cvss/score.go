@@ -0,0 +1,157 @@
+package cvss
+
+import "math"
+
+// Scores are the scores of a CVSS vector
+type Scores struct {
+	Base float64 `json:"base"`
+	// Temporal is the base score adjusted by the exploit code maturity,
+	// remediation level and report confidence, or the exploit maturity of
+	// v4.0; it equals Base when the vector has no temporal metrics
+	Temporal float64 `json:"temporal"`
+	// Environmental is the score with the security requirements and modified
+	// base metrics applied
+	Environmental float64 `json:"environmental"`
+}
+
+// weights of the v3 metric values
+var v3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"E":  {"X": 1, "H": 1, "F": 0.97, "P": 0.94, "U": 0.91},
+	"RL": {"X": 1, "U": 1, "W": 0.97, "T": 0.96, "O": 0.95},
+	"RC": {"X": 1, "C": 1, "R": 0.96, "U": 0.92},
+	"CR": {"X": 1, "H": 1.5, "M": 1, "L": 0.5},
+}
+
+// privileges returns the weight of a privileges required value, which
+// depends on whether the scope changes
+func privileges(value string, changed bool) float64 {
+	switch value {
+	case "L":
+		if changed {
+			return 0.68
+		}
+		return 0.62
+	case "H":
+		if changed {
+			return 0.5
+		}
+		return 0.27
+	}
+	return 0.85
+}
+
+// Score computes the base, temporal and environmental scores of the vector as
+// the specification of its version defines them. The scores of v4.0 vectors
+// are CVSS-B, CVSS-BT and CVSS-BTE.
+func (v *Vector) Score() (Scores, error) {
+	if v.Version == Version40 {
+		return Scores{
+			Base:          v.score40(false, false),
+			Temporal:      v.score40(true, false),
+			Environmental: v.score40(true, true),
+		}, nil
+	}
+	w := func(metric, value string) float64 {
+		switch metric {
+		case "I", "A":
+			metric = "C"
+		case "IR", "AR":
+			metric = "CR"
+		}
+		return v3Weights[metric][value]
+	}
+	roundup := roundup31
+	if v.Version == Version30 {
+		roundup = roundup30
+	}
+
+	// Base
+	changed := v.Get("S") == "C"
+	iss := 1 - (1-w("C", v.Get("C")))*(1-w("I", v.Get("I")))*(1-w("A", v.Get("A")))
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	exploitability := 8.22 * w("AV", v.Get("AV")) * w("AC", v.Get("AC")) * privileges(v.Get("PR"), changed) * w("UI", v.Get("UI"))
+	var s Scores
+	if impact > 0 {
+		if changed {
+			s.Base = roundup(math.Min(1.08*(impact+exploitability), 10))
+		} else {
+			s.Base = roundup(math.Min(impact+exploitability, 10))
+		}
+	}
+
+	// Temporal
+	temporal := w("E", v.Get("E")) * w("RL", v.Get("RL")) * w("RC", v.Get("RC"))
+	s.Temporal = s.Base
+	if v.hasTemporal() {
+		s.Temporal = roundup(s.Base * temporal)
+	}
+
+	// Environmental: modified base metrics default to the base metrics
+	if !v.HasEnvironmental() {
+		s.Environmental = s.Temporal
+		return s, nil
+	}
+	modified := func(name string) string {
+		if value := v.Get("M" + name); value != "X" {
+			return value
+		}
+		return v.Get(name)
+	}
+	mchanged := modified("S") == "C"
+	miss := math.Min(1-
+		(1-w("CR", v.Get("CR"))*w("C", modified("C")))*
+			(1-w("IR", v.Get("IR"))*w("I", modified("I")))*
+			(1-w("AR", v.Get("AR"))*w("A", modified("A"))), 0.915)
+	mimpact := 6.42 * miss
+	if mchanged {
+		if v.Version == Version30 {
+			mimpact = 7.52*(miss-0.029) - 3.25*math.Pow(miss-0.02, 15)
+		} else {
+			mimpact = 7.52*(miss-0.029) - 3.25*math.Pow(miss*0.9731-0.02, 13)
+		}
+	}
+	mexploitability := 8.22 * w("AV", modified("AV")) * w("AC", modified("AC")) * privileges(modified("PR"), mchanged) * w("UI", modified("UI"))
+	if mimpact > 0 {
+		if mchanged {
+			s.Environmental = roundup(roundup(math.Min(1.08*(mimpact+mexploitability), 10)) * temporal)
+		} else {
+			s.Environmental = roundup(roundup(math.Min(mimpact+mexploitability, 10)) * temporal)
+		}
+	}
+	return s, nil
+}
+
+// Effective returns the score that applies to the vector: environmental when
+// it has environmental metrics, else temporal when it has temporal metrics,
+// else base
+func (s Scores) Effective(v *Vector) float64 {
+	switch {
+	case v.HasEnvironmental():
+		return s.Environmental
+	case v.hasTemporal():
+		return s.Temporal
+	}
+	return s.Base
+}
+
+// roundup31 is the Roundup function of CVSS v3.1, which avoids floating
+// point errors by rounding to five decimal places first
+func roundup31(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
+
+// roundup30 is the Roundup function of CVSS v3.0
+func roundup30(x float64) float64 {
+	return math.Ceil(x*10) / 10
+}
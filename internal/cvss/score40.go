@@ -0,0 +1,226 @@
+package cvss
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// v4Levels are the severity levels of the v4.0 metric values scored, 0 the
+// most severe
+var v4Levels = map[string]map[string]int{
+	"AV": {"N": 0, "A": 1, "L": 2, "P": 3},
+	"PR": {"N": 0, "L": 1, "H": 2},
+	"UI": {"N": 0, "P": 1, "A": 2},
+	"AC": {"L": 0, "H": 1},
+	"AT": {"N": 0, "P": 1},
+	"VC": {"H": 0, "L": 1, "N": 2},
+	"VI": {"H": 0, "L": 1, "N": 2},
+	"VA": {"H": 0, "L": 1, "N": 2},
+	"SC": {"H": 0, "L": 1, "N": 2},
+	"SI": {"S": 0, "H": 1, "L": 2, "N": 3},
+	"SA": {"S": 0, "H": 1, "L": 2, "N": 3},
+	"CR": {"H": 0, "M": 1, "L": 2},
+	"IR": {"H": 0, "M": 1, "L": 2},
+	"AR": {"H": 0, "M": 1, "L": 2},
+}
+
+// v4Scored are the metrics whose distance to the most severe vectors of
+// their macro vector is measured
+var v4Scored = []string{"AV", "PR", "UI", "AC", "AT", "VC", "VI", "VA", "SC", "SI", "SA", "CR", "IR", "AR"}
+
+// The most severe vectors of each level of the equivalence sets. EQ3 and EQ6
+// are joint, indexed by the level of EQ3 and then of EQ6.
+var (
+	v4MaxEQ1 = [][]string{
+		{"AV:N/PR:N/UI:N"},
+		{"AV:A/PR:N/UI:N", "AV:N/PR:L/UI:N", "AV:N/PR:N/UI:P"},
+		{"AV:P/PR:N/UI:N", "AV:A/PR:L/UI:P"},
+	}
+	v4MaxEQ2    = [][]string{{"AC:L/AT:N"}, {"AC:H/AT:N", "AC:L/AT:P"}}
+	v4MaxEQ3EQ6 = [][][]string{
+		{
+			{"VC:H/VI:H/VA:H/CR:H/IR:H/AR:H"},
+			{"VC:H/VI:H/VA:L/CR:M/IR:M/AR:H", "VC:H/VI:H/VA:H/CR:M/IR:M/AR:M"},
+		},
+		{
+			{"VC:L/VI:H/VA:H/CR:H/IR:H/AR:H", "VC:H/VI:L/VA:H/CR:H/IR:H/AR:H"},
+			{"VC:L/VI:H/VA:H/CR:H/IR:M/AR:M", "VC:H/VI:L/VA:H/CR:M/IR:H/AR:M", "VC:H/VI:L/VA:L/CR:M/IR:H/AR:H", "VC:L/VI:H/VA:L/CR:H/IR:M/AR:H", "VC:L/VI:L/VA:H/CR:H/IR:H/AR:M"},
+		},
+		{
+			nil,
+			{"VC:L/VI:L/VA:L/CR:H/IR:H/AR:H"},
+		},
+	}
+	v4MaxEQ4 = [][]string{{"SC:H/SI:S/SA:S"}, {"SC:H/SI:H/SA:H"}, {"SC:L/SI:L/SA:L"}}
+)
+
+// The depths of the levels of the equivalence sets plus one: the severity
+// distance, in levels, between the most and the least severe vectors of a
+// macro vector
+var (
+	v4DepthEQ1    = []float64{1, 4, 5}
+	v4DepthEQ2    = []float64{1, 2}
+	v4DepthEQ3EQ6 = [][]float64{{7, 6}, {8, 8}, {0, 10}}
+	v4DepthEQ4    = []float64{6, 5, 4}
+)
+
+// score40 computes the score of a v4.0 vector: the score of its macro vector
+// in the lookup table, lowered by how far the vector is from the most severe
+// vectors of that macro vector, in proportion to the score of the next lower
+// macro vectors. The threat and environmental metrics are ignored unless
+// threat and environmental are set.
+func (v *Vector) score40(threat, environmental bool) float64 {
+	m := func(name string) string {
+		if environmental {
+			if value := v.Get("M" + name); value != "X" {
+				return value
+			}
+		}
+		value := v.Get(name)
+		switch name {
+		case "E":
+			if value == "X" || !threat {
+				return "A"
+			}
+		case "CR", "IR", "AR":
+			if value == "X" || !environmental {
+				return "H"
+			}
+		}
+		return value
+	}
+	none := true
+	for _, name := range []string{"VC", "VI", "VA", "SC", "SI", "SA"} {
+		none = none && m(name) == "N"
+	}
+	if none {
+		return 0
+	}
+
+	eq := macroVector40(m)
+	value := v4Lookup[macroKey(eq)]
+
+	// The score lost to the next lower macro vector of each equivalence set
+	lower := func(eq [6]int, at int) (float64, bool) {
+		eq[at]++
+		score, ok := v4Lookup[macroKey(eq)]
+		return value - score, ok
+	}
+	drop1, ok1 := lower(eq, 0)
+	drop2, ok2 := lower(eq, 1)
+	drop4, ok4 := lower(eq, 3)
+	drop5, ok5 := lower(eq, 4)
+	var drop36 float64
+	var ok36 bool
+	switch {
+	case eq[2] == 0 && eq[5] == 0:
+		// Either EQ3 or EQ6 can be lowered; the higher score is the next
+		d3, _ := lower(eq, 2)
+		d6, _ := lower(eq, 5)
+		drop36, ok36 = math.Min(d3, d6), true
+	case eq[2] == 1 && eq[5] == 0:
+		drop36, ok36 = lower(eq, 5)
+	case eq[2] < 2:
+		drop36, ok36 = lower(eq, 2)
+	}
+
+	dist := v4Distances(m, eq)
+	var sum float64
+	n := 0
+	for _, d := range []struct {
+		drop, dist, depth float64
+		ok                bool
+	}{
+		{drop1, dist["AV"] + dist["PR"] + dist["UI"], v4DepthEQ1[eq[0]], ok1},
+		{drop2, dist["AC"] + dist["AT"], v4DepthEQ2[eq[1]], ok2},
+		{drop36, dist["VC"] + dist["VI"] + dist["VA"] + dist["CR"] + dist["IR"] + dist["AR"], v4DepthEQ3EQ6[eq[2]][eq[5]], ok36},
+		{drop4, dist["SC"] + dist["SI"] + dist["SA"], v4DepthEQ4[eq[3]], ok4},
+		// EQ5 holds a single metric, so every vector is its most severe
+		{drop5, 0, 1, ok5},
+	} {
+		if d.ok {
+			sum += d.drop * d.dist / d.depth
+			n++
+		}
+	}
+	if n > 0 {
+		value -= sum / float64(n)
+	}
+	// The epsilon keeps values such as 4.45 from rounding down
+	return math.Round((math.Max(0, math.Min(value, 10))+1e-6)*10) / 10
+}
+
+// macroVector40 returns the levels of the equivalence sets EQ1 to EQ6 of the
+// metric values m
+func macroVector40(m func(string) string) [6]int {
+	var eq [6]int
+	av, pr, ui := m("AV"), m("PR"), m("UI")
+	switch {
+	case av == "N" && pr == "N" && ui == "N":
+		eq[0] = 0
+	case (av == "N" || pr == "N" || ui == "N") && av != "P":
+		eq[0] = 1
+	default:
+		eq[0] = 2
+	}
+	if m("AC") != "L" || m("AT") != "N" {
+		eq[1] = 1
+	}
+	vc, vi, va := m("VC"), m("VI"), m("VA")
+	switch {
+	case vc == "H" && vi == "H":
+		eq[2] = 0
+	case vc == "H" || vi == "H" || va == "H":
+		eq[2] = 1
+	default:
+		eq[2] = 2
+	}
+	switch {
+	case m("SI") == "S" || m("SA") == "S":
+		eq[3] = 0
+	case m("SC") == "H" || m("SI") == "H" || m("SA") == "H":
+		eq[3] = 1
+	default:
+		eq[3] = 2
+	}
+	eq[4] = map[string]int{"A": 0, "P": 1, "U": 2}[m("E")]
+	if !(m("CR") == "H" && vc == "H" || m("IR") == "H" && vi == "H" || m("AR") == "H" && va == "H") {
+		eq[5] = 1
+	}
+	return eq
+}
+
+// v4Distances returns the severity distance, in levels, of each scored metric
+// to the first of the most severe vectors of macro vector eq that is at least
+// as severe in every metric
+func v4Distances(m func(string) string, eq [6]int) map[string]float64 {
+	for _, eq1 := range v4MaxEQ1[eq[0]] {
+		for _, eq2 := range v4MaxEQ2[eq[1]] {
+			for _, eq36 := range v4MaxEQ3EQ6[eq[2]][eq[5]] {
+				for _, eq4 := range v4MaxEQ4[eq[3]] {
+					max := make(map[string]string)
+					for _, part := range strings.Split(strings.Join([]string{eq1, eq2, eq36, eq4}, "/"), "/") {
+						name, value, _ := strings.Cut(part, ":")
+						max[name] = value
+					}
+					dist := make(map[string]float64, len(v4Scored))
+					below := true
+					for _, name := range v4Scored {
+						d := v4Levels[name][m(name)] - v4Levels[name][max[name]]
+						below = below && d >= 0
+						dist[name] = float64(d)
+					}
+					if below {
+						return dist
+					}
+				}
+			}
+		}
+	}
+	return map[string]float64{}
+}
+
+func macroKey(eq [6]int) string {
+	return fmt.Sprintf("%d%d%d%d%d%d", eq[0], eq[1], eq[2], eq[3], eq[4], eq[5])
+}
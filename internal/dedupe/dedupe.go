@@ -0,0 +1,143 @@
+// Package dedupe collapses findings of the same issue, such as one
+// vulnerability in one package version reported once per child namespace or
+// project, into one finding with a count of its occurrences, so totals count
+// unique issues.
+package dedupe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Key fields
+const (
+	FieldCVE       = "cve"
+	FieldPackage   = "package"
+	FieldVersion   = "version"
+	FieldEcosystem = "ecosystem"
+	FieldProject   = "project"
+	FieldNamespace = "namespace"
+)
+
+// Fields lists the fields a key may combine
+var Fields = []string{FieldCVE, FieldPackage, FieldVersion, FieldEcosystem, FieldProject, FieldNamespace}
+
+// DefaultKey is the key findings are deduplicated by when none is given
+const DefaultKey = "cve,package,version"
+
+// fieldValues extract the value of each key field from a finding
+var fieldValues = map[string]func(api.Finding) string{
+	// Findings without a CVE are told apart by their other advisory IDs, or
+	// else their finding name
+	FieldCVE: func(f api.Finding) string {
+		if cve := f.CVE(); cve != "" {
+			return cve
+		}
+		if ids := f.VulnerabilityIDs(); len(ids) > 0 {
+			return ids[0]
+		}
+		return f.Meta.Name
+	},
+	FieldPackage: func(f api.Finding) string {
+		name, _ := splitPackage(f.Spec.TargetDependencyPackageName)
+		return name
+	},
+	FieldVersion: func(f api.Finding) string {
+		if f.Spec.TargetDependencyVersion != "" {
+			return f.Spec.TargetDependencyVersion
+		}
+		_, version := splitPackage(f.Spec.TargetDependencyPackageName)
+		return version
+	},
+	FieldEcosystem: func(f api.Finding) string { return f.Spec.Ecosystem },
+	FieldProject:   func(f api.Finding) string { return f.Spec.ProjectUUID },
+	FieldNamespace: func(f api.Finding) string { return f.Namespace },
+}
+
+// Key identifies the findings of one issue by a combination of fields
+type Key struct {
+	fields []string
+}
+
+// ParseKey parses a comma-separated list of Fields, e.g. cve,package,version.
+// An empty spec is DefaultKey.
+func ParseKey(spec string) (Key, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultKey
+	}
+	var k Key
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+		if _, ok := fieldValues[field]; !ok {
+			return Key{}, fmt.Errorf("unknown dedupe field %q (expected %s)", field, strings.Join(Fields, ", "))
+		}
+		seen[field] = true
+		k.fields = append(k.fields, field)
+	}
+	return k, nil
+}
+
+// String returns the fields of the key, comma-separated
+func (k Key) String() string {
+	return strings.Join(k.fields, ",")
+}
+
+// Of returns the key of f
+func (k Key) Of(f api.Finding) string {
+	values := make([]string, len(k.fields))
+	for i, field := range k.fields {
+		values[i] = fieldValues[field](f)
+	}
+	return strings.Join(values, "\x00")
+}
+
+// Dedupe returns one finding per key, in the order the keys first appear.
+// The finding kept is the most severe of its duplicates, the first one on a
+// tie; its Occurrences counts them all and Duplicates lists the others.
+// Findings deduplicated before keep their counts, so results of several
+// fetches can be deduplicated again once merged.
+func Dedupe(findings []api.Finding, key Key) []api.Finding {
+	var unique []api.Finding
+	index := make(map[string]int)
+	for _, f := range findings {
+		k := key.Of(f)
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(unique)
+			if f.Occurrences == 0 {
+				f.Occurrences = 1
+			}
+			unique = append(unique, f)
+			continue
+		}
+
+		kept := unique[i]
+		if f.Spec.Level.Rank() > kept.Spec.Level.Rank() {
+			kept, f = f, kept
+			if kept.Occurrences == 0 {
+				kept.Occurrences = 1
+			}
+		}
+		kept.Occurrences += max(f.Occurrences, 1)
+		kept.Duplicates = append(kept.Duplicates, api.Occurrence{UUID: f.UUID, Namespace: f.Namespace, ProjectUUID: f.Spec.ProjectUUID})
+		kept.Duplicates = append(kept.Duplicates, f.Duplicates...)
+		unique[i] = kept
+	}
+	return unique
+}
+
+// splitPackage splits a package name carrying its version, such as
+// npm://lodash@4.17.20, into its name and version. Scoped npm names such as
+// npm://@acme/ui keep their leading @.
+func splitPackage(pkg string) (name, version string) {
+	if i := strings.LastIndex(pkg, "@"); i > 0 && !strings.HasSuffix(pkg[:i], "/") {
+		return pkg[:i], pkg[i+1:]
+	}
+	return pkg, ""
+}
@@ -0,0 +1,264 @@
+// Package defectdojo imports findings into DefectDojo through the import-scan
+// and reimport-scan endpoints of its API v2.
+package defectdojo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scan types the integration can produce a report for
+const (
+	ScanTypeGeneric = "Generic Findings Import"
+	ScanTypeSARIF   = "SARIF"
+)
+
+// ScanTypes lists the supported scan types
+var ScanTypes = []string{ScanTypeGeneric, ScanTypeSARIF}
+
+// Defaults of a Config
+const (
+	DefaultScanType    = ScanTypeGeneric
+	DefaultTestTitle   = "Endor Labs"
+	DefaultProductType = "Endor Labs"
+)
+
+// Config selects the DefectDojo instance and where findings are imported
+type Config struct {
+	URL string
+	// Token is the API v2 key, sent as "Token <key>"
+	Token string
+	// Product and Engagement name where the test is imported; both are created
+	// when missing, the product with ProductType
+	Product     string
+	ProductType string
+	Engagement  string
+	// ScanType is one of ScanTypes
+	ScanType string
+	// TestTitle names the test, which reimports look up to update
+	TestTitle string
+	// MinimumSeverity drops findings below it on DefectDojo's side
+	MinimumSeverity string
+	Tags            []string
+	// CloseOld closes the findings of earlier imports missing from this one
+	CloseOld bool
+	// Reimport updates the engagement's existing test instead of adding a new
+	// one, closing the findings no longer reported
+	Reimport bool
+}
+
+// Result is the outcome of an import
+type Result struct {
+	Test       int
+	Engagement int
+	Product    int
+	// Created, Closed, Reactivated and Untouched count findings by what the
+	// import did to them, when DefectDojo reports statistics
+	Created     int
+	Closed      int
+	Reactivated int
+	Untouched   int
+}
+
+// Client calls the DefectDojo API
+type Client struct {
+	cfg        Config
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient checks cfg and returns a client for it
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("a DefectDojo URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("a DefectDojo API key is required")
+	}
+	if cfg.Product == "" || cfg.Engagement == "" {
+		return nil, errors.New("a DefectDojo product and engagement are required")
+	}
+	if cfg.ScanType == "" {
+		cfg.ScanType = DefaultScanType
+	}
+	if !contains(ScanTypes, cfg.ScanType) {
+		return nil, fmt.Errorf("unsupported scan type %q (expected %q)", cfg.ScanType, ScanTypes)
+	}
+	if cfg.TestTitle == "" {
+		cfg.TestTitle = DefaultTestTitle
+	}
+	if cfg.ProductType == "" {
+		cfg.ProductType = DefaultProductType
+	}
+
+	return &Client{
+		cfg:        cfg,
+		baseURL:    strings.TrimSuffix(strings.TrimRight(cfg.URL, "/"), "/api/v2"),
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Config returns the client's configuration with defaults applied
+func (c *Client) Config() Config { return c.cfg }
+
+// Import uploads report, in the format of the configured scan type, as a
+// test of the engagement scanned on scanDate
+func (c *Client) Import(ctx context.Context, report []byte, scanDate time.Time) (Result, error) {
+	endpoint := "/api/v2/import-scan/"
+	if c.cfg.Reimport {
+		endpoint = "/api/v2/reimport-scan/"
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fields := [][2]string{
+		{"scan_type", c.cfg.ScanType},
+		{"product_name", c.cfg.Product},
+		{"product_type_name", c.cfg.ProductType},
+		{"engagement_name", c.cfg.Engagement},
+		{"test_title", c.cfg.TestTitle},
+		{"auto_create_context", "true"},
+		{"deduplication_on_engagement", "true"},
+		{"active", "true"},
+		{"verified", "false"},
+		{"scan_date", scanDate.Format("2006-01-02")},
+		{"close_old_findings", strconv.FormatBool(c.cfg.CloseOld || c.cfg.Reimport)},
+	}
+	if c.cfg.MinimumSeverity != "" {
+		fields = append(fields, [2]string{"minimum_severity", c.cfg.MinimumSeverity})
+	}
+	for _, tag := range c.cfg.Tags {
+		fields = append(fields, [2]string{"tags", tag})
+	}
+	for _, f := range fields {
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			return Result{}, err
+		}
+	}
+	fw, err := mw.CreateFormFile("file", reportFilename(c.cfg.ScanType))
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err := fw.Write(report); err != nil {
+		return Result{}, err
+	}
+	if err := mw.Close(); err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+endpoint, &body)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Token "+c.cfg.Token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to import findings: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Result{}, fmt.Errorf("failed to import findings: %w", newError(resp))
+	}
+
+	var out struct {
+		Test       int `json:"test"`
+		TestID     int `json:"test_id"`
+		Engagement int `json:"engagement_id"`
+		Product    int `json:"product_id"`
+		Statistics struct {
+			Delta map[string]struct {
+				Total struct {
+					Total int `json:"total"`
+				} `json:"total"`
+			} `json:"delta"`
+		} `json:"statistics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Result{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	delta := out.Statistics.Delta
+	return Result{
+		Test:        firstNonZero(out.Test, out.TestID),
+		Engagement:  out.Engagement,
+		Product:     out.Product,
+		Created:     delta["created"].Total.Total,
+		Closed:      delta["closed"].Total.Total,
+		Reactivated: delta["reactivated"].Total.Total,
+		Untouched:   delta["untouched"].Total.Total,
+	}, nil
+}
+
+// TestURL returns the web URL of a test
+func (c *Client) TestURL(test int) string {
+	return fmt.Sprintf("%s/test/%d", c.baseURL, test)
+}
+
+// reportFilename names the uploaded report after its format
+func reportFilename(scanType string) string {
+	if scanType == ScanTypeSARIF {
+		return "endor-findings.sarif"
+	}
+	return "endor-findings.json"
+}
+
+// newError reads DefectDojo's error body: a detail message, or messages by field
+func newError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var msgs []string
+	var body map[string]any
+	if json.Unmarshal(data, &body) == nil {
+		fields := make([]string, 0, len(body))
+		for field := range body {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			msg := fmt.Sprint(body[field])
+			if list, ok := body[field].([]any); ok && len(list) == 1 {
+				msg = fmt.Sprint(list[0])
+			}
+			if field == "detail" || field == "message" {
+				msgs = append(msgs, msg)
+			} else {
+				msgs = append(msgs, field+": "+msg)
+			}
+		}
+	} else if text := strings.TrimSpace(string(data)); text != "" {
+		msgs = append(msgs, text)
+	}
+	if len(msgs) == 0 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return fmt.Errorf("status %d: %s", resp.StatusCode, strings.Join(msgs, "; "))
+}
+
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
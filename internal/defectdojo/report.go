@@ -0,0 +1,166 @@
+package defectdojo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// severities maps finding levels to DefectDojo severities
+var severities = map[api.FindingLevel]string{
+	api.LevelCritical: "Critical",
+	api.LevelHigh:     "High",
+	api.LevelMedium:   "Medium",
+	api.LevelLow:      "Low",
+}
+
+// genericReport is the Generic Findings Import JSON format
+type genericReport struct {
+	Findings []genericFinding `json:"findings"`
+}
+
+type genericFinding struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Mitigation  string `json:"mitigation,omitempty"`
+	References  string `json:"references,omitempty"`
+	Date        string `json:"date,omitempty"`
+	CWE         int    `json:"cwe,omitempty"`
+	CVSSv3      string `json:"cvssv3,omitempty"`
+	// CVSSv3Score is sent without a vector, which DefectDojo only accepts alone
+	CVSSv3Score      float64                `json:"cvssv3_score,omitempty"`
+	EPSSScore        float64                `json:"epss_score,omitempty"`
+	EPSSPercentile   float64                `json:"epss_percentile,omitempty"`
+	FilePath         string                 `json:"file_path,omitempty"`
+	ComponentName    string                 `json:"component_name,omitempty"`
+	ComponentVersion string                 `json:"component_version,omitempty"`
+	UniqueIDFromTool string                 `json:"unique_id_from_tool"`
+	VulnIDFromTool   string                 `json:"vuln_id_from_tool,omitempty"`
+	VulnerabilityIDs []genericVulnerability `json:"vulnerability_ids,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
+	StaticFinding    bool                   `json:"static_finding"`
+	DynamicFinding   bool                   `json:"dynamic_finding"`
+}
+
+type genericVulnerability struct {
+	ID string `json:"vulnerability_id"`
+}
+
+// GenericReport renders findings as a Generic Findings Import report. The
+// finding UUID is the unique ID from the tool and the first advisory ID the
+// vulnerability ID, which DefectDojo deduplicates and matches reimported
+// findings by. url returns the link that opens a finding in the Endor Labs app.
+func GenericReport(findings []api.Finding, url func(api.Finding) string) ([]byte, error) {
+	report := genericReport{Findings: []genericFinding{}}
+	for _, f := range findings {
+		report.Findings = append(report.Findings, genericFindingOf(f, url(f)))
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render DefectDojo report: %w", err)
+	}
+	return data, nil
+}
+
+// genericFindingOf converts a finding
+func genericFindingOf(f api.Finding, link string) genericFinding {
+	g := genericFinding{
+		Title:            firstNonEmpty(f.Meta.Description, f.Meta.Name, f.UUID),
+		Description:      firstNonEmpty(f.Spec.Explanation, f.Spec.Summary, f.Meta.Description, f.UUID),
+		Severity:         severities[f.Spec.Level],
+		Mitigation:       mitigation(f),
+		ComponentName:    packageName(f),
+		ComponentVersion: f.Spec.TargetDependencyVersion,
+		UniqueIDFromTool: f.UUID,
+		StaticFinding:    true,
+	}
+	if g.Severity == "" {
+		g.Severity = "Info"
+	}
+	if len(f.Meta.CreateTime) >= len("2006-01-02") {
+		g.Date = f.Meta.CreateTime[:len("2006-01-02")]
+	}
+	if len(f.Spec.DependencyFilePath) > 0 {
+		g.FilePath = strings.TrimPrefix(f.Spec.DependencyFilePath[0], "/")
+	}
+
+	ids := f.VulnerabilityIDs()
+	if len(ids) > 0 {
+		g.VulnIDFromTool = ids[0]
+	}
+	for _, id := range ids {
+		g.VulnerabilityIDs = append(g.VulnerabilityIDs, genericVulnerability{ID: id})
+	}
+	if cwes := f.CWEs(); len(cwes) > 0 {
+		g.CWE, _ = strconv.Atoi(strings.TrimPrefix(cwes[0], "CWE-"))
+	}
+	if c := f.CVSS(); c != nil {
+		if strings.HasPrefix(c.Vector, "CVSS:3.") {
+			g.CVSSv3 = c.Vector
+		} else {
+			g.CVSSv3Score = c.Score
+		}
+	}
+	if e := f.EPSS(); e != nil {
+		g.EPSSScore, g.EPSSPercentile = e.ProbabilityScore, e.PercentileScore
+	}
+
+	var refs []string
+	if link != "" {
+		refs = append(refs, link)
+	}
+	for _, id := range ids {
+		switch {
+		case strings.HasPrefix(id, "CVE-"):
+			refs = append(refs, "https://nvd.nist.gov/vuln/detail/"+id)
+		case strings.HasPrefix(id, "GHSA-"):
+			refs = append(refs, "https://github.com/advisories/"+id)
+		}
+	}
+	g.References = strings.Join(refs, "\n")
+
+	g.Tags = append(g.Tags, "endor-labs")
+	for _, c := range f.Spec.FindingCategories {
+		g.Tags = append(g.Tags, c.Short())
+	}
+	if r := f.Reachability(); r != api.ReachabilityUnknown {
+		g.Tags = append(g.Tags, string(r))
+	}
+	return g
+}
+
+// mitigation says how to resolve a finding, when that is known
+func mitigation(f api.Finding) string {
+	if to := f.UpgradeTarget(); to != "" {
+		return fmt.Sprintf("Upgrade %s to %s", firstNonEmpty(packageName(f), "the dependency"), to)
+	}
+	return firstNonEmpty(f.Spec.Remediation, f.Spec.Summary)
+}
+
+// packageName is the dependency of a finding without its ecosystem or version
+func packageName(f api.Finding) string {
+	name := f.Spec.TargetDependencyPackageName
+	if name == "" {
+		name = f.Spec.TargetDependencyName
+		if i := strings.LastIndex(name, "@"); i > 0 {
+			name = name[:i]
+		}
+	}
+	if _, rest, ok := strings.Cut(name, "://"); ok {
+		return rest
+	}
+	return name
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
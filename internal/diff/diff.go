@@ -0,0 +1,140 @@
+// Package diff compares two findings snapshots, such as the JSON exports of two
+// runs, and reports which findings are new, resolved or unchanged.
+package diff
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/snapshot"
+)
+
+// Keys decide when findings of two snapshots are the same
+const (
+	// ByUUID matches findings by UUID
+	ByUUID = "uuid"
+	// ByFingerprint matches findings by project, vulnerability and package name,
+	// so a finding recreated by a rescan or moved to another version of the
+	// package still counts as the same one
+	ByFingerprint = "fingerprint"
+)
+
+// Keys lists the supported keys
+var Keys = []string{ByUUID, ByFingerprint}
+
+// Statuses of a finding in a diff
+const (
+	StatusNew       = "new"
+	StatusResolved  = "resolved"
+	StatusUnchanged = "unchanged"
+)
+
+// Result is the difference between an old and a new snapshot
+type Result struct {
+	Key string `json:"key"`
+	// New are findings of the new snapshot missing from the old one
+	New []api.Finding `json:"new"`
+	// Resolved are findings of the old snapshot missing from the new one
+	Resolved []api.Finding `json:"resolved"`
+	// Unchanged are findings of the new snapshot also in the old one
+	Unchanged []api.Finding `json:"unchanged"`
+}
+
+// Summary describes the counts for logs, e.g. "3 new, 1 resolved, 40 unchanged"
+func (r Result) Summary() string {
+	return fmt.Sprintf("%d new, %d resolved, %d unchanged", len(r.New), len(r.Resolved), len(r.Unchanged))
+}
+
+// KeyFunc returns the function identifying findings under key
+func KeyFunc(key string) (func(api.Finding) string, error) {
+	switch strings.ToLower(key) {
+	case ByUUID, "":
+		return func(f api.Finding) string { return f.UUID }, nil
+	case ByFingerprint:
+		return Fingerprint, nil
+	default:
+		return nil, fmt.Errorf("invalid key %q (expected %s)", key, strings.Join(Keys, " or "))
+	}
+}
+
+// Fingerprint identifies a finding by its project, vulnerability (or finding
+// name, for findings without one) and package name without the version
+func Fingerprint(f api.Finding) string {
+	id := f.CVE()
+	if ids := f.VulnerabilityIDs(); id == "" && len(ids) > 0 {
+		id = ids[0]
+	}
+	if id == "" {
+		id = f.Meta.Name
+	}
+	pkg := f.Spec.TargetDependencyPackageName
+	// Package names may carry the version, e.g. npm://lodash@4.17.20
+	if i := strings.LastIndex(pkg, "@"); i > 0 && !strings.HasSuffix(pkg[:i], "/") {
+		pkg = pkg[:i]
+	}
+	return f.Spec.ProjectUUID + "|" + id + "|" + pkg
+}
+
+// Compare diffs the before and after snapshots, matching findings by key. Each
+// list of the result is sorted by level, most severe first.
+func Compare(before, after []api.Finding, key string) (Result, error) {
+	keyOf, err := KeyFunc(key)
+	if err != nil {
+		return Result{}, err
+	}
+	if key = strings.ToLower(key); key == "" {
+		key = ByUUID
+	}
+
+	inBefore := make(map[string]bool, len(before))
+	for _, f := range before {
+		inBefore[keyOf(f)] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, f := range after {
+		inAfter[keyOf(f)] = true
+	}
+
+	r := Result{Key: key, New: []api.Finding{}, Resolved: []api.Finding{}, Unchanged: []api.Finding{}}
+	for _, f := range after {
+		if inBefore[keyOf(f)] {
+			r.Unchanged = append(r.Unchanged, f)
+		} else {
+			r.New = append(r.New, f)
+		}
+	}
+	for _, f := range before {
+		if !inAfter[keyOf(f)] {
+			r.Resolved = append(r.Resolved, f)
+		}
+	}
+
+	for _, list := range [][]api.Finding{r.New, r.Resolved, r.Unchanged} {
+		sortByLevel(list)
+	}
+	return r, nil
+}
+
+func sortByLevel(findings []api.Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Spec.Level.Rank() > findings[j].Spec.Level.Rank()
+	})
+}
+
+// Load reads the findings of a snapshot: the JSON written by findings export by
+// this or an earlier version, the JSON array printed by findings list --format
+// json, or NDJSON
+func Load(path string) ([]api.Finding, error) {
+	doc, version, err := snapshot.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if version < output.SchemaVersion {
+		slog.Info("Migrated findings file", "path", path, "schema_version", version, "migrated_to", output.SchemaVersion)
+	}
+	return doc.Findings, nil
+}
@@ -0,0 +1,320 @@
+// Package email sends findings reports over SMTP as multipart messages with an
+// HTML body and a plain-text alternative.
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Connection security modes
+const (
+	// TLSStartTLS upgrades the connection with STARTTLS, failing when the
+	// server does not offer it
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects with TLS from the start, as on port 465
+	TLSImplicit = "tls"
+	// TLSNone sends in plain text, for relays on localhost or a trusted network
+	TLSNone = "none"
+)
+
+// DefaultSubject is the subject template of reports
+const DefaultSubject = `{{.Title}}: {{.Counts.critical}} critical, {{.Counts.high}} high, {{.Counts.medium}} medium, {{.Counts.low}} low`
+
+// Config selects the SMTP server, credentials and recipients
+type Config struct {
+	Host string
+	// Port defaults to 465 with TLSImplicit and 587 otherwise
+	Port int
+	// Username and Password authenticate with PLAIN auth when Username is set
+	Username string
+	Password string
+	// TLS is one of the connection security modes; the default is TLSImplicit
+	// on port 465 and TLSStartTLS on any other port
+	TLS  string
+	From string
+	To   []string
+	Cc   []string
+}
+
+// Message is one email
+type Message struct {
+	Subject string
+	// Text is the plain-text body and HTML its HTML alternative; either may be empty
+	Text string
+	HTML string
+}
+
+// Client sends messages through one SMTP server
+type Client struct {
+	cfg  Config
+	from *mail.Address
+	to   []*mail.Address
+	cc   []*mail.Address
+}
+
+// dialTimeout bounds connecting to the server
+const dialTimeout = 30 * time.Second
+
+// NewClient validates cfg and returns a client for it
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("an SMTP host is required")
+	}
+	switch cfg.TLS {
+	case "":
+		cfg.TLS = TLSStartTLS
+		if cfg.Port == 465 {
+			cfg.TLS = TLSImplicit
+		}
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("invalid SMTP TLS mode %q (expected %s, %s or %s)", cfg.TLS, TLSStartTLS, TLSImplicit, TLSNone)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.TLS == TLSImplicit {
+			cfg.Port = 465
+		}
+	}
+	if cfg.From == "" {
+		return nil, errors.New("a sender address is required")
+	}
+	if len(cfg.To) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+
+	c := &Client{cfg: cfg}
+	var err error
+	if c.from, err = mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", cfg.From, err)
+	}
+	if c.to, err = parseAddresses(cfg.To); err != nil {
+		return nil, err
+	}
+	if c.cc, err = parseAddresses(cfg.Cc); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func parseAddresses(list []string) ([]*mail.Address, error) {
+	addrs := make([]*mail.Address, 0, len(list))
+	for _, s := range list {
+		a, err := mail.ParseAddress(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", s, err)
+		}
+		addrs = append(addrs, a)
+	}
+	return addrs, nil
+}
+
+// Recipients returns the To and Cc addresses
+func (c *Client) Recipients() []string {
+	var rcpts []string
+	for _, a := range append(append([]*mail.Address{}, c.to...), c.cc...) {
+		rcpts = append(rcpts, a.Address)
+	}
+	return rcpts
+}
+
+// Send delivers msg to every recipient
+func (c *Client) Send(ctx context.Context, msg Message) error {
+	body, err := c.compose(msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	tlsConfig := &tls.Config{ServerName: c.cfg.Host}
+	var conn net.Conn
+	if c.cfg.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if c.cfg.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS; set the TLS mode to %s to send in plain text", addr, TLSNone)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+	if c.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.from.Address); err != nil {
+		return fmt.Errorf("sender rejected: %w", err)
+	}
+	for _, rcpt := range c.Recipients() {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// compose renders msg as a MIME message. Bodies are quoted-printable, and a
+// message with both bodies is multipart/alternative with HTML last.
+func (c *Client) compose(msg Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&buf, "%s: %s\r\n", name, value) }
+
+	header("From", c.from.String())
+	header("To", joinAddresses(c.to))
+	if len(c.cc) > 0 {
+		header("Cc", joinAddresses(c.cc))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", messageID(c.from.Address))
+	header("MIME-Version", "1.0")
+
+	var parts []textproto.MIMEHeader
+	var bodies []string
+	if msg.Text != "" || msg.HTML == "" {
+		parts = append(parts, partHeader("text/plain; charset=utf-8"))
+		bodies = append(bodies, msg.Text)
+	}
+	if msg.HTML != "" {
+		parts = append(parts, partHeader("text/html; charset=utf-8"))
+		bodies = append(bodies, msg.HTML)
+	}
+
+	if len(parts) == 1 {
+		for name, values := range parts[0] {
+			header(name, values[0])
+		}
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, bodies[0]); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	buf.WriteString("\r\n")
+	for i, h := range parts {
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(pw, bodies[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func partHeader(contentType string) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(body, "\r\n", "\n"))); err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return qp.Close()
+}
+
+func joinAddresses(addrs []*mail.Address) string {
+	s := make([]string, len(addrs))
+	for i, a := range addrs {
+		s[i] = a.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func messageID(from string) string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok && d != "" {
+		domain = d
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+// SubjectData is what subject templates are executed with
+type SubjectData struct {
+	Title       string
+	Description string
+	Timestamp   string
+	Total       int
+	// Counts are the numbers of findings by short level, e.g. critical; levels
+	// without findings count 0
+	Counts map[string]int
+}
+
+// RenderSubject executes the subject template text, DefaultSubject when empty
+func RenderSubject(text string, data SubjectData) (string, error) {
+	if text == "" {
+		text = DefaultSubject
+	}
+	t, err := template.New("subject").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid subject template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render subject: %w", err)
+	}
+	// Header values cannot span lines
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
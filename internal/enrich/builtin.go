@@ -0,0 +1,112 @@
+package enrich
+
+import (
+	"context"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/bundle"
+	"github.com/endor-labs/findings-api/internal/codeowners"
+)
+
+// Projects fills in the name and repository URL of each finding's project
+type Projects struct {
+	Cache *api.ProjectCache
+	Token string
+}
+
+func (Projects) Name() string { return "projects" }
+
+func (p Projects) Enrich(ctx context.Context, findings []api.Finding) error {
+	uuids := make([]string, 0, len(findings))
+	for _, f := range findings {
+		uuids = append(uuids, f.Spec.ProjectUUID)
+	}
+
+	projects, err := p.Cache.Resolve(ctx, p.Token, uuids)
+	if err != nil {
+		return err
+	}
+
+	var unresolved Warnings
+	warned := make(map[string]bool)
+	for i := range findings {
+		id := findings[i].Spec.ProjectUUID
+		proj, ok := projects[id]
+		if !ok {
+			if id != "" && !warned[id] {
+				warned[id] = true
+				unresolved = append(unresolved, api.Warning{
+					Code:     api.WarningEnrichmentFailed,
+					Message:  "project not found, name and repository left empty",
+					Resource: "projects",
+					UUID:     id,
+				})
+			}
+			continue
+		}
+		findings[i].Project = &api.ProjectInfo{
+			Name:    proj.Meta.Name,
+			RepoURL: proj.Spec.Git.HTTPCloneURL,
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return unresolved
+	}
+	return nil
+}
+
+// Owners attributes each finding to the CODEOWNERS owners of its dependency files
+type Owners struct {
+	Rules *codeowners.Ruleset
+}
+
+func (Owners) Name() string { return "owners" }
+
+func (o Owners) Enrich(ctx context.Context, findings []api.Finding) error {
+	for i := range findings {
+		findings[i].Owners = o.Rules.OwnersOf(findings[i].Spec.DependencyFilePath)
+	}
+	return nil
+}
+
+// Bundle adds the KEV status, EPSS snapshot score and CWE names of each
+// finding's CVE from a local enrichment bundle, stored in Finding.Enrichments
+// under "bundle"
+type Bundle struct {
+	Data *bundle.Bundle
+}
+
+func (Bundle) Name() string { return "bundle" }
+
+// bundleRecord is what the Bundle enricher stores for a finding
+type bundleRecord struct {
+	KEV  *bundle.KEVEntry  `json:"kev,omitempty"`
+	EPSS *bundle.EPSSEntry `json:"epss,omitempty"`
+	CWEs []bundle.CWEEntry `json:"cwes,omitempty"`
+}
+
+func (b Bundle) Enrich(ctx context.Context, findings []api.Finding) error {
+	for i := range findings {
+		var rec bundleRecord
+		if cve := findings[i].CVE(); cve != "" {
+			if kev, ok := b.Data.KEV[cve]; ok {
+				rec.KEV = &kev
+			}
+			if epss, ok := b.Data.EPSS[cve]; ok {
+				rec.EPSS = &epss
+			}
+		}
+		for _, id := range findings[i].CWEs() {
+			if cwe, ok := b.Data.CWE[id]; ok {
+				rec.CWEs = append(rec.CWEs, cwe)
+			} else {
+				rec.CWEs = append(rec.CWEs, bundle.CWEEntry{ID: id})
+			}
+		}
+		if err := setRecord(&findings[i], b.Name(), rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/cvss"
+)
+
+// CVSS parses the CVSS vector of each finding, applies the environmental
+// metrics of Environment and recomputes its scores and severity, stored in
+// Finding.Enrichments under "cvss"
+type CVSS struct {
+	Environment cvss.Environment
+}
+
+func (CVSS) Name() string { return "cvss" }
+
+// cvssRecord is what the CVSS enricher stores for a finding
+type cvssRecord struct {
+	Version string `json:"version"`
+	// Vector is the vector scored, with the environmental metrics applied
+	Vector  string            `json:"vector"`
+	Metrics map[string]string `json:"metrics"`
+	Scores  *cvss.Scores      `json:"scores,omitempty"`
+	// Score is the environmental, temporal or base score, whichever applies
+	Score    float64 `json:"score"`
+	Severity string  `json:"severity"`
+	// Computed is false when Score is the reported score, not a recomputed one
+	Computed bool `json:"computed"`
+}
+
+func (c CVSS) Enrich(ctx context.Context, findings []api.Finding) error {
+	var invalid Warnings
+	for i := range findings {
+		sev := findings[i].CVSS()
+		if sev == nil || sev.Vector == "" {
+			continue
+		}
+		v, err := cvss.Parse(sev.Vector)
+		if err != nil {
+			// v2 vectors have no version prefix and keep their reported score
+			if !strings.HasPrefix(sev.Vector, "AV:") {
+				invalid = append(invalid, api.Warning{
+					Code:     api.WarningEnrichmentFailed,
+					Message:  err.Error(),
+					Resource: "cvss",
+					UUID:     findings[i].UUID,
+				})
+			}
+			continue
+		}
+		if len(c.Environment) > 0 {
+			v = v.Adjust(c.Environment)
+		}
+
+		rec := cvssRecord{Version: v.Version, Vector: v.String(), Metrics: v.Metrics, Score: sev.Score}
+		if scores, err := v.Score(); err == nil {
+			rec.Scores, rec.Score, rec.Computed = &scores, scores.Effective(v), true
+		}
+		rec.Severity = cvss.Severity(rec.Score)
+
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		if findings[i].Enrichments == nil {
+			findings[i].Enrichments = make(map[string]json.RawMessage)
+		}
+		findings[i].Enrichments[c.Name()] = data
+	}
+	if len(invalid) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// CVSSScore returns the CVSS score of f: the score recomputed by the CVSS
+// enricher, or else the reported one. ok is false when there is neither.
+func CVSSScore(f api.Finding) (score float64, ok bool) {
+	var rec cvssRecord
+	if data, found := f.Enrichments[CVSS{}.Name()]; found && json.Unmarshal(data, &rec) == nil {
+		return rec.Score, true
+	}
+	if c := f.CVSS(); c != nil {
+		return c.Score, true
+	}
+	return 0, false
+}
+
+// CVSSSeverity returns the severity of CVSSScore, or "" when f has no score
+func CVSSSeverity(f api.Finding) string {
+	if score, ok := CVSSScore(f); ok {
+		return cvss.Severity(score)
+	}
+	return ""
+}
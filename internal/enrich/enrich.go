@@ -0,0 +1,62 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Enricher adds data to fetched findings, such as ownership, project details or
+// records from an internal asset database
+type Enricher interface {
+	// Name identifies the enricher in warnings and in Finding.Enrichments
+	Name() string
+	// Enrich updates findings in place
+	Enrich(ctx context.Context, findings []api.Finding) error
+}
+
+// Warnings is an error an enricher returns when it finished but could not
+// enrich everything; each warning is reported individually
+type Warnings []api.Warning
+
+func (w Warnings) Error() string {
+	msgs := make([]string, len(w))
+	for i, warning := range w {
+		msgs[i] = warning.String()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Pipeline runs enrichers in order over the same findings
+type Pipeline []Enricher
+
+// Run applies every enricher in turn. An enricher that fails does not stop the
+// others; its error is returned as an enrichment_failed warning.
+func (p Pipeline) Run(ctx context.Context, findings []api.Finding) []api.Warning {
+	var warnings []api.Warning
+	for _, e := range p {
+		if ctx.Err() != nil {
+			break
+		}
+
+		err := e.Enrich(ctx, findings)
+		if err == nil {
+			continue
+		}
+
+		var ws Warnings
+		if errors.As(err, &ws) {
+			warnings = append(warnings, ws...)
+			continue
+		}
+		warnings = append(warnings, api.Warning{
+			Code:     api.WarningEnrichmentFailed,
+			Message:  fmt.Sprintf("%s: %v", e.Name(), err),
+			Resource: "enrichers",
+		})
+	}
+	return warnings
+}
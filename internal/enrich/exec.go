@@ -0,0 +1,83 @@
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Command runs an external program to enrich findings, so internal data sources
+// can be plugged in without changing this tool. The program receives the
+// findings as a JSON array on stdin and prints a JSON object mapping finding
+// UUIDs to arbitrary JSON values, which are stored in Finding.Enrichments under
+// the command's name. Findings it leaves out are not changed.
+type Command struct {
+	// Label names the enricher; the program's base name when empty
+	Label string
+	Path  string
+	Args  []string
+}
+
+// ParseCommand parses "name=program arg..." or "program arg..."
+func ParseCommand(spec string) (Command, error) {
+	var c Command
+	if name, rest, ok := strings.Cut(spec, "="); ok && !strings.ContainsAny(name, " /\\") {
+		c.Label, spec = name, rest
+	}
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return Command{}, fmt.Errorf("invalid enricher %q", spec)
+	}
+	c.Path, c.Args = fields[0], fields[1:]
+	return c, nil
+}
+
+func (c Command) Name() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return strings.TrimSuffix(filepath.Base(c.Path), filepath.Ext(c.Path))
+}
+
+func (c Command) Enrich(ctx context.Context, findings []api.Finding) error {
+	input, err := json.Marshal(findings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+
+	var results map[string]json.RawMessage
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		return fmt.Errorf("failed to parse output: %w", err)
+	}
+
+	name := c.Name()
+	for i := range findings {
+		data, ok := results[findings[i].UUID]
+		if !ok {
+			continue
+		}
+		if findings[i].Enrichments == nil {
+			findings[i].Enrichments = make(map[string]json.RawMessage)
+		}
+		findings[i].Enrichments[name] = data
+	}
+	return nil
+}
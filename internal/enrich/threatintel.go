@@ -0,0 +1,140 @@
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/bundle"
+)
+
+// threatIntelTTL is how long fetched KEV and EPSS data is reused, so watches
+// and streamed pages do not download the catalog again every time
+const threatIntelTTL = 12 * time.Hour
+
+// ThreatIntel adds the KEV status of each finding's CVE from the current CISA
+// catalog and, for CVEs the API returned no EPSS score for, the current FIRST
+// EPSS score, stored in Finding.Enrichments under "threat_intel"
+type ThreatIntel struct {
+	Source bundle.Live
+
+	mu        sync.Mutex
+	fetchedAt time.Time
+	kev       map[string]bundle.KEVEntry
+	// epss caches the scores asked for, with a nil entry for CVEs without one
+	epss map[string]*bundle.EPSSEntry
+}
+
+func (*ThreatIntel) Name() string { return "threat_intel" }
+
+func (t *ThreatIntel) Enrich(ctx context.Context, findings []api.Finding) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.kev == nil || time.Since(t.fetchedAt) > threatIntelTTL {
+		kev, err := t.Source.FetchKEV(ctx)
+		if err != nil {
+			return err
+		}
+		t.kev, t.epss, t.fetchedAt = kev, make(map[string]*bundle.EPSSEntry), time.Now()
+	}
+
+	var missing []string
+	for _, f := range findings {
+		cve := f.CVE()
+		if _, cached := t.epss[cve]; cve != "" && f.EPSS() == nil && !cached {
+			missing = append(missing, cve)
+			t.epss[cve] = nil
+		}
+	}
+	var epssErr error
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		scores, err := t.Source.FetchEPSS(ctx, missing)
+		if err != nil {
+			// KEV data is still added; the scores are asked for again next time
+			for _, cve := range missing {
+				delete(t.epss, cve)
+			}
+			epssErr = err
+		}
+		for cve, score := range scores {
+			score := score
+			t.epss[cve] = &score
+		}
+	}
+
+	for i := range findings {
+		cve := findings[i].CVE()
+		if cve == "" {
+			continue
+		}
+		var rec bundleRecord
+		if kev, ok := t.kev[cve]; ok {
+			rec.KEV = &kev
+		}
+		if findings[i].EPSS() == nil {
+			rec.EPSS = t.epss[cve]
+		}
+		if err := setRecord(&findings[i], t.Name(), rec); err != nil {
+			return err
+		}
+	}
+	return epssErr
+}
+
+// setRecord stores rec under name unless it is empty
+func setRecord(f *api.Finding, name string, rec bundleRecord) error {
+	if rec.KEV == nil && rec.EPSS == nil && len(rec.CWEs) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if f.Enrichments == nil {
+		f.Enrichments = make(map[string]json.RawMessage)
+	}
+	f.Enrichments[name] = data
+	return nil
+}
+
+// records returns the KEV and EPSS records the Bundle and ThreatIntel
+// enrichers added to f, bundled data first
+func records(f api.Finding) []bundleRecord {
+	var recs []bundleRecord
+	for _, name := range []string{Bundle{}.Name(), (*ThreatIntel)(nil).Name()} {
+		var rec bundleRecord
+		if data, ok := f.Enrichments[name]; ok && json.Unmarshal(data, &rec) == nil {
+			recs = append(recs, rec)
+		}
+	}
+	return recs
+}
+
+// KEV returns the KEV catalog entry of f's CVE added by enrichment, or nil
+func KEV(f api.Finding) *bundle.KEVEntry {
+	for _, rec := range records(f) {
+		if rec.KEV != nil {
+			return rec.KEV
+		}
+	}
+	return nil
+}
+
+// EPSS returns the EPSS probability of f: the score from the API, or else one
+// added by enrichment. ok is false when there is neither.
+func EPSS(f api.Finding) (score float64, ok bool) {
+	if e := f.EPSS(); e != nil {
+		return e.ProbabilityScore, true
+	}
+	for _, rec := range records(f) {
+		if rec.EPSS != nil {
+			return rec.EPSS.Score, true
+		}
+	}
+	return 0, false
+}
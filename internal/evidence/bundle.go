@@ -0,0 +1,159 @@
+package evidence
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChecksumsFile and SignatureFile are added to every bundle
+const (
+	ChecksumsFile = "checksums.txt"
+	SignatureFile = "checksums.txt.sig"
+)
+
+// Bundle collects the files of an evidence archive
+type Bundle struct {
+	files map[string][]byte
+	// Created is the modification time recorded for every file
+	Created time.Time
+}
+
+// New creates an empty bundle
+func New() *Bundle {
+	return &Bundle{
+		files:   make(map[string][]byte),
+		Created: time.Now(),
+	}
+}
+
+// Add adds a file to the bundle, replacing any file with the same name
+func (b *Bundle) Add(name string, data []byte) {
+	b.files[name] = data
+}
+
+// AddJSON adds v to the bundle as an indented JSON file
+func (b *Bundle) AddJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	b.Add(name, data)
+	return nil
+}
+
+// Write writes the bundle as a zip archive containing every added file, a
+// checksums file in sha256sum format and, when key is set, its signature
+func (b *Bundle) Write(w io.Writer, key ed25519.PrivateKey) error {
+	names := make([]string, 0, len(b.files))
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checksums bytes.Buffer
+	for _, name := range names {
+		sum := sha256.Sum256(b.files[name])
+		fmt.Fprintf(&checksums, "%s  %s\n", hex.EncodeToString(sum[:]), name)
+	}
+
+	zw := zip.NewWriter(w)
+	add := func(name string, data []byte) error {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.Created})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		_, err = fw.Write(data)
+		return err
+	}
+
+	for _, name := range names {
+		if err := add(name, b.files[name]); err != nil {
+			return err
+		}
+	}
+	if err := add(ChecksumsFile, checksums.Bytes()); err != nil {
+		return err
+	}
+	if key != nil {
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, checksums.Bytes()))
+		if err := add(SignatureFile, []byte(sig+"\n")); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// Verify checks every file of a bundle against its checksums and, when
+// publicKey is set, the checksums against the signature
+func Verify(r io.ReaderAt, size int64, publicKey ed25519.PublicKey) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+
+	files := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		files[f.Name] = data
+	}
+
+	checksums, ok := files[ChecksumsFile]
+	if !ok {
+		return errors.New("bundle has no checksums file")
+	}
+
+	if publicKey != nil {
+		sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(files[SignatureFile])))
+		if err != nil || len(sig) == 0 {
+			return errors.New("bundle is not signed")
+		}
+		if !ed25519.Verify(publicKey, checksums, sig) {
+			return errors.New("signature does not match checksums")
+		}
+	}
+
+	listed := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(checksums)), "\n") {
+		want, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return fmt.Errorf("malformed checksums line %q", line)
+		}
+		data, ok := files[name]
+		if !ok {
+			return fmt.Errorf("%s is listed but missing", name)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != want {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		listed[name] = true
+	}
+
+	for name := range files {
+		if name != ChecksumsFile && name != SignatureFile && !listed[name] {
+			return fmt.Errorf("%s is not listed in the checksums", name)
+		}
+	}
+
+	return nil
+}
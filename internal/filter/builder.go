@@ -0,0 +1,230 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Prefixes of the enum values accepted by the Endor API
+const (
+	LevelPrefix    = "FINDING_LEVEL_"
+	CategoryPrefix = "FINDING_CATEGORY_"
+	TagPrefix      = "FINDING_TAGS_"
+)
+
+// Builder composes a validated Endor filter expression.
+//
+// Clauses are joined with "and" unless Or() is called between them:
+//
+//	expr, err := filter.New().
+//		Level("critical", "high").
+//		Categories("vulnerability").
+//		EPSSAtLeast(0.01).
+//		Build()
+type Builder struct {
+	clauses []string
+	ops     []string
+	pending string
+	errs    []error
+}
+
+// New creates an empty filter builder
+func New() *Builder {
+	return &Builder{}
+}
+
+// And joins the next clause with "and" (the default)
+func (b *Builder) And() *Builder {
+	return b.setOperator("and")
+}
+
+// Or joins the next clause with "or"
+func (b *Builder) Or() *Builder {
+	return b.setOperator("or")
+}
+
+// Where adds a raw filter clause, parenthesized so an "or" in it stays
+// within the clause
+func (b *Builder) Where(expr string) *Builder {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		b.errs = append(b.errs, errors.New("empty filter clause"))
+		return b
+	}
+	return b.add("(" + expr + ")")
+}
+
+// Group adds a parenthesized sub-expression built by fn
+func (b *Builder) Group(fn func(g *Builder)) *Builder {
+	g := New()
+	fn(g)
+	expr, err := g.Build()
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	if expr == "" {
+		return b
+	}
+	return b.add(expr)
+}
+
+// Equals adds a field == "value" clause
+func (b *Builder) Equals(field, value string) *Builder {
+	if err := validateValue(value); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%s: %w", field, err))
+		return b
+	}
+	return b.add(fmt.Sprintf("%s == %q", field, value))
+}
+
+// ProjectUUID restricts findings to a single project
+func (b *Builder) ProjectUUID(uuid string) *Builder {
+	if err := validateValue(uuid); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("project uuid: %w", err))
+		return b
+	}
+	return b.add(fmt.Sprintf("spec.project_uuid==%s", uuid))
+}
+
+// Context restricts findings to a context type such as "CONTEXT_TYPE_MAIN"
+func (b *Builder) Context(contextType string) *Builder {
+	return b.Equals("context.type", contextType)
+}
+
+// Level matches findings with any of the given levels
+func (b *Builder) Level(levels ...string) *Builder {
+	return b.list("spec.level in", LevelPrefix, levels)
+}
+
+// Categories matches findings in any of the given categories
+func (b *Builder) Categories(categories ...string) *Builder {
+	return b.list("spec.finding_categories contains", CategoryPrefix, categories)
+}
+
+// Tags matches findings carrying any of the given tags
+func (b *Builder) Tags(tags ...string) *Builder {
+	return b.list("spec.finding_tags contains", TagPrefix, tags)
+}
+
+// NotTags matches findings carrying none of the given tags
+func (b *Builder) NotTags(tags ...string) *Builder {
+	return b.list("spec.finding_tags not contains", TagPrefix, tags)
+}
+
+// EPSSAtLeast matches findings whose EPSS probability is at least score
+func (b *Builder) EPSSAtLeast(score float64) *Builder {
+	if score < 0 || score > 1 {
+		b.errs = append(b.errs, fmt.Errorf("EPSS score %g must be between 0 and 1", score))
+		return b
+	}
+	return b.add("spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= " + strconv.FormatFloat(score, 'g', -1, 64))
+}
+
+// Build returns the filter expression, or the first validation error encountered.
+// An empty builder yields an empty expression.
+func (b *Builder) Build() (string, error) {
+	if len(b.errs) > 0 {
+		return "", errors.Join(b.errs...)
+	}
+	if b.pending != "" {
+		return "", fmt.Errorf("dangling %q operator at end of filter", b.pending)
+	}
+	if len(b.clauses) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("(")
+	for i, clause := range b.clauses {
+		if i > 0 {
+			sb.WriteString(" " + b.ops[i] + " ")
+		}
+		sb.WriteString(clause)
+	}
+	sb.WriteString(")")
+
+	return sb.String(), nil
+}
+
+// String returns the filter expression, ignoring validation errors
+func (b *Builder) String() string {
+	expr, _ := b.Build()
+	return expr
+}
+
+// Normalize converts a short name such as "critical" into its API form
+// (e.g. "FINDING_LEVEL_CRITICAL") using prefix
+func Normalize(value, prefix string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" || strings.HasPrefix(value, prefix) {
+		return value
+	}
+	return prefix + strings.ReplaceAll(value, "-", "_")
+}
+
+// SplitList splits a comma-separated list, dropping empty entries
+func SplitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func (b *Builder) setOperator(op string) *Builder {
+	if len(b.clauses) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("%q operator without a preceding clause", op))
+		return b
+	}
+	if b.pending != "" {
+		b.errs = append(b.errs, fmt.Errorf("%q operator follows %q", op, b.pending))
+		return b
+	}
+	b.pending = op
+	return b
+}
+
+func (b *Builder) add(clause string) *Builder {
+	op := b.pending
+	if op == "" {
+		op = "and"
+	}
+	b.clauses = append(b.clauses, clause)
+	b.ops = append(b.ops, op)
+	b.pending = ""
+	return b
+}
+
+func (b *Builder) list(field, prefix string, values []string) *Builder {
+	if len(values) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("%s: at least one value is required", field))
+		return b
+	}
+
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		v = Normalize(v, prefix)
+		if err := validateValue(v); err != nil {
+			b.errs = append(b.errs, fmt.Errorf("%s: %w", field, err))
+			return b
+		}
+		quoted = append(quoted, strconv.Quote(v))
+	}
+
+	return b.add(fmt.Sprintf("%s [%s]", field, strings.Join(quoted, ",")))
+}
+
+func validateValue(value string) error {
+	if value == "" {
+		return errors.New("empty value")
+	}
+	if strings.ContainsAny(value, "\"[]()") {
+		return fmt.Errorf("invalid character in value %q", value)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package filter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/endor-labs/findings-api/internal/filter"
+)
+
+// baseline is the default filter of a single-project run before the builder,
+// with the levels left to fill in
+const baseline = `(context.type == "CONTEXT_TYPE_MAIN" and spec.finding_tags not contains ["FINDING_TAGS_EXCEPTION"] and spec.level in [%s] and spec.finding_categories contains ["FINDING_CATEGORY_VULNERABILITY"] and spec.finding_tags contains ["FINDING_TAGS_POTENTIALLY_REACHABLE_FUNCTION","FINDING_TAGS_REACHABLE_FUNCTION"] and spec.finding_tags contains ["FINDING_TAGS_REACHABLE_DEPENDENCY"] and spec.finding_tags contains ["FINDING_TAGS_FIX_AVAILABLE"] and spec.finding_tags contains ["FINDING_TAGS_NORMAL"] and spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 0.01)`
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *filter.Builder)
+		want  string
+	}{
+		{"empty", func(b *filter.Builder) {}, ""},
+		{"level", func(b *filter.Builder) { b.Level("critical", "high") },
+			`(spec.level in ["FINDING_LEVEL_CRITICAL","FINDING_LEVEL_HIGH"])`},
+		{"level in API form", func(b *filter.Builder) { b.Level("FINDING_LEVEL_LOW", " medium ") },
+			`(spec.level in ["FINDING_LEVEL_LOW","FINDING_LEVEL_MEDIUM"])`},
+		{"tags", func(b *filter.Builder) { b.Tags("fix-available", "normal") },
+			`(spec.finding_tags contains ["FINDING_TAGS_FIX_AVAILABLE","FINDING_TAGS_NORMAL"])`},
+		{"not tags", func(b *filter.Builder) { b.NotTags("exception") },
+			`(spec.finding_tags not contains ["FINDING_TAGS_EXCEPTION"])`},
+		{"categories", func(b *filter.Builder) { b.Categories("vulnerability", "supply-chain") },
+			`(spec.finding_categories contains ["FINDING_CATEGORY_VULNERABILITY","FINDING_CATEGORY_SUPPLY_CHAIN"])`},
+		{"epss", func(b *filter.Builder) { b.EPSSAtLeast(0.25) },
+			`(spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 0.25)`},
+		{"epss bounds", func(b *filter.Builder) { b.EPSSAtLeast(0).And().EPSSAtLeast(1) },
+			`(spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 0 and spec.finding_metadata.vulnerability.spec.epss_score.probability_score >= 1)`},
+		{"and by default", func(b *filter.Builder) { b.Level("critical").Tags("normal") },
+			`(spec.level in ["FINDING_LEVEL_CRITICAL"] and spec.finding_tags contains ["FINDING_TAGS_NORMAL"])`},
+		{"or", func(b *filter.Builder) { b.Level("critical").Or().Tags("exploited") },
+			`(spec.level in ["FINDING_LEVEL_CRITICAL"] or spec.finding_tags contains ["FINDING_TAGS_EXPLOITED"])`},
+		{"nested groups", func(b *filter.Builder) {
+			b.Context("CONTEXT_TYPE_MAIN").Group(func(g *filter.Builder) {
+				g.Level("critical").Or().Group(func(h *filter.Builder) {
+					h.Level("high").Tags("exploited")
+				})
+			})
+		}, `(context.type == "CONTEXT_TYPE_MAIN" and (spec.level in ["FINDING_LEVEL_CRITICAL"] or (spec.level in ["FINDING_LEVEL_HIGH"] and spec.finding_tags contains ["FINDING_TAGS_EXPLOITED"])))`},
+		{"empty group", func(b *filter.Builder) { b.Level("low").Group(func(*filter.Builder) {}) },
+			`(spec.level in ["FINDING_LEVEL_LOW"])`},
+		{"where keeps its or inside", func(b *filter.Builder) { b.Where(`spec.a == 1 or spec.b == 2`).Level("low") },
+			`((spec.a == 1 or spec.b == 2) and spec.level in ["FINDING_LEVEL_LOW"])`},
+		{"equals quotes", func(b *filter.Builder) { b.Equals("context.id", `ref/with space\slash`) },
+			`(context.id == "ref/with space\\slash")`},
+		{"project", func(b *filter.Builder) { b.ProjectUUID("6650a0000000000000000002") },
+			`(spec.project_uuid==6650a0000000000000000002)`},
+		{"default single-project filter", func(b *filter.Builder) { defaults(b, "critical") },
+			strings.Replace(baseline, "%s", `"FINDING_LEVEL_CRITICAL"`, 1)},
+		{"default all-projects filter", func(b *filter.Builder) { defaults(b, "critical", "high") },
+			strings.Replace(baseline, "%s", `"FINDING_LEVEL_CRITICAL","FINDING_LEVEL_HIGH"`, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := filter.New()
+			tt.build(b)
+			got, err := b.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// defaults builds the filter of the default flags as the findings commands do
+func defaults(b *filter.Builder, levels ...string) {
+	b.Context("CONTEXT_TYPE_MAIN").
+		NotTags("exception").
+		Level(levels...).
+		Categories("vulnerability").
+		Tags("potentially-reachable-function", "reachable-function").
+		Tags("reachable-dependency").
+		Tags("fix-available").
+		Tags("normal").
+		EPSSAtLeast(0.01)
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *filter.Builder)
+		want  string
+	}{
+		{"no levels", func(b *filter.Builder) { b.Level() }, "at least one value is required"},
+		{"no tags", func(b *filter.Builder) { b.Tags() }, "at least one value is required"},
+		{"empty category", func(b *filter.Builder) { b.Categories("vulnerability", " ") }, "empty value"},
+		{"quote in a value", func(b *filter.Builder) { b.Tags(`normal"] or [`) }, "invalid character"},
+		{"bracket in a value", func(b *filter.Builder) { b.Equals("context.id", "a]") }, "invalid character"},
+		{"parenthesis in a project", func(b *filter.Builder) { b.ProjectUUID("x) or (y") }, "invalid character"},
+		{"epss below 0", func(b *filter.Builder) { b.EPSSAtLeast(-0.1) }, "must be between 0 and 1"},
+		{"epss above 1", func(b *filter.Builder) { b.EPSSAtLeast(1.5) }, "must be between 0 and 1"},
+		{"empty where", func(b *filter.Builder) { b.Where("  ") }, "empty filter clause"},
+		{"leading operator", func(b *filter.Builder) { b.Or().Level("low") }, `"or" operator without a preceding clause`},
+		{"repeated operator", func(b *filter.Builder) { b.Level("low").And().Or().Level("high") }, `"or" operator follows "and"`},
+		{"dangling operator", func(b *filter.Builder) { b.Level("low").Or() }, `dangling "or" operator`},
+		{"error in a group", func(b *filter.Builder) {
+			b.Level("low").Group(func(g *filter.Builder) { g.EPSSAtLeast(2) })
+		}, "must be between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := filter.New()
+			tt.build(b)
+			got, err := b.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %q and error %v, want an error containing %q", got, err, tt.want)
+			}
+			if got != "" {
+				t.Errorf("got expression %q with the error, want none", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+// Package ghissues creates, updates and closes GitHub issues for findings
+// through the REST API, on github.com or GitHub Enterprise Server. Issues carry
+// the UUIDs of the findings they track in hidden markers in their body, so they
+// can be found again without any local state.
+package ghissues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/scm"
+)
+
+// DefaultLabel marks the issues the integration manages
+const DefaultLabel = "endor-labs"
+
+// SeverityLabelPrefix prefixes the severity label of an issue, e.g. severity:critical
+const SeverityLabelPrefix = "severity:"
+
+// Config selects the repository and how issues are labeled
+type Config struct {
+	SCM scm.Config
+	// Repo is the repository slug, owner/name
+	Repo string
+	// Label marks managed issues; only issues carrying it are ever closed
+	Label string
+	// Labels are extra labels set on created issues
+	Labels []string
+}
+
+// Issue is a GitHub issue
+type Issue struct {
+	Number int
+	URL    string
+	State  string
+	Labels []string
+	Body   string
+}
+
+// Ref returns the issue's owner/name#number reference
+func (i Issue) Ref(repo string) string {
+	return repo + "#" + strconv.Itoa(i.Number)
+}
+
+// Fields are the issue fields the integration manages
+type Fields struct {
+	Title string
+	// Body is the visible description; markers for Findings are appended to it
+	Body     string
+	Findings []Marker
+	// Severity is the short level of the most severe finding, e.g. critical
+	Severity string
+}
+
+// Marker ties an issue to one finding and the project it belongs to
+type Marker struct {
+	Finding string
+	Project string
+}
+
+// Client calls the GitHub issues API of one repository
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// NewClient checks cfg and returns a client for it
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.SCM.Token == "" {
+		return nil, errors.New("a GitHub token is required")
+	}
+	owner, name, ok := strings.Cut(cfg.Repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid repository %q (expected owner/name)", cfg.Repo)
+	}
+	if cfg.Label == "" {
+		cfg.Label = DefaultLabel
+	}
+	cfg.SCM.Kind = scm.GitHub
+
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Repo returns the repository slug
+func (c *Client) Repo() string {
+	return c.cfg.Repo
+}
+
+// Create opens a new issue
+func (c *Client) Create(ctx context.Context, f Fields) (Issue, error) {
+	body := map[string]any{
+		"title":  f.Title,
+		"body":   withMarkers(f.Body, f.Findings),
+		"labels": c.labels(nil, f.Severity),
+	}
+	var created issueJSON
+	if err := c.do(ctx, "POST", c.repoPath("issues"), nil, body, &created); err != nil {
+		return Issue{}, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return created.issue(), nil
+}
+
+// Update replaces the managed fields of an issue and reopens it if it was
+// closed. Labels added by people are kept; the severity label is replaced.
+func (c *Client) Update(ctx context.Context, issue Issue, f Fields) (Issue, error) {
+	body := map[string]any{
+		"title":  f.Title,
+		"body":   withMarkers(f.Body, f.Findings),
+		"labels": c.labels(issue.Labels, f.Severity),
+		"state":  "open",
+	}
+	var updated issueJSON
+	if err := c.do(ctx, "PATCH", c.repoPath("issues", strconv.Itoa(issue.Number)), nil, body, &updated); err != nil {
+		return Issue{}, fmt.Errorf("failed to update issue #%d: %w", issue.Number, err)
+	}
+	return updated.issue(), nil
+}
+
+// Close comments on an issue, if comment is set, and closes it as completed
+func (c *Client) Close(ctx context.Context, number int, comment string) error {
+	if comment != "" {
+		if err := c.do(ctx, "POST", c.repoPath("issues", strconv.Itoa(number), "comments"), nil, map[string]string{"body": comment}, nil); err != nil {
+			return fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+		}
+	}
+	body := map[string]string{"state": "closed", "state_reason": "completed"}
+	if err := c.do(ctx, "PATCH", c.repoPath("issues", strconv.Itoa(number)), nil, body, nil); err != nil {
+		return fmt.Errorf("failed to close issue #%d: %w", number, err)
+	}
+	return nil
+}
+
+// Get returns an issue by number
+func (c *Client) Get(ctx context.Context, number int) (Issue, error) {
+	var issue issueJSON
+	if err := c.do(ctx, "GET", c.repoPath("issues", strconv.Itoa(number)), nil, nil, &issue); err != nil {
+		return Issue{}, fmt.Errorf("failed to get issue #%d: %w", number, err)
+	}
+	return issue.issue(), nil
+}
+
+// FindByUUID searches the repository, open and closed issues alike, for the
+// issue whose body marks findingUUID. ok is false when there is none.
+func (c *Client) FindByUUID(ctx context.Context, findingUUID string) (issue Issue, ok bool, err error) {
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf("repo:%s is:issue in:body %q", c.cfg.Repo, findingUUID))
+	query.Set("per_page", "10")
+
+	var result struct {
+		Items []issueJSON `json:"items"`
+	}
+	if err := c.do(ctx, "GET", "search/issues", query, nil, &result); err != nil {
+		return Issue{}, false, fmt.Errorf("failed to search issues: %w", err)
+	}
+	// Search matches words anywhere in the body; only a marker counts
+	for _, item := range result.Items {
+		issue := item.issue()
+		for _, m := range ParseMarkers(issue.Body) {
+			if m.Finding == findingUUID {
+				return issue, true, nil
+			}
+		}
+	}
+	return Issue{}, false, nil
+}
+
+// ListOpen returns the open issues carrying the managed label
+func (c *Client) ListOpen(ctx context.Context) ([]Issue, error) {
+	const perPage = 100
+
+	var issues []Issue
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("labels", c.cfg.Label)
+		query.Set("state", "open")
+		query.Set("per_page", strconv.Itoa(perPage))
+		query.Set("page", strconv.Itoa(page))
+
+		var items []issueJSON
+		if err := c.do(ctx, "GET", c.repoPath("issues"), query, nil, &items); err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, item := range items {
+			// The issues endpoint lists pull requests too
+			if item.PullRequest == nil {
+				issues = append(issues, item.issue())
+			}
+		}
+		if len(items) < perPage {
+			return issues, nil
+		}
+	}
+}
+
+// labels returns the labels of an issue that had existing labels: the
+// configured labels are added and the severity label replaced
+func (c *Client) labels(existing []string, severity string) []string {
+	seen := make(map[string]bool)
+	var labels []string
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			labels = append(labels, l)
+		}
+	}
+
+	for _, l := range existing {
+		if !strings.HasPrefix(l, SeverityLabelPrefix) {
+			add(l)
+		}
+	}
+	add(c.cfg.Label)
+	for _, l := range c.cfg.Labels {
+		add(l)
+	}
+	if severity != "" {
+		add(SeverityLabelPrefix + severity)
+	}
+	return labels
+}
+
+var markerPattern = regexp.MustCompile(`<!-- endor-labs finding=(\S+) project=(\S*) -->`)
+
+// withMarkers appends a hidden marker per finding to body
+func withMarkers(body string, markers []Marker) string {
+	var b strings.Builder
+	b.WriteString(body)
+	b.WriteString("\n\n")
+	for _, m := range markers {
+		fmt.Fprintf(&b, "<!-- endor-labs finding=%s project=%s -->\n", m.Finding, m.Project)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// ParseMarkers returns the findings marked in an issue body
+func ParseMarkers(body string) []Marker {
+	var markers []Marker
+	for _, m := range markerPattern.FindAllStringSubmatch(body, -1) {
+		markers = append(markers, Marker{Finding: m[1], Project: m[2]})
+	}
+	return markers
+}
+
+// issueJSON is an issue as the API returns it
+type issueJSON struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Body    string `json:"body"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+func (j issueJSON) issue() Issue {
+	issue := Issue{Number: j.Number, URL: j.HTMLURL, State: j.State, Body: j.Body}
+	for _, l := range j.Labels {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+	return issue
+}
+
+// repoPath returns the API path of the repository joined with elems
+func (c *Client) repoPath(elems ...string) string {
+	return "repos/" + c.cfg.Repo + "/" + strings.Join(elems, "/")
+}
+
+// do sends a request with body encoded as JSON and decodes the response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := c.cfg.SCM.NewRequest(ctx, method, path, reader)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// newError reads GitHub's error body: a message, plus per-field errors
+func newError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Field   string `json:"field"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	var msgs []string
+	if json.Unmarshal(data, &body) == nil {
+		if body.Message != "" {
+			msgs = append(msgs, body.Message)
+		}
+		for _, e := range body.Errors {
+			switch {
+			case e.Message != "":
+				msgs = append(msgs, e.Message)
+			case e.Field != "":
+				msgs = append(msgs, e.Field+": "+e.Code)
+			}
+		}
+	}
+	if len(msgs) == 0 {
+		if text := strings.TrimSpace(string(data)); text != "" {
+			msgs = append(msgs, text)
+		}
+	}
+	if len(msgs) == 0 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return fmt.Errorf("status %d: %s", resp.StatusCode, strings.Join(msgs, "; "))
+}
@@ -0,0 +1,63 @@
+// Package golden renders every text output format from a canonical findings
+// fixture. Its tests compare the results with checked-in golden files, so a
+// change to a format shows up as a reviewable diff of its goldens.
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/output"
+	"github.com/endor-labs/findings-api/internal/snapshot"
+)
+
+// FixtureFile is the canonical findings document every format is rendered from
+const FixtureFile = "fixture.json"
+
+// Formats lists the formats with goldens. xlsx is left out: it is a zip
+// archive, so a diff of it would not be reviewable.
+var Formats = []string{"json", "ndjson", "table", "csv", "sarif", "gitlab", "openvex", "cyclonedx-vex", "remediation", "markdown", "html"}
+
+// options fixes every setting that changes the rendered output, so goldens
+// only change with the formats themselves
+func options() (output.Options, error) {
+	cols, err := output.ParseColumns(strings.Join(output.ColumnNames(), ","))
+	if err != nil {
+		return output.Options{}, err
+	}
+	return output.Options{
+		Columns:        cols,
+		MaxColumnWidth: 40,
+		UIURL:          output.DefaultUIURL,
+		Namespace:      "acme",
+	}, nil
+}
+
+// Render renders the fixture in dir in format
+func Render(dir, format string) ([]byte, error) {
+	doc, _, err := snapshot.Load(filepath.Join(dir, FixtureFile))
+	if err != nil {
+		return nil, err
+	}
+	opts, err := options()
+	if err != nil {
+		return nil, err
+	}
+	w, err := output.NewWriter(format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := w.Write(&buf, doc); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", format, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Path returns the golden file of format in dir
+func Path(dir, format string) string {
+	return filepath.Join(dir, "findings."+format+".golden")
+}
@@ -0,0 +1,76 @@
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite goldens that differ or are missing")
+
+// dir holds the fixture and goldens
+const dir = "testdata"
+
+// maxDiffLines bounds the differing lines reported for a format
+const maxDiffLines = 10
+
+func TestGoldens(t *testing.T) {
+	for _, format := range Formats {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			got, err := Render(dir, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := Path(dir, format)
+
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatalf("failed to write golden: %v", err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden: %v; run go test ./internal/golden -update to create it", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s does not match the rendered output; run go test ./internal/golden -update if the change is intended\n%s", path, lineDiff(want, got))
+			}
+		})
+	}
+}
+
+// lineDiff describes the first lines that differ between want and got
+func lineDiff(want, got []byte) string {
+	wl := strings.Split(string(want), "\n")
+	gl := strings.Split(string(got), "\n")
+	var b strings.Builder
+	shown := 0
+	for i := 0; i < len(wl) || i < len(gl); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w == g {
+			continue
+		}
+		if shown == maxDiffLines {
+			b.WriteString("  ...\n")
+			break
+		}
+		fmt.Fprintf(&b, "  line %d:\n    - %s\n    + %s\n", i+1, w, g)
+		shown++
+	}
+	if len(wl) != len(gl) {
+		fmt.Fprintf(&b, "  golden has %d lines, rendered output %d\n", len(wl), len(gl))
+	}
+	return b.String()
+}
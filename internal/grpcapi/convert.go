@@ -0,0 +1,129 @@
+package grpcapi
+
+import (
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/grpcapi/findingspb"
+	"github.com/endor-labs/findings-api/internal/prefetch"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NewFinding converts a finding to its protobuf model
+func NewFinding(f api.Finding) *findingspb.Finding {
+	pf := &findingspb.Finding{
+		Uuid: f.UUID,
+		Meta: &findingspb.FindingMeta{
+			Name:        f.Meta.Name,
+			Description: f.Meta.Description,
+			ParentUuid:  f.Meta.ParentUUID,
+			CreateTime:  f.Meta.CreateTime,
+		},
+		Context: &findingspb.FindingContext{Type: f.Context.Type, Id: f.Context.ID},
+		Spec: &findingspb.FindingSpec{
+			Approximation:               f.Spec.Approximation,
+			DependencyFilePaths:         f.Spec.DependencyFilePath,
+			Ecosystem:                   f.Spec.Ecosystem,
+			Explanation:                 f.Spec.Explanation,
+			FindingCategories:           make([]string, len(f.Spec.FindingCategories)),
+			FindingTags:                 make([]string, len(f.Spec.FindingTags)),
+			Level:                       NewLevel(f.Spec.Level),
+			LocationUrls:                f.Spec.LocationUrls,
+			ProjectUuid:                 f.Spec.ProjectUUID,
+			ProposedVersion:             f.Spec.ProposedVersion,
+			Relationship:                f.Spec.Relationship,
+			Remediation:                 f.Spec.Remediation,
+			RemediationAction:           f.Spec.RemediationAction,
+			Summary:                     f.Spec.Summary,
+			TargetDependencyName:        f.Spec.TargetDependencyName,
+			TargetDependencyPackageName: f.Spec.TargetDependencyPackageName,
+			TargetDependencyVersion:     f.Spec.TargetDependencyVersion,
+			Vulnerability:               newVulnerability(f.Spec.FindingMetadata.Vulnerability),
+		},
+		Namespace:     f.Namespace,
+		Owners:        f.Owners,
+		Contexts:      f.Contexts,
+		CorrelationId: f.CorrelationID,
+	}
+	for i, c := range f.Spec.FindingCategories {
+		pf.Spec.FindingCategories[i] = string(c)
+	}
+	for i, t := range f.Spec.FindingTags {
+		pf.Spec.FindingTags[i] = string(t)
+	}
+	if f.Project != nil {
+		pf.Project = &findingspb.ProjectInfo{Name: f.Project.Name, RepoUrl: f.Project.RepoURL}
+	}
+	return pf
+}
+
+// NewLevel converts a finding level; levels the model does not know are
+// FINDING_LEVEL_UNSPECIFIED
+func NewLevel(l api.FindingLevel) findingspb.FindingLevel {
+	return findingspb.FindingLevel(findingspb.FindingLevel_value[string(l)])
+}
+
+func newVulnerability(v *api.Vulnerability) *findingspb.Vulnerability {
+	if v == nil {
+		return nil
+	}
+	pv := &findingspb.Vulnerability{
+		Name:        v.Meta.Name,
+		Description: v.Meta.Description,
+		Aliases:     v.Spec.Aliases,
+		Summary:     v.Spec.Summary,
+		Published:   v.Spec.Published,
+		Modified:    v.Spec.Modified,
+		CvssV3:      newCVSS(v.Spec.CVSSV3Severity),
+		CvssV2:      newCVSS(v.Spec.CVSSV2Severity),
+		CweIds:      v.Spec.DatabaseSpecific.CWEIDs,
+	}
+	if e := v.Spec.EPSSScore; e != nil {
+		pv.Epss = &findingspb.EPSSScore{Probability: e.ProbabilityScore, Percentile: e.PercentileScore}
+	}
+	return pv
+}
+
+func newCVSS(s *api.CVSSSeverity) *findingspb.CVSSSeverity {
+	if s == nil {
+		return nil
+	}
+	return &findingspb.CVSSSeverity{Level: s.Level, Score: s.Score, Vector: s.Vector}
+}
+
+// NewProject converts a project to its protobuf model
+func NewProject(p api.Project) *findingspb.Project {
+	return &findingspb.Project{
+		Uuid:           p.UUID,
+		Name:           p.Meta.Name,
+		Description:    p.Meta.Description,
+		Tags:           p.Meta.Tags,
+		Annotations:    p.Meta.Annotations,
+		CreateTime:     p.Meta.CreateTime,
+		UpdateTime:     p.Meta.UpdateTime,
+		PlatformSource: p.Spec.PlatformSource,
+		HttpCloneUrl:   p.Spec.Git.HTTPCloneURL,
+		WebUrl:         p.Spec.Git.WebURL,
+		FullName:       p.Spec.Git.FullName,
+		Namespace:      p.TenantMeta.Namespace,
+	}
+}
+
+// NewFreshness converts the freshness of a namespace
+func NewFreshness(f prefetch.Freshness) *findingspb.Freshness {
+	pf := &findingspb.Freshness{
+		Namespace: f.Key,
+		Ready:     f.Ready,
+		Stale:     f.Stale,
+		Age:       durationpb.New(f.Age),
+		Refreshes: int64(f.Refreshes),
+		Failures:  int64(f.Failures),
+		LastError: f.LastError,
+	}
+	if !f.FetchedAt.IsZero() {
+		pf.FetchedAt = timestamppb.New(f.FetchedAt)
+	}
+	if !f.NextRefresh.IsZero() {
+		pf.NextRefresh = timestamppb.New(f.NextRefresh)
+	}
+	return pf
+}
@@ -0,0 +1,256 @@
+// Typed findings API served by findings-api serve --grpc-addr, for internal
+// services that consume findings without parsing the REST responses.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: findings.proto
+
+package findingspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FindingsService_ListFindings_FullMethodName   = "/findings.v1.FindingsService/ListFindings"
+	FindingsService_StreamFindings_FullMethodName = "/findings.v1.FindingsService/StreamFindings"
+	FindingsService_ListProjects_FullMethodName   = "/findings.v1.FindingsService/ListProjects"
+	FindingsService_GetFreshness_FullMethodName   = "/findings.v1.FindingsService/GetFreshness"
+)
+
+// FindingsServiceClient is the client API for FindingsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FindingsService answers from the findings serve keeps in memory
+type FindingsServiceClient interface {
+	// ListFindings returns the findings of a namespace, or of one project
+	ListFindings(ctx context.Context, in *ListFindingsRequest, opts ...grpc.CallOption) (*ListFindingsResponse, error)
+	// StreamFindings sends the findings of a namespace, or of one project, one
+	// message per finding, so large namespaces need not fit in one message
+	StreamFindings(ctx context.Context, in *StreamFindingsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Finding], error)
+	// ListProjects returns the projects of a namespace from the Endor Labs API
+	ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error)
+	// GetFreshness returns the freshness of every served namespace
+	GetFreshness(ctx context.Context, in *GetFreshnessRequest, opts ...grpc.CallOption) (*GetFreshnessResponse, error)
+}
+
+type findingsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFindingsServiceClient(cc grpc.ClientConnInterface) FindingsServiceClient {
+	return &findingsServiceClient{cc}
+}
+
+func (c *findingsServiceClient) ListFindings(ctx context.Context, in *ListFindingsRequest, opts ...grpc.CallOption) (*ListFindingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFindingsResponse)
+	err := c.cc.Invoke(ctx, FindingsService_ListFindings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *findingsServiceClient) StreamFindings(ctx context.Context, in *StreamFindingsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Finding], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FindingsService_ServiceDesc.Streams[0], FindingsService_StreamFindings_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamFindingsRequest, Finding]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FindingsService_StreamFindingsClient = grpc.ServerStreamingClient[Finding]
+
+func (c *findingsServiceClient) ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (*ListProjectsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProjectsResponse)
+	err := c.cc.Invoke(ctx, FindingsService_ListProjects_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *findingsServiceClient) GetFreshness(ctx context.Context, in *GetFreshnessRequest, opts ...grpc.CallOption) (*GetFreshnessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetFreshnessResponse)
+	err := c.cc.Invoke(ctx, FindingsService_GetFreshness_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindingsServiceServer is the server API for FindingsService service.
+// All implementations must embed UnimplementedFindingsServiceServer
+// for forward compatibility.
+//
+// FindingsService answers from the findings serve keeps in memory
+type FindingsServiceServer interface {
+	// ListFindings returns the findings of a namespace, or of one project
+	ListFindings(context.Context, *ListFindingsRequest) (*ListFindingsResponse, error)
+	// StreamFindings sends the findings of a namespace, or of one project, one
+	// message per finding, so large namespaces need not fit in one message
+	StreamFindings(*StreamFindingsRequest, grpc.ServerStreamingServer[Finding]) error
+	// ListProjects returns the projects of a namespace from the Endor Labs API
+	ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error)
+	// GetFreshness returns the freshness of every served namespace
+	GetFreshness(context.Context, *GetFreshnessRequest) (*GetFreshnessResponse, error)
+	mustEmbedUnimplementedFindingsServiceServer()
+}
+
+// UnimplementedFindingsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFindingsServiceServer struct{}
+
+func (UnimplementedFindingsServiceServer) ListFindings(context.Context, *ListFindingsRequest) (*ListFindingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFindings not implemented")
+}
+func (UnimplementedFindingsServiceServer) StreamFindings(*StreamFindingsRequest, grpc.ServerStreamingServer[Finding]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamFindings not implemented")
+}
+func (UnimplementedFindingsServiceServer) ListProjects(context.Context, *ListProjectsRequest) (*ListProjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProjects not implemented")
+}
+func (UnimplementedFindingsServiceServer) GetFreshness(context.Context, *GetFreshnessRequest) (*GetFreshnessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFreshness not implemented")
+}
+func (UnimplementedFindingsServiceServer) mustEmbedUnimplementedFindingsServiceServer() {}
+func (UnimplementedFindingsServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeFindingsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FindingsServiceServer will
+// result in compilation errors.
+type UnsafeFindingsServiceServer interface {
+	mustEmbedUnimplementedFindingsServiceServer()
+}
+
+func RegisterFindingsServiceServer(s grpc.ServiceRegistrar, srv FindingsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFindingsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FindingsService_ServiceDesc, srv)
+}
+
+func _FindingsService_ListFindings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFindingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FindingsServiceServer).ListFindings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FindingsService_ListFindings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FindingsServiceServer).ListFindings(ctx, req.(*ListFindingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FindingsService_StreamFindings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamFindingsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FindingsServiceServer).StreamFindings(m, &grpc.GenericServerStream[StreamFindingsRequest, Finding]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FindingsService_StreamFindingsServer = grpc.ServerStreamingServer[Finding]
+
+func _FindingsService_ListProjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FindingsServiceServer).ListProjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FindingsService_ListProjects_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FindingsServiceServer).ListProjects(ctx, req.(*ListProjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FindingsService_GetFreshness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFreshnessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FindingsServiceServer).GetFreshness(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FindingsService_GetFreshness_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FindingsServiceServer).GetFreshness(ctx, req.(*GetFreshnessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FindingsService_ServiceDesc is the grpc.ServiceDesc for FindingsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FindingsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "findings.v1.FindingsService",
+	HandlerType: (*FindingsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListFindings",
+			Handler:    _FindingsService_ListFindings_Handler,
+		},
+		{
+			MethodName: "ListProjects",
+			Handler:    _FindingsService_ListProjects_Handler,
+		},
+		{
+			MethodName: "GetFreshness",
+			Handler:    _FindingsService_GetFreshness_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamFindings",
+			Handler:       _FindingsService_StreamFindings_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "findings.proto",
+}
@@ -0,0 +1,138 @@
+// Package grpcapi serves the findings kept by serve over gRPC, with the
+// protobuf models of findingspb, so internal services get typed findings and
+// can stream large namespaces one finding at a time.
+package grpcapi
+
+//go:generate protoc --go_out=findingspb --go_opt=paths=source_relative --go-grpc_out=findingspb --go-grpc_opt=paths=source_relative -I findingspb findings.proto
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/grpcapi/findingspb"
+	"github.com/endor-labs/findings-api/internal/prefetch"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// ProjectsFunc lists the projects of a namespace whose name contains name
+type ProjectsFunc func(ctx context.Context, namespace, name string) ([]api.Project, error)
+
+// Service implements findingspb.FindingsServiceServer from a refresher's data
+type Service struct {
+	findingspb.UnimplementedFindingsServiceServer
+
+	refresher *prefetch.Refresher[[]api.Finding]
+	// defaultNamespace answers requests without a namespace
+	defaultNamespace func() string
+	projects         ProjectsFunc
+}
+
+// NewService returns a service answering from refresher. Requests without a
+// namespace are answered for the namespace defaultNamespace returns.
+func NewService(refresher *prefetch.Refresher[[]api.Finding], defaultNamespace func() string, projects ProjectsFunc) *Service {
+	return &Service{refresher: refresher, defaultNamespace: defaultNamespace, projects: projects}
+}
+
+// NewServer returns a gRPC server with svc and server reflection registered,
+// so tools such as grpcurl can call it without the .proto file
+func NewServer(svc *Service, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(opts...)
+	findingspb.RegisterFindingsServiceServer(server, svc)
+	reflection.Register(server)
+	return server
+}
+
+// ListFindings returns the findings of a namespace, or of one of its projects
+func (s *Service) ListFindings(ctx context.Context, req *findingspb.ListFindingsRequest) (*findingspb.ListFindingsResponse, error) {
+	namespace, snap, err := s.snapshot(req.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	resp := &findingspb.ListFindingsResponse{
+		Namespace:   namespace,
+		ProjectUuid: req.GetProjectUuid(),
+		Freshness:   NewFreshness(snap.Freshness),
+	}
+	for _, f := range snap.Data {
+		if req.GetProjectUuid() == "" || f.Spec.ProjectUUID == req.GetProjectUuid() {
+			resp.Findings = append(resp.Findings, NewFinding(f))
+		}
+	}
+	return resp, nil
+}
+
+// StreamFindings sends the findings of a namespace, or of one of its
+// projects, one message per finding
+func (s *Service) StreamFindings(req *findingspb.StreamFindingsRequest, stream findingspb.FindingsService_StreamFindingsServer) error {
+	_, snap, err := s.snapshot(req.GetNamespace())
+	if err != nil {
+		return err
+	}
+	for _, f := range snap.Data {
+		if req.GetProjectUuid() != "" && f.Spec.ProjectUUID != req.GetProjectUuid() {
+			continue
+		}
+		if err := stream.Send(NewFinding(f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListProjects returns the projects of a served namespace
+func (s *Service) ListProjects(ctx context.Context, req *findingspb.ListProjectsRequest) (*findingspb.ListProjectsResponse, error) {
+	namespace := s.namespace(req.GetNamespace())
+	// Only served namespaces are listed, so the server's credentials cannot be
+	// used to read other namespaces
+	if _, ok := s.refresher.Get(namespace); !ok {
+		return nil, status.Errorf(codes.NotFound, "namespace %q is not served", namespace)
+	}
+	projects, err := s.projects(ctx, namespace, req.GetNameContains())
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "failed to list projects: %v", err)
+	}
+	resp := &findingspb.ListProjectsResponse{Namespace: namespace}
+	for _, p := range projects {
+		resp.Projects = append(resp.Projects, NewProject(p))
+	}
+	return resp, nil
+}
+
+// GetFreshness returns the freshness of every served namespace
+func (s *Service) GetFreshness(ctx context.Context, req *findingspb.GetFreshnessRequest) (*findingspb.GetFreshnessResponse, error) {
+	resp := &findingspb.GetFreshnessResponse{}
+	for _, f := range s.refresher.Freshness() {
+		resp.Namespaces = append(resp.Namespaces, NewFreshness(f))
+	}
+	return resp, nil
+}
+
+func (s *Service) namespace(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return s.defaultNamespace()
+}
+
+// snapshot returns the ready data of a namespace, with the same errors as the
+// REST endpoints as gRPC status codes: NotFound for a namespace that is not
+// served and Unavailable until its first refresh completes
+func (s *Service) snapshot(requested string) (string, prefetch.Snapshot[[]api.Finding], error) {
+	namespace := s.namespace(requested)
+	snap, ok := s.refresher.Get(namespace)
+	if !ok {
+		return namespace, snap, status.Errorf(codes.NotFound, "namespace %q is not served", namespace)
+	}
+	if !snap.Freshness.Ready {
+		msg := fmt.Sprintf("namespace %q has not been fetched yet", namespace)
+		if snap.Freshness.LastError != "" {
+			msg += ": " + snap.Freshness.LastError
+		}
+		return namespace, snap, status.Error(codes.Unavailable, msg)
+	}
+	return namespace, snap, nil
+}
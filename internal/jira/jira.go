@@ -0,0 +1,226 @@
+// Package jira creates and updates Jira issues through the REST API v2, which
+// both Jira Cloud and Jira Data Center serve.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultIssueType is used when no issue type is configured
+const DefaultIssueType = "Bug"
+
+// Config selects the Jira instance, credentials and where issues are created
+type Config struct {
+	URL string
+	// Email and Token are Jira Cloud basic auth credentials. Without Email,
+	// Token is sent as a Data Center personal access token.
+	Email     string
+	Token     string
+	Project   string
+	IssueType string
+	Labels    []string
+	// UUIDField is the custom text field holding the tracked finding UUIDs,
+	// e.g. customfield_10050. Without it, duplicates are only avoided through
+	// the local link store.
+	UUIDField string
+}
+
+// Issue is a created or found Jira issue
+type Issue struct {
+	Key string
+	URL string
+}
+
+// Fields are the issue fields the integration manages
+type Fields struct {
+	Summary     string
+	Description string
+	// UUIDs are stored in the UUID field, space-separated
+	UUIDs []string
+}
+
+// Client calls the Jira REST API
+type Client struct {
+	cfg        Config
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient checks cfg and returns a client for it
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("a Jira URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("a Jira API token is required")
+	}
+	if cfg.Project == "" {
+		return nil, errors.New("a Jira project key is required")
+	}
+	if cfg.IssueType == "" {
+		cfg.IssueType = DefaultIssueType
+	}
+	if cfg.UUIDField != "" && !strings.HasPrefix(cfg.UUIDField, "customfield_") {
+		return nil, fmt.Errorf("invalid UUID field %q (expected a custom field ID such as customfield_10050)", cfg.UUIDField)
+	}
+
+	return &Client{
+		cfg:        cfg,
+		baseURL:    strings.TrimRight(cfg.URL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// BrowseURL returns the web URL of the issue key
+func (c *Client) BrowseURL(key string) string {
+	return c.baseURL + "/browse/" + key
+}
+
+// Create opens a new issue
+func (c *Client) Create(ctx context.Context, f Fields) (Issue, error) {
+	fields := c.fields(f)
+	fields["project"] = map[string]string{"key": c.cfg.Project}
+	fields["issuetype"] = map[string]string{"name": c.cfg.IssueType}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, "POST", "/rest/api/2/issue", map[string]any{"fields": fields}, &created); err != nil {
+		return Issue{}, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return Issue{Key: created.Key, URL: c.BrowseURL(created.Key)}, nil
+}
+
+// Update replaces the managed fields of the issue key
+func (c *Client) Update(ctx context.Context, key string, f Fields) error {
+	if err := c.do(ctx, "PUT", "/rest/api/2/issue/"+url.PathEscape(key), map[string]any{"fields": c.fields(f)}, nil); err != nil {
+		return fmt.Errorf("failed to update issue %s: %w", key, err)
+	}
+	return nil
+}
+
+// FindByUUID searches the project for an issue whose UUID field contains
+// findingUUID. ok is false when there is none or no UUID field is configured.
+func (c *Client) FindByUUID(ctx context.Context, findingUUID string) (issue Issue, ok bool, err error) {
+	if c.cfg.UUIDField == "" {
+		return Issue{}, false, nil
+	}
+
+	jql := fmt.Sprintf("project = %q AND cf[%s] ~ %q", c.cfg.Project, strings.TrimPrefix(c.cfg.UUIDField, "customfield_"), findingUUID)
+	params := url.Values{}
+	params.Set("jql", jql)
+	params.Set("fields", "key")
+	params.Set("maxResults", "1")
+
+	var result struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	if err := c.do(ctx, "GET", "/rest/api/2/search?"+params.Encode(), nil, &result); err != nil {
+		return Issue{}, false, fmt.Errorf("failed to search issues: %w", err)
+	}
+	if len(result.Issues) == 0 {
+		return Issue{}, false, nil
+	}
+	key := result.Issues[0].Key
+	return Issue{Key: key, URL: c.BrowseURL(key)}, true, nil
+}
+
+// fields returns the fields set on both created and updated issues
+func (c *Client) fields(f Fields) map[string]any {
+	fields := map[string]any{
+		"summary":     f.Summary,
+		"description": f.Description,
+	}
+	if len(c.cfg.Labels) > 0 {
+		fields["labels"] = c.cfg.Labels
+	}
+	if c.cfg.UUIDField != "" {
+		fields[c.cfg.UUIDField] = strings.Join(f.UUIDs, " ")
+	}
+	return fields
+}
+
+// do sends a request with body encoded as JSON and decodes the response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Email != "" {
+		req.SetBasicAuth(c.cfg.Email, c.cfg.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// newError reads Jira's error body: errorMessages, plus per-field errors
+func newError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	msgs := []string{}
+	if json.Unmarshal(data, &body) == nil {
+		msgs = append(msgs, body.ErrorMessages...)
+		fields := make([]string, 0, len(body.Errors))
+		for field := range body.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			msgs = append(msgs, field+": "+body.Errors[field])
+		}
+	}
+	if len(msgs) == 0 {
+		if text := strings.TrimSpace(string(data)); text != "" {
+			msgs = append(msgs, text)
+		}
+	}
+	if len(msgs) == 0 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return fmt.Errorf("status %d: %s", resp.StatusCode, strings.Join(msgs, "; "))
+}
@@ -0,0 +1,156 @@
+// Package links keeps the mapping between Endor Labs findings and the tickets
+// and issues created for them in external trackers, so integrations can find
+// their earlier work and users can trace a finding across systems between runs.
+package links
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Well-known tracker systems
+const (
+	SystemJira       = "jira"
+	SystemGitHub     = "github"
+	SystemServiceNow = "servicenow"
+)
+
+// Link is a finding's counterpart in one external system
+type Link struct {
+	System string `json:"system"`
+	// ID is the system's identifier, e.g. SEC-123, acme/app#42 or INC0012345
+	ID        string    `json:"id"`
+	URL       string    `json:"url,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store persists links. Integrations record what they create with Set and look
+// up what they created earlier with Get or Find.
+type Store interface {
+	// Get returns the links of a finding, sorted by system
+	Get(findingUUID string) ([]Link, error)
+	// Find returns the finding linked to id in system; ok is false when there is none
+	Find(system, id string) (findingUUID string, ok bool, err error)
+	// Set records or replaces the finding's link in link.System
+	Set(findingUUID string, link Link) error
+	// Delete removes the finding's link in system
+	Delete(findingUUID, system string) error
+}
+
+// DefaultPath returns ~/.endor/links.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".endor", "links.json"), nil
+}
+
+// FileStore is a Store kept in a JSON file, mapping finding UUIDs to their
+// links by system. The file is rewritten atomically on every change.
+type FileStore struct {
+	path string
+
+	mu    sync.Mutex
+	links map[string]map[string]Link
+}
+
+// OpenFile loads the store at path; a missing file is an empty store
+func OpenFile(path string) (*FileStore, error) {
+	s := &FileStore{path: path, links: make(map[string]map[string]Link)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read links: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.links); err != nil {
+		return nil, fmt.Errorf("failed to parse links %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(findingUUID string) ([]Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links := make([]Link, 0, len(s.links[findingUUID]))
+	for _, l := range s.links[findingUUID] {
+		links = append(links, l)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].System < links[j].System })
+	return links, nil
+}
+
+func (s *FileStore) Find(system, id string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for uuid, bySystem := range s.links {
+		if l, ok := bySystem[system]; ok && l.ID == id {
+			return uuid, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (s *FileStore) Set(findingUUID string, link Link) error {
+	if findingUUID == "" || link.System == "" || link.ID == "" {
+		return errors.New("a link needs a finding UUID, system and ID")
+	}
+	if link.UpdatedAt.IsZero() {
+		link.UpdatedAt = time.Now().UTC()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.links[findingUUID] == nil {
+		s.links[findingUUID] = make(map[string]Link)
+	}
+	s.links[findingUUID][link.System] = link
+	return s.save()
+}
+
+func (s *FileStore) Delete(findingUUID, system string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.links[findingUUID][system]; !ok {
+		return nil
+	}
+	delete(s.links[findingUUID], system)
+	if len(s.links[findingUUID]) == 0 {
+		delete(s.links, findingUUID)
+	}
+	return s.save()
+}
+
+// save writes the store to a temporary file and renames it over the old one,
+// so an interrupted run never leaves a truncated file behind
+func (s *FileStore) save() error {
+	data, err := json.MarshalIndent(s.links, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal links: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create links directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write links: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write links: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Package manifest describes a run that produced artifacts in a machine-readable
+// run.json, so pipelines can verify and catalog runs without parsing logs.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/ci"
+)
+
+// FileName is the name of the manifest written next to a run's artifacts
+const FileName = "run.json"
+
+// Run statuses
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Counts summarizes what a run fetched
+type Counts struct {
+	Findings      int            `json:"findings"`
+	ByLevel       map[string]int `json:"by_level,omitempty"`
+	Projects      int            `json:"projects"`
+	Warnings      int            `json:"warnings"`
+	ProjectErrors int            `json:"project_errors"`
+}
+
+// Output is an artifact a run produced
+type Output struct {
+	Path   string `json:"path"`
+	Format string `json:"format,omitempty"`
+	// Bytes and SHA256 are unset for outputs written to stdout
+	Bytes  int64  `json:"bytes,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+	// URL is where the artifact was uploaded, if it was
+	URL string `json:"url,omitempty"`
+}
+
+// Manifest describes one run: what was asked for, what it produced and how it ended
+type Manifest struct {
+	Tool      string `json:"tool"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	// Flags holds the flags set on the command line, by name
+	Flags     map[string]string `json:"flags,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Selection string            `json:"selection,omitempty"`
+	Filter    string            `json:"filter,omitempty"`
+	// CI is the build the run was part of, when one was detected
+	CI *ci.Env `json:"ci,omitempty"`
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMS int64     `json:"duration_ms"`
+
+	Status  string   `json:"status"`
+	Counts  Counts   `json:"counts"`
+	Outputs []Output `json:"outputs"`
+	Errors  []string `json:"errors,omitempty"`
+
+	mu sync.Mutex
+}
+
+// New starts the manifest of a run of command
+func New(command string) *Manifest {
+	return &Manifest{
+		Command:   command,
+		Flags:     make(map[string]string),
+		StartedAt: time.Now().UTC(),
+		Outputs:   []Output{},
+	}
+}
+
+// SetFindings records the counts of a findings fetch
+func (m *Manifest) SetFindings(findings []api.Finding, warnings, projectErrors int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := Counts{
+		Findings:      len(findings),
+		ByLevel:       make(map[string]int),
+		Warnings:      warnings,
+		ProjectErrors: projectErrors,
+	}
+	projects := make(map[string]bool)
+	for _, f := range findings {
+		c.ByLevel[f.Spec.Level.Short()]++
+		projects[f.Spec.ProjectUUID] = true
+	}
+	c.Projects = len(projects)
+	m.Counts = c
+}
+
+// AddOutput records an artifact written to path, with its size and checksum.
+// path "-" records an output written to stdout.
+func (m *Manifest) AddOutput(path, format string) error {
+	out := Output{Path: path, Format: format}
+	if path != "-" {
+		size, sum, err := checksum(path)
+		if err != nil {
+			return fmt.Errorf("failed to checksum %s: %w", path, err)
+		}
+		out.Bytes, out.SHA256 = size, sum
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Outputs = append(m.Outputs, out)
+	return nil
+}
+
+// SetUploaded records the URL the artifact at path was uploaded to
+func (m *Manifest) SetUploaded(path, url string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.Outputs {
+		if m.Outputs[i].Path == path {
+			m.Outputs[i].URL = url
+		}
+	}
+}
+
+// AddError records an error that failed the run or one of its parts
+func (m *Manifest) AddError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Errors = append(m.Errors, err.Error())
+}
+
+// Finish records the end of the run; err is the error the run ended with, if any
+func (m *Manifest) Finish(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.FinishedAt = time.Now().UTC()
+	m.DurationMS = m.FinishedAt.Sub(m.StartedAt).Milliseconds()
+	m.Status = StatusSucceeded
+	if err != nil {
+		m.Status = StatusFailed
+		m.Errors = append(m.Errors, err.Error())
+	}
+}
+
+// Write saves the manifest as indented JSON at path
+func (m *Manifest) Write(path string) error {
+	m.mu.Lock()
+	data, err := json.MarshalIndent(m, "", "  ")
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal run manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	return nil
+}
+
+func checksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,246 @@
+// Package metrics collects findings counts and API request outcomes and
+// exposes them in the Prometheus text exposition format, either scraped from
+// an HTTP endpoint or pushed to a Pushgateway, so security dashboards can
+// chart them.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4"
+
+// pushTimeout bounds a push to a Pushgateway
+const pushTimeout = 30 * time.Second
+
+// projectKey identifies a project in the per-project gauge
+type projectKey struct {
+	uuid, name string
+}
+
+// namespaceFindings is the last fetched state of a namespace
+type namespaceFindings struct {
+	levels    map[api.FindingLevel]int
+	projects  map[projectKey]int
+	fetchedAt time.Time
+}
+
+// errorKey identifies failed requests by endpoint and status
+type errorKey struct {
+	endpoint, status string
+}
+
+// Collector holds the current findings gauges of each namespace and the
+// request counters of every client it observed. It is safe for concurrent use.
+type Collector struct {
+	mu         sync.Mutex
+	namespaces map[string]namespaceFindings
+	requests   map[string]int
+	retries    map[string]int
+	errors     map[errorKey]int
+}
+
+// New returns an empty collector
+func New() *Collector {
+	return &Collector{
+		namespaces: make(map[string]namespaceFindings),
+		requests:   make(map[string]int),
+		retries:    make(map[string]int),
+		errors:     make(map[errorKey]int),
+	}
+}
+
+// SetFindings replaces the findings gauges of namespace with the counts of
+// findings, fetched at fetchedAt
+func (c *Collector) SetFindings(namespace string, findings []api.Finding, fetchedAt time.Time) {
+	state := namespaceFindings{
+		levels:    make(map[api.FindingLevel]int),
+		projects:  make(map[projectKey]int),
+		fetchedAt: fetchedAt,
+	}
+	for _, f := range findings {
+		state.levels[f.Spec.Level]++
+		key := projectKey{uuid: f.Spec.ProjectUUID}
+		if f.Project != nil {
+			key.name = f.Project.Name
+		}
+		state.projects[key]++
+	}
+
+	c.mu.Lock()
+	c.namespaces[namespace] = state
+	c.mu.Unlock()
+}
+
+// Forget drops the findings gauges of a namespace that is no longer fetched
+func (c *Collector) Forget(namespace string) {
+	c.mu.Lock()
+	delete(c.namespaces, namespace)
+	c.mu.Unlock()
+}
+
+// ObserveRequests adds requests to the request, retry and error counters.
+// Pass each request once: a long-lived client's requests since the last call.
+func (c *Collector) ObserveRequests(requests []api.RequestReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, rr := range requests {
+		c.requests[rr.Endpoint]++
+		c.retries[rr.Endpoint] += rr.Retries
+		if !rr.Succeeded {
+			status := "error"
+			if rr.Status != 0 {
+				status = strconv.Itoa(rr.Status)
+			}
+			c.errors[errorKey{rr.Endpoint, status}]++
+		}
+	}
+}
+
+// Write writes every metric in the text exposition format
+func (c *Collector) Write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	namespaces := make([]string, 0, len(c.namespaces))
+	for ns := range c.namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	var b strings.Builder
+	family(&b, "findings_api_findings", "gauge", "Findings by namespace and level at the last fetch")
+	for _, ns := range namespaces {
+		for _, level := range api.FindingLevels {
+			sample(&b, "findings_api_findings", float64(c.namespaces[ns].levels[level]), "namespace", ns, "level", level.Short())
+		}
+	}
+
+	family(&b, "findings_api_project_findings", "gauge", "Findings by project at the last fetch")
+	for _, ns := range namespaces {
+		projects := c.namespaces[ns].projects
+		keys := make([]projectKey, 0, len(projects))
+		for k := range projects {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].uuid < keys[j].uuid })
+		for _, k := range keys {
+			sample(&b, "findings_api_project_findings", float64(projects[k]), "namespace", ns, "project_uuid", k.uuid, "project", k.name)
+		}
+	}
+
+	family(&b, "findings_api_last_fetch_timestamp_seconds", "gauge", "Unix time of the last successful fetch of the namespace")
+	for _, ns := range namespaces {
+		sample(&b, "findings_api_last_fetch_timestamp_seconds", float64(c.namespaces[ns].fetchedAt.Unix()), "namespace", ns)
+	}
+
+	family(&b, "findings_api_api_requests_total", "counter", "API requests by endpoint, retries not counted")
+	for _, endpoint := range sortedKeys(c.requests) {
+		sample(&b, "findings_api_api_requests_total", float64(c.requests[endpoint]), "endpoint", endpoint)
+	}
+
+	family(&b, "findings_api_api_retries_total", "counter", "Retried API requests by endpoint")
+	for _, endpoint := range sortedKeys(c.retries) {
+		sample(&b, "findings_api_api_retries_total", float64(c.retries[endpoint]), "endpoint", endpoint)
+	}
+
+	family(&b, "findings_api_api_errors_total", "counter", "API requests that failed after their retries, by endpoint and final status")
+	errs := make([]errorKey, 0, len(c.errors))
+	for k := range c.errors {
+		errs = append(errs, k)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].endpoint != errs[j].endpoint {
+			return errs[i].endpoint < errs[j].endpoint
+		}
+		return errs[i].status < errs[j].status
+	})
+	for _, k := range errs {
+		sample(&b, "findings_api_api_errors_total", float64(c.errors[k]), "endpoint", k.endpoint, "status", k.status)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ServeHTTP serves the metrics to a Prometheus scrape
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	c.Write(w)
+}
+
+// Push replaces the metrics of job on the Pushgateway at gatewayURL, e.g.
+// http://pushgateway:9091, for runs too short-lived to be scraped
+func (c *Collector) Push(ctx context.Context, gatewayURL, job string) error {
+	var body bytes.Buffer
+	if err := c.Write(&body); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+
+	pushURL := strings.TrimRight(gatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL, &body)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to push metrics: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// family writes the HELP and TYPE lines of a metric
+func family(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one sample of a metric with labels given as name, value pairs
+func sample(b *strings.Builder, name string, value float64, labels ...string) {
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(b, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+	}
+	b.WriteString("} ")
+	b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	b.WriteByte('\n')
+}
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
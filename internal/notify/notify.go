@@ -0,0 +1,176 @@
+// Package notify routes findings to notification channels ("sinks") by rules
+// on their level, category and tags, so one run can page on criticals, file
+// tickets for highs and collect mediums for a digest.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/config"
+)
+
+// Sink delivers findings to one channel
+type Sink interface {
+	// Name identifies the sink in routes and results
+	Name() string
+	// Send delivers findings; it is called once per run with every finding
+	// routed to the sink
+	Send(ctx context.Context, findings []api.Finding) error
+}
+
+// Route sends the findings it matches to its sinks
+type Route struct {
+	Name       string
+	Levels     []api.FindingLevel
+	Categories []api.FindingCategory
+	Tags       []api.FindingTag
+	Sinks      []string
+}
+
+// Matches reports whether f has one of the route's levels, categories and tags.
+// Empty lists match any finding.
+func (r Route) Matches(f api.Finding) bool {
+	if len(r.Levels) > 0 && !containsAny(r.Levels, f.Spec.Level) {
+		return false
+	}
+	if len(r.Categories) > 0 && !containsAny(r.Categories, f.Spec.FindingCategories...) {
+		return false
+	}
+	if len(r.Tags) > 0 && !containsAny(r.Tags, f.Spec.FindingTags...) {
+		return false
+	}
+	return true
+}
+
+// Router holds the routes and the sinks they refer to
+type Router struct {
+	Routes []Route
+	Sinks  map[string]Sink
+}
+
+// Result is the outcome of delivering to one sink
+type Result struct {
+	Sink     string `json:"sink"`
+	Findings int    `json:"findings"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Plan returns the findings each sink receives. A finding matching several
+// routes to the same sink is included once.
+func (r *Router) Plan(findings []api.Finding) map[string][]api.Finding {
+	plan := make(map[string][]api.Finding)
+	for _, f := range findings {
+		seen := make(map[string]bool)
+		for _, route := range r.Routes {
+			if !route.Matches(f) {
+				continue
+			}
+			for _, sink := range route.Sinks {
+				if !seen[sink] {
+					seen[sink] = true
+					plan[sink] = append(plan[sink], f)
+				}
+			}
+		}
+	}
+	return plan
+}
+
+// Deliver routes findings and sends each sink its share. A failing sink does
+// not stop the others; results are sorted by sink name.
+func (r *Router) Deliver(ctx context.Context, findings []api.Finding) []Result {
+	plan := r.Plan(findings)
+
+	results := make([]Result, 0, len(plan))
+	for name, batch := range plan {
+		res := Result{Sink: name, Findings: len(batch)}
+		if err := r.Sinks[name].Send(ctx, batch); err != nil {
+			res.Error = err.Error()
+		}
+		results = append(results, res)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Sink < results[j].Sink })
+	return results
+}
+
+// RemoteSinks returns the names of the sinks that call external services,
+// sorted, so air-gapped runs can refuse them up front
+func (r *Router) RemoteSinks() []string {
+	var names []string
+	for name, s := range r.Sinks {
+		if _, local := s.(*File); !local {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromConfig builds the router described by cfg, checking that every route
+// refers to a configured sink and uses known levels, categories and tags
+func FromConfig(cfg config.Notifications) (*Router, error) {
+	r := &Router{Sinks: make(map[string]Sink, len(cfg.Sinks))}
+	for name, sc := range cfg.Sinks {
+		sink, err := NewSink(name, sc)
+		if err != nil {
+			return nil, fmt.Errorf("sink %s: %w", name, err)
+		}
+		r.Sinks[name] = sink
+	}
+
+	for i, rc := range cfg.Routes {
+		name := rc.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		route := Route{Name: name, Sinks: rc.Sinks}
+
+		var err error
+		if route.Levels, err = parseAll(rc.Levels, api.ParseFindingLevel); err != nil {
+			return nil, fmt.Errorf("route %s: %w", name, err)
+		}
+		if route.Categories, err = parseAll(rc.Categories, api.ParseFindingCategory); err != nil {
+			return nil, fmt.Errorf("route %s: %w", name, err)
+		}
+		if route.Tags, err = parseAll(rc.Tags, api.ParseFindingTag); err != nil {
+			return nil, fmt.Errorf("route %s: %w", name, err)
+		}
+		if len(route.Sinks) == 0 {
+			return nil, fmt.Errorf("route %s has no sinks", name)
+		}
+		for _, s := range route.Sinks {
+			if _, ok := r.Sinks[s]; !ok {
+				return nil, fmt.Errorf("route %s refers to unknown sink %q", name, s)
+			}
+		}
+		r.Routes = append(r.Routes, route)
+	}
+
+	return r, nil
+}
+
+func parseAll[T any](values []string, parse func(string) (T, error)) ([]T, error) {
+	parsed := make([]T, 0, len(values))
+	for _, v := range values {
+		p, err := parse(v)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+func containsAny[T comparable](want []T, have ...T) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,325 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Defaults of the SIEM sinks
+const (
+	DefaultSourcetype = "endor:finding"
+	DefaultSource     = "findings-api"
+	DefaultAppName    = "findings-api"
+)
+
+// hecEventPath is the Splunk HTTP Event Collector endpoint for JSON events
+const hecEventPath = "/services/collector/event"
+
+// hecBatchSize is how many events are sent per HEC request
+const hecBatchSize = 100
+
+// Event is the flat form of a finding that SIEM sinks send, so searches and
+// correlation rules can use top-level fields instead of the nested API model
+type Event struct {
+	UUID         string   `json:"uuid"`
+	Namespace    string   `json:"namespace,omitempty"`
+	ProjectUUID  string   `json:"project_uuid"`
+	Project      string   `json:"project,omitempty"`
+	Level        string   `json:"level"`
+	Categories   []string `json:"categories,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Ecosystem    string   `json:"ecosystem,omitempty"`
+	Package      string   `json:"package,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	Advisories   []string `json:"advisories,omitempty"`
+	CVE          string   `json:"cve,omitempty"`
+	CWEs         []string `json:"cwes,omitempty"`
+	CVSS         float64  `json:"cvss,omitempty"`
+	EPSS         float64  `json:"epss,omitempty"`
+	Reachability string   `json:"reachability,omitempty"`
+	Fix          string   `json:"fix,omitempty"`
+	CreatedAt    string   `json:"created_at,omitempty"`
+}
+
+// NewEvent flattens f into an Event
+func NewEvent(f api.Finding) Event {
+	e := Event{
+		UUID:         f.UUID,
+		Namespace:    f.Namespace,
+		ProjectUUID:  f.Spec.ProjectUUID,
+		Level:        string(f.Spec.Level),
+		Name:         f.Meta.Name,
+		Description:  f.Meta.Description,
+		Ecosystem:    f.Spec.Ecosystem,
+		Package:      f.Spec.TargetDependencyPackageName,
+		Version:      f.Spec.TargetDependencyVersion,
+		Advisories:   f.VulnerabilityIDs(),
+		CVE:          f.CVE(),
+		CWEs:         f.CWEs(),
+		Reachability: string(f.Reachability()),
+		Fix:          f.UpgradeTarget(),
+		CreatedAt:    f.Meta.CreateTime,
+	}
+	if f.Project != nil {
+		e.Project = f.Project.Name
+	}
+	for _, c := range f.Spec.FindingCategories {
+		e.Categories = append(e.Categories, string(c))
+	}
+	for _, t := range f.Spec.FindingTags {
+		e.Tags = append(e.Tags, string(t))
+	}
+	if cvss := f.CVSS(); cvss != nil {
+		e.CVSS = cvss.Score
+	}
+	if epss := f.EPSS(); epss != nil {
+		e.EPSS = epss.ProbabilityScore
+	}
+	return e
+}
+
+// eventTime is the finding's creation time, or now when it is unknown
+func eventTime(f api.Finding) time.Time {
+	if t, err := time.Parse(time.RFC3339, f.Meta.CreateTime); err == nil {
+		return t
+	}
+	return time.Now()
+}
+
+// Splunk sends one event per finding to a Splunk HTTP Event Collector. Events
+// are batched, and timestamped with the finding's creation time.
+type Splunk struct {
+	SinkName   string
+	URL        string
+	Token      string
+	Index      string
+	Sourcetype string
+	Source     string
+	Host       string
+}
+
+func (s *Splunk) Name() string { return s.SinkName }
+
+func (s *Splunk) Send(ctx context.Context, findings []api.Finding) error {
+	endpoint := strings.TrimRight(s.URL, "/")
+	if !strings.HasSuffix(endpoint, hecEventPath) {
+		endpoint += hecEventPath
+	}
+	headers := map[string]string{"Authorization": "Splunk " + s.Token}
+
+	for start := 0; start < len(findings); start += hecBatchSize {
+		end := min(start+hecBatchSize, len(findings))
+		// HEC takes concatenated JSON objects rather than an array
+		var body bytes.Buffer
+		enc := json.NewEncoder(&body)
+		for _, f := range findings[start:end] {
+			event := map[string]any{
+				"time":       float64(eventTime(f).UnixMilli()) / 1000,
+				"sourcetype": firstNonEmpty(s.Sourcetype, DefaultSourcetype),
+				"source":     firstNonEmpty(s.Source, DefaultSource),
+				"event":      NewEvent(f),
+			}
+			if s.Index != "" {
+				event["index"] = s.Index
+			}
+			if s.Host != "" {
+				event["host"] = s.Host
+			}
+			if err := enc.Encode(event); err != nil {
+				return fmt.Errorf("failed to marshal event: %w", err)
+			}
+		}
+		if err := post(ctx, endpoint, "application/json", headers, body.Bytes()); err != nil {
+			return fmt.Errorf("events %d-%d of %d: %w", start+1, end, len(findings), err)
+		}
+	}
+	return nil
+}
+
+// Events posts the routed findings as NDJSON events to a generic HTTPS
+// collector, e.g. a log shipper or a SIEM's HTTP intake
+type Events struct {
+	SinkName string
+	URL      string
+	Headers  map[string]string
+}
+
+func (e *Events) Name() string { return e.SinkName }
+
+func (e *Events) Send(ctx context.Context, findings []api.Finding) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, f := range findings {
+		if err := enc.Encode(NewEvent(f)); err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+	}
+	return post(ctx, e.URL, "application/x-ndjson", e.Headers, body.Bytes())
+}
+
+// Syslog sends one RFC 5424 message per finding, with the event as its JSON
+// message, over UDP, TCP or TLS. Stream transports use octet-counting framing.
+type Syslog struct {
+	SinkName string
+	// Address is udp://host:port, tcp://host:port or tls://host:port
+	Address string
+	AppName string
+	// Hostname is the HOSTNAME of messages (default: the local host name)
+	Hostname string
+}
+
+func (s *Syslog) Name() string { return s.SinkName }
+
+// syslogSeverities maps finding levels to syslog severities
+var syslogSeverities = map[api.FindingLevel]int{
+	api.LevelCritical: 2, // crit
+	api.LevelHigh:     3, // err
+	api.LevelMedium:   4, // warning
+	api.LevelLow:      5, // notice
+}
+
+// syslogFacility is local0, which SIEM inputs commonly reserve for applications
+const syslogFacility = 16
+
+func (s *Syslog) Send(ctx context.Context, findings []api.Finding) error {
+	network, addr, err := parseSyslogAddress(s.Address)
+	if err != nil {
+		return err
+	}
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if network == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: dialer}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, network, addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", s.Address, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	hostname := s.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	for _, f := range findings {
+		msg, err := s.format(f, hostname)
+		if err != nil {
+			return err
+		}
+		if network != "udp" {
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		if _, err := conn.Write(msg); err != nil {
+			return fmt.Errorf("failed to send finding %s: %w", f.UUID, err)
+		}
+	}
+	return nil
+}
+
+// format renders f as an RFC 5424 message
+func (s *Syslog) format(f api.Finding, hostname string) ([]byte, error) {
+	severity, ok := syslogSeverities[f.Spec.Level]
+	if !ok {
+		severity = 4
+	}
+	payload, err := json.Marshal(NewEvent(f))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+		syslogFacility*8+severity,
+		time.Now().UTC().Format(time.RFC3339Nano),
+		syslogField(hostname, 255),
+		syslogField(firstNonEmpty(s.AppName, DefaultAppName), 48),
+		os.Getpid(),
+		syslogField(f.Meta.Name, 32),
+	)
+	return append([]byte(header), payload...), nil
+}
+
+// parseSyslogAddress splits udp://host:port, tcp://host:port and
+// tls://host:port into a network and an address
+func parseSyslogAddress(address string) (string, string, error) {
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("invalid syslog address %q (expected udp://, tcp:// or tls://host:port)", address)
+	}
+	switch u.Scheme {
+	case "udp", "tcp", "tls":
+	default:
+		return "", "", fmt.Errorf("unsupported syslog transport %q (expected udp, tcp or tls)", u.Scheme)
+	}
+	if u.Port() == "" {
+		return "", "", fmt.Errorf("syslog address %q has no port", address)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// syslogField makes s a valid header field: printable ASCII without spaces,
+// at most max characters, and "-" when empty
+func syslogField(s string, max int) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+		if b.Len() == max {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+// post sends body to url and fails on any non-2xx response
+func post(ctx context.Context, url, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
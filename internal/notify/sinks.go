@@ -0,0 +1,200 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/config"
+)
+
+// Sink types
+const (
+	TypeWebhook   = "webhook"
+	TypePagerDuty = "pagerduty"
+	TypeFile      = "file"
+	TypeSplunk    = "splunk"
+	TypeSyslog    = "syslog"
+	TypeEvents    = "events"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// httpClient is shared by the sinks that call HTTP endpoints
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// NewSink creates the sink described by sc
+func NewSink(name string, sc config.Sink) (Sink, error) {
+	switch sc.Type {
+	case TypeWebhook:
+		if sc.URL == "" {
+			return nil, errors.New("webhook sinks need a url")
+		}
+		return &Webhook{SinkName: name, URL: os.ExpandEnv(sc.URL), Headers: expandHeaders(sc.Headers)}, nil
+	case TypePagerDuty:
+		key := os.ExpandEnv(sc.RoutingKey)
+		if key == "" {
+			return nil, errors.New("pagerduty sinks need a routing_key")
+		}
+		return &PagerDuty{SinkName: name, RoutingKey: key}, nil
+	case TypeFile:
+		if sc.Path == "" {
+			return nil, errors.New("file sinks need a path")
+		}
+		return &File{SinkName: name, Path: sc.Path}, nil
+	case TypeSplunk:
+		token := os.ExpandEnv(sc.Token)
+		if sc.URL == "" || token == "" {
+			return nil, errors.New("splunk sinks need a url and a token")
+		}
+		return &Splunk{
+			SinkName:   name,
+			URL:        os.ExpandEnv(sc.URL),
+			Token:      token,
+			Index:      sc.Index,
+			Sourcetype: sc.Sourcetype,
+			Source:     sc.Source,
+			Host:       sc.Host,
+		}, nil
+	case TypeSyslog:
+		address := os.ExpandEnv(sc.Address)
+		if _, _, err := parseSyslogAddress(address); err != nil {
+			return nil, err
+		}
+		return &Syslog{SinkName: name, Address: address, AppName: sc.AppName, Hostname: sc.Host}, nil
+	case TypeEvents:
+		if sc.URL == "" {
+			return nil, errors.New("events sinks need a url")
+		}
+		return &Events{SinkName: name, URL: os.ExpandEnv(sc.URL), Headers: expandHeaders(sc.Headers)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sink type %q (expected %s, %s, %s, %s, %s or %s)", sc.Type,
+			TypeWebhook, TypePagerDuty, TypeFile, TypeSplunk, TypeSyslog, TypeEvents)
+	}
+}
+
+// expandHeaders expands the environment variables in header values
+func expandHeaders(headers map[string]string) map[string]string {
+	expanded := make(map[string]string, len(headers))
+	for k, v := range headers {
+		expanded[k] = os.ExpandEnv(v)
+	}
+	return expanded
+}
+
+// Webhook posts the routed findings to a URL as one JSON document
+type Webhook struct {
+	SinkName string
+	URL      string
+	Headers  map[string]string
+}
+
+func (w *Webhook) Name() string { return w.SinkName }
+
+func (w *Webhook) Send(ctx context.Context, findings []api.Finding) error {
+	payload := struct {
+		Sink     string        `json:"sink"`
+		SentAt   time.Time     `json:"sent_at"`
+		Count    int           `json:"count"`
+		Findings []api.Finding `json:"findings"`
+	}{w.SinkName, time.Now().UTC(), len(findings), findings}
+
+	return postJSON(ctx, w.URL, w.Headers, payload)
+}
+
+// PagerDuty triggers one PagerDuty incident per finding. The finding UUID is
+// the dedup key, so repeated runs update the open incident instead of paging again.
+type PagerDuty struct {
+	SinkName   string
+	RoutingKey string
+}
+
+func (p *PagerDuty) Name() string { return p.SinkName }
+
+// pagerDutySeverities maps finding levels to PagerDuty severities
+var pagerDutySeverities = map[api.FindingLevel]string{
+	api.LevelCritical: "critical",
+	api.LevelHigh:     "error",
+	api.LevelMedium:   "warning",
+	api.LevelLow:      "info",
+}
+
+func (p *PagerDuty) Send(ctx context.Context, findings []api.Finding) error {
+	var errs []error
+	for _, f := range findings {
+		severity, ok := pagerDutySeverities[f.Spec.Level]
+		if !ok {
+			severity = "warning"
+		}
+
+		summary := f.Meta.Description
+		if cve := f.CVE(); cve != "" {
+			summary = cve + ": " + summary
+		}
+		if len(summary) > 1024 {
+			summary = summary[:1024]
+		}
+
+		event := map[string]any{
+			"routing_key":  p.RoutingKey,
+			"event_action": "trigger",
+			"dedup_key":    f.UUID,
+			"payload": map[string]any{
+				"summary":        summary,
+				"source":         f.Spec.TargetDependencyPackageName,
+				"severity":       severity,
+				"component":      f.Spec.ProjectUUID,
+				"class":          f.Meta.Name,
+				"custom_details": f,
+			},
+		}
+		if err := postJSON(ctx, pagerDutyEventsURL, nil, event); err != nil {
+			errs = append(errs, fmt.Errorf("finding %s: %w", f.UUID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// File appends the routed findings to a file as NDJSON, e.g. to collect them
+// for a periodic digest
+type File struct {
+	SinkName string
+	Path     string
+}
+
+func (f *File) Name() string { return f.SinkName }
+
+func (f *File) Send(ctx context.Context, findings []api.Finding) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	for _, finding := range findings {
+		if err := enc.Encode(finding); err != nil {
+			return fmt.Errorf("failed to write finding: %w", err)
+		}
+	}
+	return file.Close()
+}
+
+// postJSON posts v as JSON to url and fails on any non-2xx response
+func postJSON(ctx context.Context, url string, headers map[string]string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return post(ctx, url, "application/json", headers, body)
+}
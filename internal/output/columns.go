@@ -0,0 +1,153 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/enrich"
+	"github.com/endor-labs/findings-api/internal/risk"
+)
+
+// Column is a named value extracted from a finding for tabular formats
+type Column struct {
+	Name   string
+	Header string
+	Value  func(f api.Finding) string
+}
+
+// DefaultColumns is the column set used when none is configured
+var DefaultColumns = []string{"uuid", "name", "cve", "level", "package", "ecosystem", "tags", "file_paths"}
+
+// columns lists every column that can be selected, keyed by name
+var columns = map[string]Column{
+	"uuid":         {Header: "UUID", Value: func(f api.Finding) string { return f.UUID }},
+	"name":         {Header: "Name", Value: func(f api.Finding) string { return f.Meta.Name }},
+	"description":  {Header: "Description", Value: func(f api.Finding) string { return f.Meta.Description }},
+	"level":        {Header: "Level", Value: func(f api.Finding) string { return string(f.Spec.Level) }},
+	"package":      {Header: "Package", Value: func(f api.Finding) string { return f.Spec.TargetDependencyPackageName }},
+	"ecosystem":    {Header: "Ecosystem", Value: func(f api.Finding) string { return f.Spec.Ecosystem }},
+	"tags":         {Header: "Tags", Value: func(f api.Finding) string { return api.JoinEnums(f.Spec.FindingTags, ";") }},
+	"categories":   {Header: "Categories", Value: func(f api.Finding) string { return api.JoinEnums(f.Spec.FindingCategories, ";") }},
+	"file_paths":   {Header: "File Paths", Value: func(f api.Finding) string { return strings.Join(f.Spec.DependencyFilePath, ";") }},
+	"relationship": {Header: "Relationship", Value: func(f api.Finding) string { return f.Spec.Relationship }},
+	"summary":      {Header: "Summary", Value: func(f api.Finding) string { return f.Spec.Summary }},
+	"explanation":  {Header: "Explanation", Value: func(f api.Finding) string { return f.Spec.Explanation }},
+	"cve":          {Header: "CVE", Value: func(f api.Finding) string { return f.CVE() }},
+	"vuln_ids":     {Header: "Vulnerability IDs", Value: func(f api.Finding) string { return strings.Join(f.VulnerabilityIDs(), ";") }},
+	"cvss_score": {Header: "CVSS Score", Value: func(f api.Finding) string {
+		if c := f.CVSS(); c != nil {
+			return strconv.FormatFloat(c.Score, 'f', 1, 64)
+		}
+		return ""
+	}},
+	"cvss_vector": {Header: "CVSS Vector", Value: func(f api.Finding) string {
+		if c := f.CVSS(); c != nil {
+			return c.Vector
+		}
+		return ""
+	}},
+	"cvss_adjusted_score": {Header: "Adjusted CVSS Score", Value: func(f api.Finding) string {
+		if score, ok := enrich.CVSSScore(f); ok {
+			return strconv.FormatFloat(score, 'f', 1, 64)
+		}
+		return ""
+	}},
+	"cvss_severity": {Header: "CVSS Severity", Value: enrich.CVSSSeverity},
+	"epss": {Header: "EPSS", Value: func(f api.Finding) string {
+		if e := f.EPSS(); e != nil {
+			return strconv.FormatFloat(e.ProbabilityScore, 'f', -1, 64)
+		}
+		return ""
+	}},
+	"risk_score": {Header: "Risk Score", Value: func(f api.Finding) string {
+		if score, ok := risk.Of(f); ok {
+			return strconv.FormatFloat(score, 'f', 1, 64)
+		}
+		return ""
+	}},
+	"fixed_versions":  {Header: "Fixed Versions", Value: func(f api.Finding) string { return strings.Join(f.FixedVersions(), ";") }},
+	"affected_ranges": {Header: "Affected Versions", Value: func(f api.Finding) string { return strings.Join(f.AffectedRanges(), ";") }},
+	"owners":          {Header: "Owners", Value: func(f api.Finding) string { return strings.Join(f.Owners, ";") }},
+	"occurrences": {Header: "Occurrences", Value: func(f api.Finding) string {
+		if f.Occurrences == 0 {
+			return ""
+		}
+		return strconv.Itoa(f.Occurrences)
+	}},
+	"correlation_id": {Header: "Correlation ID", Value: func(f api.Finding) string { return f.CorrelationID }},
+	"context":        {Header: "Context", Value: func(f api.Finding) string { return strings.TrimPrefix(f.Context.Type, "CONTEXT_TYPE_") + contextID(f) }},
+	"contexts":       {Header: "Contexts", Value: func(f api.Finding) string { return strings.Join(f.Contexts, ";") }},
+	"namespace":      {Header: "Namespace", Value: func(f api.Finding) string { return f.Namespace }},
+	"project_uuid":   {Header: "Project UUID", Value: func(f api.Finding) string { return f.Spec.ProjectUUID }},
+	"project_name": {Header: "Project", Value: func(f api.Finding) string {
+		if f.Project == nil {
+			return ""
+		}
+		return f.Project.Name
+	}},
+}
+
+// ParseColumns resolves a comma-separated list of column names.
+// An empty spec selects DefaultColumns.
+func ParseColumns(spec string) ([]Column, error) {
+	names := DefaultColumns
+	if strings.TrimSpace(spec) != "" {
+		names = nil
+		for _, name := range strings.Split(spec, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	selected := make([]Column, 0, len(names))
+	for _, name := range names {
+		col, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(ColumnNames(), ", "))
+		}
+		col.Name = name
+		selected = append(selected, col)
+	}
+
+	return selected, nil
+}
+
+// ColumnNames returns the names of all selectable columns in sorted order
+func ColumnNames() []string {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// contextID returns ":<id>" for findings in a context with an ID
+func contextID(f api.Finding) string {
+	if f.Context.ID == "" {
+		return ""
+	}
+	return ":" + f.Context.ID
+}
+
+// row extracts the values of columns from f
+func row(f api.Finding, cols []Column) []string {
+	values := make([]string, len(cols))
+	for i, col := range cols {
+		values[i] = col.Value(f)
+	}
+	return values
+}
+
+// headers returns the header row for cols
+func headers(cols []Column) []string {
+	values := make([]string, len(cols))
+	for i, col := range cols {
+		values[i] = col.Header
+	}
+	return values
+}
@@ -0,0 +1,27 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// WriteCSV writes findings as CSV with a header row, one finding per row
+func WriteCSV(w io.Writer, findings []api.Finding, cols []Column) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(headers(cols)); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, f := range findings {
+		if err := cw.Write(row(f, cols)); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
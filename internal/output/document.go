@@ -0,0 +1,39 @@
+package output
+
+import (
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/correlate"
+)
+
+// SchemaVersion is the version of the Document layout. Bump it, and add a
+// migration to the snapshot package, when a change would break reading older files.
+const SchemaVersion = 2
+
+// Document is the full result of a findings fetch, as written by the JSON format
+type Document struct {
+	SchemaVersion     int               `json:"schema_version"`
+	Timestamp         string            `json:"timestamp"`
+	SearchDescription string            `json:"search_description"`
+	TotalFindings     int               `json:"total_findings"`
+	Findings          []api.Finding     `json:"findings"`
+	ProjectErrors     map[string]string `json:"project_errors,omitempty"`
+	Warnings          []api.Warning     `json:"warnings,omitempty"`
+	Correlations      []correlate.Group `json:"correlations,omitempty"`
+	FetchReport       api.FetchReport   `json:"fetch_report"`
+}
+
+// NewDocument builds a document for findings, timestamped now
+func NewDocument(findings []api.Finding, searchDescription string, report api.FetchReport, projectErrors map[string]string, warnings []api.Warning) *Document {
+	return &Document{
+		SchemaVersion:     SchemaVersion,
+		Timestamp:         time.Now().Format(time.RFC3339),
+		SearchDescription: searchDescription,
+		TotalFindings:     len(findings),
+		Findings:          findings,
+		ProjectErrors:     projectErrors,
+		Warnings:          warnings,
+		FetchReport:       report,
+	}
+}
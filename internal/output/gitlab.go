@@ -0,0 +1,219 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+)
+
+// GitLab security report identifiers
+const (
+	gitlabSchemaVersion = "15.0.7"
+	gitlabScanType      = "dependency_scanning"
+	gitlabTimeLayout    = "2006-01-02T15:04:05"
+	gitlabVendor        = "Endor Labs"
+)
+
+// gitlabSeverities maps finding levels to GitLab severities
+var gitlabSeverities = map[api.FindingLevel]string{
+	api.LevelCritical: "Critical",
+	api.LevelHigh:     "High",
+	api.LevelMedium:   "Medium",
+	api.LevelLow:      "Low",
+}
+
+type gitlabReport struct {
+	Version         string                `json:"version"`
+	Scan            gitlabScan            `json:"scan"`
+	Vulnerabilities []gitlabVulnerability `json:"vulnerabilities"`
+}
+
+type gitlabScan struct {
+	Analyzer  gitlabScanner `json:"analyzer"`
+	Scanner   gitlabScanner `json:"scanner"`
+	Type      string        `json:"type"`
+	StartTime string        `json:"start_time"`
+	EndTime   string        `json:"end_time"`
+	Status    string        `json:"status"`
+}
+
+type gitlabScanner struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	URL     string `json:"url,omitempty"`
+	Version string `json:"version"`
+	Vendor  struct {
+		Name string `json:"name"`
+	} `json:"vendor"`
+}
+
+type gitlabVulnerability struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Severity    string             `json:"severity"`
+	Solution    string             `json:"solution,omitempty"`
+	Identifiers []gitlabIdentifier `json:"identifiers"`
+	Links       []gitlabLink       `json:"links,omitempty"`
+	CVSSVectors []gitlabCVSSVector `json:"cvss_vectors,omitempty"`
+	Location    struct {
+		File       string `json:"file"`
+		Dependency struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+			Version string `json:"version"`
+			Direct  bool   `json:"direct,omitempty"`
+		} `json:"dependency"`
+	} `json:"location"`
+}
+
+type gitlabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type gitlabLink struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+}
+
+type gitlabCVSSVector struct {
+	Vendor string `json:"vendor"`
+	Vector string `json:"vector"`
+}
+
+// gitlabWriter writes findings as a GitLab dependency scanning report, so they
+// show in merge request security widgets and the vulnerability report. Each
+// finding of a dependency becomes a vulnerability located at its first
+// dependency file; its advisories come first among its identifiers, which
+// GitLab tracks vulnerabilities by across pipelines.
+type gitlabWriter struct {
+	uiURL     string
+	namespace string
+}
+
+func (gitlabWriter) Extension() string { return "gitlab.json" }
+
+func (g gitlabWriter) Write(w io.Writer, doc *Document) error {
+	out := gitlabReport{
+		Version:         gitlabSchemaVersion,
+		Scan:            gitlabScanFor(doc),
+		Vulnerabilities: []gitlabVulnerability{},
+	}
+	for _, f := range doc.Findings {
+		if f.Spec.TargetDependencyPackageName == "" && f.Spec.TargetDependencyName == "" {
+			continue
+		}
+		out.Vulnerabilities = append(out.Vulnerabilities, g.vulnerability(f))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// gitlabScanFor describes the scan that produced doc
+func gitlabScanFor(doc *Document) gitlabScan {
+	tool := gitlabScanner{ID: "findings-api", Name: "findings-api", URL: sarifToolURI, Version: buildinfo.Version}
+	tool.Vendor.Name = gitlabVendor
+	scanner := gitlabScanner{ID: "endor-labs", Name: "Endor Labs", URL: "https://www.endorlabs.com", Version: buildinfo.Version}
+	scanner.Vendor.Name = gitlabVendor
+
+	timestamp := doc.Timestamp
+	if t, err := time.Parse(time.RFC3339, doc.Timestamp); err == nil {
+		timestamp = t.UTC().Format(gitlabTimeLayout)
+	}
+	return gitlabScan{
+		Analyzer:  tool,
+		Scanner:   scanner,
+		Type:      gitlabScanType,
+		StartTime: timestamp,
+		EndTime:   timestamp,
+		Status:    "success",
+	}
+}
+
+// vulnerability converts a finding to a GitLab vulnerability
+func (g gitlabWriter) vulnerability(f api.Finding) gitlabVulnerability {
+	name := firstNonEmpty(f.Meta.Description, f.Meta.Name, f.UUID)
+	if vuln := f.Vulnerability(); vuln != nil {
+		name = firstNonEmpty(vuln.Spec.Summary, name)
+	}
+	v := gitlabVulnerability{
+		ID:          f.UUID,
+		Name:        name,
+		Description: firstNonEmpty(f.Spec.Explanation, f.Spec.Summary, f.Meta.Description),
+		Severity:    gitlabSeverities[f.Spec.Level],
+		Solution:    gitlabSolution(f),
+	}
+	if v.Severity == "" {
+		v.Severity = "Unknown"
+	}
+
+	for _, id := range f.VulnerabilityIDs() {
+		ident := gitlabIdentifier{Type: strings.ToLower(strings.SplitN(id, "-", 2)[0]), Name: id, Value: id}
+		if src := advisorySource(id); src != nil {
+			ident.URL = src.URL
+			v.Links = append(v.Links, gitlabLink{Name: src.Name, URL: src.URL})
+		}
+		v.Identifiers = append(v.Identifiers, ident)
+	}
+	for _, cwe := range f.CWEs() {
+		n := strings.TrimPrefix(cwe, "CWE-")
+		v.Identifiers = append(v.Identifiers, gitlabIdentifier{
+			Type:  "cwe",
+			Name:  cwe,
+			Value: n,
+			URL:   fmt.Sprintf("https://cwe.mitre.org/data/definitions/%s.html", n),
+		})
+	}
+	endor := gitlabIdentifier{Type: "endor_labs_finding", Name: "Endor Labs finding " + f.UUID, Value: f.UUID}
+	if link := FindingURL(g.uiURL, g.namespace, f); link != "" {
+		endor.URL = link
+		v.Links = append(v.Links, gitlabLink{Name: gitlabVendor, URL: link})
+	}
+	v.Identifiers = append(v.Identifiers, endor)
+
+	if c := f.CVSS(); c != nil && strings.HasPrefix(c.Vector, "CVSS:3.") {
+		v.CVSSVectors = []gitlabCVSSVector{{Vendor: gitlabVendor, Vector: c.Vector}}
+	}
+
+	v.Location.File = "."
+	if len(f.Spec.DependencyFilePath) > 0 {
+		v.Location.File = strings.TrimPrefix(f.Spec.DependencyFilePath[0], "/")
+	}
+	pkg := firstNonEmpty(f.Spec.TargetDependencyPackageName, f.Spec.TargetDependencyName)
+	if _, rest, ok := strings.Cut(pkg, "://"); ok {
+		pkg = rest
+	}
+	if f.Spec.TargetDependencyPackageName == "" {
+		// Only the versioned name is known, e.g. npm://lodash@4.17.11
+		if i := strings.LastIndex(pkg, "@"); i > 0 {
+			pkg = pkg[:i]
+		}
+	}
+	v.Location.Dependency.Package.Name = pkg
+	v.Location.Dependency.Version = f.Spec.TargetDependencyVersion
+	v.Location.Dependency.Direct = strings.EqualFold(f.Spec.Relationship, "direct") || f.HasTag(api.TagDirect)
+	return v
+}
+
+// gitlabSolution says how to resolve a finding, when that is known
+func gitlabSolution(f api.Finding) string {
+	if to := f.UpgradeTarget(); to != "" {
+		pkg := f.Spec.TargetDependencyPackageName
+		if _, rest, ok := strings.Cut(pkg, "://"); ok {
+			pkg = rest
+		}
+		return fmt.Sprintf("Upgrade %s to %s", firstNonEmpty(pkg, "the dependency"), to)
+	}
+	return firstNonEmpty(f.Spec.Remediation, f.Spec.Summary)
+}
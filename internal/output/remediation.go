@@ -0,0 +1,133 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/version"
+)
+
+// upgrade is one dependency upgrade and the findings it resolves
+type upgrade struct {
+	pkg      string
+	from, to string
+	findings []api.Finding
+	projects map[string]bool
+}
+
+// remediationWriter writes the upgrades that resolve the findings, one line
+// per dependency version, ordered by the number of findings each resolves.
+// Findings of the same dependency version are resolved by its highest upgrade
+// target. Findings no upgrade resolves are listed after them.
+type remediationWriter struct{}
+
+func (remediationWriter) Extension() string { return "remediation.txt" }
+
+func (remediationWriter) Write(w io.Writer, doc *Document) error {
+	byDependency := make(map[string]*upgrade)
+	var upgrades []*upgrade
+	var unresolved []api.Finding
+	for _, f := range doc.Findings {
+		to := f.UpgradeTarget()
+		if to == "" {
+			unresolved = append(unresolved, f)
+			continue
+		}
+		key := f.Spec.TargetDependencyPackageName + "@" + f.Spec.TargetDependencyVersion
+		u, ok := byDependency[key]
+		if !ok {
+			u = &upgrade{
+				pkg:      f.Spec.TargetDependencyPackageName,
+				from:     f.Spec.TargetDependencyVersion,
+				projects: make(map[string]bool),
+			}
+			byDependency[key] = u
+			upgrades = append(upgrades, u)
+		}
+		if u.to == "" || version.Compare(to, u.to) > 0 {
+			u.to = to
+		}
+		u.findings = append(u.findings, f)
+		u.projects[f.Spec.ProjectUUID] = true
+	}
+	sort.SliceStable(upgrades, func(i, j int) bool { return len(upgrades[i].findings) > len(upgrades[j].findings) })
+
+	fmt.Fprintf(w, "Remediation for %s\n\n", firstNonEmpty(doc.SearchDescription, "findings"))
+	resolved := 0
+	for _, u := range upgrades {
+		resolved += len(u.findings)
+		fmt.Fprintf(w, "upgrade %s from %s to %s to resolve %s (%s)", u.pkg, firstNonEmpty(u.from, "?"), u.to, plural(len(u.findings), "finding"), levelCounts(u.findings))
+		if len(u.projects) > 1 {
+			fmt.Fprintf(w, " in %d projects", len(u.projects))
+		}
+		fmt.Fprintln(w)
+		if ids := remediationIDs(u.findings); ids != "" {
+			fmt.Fprintf(w, "  %s\n", ids)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		fmt.Fprintf(w, "\nNo upgrade resolves %s:\n", plural(len(unresolved), "finding"))
+		for _, f := range unresolved {
+			fmt.Fprintf(w, "  %s  %s", f.Spec.Level.Short(), firstNonEmpty(f.Spec.TargetDependencyName, f.Meta.Name, f.UUID))
+			if advice := firstNonEmpty(f.Spec.Remediation, f.Spec.Summary); advice != "" {
+				fmt.Fprintf(w, ": %s", advice)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	verb := "resolve"
+	if len(upgrades) == 1 {
+		verb = "resolves"
+	}
+	_, err := fmt.Fprintf(w, "\n%s %s %d of %s\n", plural(len(upgrades), "upgrade"), verb, resolved, plural(len(doc.Findings), "finding"))
+	return err
+}
+
+// levelCounts summarizes findings by level, most severe first, e.g. "1 critical, 2 high"
+func levelCounts(findings []api.Finding) string {
+	counts := make(map[api.FindingLevel]int)
+	for _, f := range findings {
+		counts[f.Spec.Level]++
+	}
+	var parts []string
+	for _, l := range api.FindingLevels {
+		if counts[l] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[l], l.Short()))
+			delete(counts, l)
+		}
+	}
+	other := 0
+	for _, n := range counts {
+		other += n
+	}
+	if other > 0 {
+		parts = append(parts, fmt.Sprintf("%d other", other))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// remediationIDs lists the advisories the findings refer to, CVE IDs preferred
+func remediationIDs(findings []api.Finding) string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		if vids := f.VulnerabilityIDs(); len(vids) > 0 && !seen[vids[0]] {
+			seen[vids[0]] = true
+			ids = append(ids, vids[0])
+		}
+	}
+	return strings.Join(ids, ", ")
+}
+
+// plural formats n with noun, adding an s unless n is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,284 @@
+package output
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// DefaultUIURL is the Endor Labs web app reports link findings to
+const DefaultUIURL = "https://app.endorlabs.com"
+
+// ReportData is what markdown and html report templates are executed with
+type ReportData struct {
+	Title       string
+	Description string
+	Timestamp   string
+	Total       int
+	Theme       Theme
+	// Counts are the numbers of findings by short level, e.g. critical
+	Counts map[string]int
+	// Levels group the findings by level, most severe first, skipping empty levels
+	Levels   []ReportLevel
+	Document *Document
+}
+
+// ReportLevel is the findings of one level, grouped by package
+type ReportLevel struct {
+	Level    string
+	Count    int
+	Packages []ReportPackage
+}
+
+// ReportPackage is the findings of one level in one package version, most
+// common packages first
+type ReportPackage struct {
+	Name     string
+	Version  string
+	Findings []ReportFinding
+}
+
+// ReportFinding is a finding with its links to the Endor Labs app
+type ReportFinding struct {
+	api.Finding
+	// URL opens the finding in the app; empty when the namespace is unknown
+	URL string
+	// ProjectURL opens the finding's project in the app
+	ProjectURL  string
+	ProjectName string
+}
+
+// reportLinks builds app links for findings, in the finding's own namespace
+// or else namespace, so findings of child namespaces link to their own pages
+type reportLinks struct {
+	base      string
+	namespace string
+}
+
+func (l reportLinks) finding(f api.Finding) string {
+	ns := firstNonEmpty(f.Namespace, l.namespace)
+	if ns == "" || f.UUID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/t/%s/findings/%s", l.base, url.PathEscape(ns), url.PathEscape(f.UUID))
+}
+
+func (l reportLinks) project(f api.Finding) string {
+	ns := firstNonEmpty(f.Namespace, l.namespace)
+	if ns == "" || f.Spec.ProjectUUID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/t/%s/projects/%s", l.base, url.PathEscape(ns), url.PathEscape(f.Spec.ProjectUUID))
+}
+
+// FindingURL returns the link that opens f in the Endor Labs app at uiURL
+// (DefaultUIURL when empty), in the finding's own namespace or else namespace.
+// It is empty when neither namespace is known.
+func FindingURL(uiURL, namespace string, f api.Finding) string {
+	links := reportLinks{base: strings.TrimRight(firstNonEmpty(uiURL, DefaultUIURL), "/"), namespace: namespace}
+	return links.finding(f)
+}
+
+// NewReportData groups the findings of doc by level and package for report templates
+func NewReportData(doc *Document, theme Theme, uiURL, namespace string) ReportData {
+	links := reportLinks{base: strings.TrimRight(firstNonEmpty(uiURL, DefaultUIURL), "/"), namespace: namespace}
+	data := ReportData{
+		Title:       "Endor Labs Findings Report",
+		Description: doc.SearchDescription,
+		Timestamp:   doc.Timestamp,
+		Total:       len(doc.Findings),
+		Theme:       theme,
+		Counts:      make(map[string]int),
+		Document:    doc,
+	}
+	if theme.CompanyName != "" {
+		data.Title = theme.CompanyName + " " + data.Title
+	}
+
+	byLevel := make(map[api.FindingLevel][]api.Finding)
+	var levels []api.FindingLevel
+	for _, f := range doc.Findings {
+		if _, ok := byLevel[f.Spec.Level]; !ok {
+			levels = append(levels, f.Spec.Level)
+		}
+		byLevel[f.Spec.Level] = append(byLevel[f.Spec.Level], f)
+		data.Counts[f.Spec.Level.Short()]++
+	}
+	// Unknown levels rank 0 and come last, in the order they were found
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].Rank() > levels[j].Rank() })
+
+	for _, level := range levels {
+		findings := byLevel[level]
+		group := ReportLevel{Level: level.Short(), Count: len(findings)}
+		index := make(map[string]int)
+		for _, f := range findings {
+			key := f.Spec.TargetDependencyPackageName + "@" + f.Spec.TargetDependencyVersion
+			i, ok := index[key]
+			if !ok {
+				i = len(group.Packages)
+				index[key] = i
+				group.Packages = append(group.Packages, ReportPackage{Name: f.Spec.TargetDependencyPackageName, Version: f.Spec.TargetDependencyVersion})
+			}
+			rf := ReportFinding{Finding: f, URL: links.finding(f), ProjectURL: links.project(f), ProjectName: f.Spec.ProjectUUID}
+			if f.Project != nil && f.Project.Name != "" {
+				rf.ProjectName = f.Project.Name
+			}
+			group.Packages[i].Findings = append(group.Packages[i].Findings, rf)
+		}
+		sort.SliceStable(group.Packages, func(i, j int) bool {
+			return len(group.Packages[i].Findings) > len(group.Packages[j].Findings)
+		})
+		data.Levels = append(data.Levels, group)
+	}
+	return data
+}
+
+// reportFuncs are available to report templates
+var reportFuncs = map[string]any{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"short": api.FindingLevel.Short,
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	// mdcell escapes a value for a markdown table cell
+	"mdcell": func(s string) string {
+		s = strings.Join(strings.Fields(s), " ")
+		return strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;").Replace(s)
+	},
+}
+
+// Default report templates
+const (
+	DefaultMarkdownTemplate = `# {{.Title}}
+
+{{with .Description}}Findings for {{.}}, {{end}}generated {{.Timestamp}}.
+
+| Level | Findings |
+|---|---|
+{{- range .Levels}}
+| {{title .Level}} | {{.Count}} |
+{{- end}}
+| **Total** | **{{.Total}}** |
+{{range .Levels}}
+## {{title .Level}} ({{.Count}})
+{{range .Packages}}
+### {{if .Name}}` + "`{{.Name}}{{with .Version}}@{{.}}{{end}}`" + `{{else}}No package{{end}}
+
+| Finding | CVE | Fixed in | Project |
+|---|---|---|---|
+{{- range .Findings}}
+| {{if .URL}}[{{mdcell .Meta.Description}}]({{.URL}}){{else}}{{mdcell .Meta.Description}}{{end}} | {{mdcell .CVE}} | {{mdcell (join .FixedVersions ", ")}} | {{if .ProjectURL}}[{{mdcell .ProjectName}}]({{.ProjectURL}}){{else}}{{mdcell .ProjectName}}{{end}} |
+{{- end}}
+{{end}}{{end}}`
+
+	DefaultHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: {{.Theme.FontFamily}}, sans-serif; color: {{.Theme.PrimaryColor}}; margin: 2em; }
+h1, h2 { color: {{.Theme.PrimaryColor}}; }
+h3 { font-family: monospace; }
+a { color: {{.Theme.AccentColor}}; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #d1d5db; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: {{.Theme.PrimaryColor}}; color: #fff; }
+.logo { max-height: 48px; }
+</style>
+</head>
+<body>
+{{with .Theme.LogoURL}}<img class="logo" src="{{.}}" alt="">{{end}}
+<h1>{{.Title}}</h1>
+<p>{{with .Description}}Findings for {{.}}, {{end}}generated {{.Timestamp}}.</p>
+<table>
+<tr><th>Level</th><th>Findings</th></tr>
+{{- range .Levels}}
+<tr><td>{{title .Level}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+<tr><td><strong>Total</strong></td><td><strong>{{.Total}}</strong></td></tr>
+</table>
+{{range .Levels}}
+<h2>{{title .Level}} ({{.Count}})</h2>
+{{range .Packages}}
+<h3>{{if .Name}}{{.Name}}{{with .Version}}@{{.}}{{end}}{{else}}No package{{end}}</h3>
+<table>
+<tr><th>Finding</th><th>CVE</th><th>Fixed in</th><th>Project</th></tr>
+{{- range .Findings}}
+<tr><td>{{if .URL}}<a href="{{.URL}}">{{.Meta.Description}}</a>{{else}}{{.Meta.Description}}{{end}}</td><td>{{.CVE}}</td><td>{{join .FixedVersions ", "}}</td><td>{{if .ProjectURL}}<a href="{{.ProjectURL}}">{{.ProjectName}}</a>{{else}}{{.ProjectName}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{end}}{{end}}
+</body>
+</html>
+`
+)
+
+// templateExecutor is a parsed text/template or html/template
+type templateExecutor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// parseReportTemplate parses the report template of format from text, or from
+// the file at path when one is given. HTML templates escape their values.
+func parseReportTemplate(format, path string) (templateExecutor, error) {
+	text := DefaultMarkdownTemplate
+	if format == "html" {
+		text = DefaultHTMLTemplate
+	}
+	return parseTemplate(format, text, path)
+}
+
+// parseTemplate parses a markdown or html template from text, or from the file
+// at path when one is given
+func parseTemplate(format, text, path string) (templateExecutor, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+	}
+
+	var t templateExecutor
+	var err error
+	if format == "html" {
+		t, err = htmltemplate.New(format).Funcs(reportFuncs).Option("missingkey=error").Parse(text)
+	} else {
+		t, err = texttemplate.New(format).Funcs(reportFuncs).Option("missingkey=error").Parse(text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", format, err)
+	}
+	return t, nil
+}
+
+// reportWriter renders a markdown or html report grouped by level and package
+type reportWriter struct {
+	ext       string
+	tmpl      templateExecutor
+	theme     Theme
+	uiURL     string
+	namespace string
+}
+
+func (w reportWriter) Extension() string { return w.ext }
+
+func (w reportWriter) Write(out io.Writer, doc *Document) error {
+	if err := w.tmpl.Execute(out, NewReportData(doc, w.theme, w.uiURL, w.namespace)); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+)
+
+// SARIF identifiers
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifTool    = "findings-api"
+	sarifToolURI = "https://github.com/arsalan-learn/golang_endor_api_template"
+)
+
+// sarifLevels maps finding levels to a SARIF level and the "security-severity"
+// score code scanning tools use to rank results
+var sarifLevels = map[string]struct {
+	level    string
+	severity string
+}{
+	"critical": {"error", "9.5"},
+	"high":     {"error", "8.0"},
+	"medium":   {"warning", "5.5"},
+	"low":      {"note", "2.0"},
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name           string      `json:"name"`
+			Version        string      `json:"version"`
+			InformationURI string      `json:"informationUri"`
+			Rules          []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifRule struct {
+	ID               string         `json:"id"`
+	ShortDescription sarifMessage   `json:"shortDescription"`
+	FullDescription  *sarifMessage  `json:"fullDescription,omitempty"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+// sarifWriter writes findings as a SARIF 2.1.0 log for code scanning tools.
+// Each finding becomes a result located at its dependency files; findings with
+// the same name share a rule.
+type sarifWriter struct{}
+
+func (sarifWriter) Extension() string { return "sarif" }
+
+func (sarifWriter) Write(w io.Writer, doc *Document) error {
+	var run sarifRun
+	run.Tool.Driver.Name = sarifTool
+	run.Tool.Driver.Version = buildinfo.Version
+	run.Tool.Driver.InformationURI = sarifToolURI
+	run.Tool.Driver.Rules = []sarifRule{}
+	run.Results = []sarifResult{}
+
+	rules := make(map[string]bool)
+	for _, f := range doc.Findings {
+		ruleID := sarifRuleID(f)
+		level := sarifLevels[f.Spec.Level.Short()]
+		if level.level == "" {
+			level.level = "warning"
+		}
+
+		if !rules[ruleID] {
+			rules[ruleID] = true
+			rule := sarifRule{
+				ID:               ruleID,
+				ShortDescription: sarifMessage{Text: firstNonEmpty(f.Spec.Summary, f.Meta.Description, ruleID)},
+				Properties:       map[string]any{"tags": []string{"security"}},
+			}
+			if f.Spec.Explanation != "" {
+				rule.FullDescription = &sarifMessage{Text: f.Spec.Explanation}
+			}
+			if level.severity != "" {
+				rule.Properties["security-severity"] = level.severity
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		result := sarifResult{
+			RuleID:  ruleID,
+			Level:   level.level,
+			Message: sarifMessage{Text: firstNonEmpty(f.Meta.Description, ruleID)},
+		}
+		if f.UUID != "" {
+			result.PartialFingerprints = map[string]string{"endorFindingUUID": f.UUID}
+		}
+		for _, path := range f.Spec.DependencyFilePath {
+			var loc sarifLocation
+			loc.PhysicalLocation.ArtifactLocation.URI = strings.TrimPrefix(path, "/")
+			result.Locations = append(result.Locations, loc)
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleID identifies the rule a finding belongs to
+func sarifRuleID(f api.Finding) string {
+	return firstNonEmpty(f.Meta.Name, f.UUID, "endor-finding")
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
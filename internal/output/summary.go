@@ -0,0 +1,236 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/aggregate"
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// DefaultTopDependencies is the number of dependencies an org summary lists
+const DefaultTopDependencies = 10
+
+// OrgSummary is the organization-wide roll-up of findings that org summary
+// templates are executed with
+type OrgSummary struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Timestamp   string `json:"timestamp"`
+	Total       int    `json:"total"`
+	Theme       Theme  `json:"-"`
+	// Counts are the numbers of findings by short level, e.g. critical
+	Counts map[string]int `json:"counts"`
+	// Levels lists every level, most severe first, with its count
+	Levels []SummaryLevel `json:"-"`
+	// Projects are the projects with findings, worst level first, then most
+	// findings first
+	Projects []SummaryProject `json:"projects"`
+	// TopDependencies are the vulnerable package versions with the worst
+	// findings, then the most findings, across the organization
+	TopDependencies []aggregate.Group `json:"top_dependencies"`
+}
+
+// SummaryLevel is the number of findings of one level
+type SummaryLevel struct {
+	Level string
+	Count int
+}
+
+// SummaryProject is the roll-up of one project's findings
+type SummaryProject struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// URL opens the project in the app; empty when the namespace is unknown
+	URL   string           `json:"url,omitempty"`
+	Total int              `json:"total"`
+	Worst api.FindingLevel `json:"worst_level"`
+	// Counts are the numbers of findings by short level
+	Counts map[string]int `json:"counts"`
+}
+
+// NewOrgSummary rolls findings up by project and lists the top vulnerable
+// dependencies. Project names come from resolved projects, else their UUIDs.
+func NewOrgSummary(findings []api.Finding, description, timestamp string, top int, theme Theme, uiURL, namespace string) OrgSummary {
+	base := strings.TrimRight(firstNonEmpty(uiURL, DefaultUIURL), "/")
+	s := OrgSummary{
+		Title:       "Endor Labs Organization Summary",
+		Description: description,
+		Timestamp:   timestamp,
+		Total:       len(findings),
+		Theme:       theme,
+		Counts:      make(map[string]int),
+		Projects:    []SummaryProject{},
+	}
+	if theme.CompanyName != "" {
+		s.Title = theme.CompanyName + " " + s.Title
+	}
+
+	index := make(map[string]int)
+	for _, f := range findings {
+		level := f.Spec.Level.Short()
+		s.Counts[level]++
+
+		i, ok := index[f.Spec.ProjectUUID]
+		if !ok {
+			i = len(s.Projects)
+			index[f.Spec.ProjectUUID] = i
+			p := SummaryProject{UUID: f.Spec.ProjectUUID, Name: f.Spec.ProjectUUID, Counts: make(map[string]int)}
+			// Findings of several namespaces link to their own
+			p.URL = reportLinks{base: base, namespace: namespace}.project(f)
+			if f.Project != nil && f.Project.Name != "" {
+				p.Name = f.Project.Name
+			}
+			if p.Name == "" {
+				p.Name = "(no project)"
+			}
+			s.Projects = append(s.Projects, p)
+		}
+		p := &s.Projects[i]
+		p.Total++
+		p.Counts[level]++
+		if p.Worst == "" || f.Spec.Level.Rank() > p.Worst.Rank() {
+			p.Worst = f.Spec.Level
+		}
+	}
+	sort.SliceStable(s.Projects, func(i, j int) bool {
+		a, b := s.Projects[i], s.Projects[j]
+		if a.Worst.Rank() != b.Worst.Rank() {
+			return a.Worst.Rank() > b.Worst.Rank()
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Name < b.Name
+	})
+
+	for _, level := range api.FindingLevels {
+		s.Levels = append(s.Levels, SummaryLevel{Level: level.Short(), Count: s.Counts[level.Short()]})
+	}
+
+	// Grouping by package cannot fail; findings without a package are left out
+	groups, _ := aggregate.By(findings, aggregate.ByPackage)
+	s.TopDependencies = []aggregate.Group{}
+	for _, g := range groups {
+		if len(s.TopDependencies) == top {
+			break
+		}
+		if g.Key != "(no package)" {
+			s.TopDependencies = append(s.TopDependencies, g)
+		}
+	}
+	return s
+}
+
+// Default org summary templates
+const (
+	DefaultOrgMarkdownTemplate = `# {{.Title}}
+
+{{with .Description}}Findings for {{.}}, {{end}}generated {{.Timestamp}}.
+
+## Overview
+
+**{{.Total}}** findings in **{{len .Projects}}** projects.
+
+| Level | Findings |
+|---|---|
+{{- range .Levels}}
+| {{title .Level}} | {{.Count}} |
+{{- end}}
+| **Total** | **{{.Total}}** |
+
+## Top Vulnerable Dependencies
+{{if .TopDependencies}}
+| Dependency | Worst | Findings | Projects | Fixed in |
+|---|---|---|---|---|
+{{- range .TopDependencies}}
+| ` + "`{{mdcell .Key}}`" + ` | {{title (short .Worst)}} | {{.Count}} | {{.Projects}} | {{mdcell (join .FixedVersions ", ")}} |
+{{- end}}
+{{else}}
+No vulnerable dependencies.
+{{end}}
+## Projects
+{{if .Projects}}
+| Project | Worst | Critical | High | Medium | Low | Total |
+|---|---|---|---|---|---|---|
+{{- range .Projects}}
+| {{if .URL}}[{{mdcell .Name}}]({{.URL}}){{else}}{{mdcell .Name}}{{end}} | {{title (short .Worst)}} | {{index .Counts "critical"}} | {{index .Counts "high"}} | {{index .Counts "medium"}} | {{index .Counts "low"}} | {{.Total}} |
+{{- end}}
+{{else}}
+No projects have findings.
+{{end}}`
+
+	DefaultOrgHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: {{.Theme.FontFamily}}, sans-serif; color: {{.Theme.PrimaryColor}}; margin: 2em; }
+h1, h2 { color: {{.Theme.PrimaryColor}}; }
+a { color: {{.Theme.AccentColor}}; }
+code { font-family: monospace; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #d1d5db; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: {{.Theme.PrimaryColor}}; color: #fff; }
+.logo { max-height: 48px; }
+</style>
+</head>
+<body>
+{{with .Theme.LogoURL}}<img class="logo" src="{{.}}" alt="">{{end}}
+<h1>{{.Title}}</h1>
+<p>{{with .Description}}Findings for {{.}}, {{end}}generated {{.Timestamp}}.</p>
+<h2>Overview</h2>
+<p><strong>{{.Total}}</strong> findings in <strong>{{len .Projects}}</strong> projects.</p>
+<table>
+<tr><th>Level</th><th>Findings</th></tr>
+{{- range .Levels}}
+<tr><td>{{title .Level}}</td><td>{{.Count}}</td></tr>
+{{- end}}
+<tr><td><strong>Total</strong></td><td><strong>{{.Total}}</strong></td></tr>
+</table>
+<h2>Top Vulnerable Dependencies</h2>
+{{if .TopDependencies}}
+<table>
+<tr><th>Dependency</th><th>Worst</th><th>Findings</th><th>Projects</th><th>Fixed in</th></tr>
+{{- range .TopDependencies}}
+<tr><td><code>{{.Key}}</code></td><td>{{title (short .Worst)}}</td><td>{{.Count}}</td><td>{{.Projects}}</td><td>{{join .FixedVersions ", "}}</td></tr>
+{{- end}}
+</table>
+{{else}}
+<p>No vulnerable dependencies.</p>
+{{end}}
+<h2>Projects</h2>
+{{if .Projects}}
+<table>
+<tr><th>Project</th><th>Worst</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Total</th></tr>
+{{- range .Projects}}
+<tr><td>{{if .URL}}<a href="{{.URL}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td><td>{{title (short .Worst)}}</td><td>{{index .Counts "critical"}}</td><td>{{index .Counts "high"}}</td><td>{{index .Counts "medium"}}</td><td>{{index .Counts "low"}}</td><td>{{.Total}}</td></tr>
+{{- end}}
+</table>
+{{else}}
+<p>No projects have findings.</p>
+{{end}}
+</body>
+</html>
+`
+)
+
+// WriteOrgSummary renders s as a markdown or html executive summary, with the
+// template file at path replacing the built-in one when given
+func WriteOrgSummary(w io.Writer, s OrgSummary, format, path string) error {
+	text := DefaultOrgMarkdownTemplate
+	if format == "html" {
+		text = DefaultOrgHTMLTemplate
+	}
+	tmpl, err := parseTemplate(format, text, path)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, s); err != nil {
+		return fmt.Errorf("failed to render org summary: %w", err)
+	}
+	return nil
+}
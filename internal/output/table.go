@@ -0,0 +1,47 @@
+package output
+
+import (
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// WriteTable writes findings as an aligned plain-text table. Line breaks and
+// tabs inside values are replaced with spaces to keep one finding per line.
+// Values longer than maxWidth characters are cut short with an ellipsis;
+// maxWidth 0 keeps them whole.
+func WriteTable(w io.Writer, findings []api.Finding, cols []Column, maxWidth int) error {
+	clean := strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := io.WriteString(tw, strings.ToUpper(strings.Join(headers(cols), "\t"))+"\n"); err != nil {
+		return err
+	}
+	for _, f := range findings {
+		values := row(f, cols)
+		for i, v := range values {
+			values[i] = truncate(clean.Replace(v), maxWidth)
+		}
+		if _, err := io.WriteString(tw, strings.Join(values, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// truncate shortens s to at most max characters, ending it with an ellipsis
+func truncate(s string, max int) string {
+	if max <= 0 {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	if max == 1 {
+		return "…"
+	}
+	return string(runes[:max-1]) + "…"
+}
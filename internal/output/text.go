@@ -0,0 +1,177 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Text modes for long text fields
+const (
+	// TextFull renders long text fields as they are
+	TextFull = "full"
+	// TextParagraph keeps the first paragraph of long text fields
+	TextParagraph = "paragraph"
+	// TextOmit leaves long text fields empty
+	TextOmit = "omit"
+)
+
+// TextPolicy controls how the long text fields of a finding are rendered: the
+// spec summary and explanation, and the advisory description and summary
+type TextPolicy struct {
+	Mode string
+	// Max cuts the fields to this many characters after Mode is applied (0 keeps them whole)
+	Max int
+}
+
+// Full reports whether p leaves the fields untouched
+func (p TextPolicy) Full() bool {
+	return (p.Mode == "" || p.Mode == TextFull) && p.Max <= 0
+}
+
+// String returns p as ParseTextPolicy accepts it
+func (p TextPolicy) String() string {
+	switch {
+	case p.Max > 0 && p.Mode == TextParagraph:
+		return TextParagraph + ":" + strconv.Itoa(p.Max)
+	case p.Max > 0:
+		return strconv.Itoa(p.Max)
+	case p.Mode == "":
+		return TextFull
+	default:
+		return p.Mode
+	}
+}
+
+// ParseTextPolicy parses "full", "omit", "paragraph", a character count such
+// as "200", or "paragraph:200" for the first paragraph cut to 200 characters
+func ParseTextPolicy(s string) (TextPolicy, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	mode, max, hasMax := strings.Cut(s, ":")
+	if !hasMax {
+		if n, err := strconv.Atoi(s); err == nil {
+			mode, max, hasMax = TextFull, strconv.Itoa(n), true
+		}
+	}
+
+	p := TextPolicy{Mode: mode}
+	switch mode {
+	case TextFull, TextParagraph:
+	case TextOmit:
+		if hasMax {
+			return TextPolicy{}, fmt.Errorf("invalid text policy %q (omit takes no length)", s)
+		}
+	default:
+		return TextPolicy{}, fmt.Errorf("invalid text policy %q (expected full, paragraph, omit or a character count)", s)
+	}
+	if hasMax {
+		n, err := strconv.Atoi(max)
+		if err != nil || n < 1 {
+			return TextPolicy{}, fmt.Errorf("invalid text length %q (expected a positive number)", max)
+		}
+		p.Max = n
+	}
+	return p, nil
+}
+
+// ParseTextPolicies parses a comma-separated list of policies by format, e.g.
+// "table=120,csv=paragraph,sarif=full". A policy without a format applies to
+// every format not listed.
+func ParseTextPolicies(spec string) (map[string]TextPolicy, error) {
+	policies := make(map[string]TextPolicy)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		format, value, ok := strings.Cut(part, "=")
+		if !ok {
+			format, value = "", part
+		}
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != "" && !validFormat(format) {
+			return nil, fmt.Errorf("unknown format %q in --text (expected %s)", format, strings.Join(Formats, ", "))
+		}
+		p, err := ParseTextPolicy(value)
+		if err != nil {
+			return nil, err
+		}
+		policies[format] = p
+	}
+	return policies, nil
+}
+
+// TextPolicyFor returns the policy of format in policies, falling back to the
+// policy without a format
+func TextPolicyFor(policies map[string]TextPolicy, format string) TextPolicy {
+	if p, ok := policies[strings.ToLower(format)]; ok {
+		return p
+	}
+	return policies[""]
+}
+
+// ApplyText returns findings with their long text fields rendered by p. The
+// findings are copied when p changes them; the originals are left alone.
+func ApplyText(findings []api.Finding, p TextPolicy) []api.Finding {
+	if p.Full() {
+		return findings
+	}
+	out := make([]api.Finding, len(findings))
+	for i, f := range findings {
+		f.Spec.Summary = p.apply(f.Spec.Summary)
+		f.Spec.Explanation = p.apply(f.Spec.Explanation)
+		if v := f.Spec.FindingMetadata.Vulnerability; v != nil {
+			vc := *v
+			vc.Meta.Description = p.apply(vc.Meta.Description)
+			vc.Spec.Summary = p.apply(vc.Spec.Summary)
+			f.Spec.FindingMetadata.Vulnerability = &vc
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// apply renders one field by p
+func (p TextPolicy) apply(s string) string {
+	switch p.Mode {
+	case TextOmit:
+		return ""
+	case TextParagraph:
+		s = firstParagraph(s)
+	}
+	return truncate(strings.TrimSpace(s), p.Max)
+}
+
+// firstParagraph returns s up to its first blank line
+func firstParagraph(s string) string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	if i := strings.Index(s, "\n\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func validFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// textWriter renders the long text fields of the document by policy before
+// handing it to the wrapped writer
+type textWriter struct {
+	Writer
+	policy TextPolicy
+}
+
+func (t textWriter) Write(w io.Writer, doc *Document) error {
+	copied := *doc
+	copied.Findings = ApplyText(doc.Findings, t.policy)
+	return t.Writer.Write(w, &copied)
+}
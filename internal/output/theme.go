@@ -0,0 +1,93 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Theme brands generated reports
+type Theme struct {
+	CompanyName  string `json:"company_name"`
+	LogoURL      string `json:"logo_url"`
+	PrimaryColor string `json:"primary_color"`
+	AccentColor  string `json:"accent_color"`
+	FontFamily   string `json:"font_family"`
+}
+
+// DefaultTheme is used for anything a custom theme leaves unset
+var DefaultTheme = Theme{
+	PrimaryColor: "#1F2937",
+	AccentColor:  "#2563EB",
+	FontFamily:   "Calibri",
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// LoadTheme reads a theme from a JSON file, either as a top-level object or under
+// a "theme" key, and fills unset fields from DefaultTheme
+func LoadTheme(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("failed to read theme: %w", err)
+	}
+
+	var doc struct {
+		Theme *Theme `json:"theme"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme: %w", err)
+	}
+
+	var theme Theme
+	if doc.Theme != nil {
+		theme = *doc.Theme
+	} else if err := json.Unmarshal(data, &theme); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme: %w", err)
+	}
+
+	theme = theme.WithDefaults()
+	if err := theme.Validate(); err != nil {
+		return Theme{}, err
+	}
+	return theme, nil
+}
+
+// WithDefaults returns t with unset fields taken from DefaultTheme
+func (t Theme) WithDefaults() Theme {
+	if t.PrimaryColor == "" {
+		t.PrimaryColor = DefaultTheme.PrimaryColor
+	}
+	if t.AccentColor == "" {
+		t.AccentColor = DefaultTheme.AccentColor
+	}
+	if t.FontFamily == "" {
+		t.FontFamily = DefaultTheme.FontFamily
+	}
+	return t
+}
+
+// Validate checks that colours are #RRGGBB values
+func (t Theme) Validate() error {
+	for name, color := range map[string]string{"primary_color": t.PrimaryColor, "accent_color": t.AccentColor} {
+		if color != "" && !hexColor.MatchString(color) {
+			return fmt.Errorf("invalid %s %q (expected #RRGGBB)", name, color)
+		}
+	}
+	return nil
+}
+
+// Title returns the report title, prefixed with the company name when set
+func (t Theme) Title(title string) string {
+	if t.CompanyName == "" {
+		return title
+	}
+	return t.CompanyName + " - " + title
+}
+
+// argb converts a #RRGGBB colour into the ARGB form used by spreadsheets
+func argb(color string) string {
+	return "FF" + strings.ToUpper(strings.TrimPrefix(color, "#"))
+}
@@ -0,0 +1,361 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/buildinfo"
+)
+
+// VEX identifiers
+const (
+	vexTool             = "findings-api"
+	openVEXContext      = "https://openvex.dev/ns/v0.2.0"
+	openVEXIDPrefix     = "https://openvex.dev/docs/public/vex-"
+	cycloneDXVEXVersion = "1.5"
+)
+
+// vexStatement is what a VEX document states about one vulnerability
+// finding, derived from its reachability
+type vexStatement struct {
+	finding api.Finding
+	// ids are the advisory IDs, CVE IDs first
+	ids []string
+	// product is the package URL of the vulnerable dependency
+	product string
+	// status is the OpenVEX status: affected, not_affected or under_investigation
+	status string
+	// detail explains the status
+	detail string
+	// action is how to resolve an affected vulnerability
+	action string
+	fixed  bool
+}
+
+// vexStatements builds a statement for each vulnerability finding of doc.
+// Reachable findings are affected, unreachable ones not affected, and those
+// without reachability data under investigation. Other findings are left out.
+func vexStatements(doc *Document) []vexStatement {
+	var statements []vexStatement
+	for _, f := range doc.Findings {
+		ids := f.VulnerabilityIDs()
+		if len(ids) == 0 {
+			continue
+		}
+		s := vexStatement{
+			finding: f,
+			ids:     ids,
+			product: purl(f.Spec.TargetDependencyName),
+			fixed:   f.HasTag(api.TagFixAvailable) || len(f.FixedVersions()) > 0,
+		}
+		switch f.Reachability() {
+		case api.Reachable:
+			s.status = "affected"
+			s.detail = "Endor Labs reachability analysis found the vulnerable function reachable from the project"
+			s.action = vexAction(f)
+		case api.UnreachableFunction:
+			s.status = "not_affected"
+			s.detail = "Endor Labs reachability analysis found the vulnerable function unreachable from the project"
+		case api.UnreachableDependency:
+			s.status = "not_affected"
+			s.detail = "Endor Labs reachability analysis found the vulnerable dependency unused by the project"
+		default:
+			s.status = "under_investigation"
+			s.detail = "Endor Labs has no reachability data for this finding"
+		}
+		statements = append(statements, s)
+	}
+	return statements
+}
+
+// vexAction returns how to resolve an affected finding
+func vexAction(f api.Finding) string {
+	if f.Spec.Summary != "" {
+		return f.Spec.Summary
+	}
+	if f.Spec.ProposedVersion != "" {
+		return fmt.Sprintf("Upgrade %s to %s", f.Spec.TargetDependencyPackageName, f.Spec.ProposedVersion)
+	}
+	if fixed := f.FixedVersions(); len(fixed) > 0 {
+		return fmt.Sprintf("Upgrade %s to %s", f.Spec.TargetDependencyPackageName, fixed[0])
+	}
+	return "No fixed version is available"
+}
+
+// purlTypes maps Endor ecosystems to package URL types where they differ
+var purlTypes = map[string]string{
+	"go":        "golang",
+	"packagist": "composer",
+	"rubygems":  "gem",
+}
+
+// purl converts a dependency name such as npm://lodash@4.17.11 to a package
+// URL such as pkg:npm/lodash@4.17.11. Maven group and artifact IDs become the
+// namespace and name; names that are not ecosystem-qualified are kept as-is.
+func purl(dependency string) string {
+	ecosystem, rest, ok := strings.Cut(dependency, "://")
+	if !ok {
+		return dependency
+	}
+	typ := strings.ToLower(ecosystem)
+	if t, ok := purlTypes[typ]; ok {
+		typ = t
+	}
+
+	name, version := rest, ""
+	if i := strings.LastIndex(rest, "@"); i > 0 {
+		name, version = rest[:i], rest[i+1:]
+	}
+	if typ == "maven" {
+		name = strings.Replace(name, ":", "/", 1)
+	}
+
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	p := "pkg:" + typ + "/" + strings.Join(segments, "/")
+	if version != "" {
+		p += "@" + url.PathEscape(version)
+	}
+	return p
+}
+
+// vexDocumentID derives a stable ID for the document from its findings, so
+// the same findings always produce the same document
+func vexDocumentID(doc *Document) string {
+	h := sha256.New()
+	for _, f := range doc.Findings {
+		h.Write([]byte(f.UUID))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type openVEXDocument struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  string             `json:"timestamp"`
+	Version    int                `json:"version"`
+	Tooling    string             `json:"tooling"`
+	Statements []openVEXStatement `json:"statements"`
+}
+
+type openVEXProduct struct {
+	ID string `json:"@id"`
+}
+
+type openVEXStatement struct {
+	Vulnerability struct {
+		Name    string   `json:"name"`
+		Aliases []string `json:"aliases,omitempty"`
+	} `json:"vulnerability"`
+	Products        []openVEXProduct `json:"products"`
+	Status          string           `json:"status"`
+	Justification   string           `json:"justification,omitempty"`
+	ImpactStatement string           `json:"impact_statement,omitempty"`
+	ActionStatement string           `json:"action_statement,omitempty"`
+	StatusNotes     string           `json:"status_notes,omitempty"`
+}
+
+// openVEXWriter writes vulnerability findings as an OpenVEX document, one
+// statement per finding
+type openVEXWriter struct{}
+
+func (openVEXWriter) Extension() string { return "openvex.json" }
+
+func (openVEXWriter) Write(w io.Writer, doc *Document) error {
+	out := openVEXDocument{
+		Context:    openVEXContext,
+		ID:         openVEXIDPrefix + vexDocumentID(doc),
+		Author:     vexTool,
+		Timestamp:  doc.Timestamp,
+		Version:    1,
+		Tooling:    vexTool + " " + buildinfo.Version,
+		Statements: []openVEXStatement{},
+	}
+	for _, s := range vexStatements(doc) {
+		var st openVEXStatement
+		st.Vulnerability.Name = s.ids[0]
+		st.Vulnerability.Aliases = s.ids[1:]
+		st.Products = []openVEXProduct{{ID: s.product}}
+		st.Status = s.status
+		switch s.status {
+		case "affected":
+			st.ActionStatement = s.action
+		case "not_affected":
+			st.Justification = "vulnerable_code_not_in_execute_path"
+			st.ImpactStatement = s.detail
+		default:
+			st.StatusNotes = s.detail
+		}
+		out.Statements = append(out.Statements, st)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+type cycloneDXVEX struct {
+	BOMFormat    string `json:"bomFormat"`
+	SpecVersion  string `json:"specVersion"`
+	SerialNumber string `json:"serialNumber"`
+	Version      int    `json:"version"`
+	Metadata     struct {
+		Timestamp string `json:"timestamp"`
+		Tools     struct {
+			Components []cycloneDXTool `json:"components"`
+		} `json:"tools"`
+	} `json:"metadata"`
+	Vulnerabilities []cycloneDXVulnerability `json:"vulnerabilities"`
+}
+
+type cycloneDXTool struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cycloneDXVulnerability struct {
+	BOMRef      string               `json:"bom-ref"`
+	ID          string               `json:"id"`
+	Source      *cycloneDXSource     `json:"source,omitempty"`
+	References  []cycloneDXReference `json:"references,omitempty"`
+	Ratings     []cycloneDXRating    `json:"ratings,omitempty"`
+	CWEs        []int                `json:"cwes,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Analysis    struct {
+		State         string   `json:"state"`
+		Justification string   `json:"justification,omitempty"`
+		Response      []string `json:"response,omitempty"`
+		Detail        string   `json:"detail,omitempty"`
+	} `json:"analysis"`
+	Affects []cycloneDXAffect `json:"affects"`
+}
+
+type cycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+type cycloneDXSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type cycloneDXReference struct {
+	ID     string          `json:"id"`
+	Source cycloneDXSource `json:"source"`
+}
+
+type cycloneDXRating struct {
+	Score    float64 `json:"score"`
+	Severity string  `json:"severity,omitempty"`
+	Method   string  `json:"method,omitempty"`
+	Vector   string  `json:"vector,omitempty"`
+}
+
+// cycloneDXStates maps OpenVEX statuses to CycloneDX analysis states
+var cycloneDXStates = map[string]string{
+	"affected":            "exploitable",
+	"not_affected":        "not_affected",
+	"under_investigation": "in_triage",
+}
+
+// cycloneDXVEXWriter writes vulnerability findings as a CycloneDX VEX BOM,
+// one vulnerability per finding
+type cycloneDXVEXWriter struct{}
+
+func (cycloneDXVEXWriter) Extension() string { return "cdx.json" }
+
+func (cycloneDXVEXWriter) Write(w io.Writer, doc *Document) error {
+	id := vexDocumentID(doc)
+	out := cycloneDXVEX{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     cycloneDXVEXVersion,
+		SerialNumber:    fmt.Sprintf("urn:uuid:%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:32]),
+		Version:         1,
+		Vulnerabilities: []cycloneDXVulnerability{},
+	}
+	out.Metadata.Timestamp = doc.Timestamp
+	out.Metadata.Tools.Components = []cycloneDXTool{{Type: "application", Name: vexTool, Version: buildinfo.Version}}
+
+	for _, s := range vexStatements(doc) {
+		f := s.finding
+		v := cycloneDXVulnerability{
+			BOMRef:      f.UUID,
+			ID:          s.ids[0],
+			Source:      advisorySource(s.ids[0]),
+			Description: firstNonEmpty(f.Vulnerability().Spec.Summary, f.Vulnerability().Meta.Description),
+		}
+		for _, alias := range s.ids[1:] {
+			if src := advisorySource(alias); src != nil {
+				v.References = append(v.References, cycloneDXReference{ID: alias, Source: *src})
+			}
+		}
+		if c := f.CVSS(); c != nil {
+			v.Ratings = append(v.Ratings, cycloneDXRating{
+				Score:    c.Score,
+				Severity: strings.ToLower(c.Level),
+				Method:   cvssMethod(c.Vector),
+				Vector:   c.Vector,
+			})
+		}
+		for _, cwe := range f.CWEs() {
+			if n, err := strconv.Atoi(strings.TrimPrefix(cwe, "CWE-")); err == nil {
+				v.CWEs = append(v.CWEs, n)
+			}
+		}
+
+		v.Analysis.State = cycloneDXStates[s.status]
+		v.Analysis.Detail = s.detail
+		switch s.status {
+		case "affected":
+			if s.fixed {
+				v.Analysis.Response = []string{"update"}
+			}
+			v.Analysis.Detail += ". " + s.action
+		case "not_affected":
+			v.Analysis.Justification = "code_not_reachable"
+		}
+		v.Affects = []cycloneDXAffect{{Ref: s.product}}
+		out.Vulnerabilities = append(out.Vulnerabilities, v)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// advisorySource returns the database an advisory ID comes from, or nil
+func advisorySource(id string) *cycloneDXSource {
+	switch {
+	case strings.HasPrefix(id, "CVE-"):
+		return &cycloneDXSource{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + id}
+	case strings.HasPrefix(id, "GHSA-"):
+		return &cycloneDXSource{Name: "GitHub", URL: "https://github.com/advisories/" + id}
+	}
+	return nil
+}
+
+// cvssMethod returns the CycloneDX rating method of a CVSS vector
+func cvssMethod(vector string) string {
+	switch {
+	case strings.HasPrefix(vector, "CVSS:3.1/"):
+		return "CVSSv31"
+	case strings.HasPrefix(vector, "CVSS:3.0/"):
+		return "CVSSv3"
+	case strings.HasPrefix(vector, "CVSS:4.0/"):
+		return "CVSSv4"
+	case vector != "":
+		return "CVSSv2"
+	}
+	return ""
+}
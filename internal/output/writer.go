@@ -0,0 +1,145 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"json", "ndjson", "table", "csv", "xlsx", "sarif", "gitlab", "openvex", "cyclonedx-vex", "remediation", "markdown", "html"}
+
+// Writer renders a findings document in one output format
+type Writer interface {
+	// Write renders doc to w
+	Write(w io.Writer, doc *Document) error
+	// Extension is the file extension for the format, without the dot
+	Extension() string
+}
+
+// Options configure the formats that support them
+type Options struct {
+	// Columns select the fields of the table, csv and xlsx formats
+	Columns []Column
+	// Theme styles the xlsx format
+	Theme Theme
+	// MaxColumnWidth truncates longer values in the table format (0 keeps them whole)
+	MaxColumnWidth int
+	// Text renders long text fields by format; see ParseTextPolicies
+	Text map[string]TextPolicy
+	// Template is a Go template file replacing the built-in markdown and html reports
+	Template string
+	// UIURL and Namespace build the app links of markdown and html reports and
+	// the gitlab format; findings without a namespace have no links
+	UIURL     string
+	Namespace string
+}
+
+// NewWriter returns the writer for format
+func NewWriter(format string, opts Options) (Writer, error) {
+	if opts.Columns == nil {
+		cols, err := ParseColumns("")
+		if err != nil {
+			return nil, err
+		}
+		opts.Columns = cols
+	}
+
+	var w Writer
+	switch strings.ToLower(format) {
+	case "json":
+		w = jsonWriter{}
+	case "ndjson":
+		w = ndjsonWriter{}
+	case "table":
+		w = tableWriter{cols: opts.Columns, maxWidth: opts.MaxColumnWidth}
+	case "csv":
+		w = csvWriter{cols: opts.Columns}
+	case "xlsx":
+		w = xlsxWriter{cols: opts.Columns, theme: opts.Theme.WithDefaults()}
+	case "sarif":
+		w = sarifWriter{}
+	case "gitlab":
+		w = gitlabWriter{uiURL: opts.UIURL, namespace: opts.Namespace}
+	case "openvex":
+		w = openVEXWriter{}
+	case "cyclonedx-vex":
+		w = cycloneDXVEXWriter{}
+	case "remediation":
+		w = remediationWriter{}
+	case "markdown", "md", "html":
+		name, ext := "markdown", "md"
+		if strings.EqualFold(format, "html") {
+			name, ext = "html", "html"
+		}
+		tmpl, err := parseReportTemplate(name, opts.Template)
+		if err != nil {
+			return nil, err
+		}
+		w = reportWriter{ext: ext, tmpl: tmpl, theme: opts.Theme.WithDefaults(), uiURL: opts.UIURL, namespace: opts.Namespace}
+	default:
+		return nil, fmt.Errorf("unsupported format %q (expected %s)", format, strings.Join(Formats, ", "))
+	}
+
+	if p := TextPolicyFor(opts.Text, format); !p.Full() {
+		w = textWriter{Writer: w, policy: p}
+	}
+	return w, nil
+}
+
+// jsonWriter writes the whole document as indented JSON
+type jsonWriter struct{}
+
+func (jsonWriter) Extension() string { return "json" }
+
+func (jsonWriter) Write(w io.Writer, doc *Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// ndjsonWriter writes one finding per line, for streaming into other tools
+type ndjsonWriter struct{}
+
+func (ndjsonWriter) Extension() string { return "ndjson" }
+
+func (ndjsonWriter) Write(w io.Writer, doc *Document) error {
+	enc := json.NewEncoder(w)
+	for _, f := range doc.Findings {
+		if err := enc.Encode(f); err != nil {
+			return fmt.Errorf("failed to write finding: %w", err)
+		}
+	}
+	return nil
+}
+
+type tableWriter struct {
+	cols     []Column
+	maxWidth int
+}
+
+func (tableWriter) Extension() string { return "txt" }
+
+func (t tableWriter) Write(w io.Writer, doc *Document) error {
+	return WriteTable(w, doc.Findings, t.cols, t.maxWidth)
+}
+
+type csvWriter struct{ cols []Column }
+
+func (csvWriter) Extension() string { return "csv" }
+
+func (c csvWriter) Write(w io.Writer, doc *Document) error {
+	return WriteCSV(w, doc.Findings, c.cols)
+}
+
+type xlsxWriter struct {
+	cols  []Column
+	theme Theme
+}
+
+func (xlsxWriter) Extension() string { return "xlsx" }
+
+func (x xlsxWriter) Write(w io.Writer, doc *Document) error {
+	return WriteXLSX(w, doc.Findings, x.cols, x.theme)
+}
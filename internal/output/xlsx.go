@@ -0,0 +1,158 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Static parts of a minimal single-sheet XLSX workbook
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Findings" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+)
+
+// Cell styles defined by xlsxStyles
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleHeader  = 1
+	xlsxStyleTitle   = 2
+)
+
+// xlsxStyles renders the stylesheet: a default style, a header style (bold white
+// text on the theme's primary colour) and a title style in the accent colour
+func xlsxStyles(theme Theme) string {
+	font := escapeXML(theme.FontFamily)
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="3"><font><sz val="11"/><name val="` + font + `"/></font><font><b/><sz val="11"/><color rgb="FFFFFFFF"/><name val="` + font + `"/></font><font><b/><sz val="14"/><color rgb="` + argb(theme.AccentColor) + `"/><name val="` + font + `"/></font></fonts>
+<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill><fill><patternFill patternType="solid"><fgColor rgb="` + argb(theme.PrimaryColor) + `"/></patternFill></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/><xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+</styleSheet>`
+}
+
+// xlsxMaxCellLength is the maximum number of characters Excel accepts in a cell
+const xlsxMaxCellLength = 32767
+
+// WriteXLSX writes findings as a single-sheet Excel workbook styled with theme.
+// When the theme names a company, a title row precedes the header row.
+func WriteXLSX(w io.Writer, findings []api.Finding, cols []Column, theme Theme) error {
+	theme = theme.WithDefaults()
+
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles(theme)},
+		{"xl/worksheets/sheet1.xml", buildSheet(findings, cols, theme)},
+	}
+
+	for _, part := range parts {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// buildSheet renders the worksheet XML using inline strings
+func buildSheet(findings []api.Finding, cols []Column, theme Theme) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	rowNum := 1
+	if theme.CompanyName != "" {
+		writeSheetRow(&sb, rowNum, []string{theme.Title("Security Findings")}, xlsxStyleTitle)
+		rowNum++
+	}
+
+	writeSheetRow(&sb, rowNum, headers(cols), xlsxStyleHeader)
+	for _, f := range findings {
+		rowNum++
+		writeSheetRow(&sb, rowNum, row(f, cols), xlsxStyleDefault)
+	}
+
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+func writeSheetRow(sb *strings.Builder, rowNum int, values []string, style int) {
+	fmt.Fprintf(sb, `<row r="%d">`, rowNum)
+	for i, v := range values {
+		if utf8.RuneCountInString(v) > xlsxMaxCellLength {
+			v = string([]rune(v)[:xlsxMaxCellLength])
+		}
+		fmt.Fprintf(sb, `<c r="%s%d" t="inlineStr"`, columnLetter(i), rowNum)
+		if style != xlsxStyleDefault {
+			fmt.Fprintf(sb, ` s="%d"`, style)
+		}
+		sb.WriteString(`><is><t xml:space="preserve">`)
+		sb.WriteString(escapeXML(v))
+		sb.WriteString(`</t></is></c>`)
+	}
+	sb.WriteString(`</row>`)
+}
+
+// columnLetter converts a zero-based column index into a spreadsheet letter (A, B, ..., AA)
+func columnLetter(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
+
+// escapeXML escapes text for use in XML, dropping characters XML cannot represent
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || r >= 0x20 {
+			return r
+		}
+		return -1
+	}, s)))
+	return buf.String()
+}
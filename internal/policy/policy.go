@@ -0,0 +1,362 @@
+// Package policy evaluates rules written in YAML, such as "no critical
+// reachable finding with a fix may be older than 30 days", against findings,
+// so a pipeline gate needs no jq script around the JSON output.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule severities
+const (
+	// SeverityError fails the check when the rule is violated
+	SeverityError = "error"
+	// SeverityWarning reports the violations without failing the check
+	SeverityWarning = "warning"
+)
+
+// File is the YAML policy file
+type File struct {
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// RuleConfig is a rule as written in the policy file
+type RuleConfig struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Severity is error (the default) or warning
+	Severity string      `yaml:"severity"`
+	Match    MatchConfig `yaml:"match"`
+	// MaxViolations is how many matching findings are allowed before the rule
+	// is violated; 0 allows none
+	MaxViolations int `yaml:"max_violations"`
+}
+
+// MatchConfig selects the findings a rule forbids. Every set condition must
+// hold; a list matches a finding having any of its values.
+type MatchConfig struct {
+	Levels       []string `yaml:"levels"`
+	Categories   []string `yaml:"categories"`
+	Tags         []string `yaml:"tags"`
+	Ecosystems   []string `yaml:"ecosystems"`
+	Projects     []string `yaml:"projects"`
+	Reachable    *bool    `yaml:"reachable"`
+	FixAvailable *bool    `yaml:"fix_available"`
+	// OlderThan is an age such as 30d or 12h, from the finding's create time
+	OlderThan string  `yaml:"older_than"`
+	EPSSMin   float64 `yaml:"epss_min"`
+	CVSSMin   float64 `yaml:"cvss_min"`
+}
+
+// Policy is a parsed policy file
+type Policy struct {
+	Rules []Rule
+}
+
+// Rule is a checked rule of a policy
+type Rule struct {
+	Name          string
+	Description   string
+	Severity      string
+	MaxViolations int
+
+	levels       []api.FindingLevel
+	categories   []api.FindingCategory
+	tags         []api.FindingTag
+	ecosystems   []string
+	projects     []string
+	reachable    *bool
+	fixAvailable *bool
+	olderThan    time.Duration
+	epssMin      float64
+	cvssMin      float64
+}
+
+// Load reads and parses the policy file at path
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse parses a policy, rejecting unknown fields, levels, categories and
+// tags so a typo does not silently disable a rule
+func Parse(data []byte) (*Policy, error) {
+	var file File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, err
+	}
+	if len(file.Rules) == 0 {
+		return nil, errors.New("no rules")
+	}
+
+	p := &Policy{}
+	seen := make(map[string]bool)
+	for i, rc := range file.Rules {
+		name := rc.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate rule %s", name)
+		}
+		seen[name] = true
+		rule, err := newRule(name, rc)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		p.Rules = append(p.Rules, rule)
+	}
+	return p, nil
+}
+
+func newRule(name string, rc RuleConfig) (Rule, error) {
+	r := Rule{
+		Name:          name,
+		Description:   rc.Description,
+		Severity:      strings.ToLower(rc.Severity),
+		MaxViolations: rc.MaxViolations,
+		ecosystems:    ecosystems(rc.Match.Ecosystems),
+		projects:      rc.Match.Projects,
+		reachable:     rc.Match.Reachable,
+		fixAvailable:  rc.Match.FixAvailable,
+		epssMin:       rc.Match.EPSSMin,
+		cvssMin:       rc.Match.CVSSMin,
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return r, fmt.Errorf("unknown severity %q (expected error or warning)", rc.Severity)
+	}
+	if r.MaxViolations < 0 {
+		return r, errors.New("max_violations cannot be negative")
+	}
+
+	var err error
+	if r.levels, err = parseAll(rc.Match.Levels, api.ParseFindingLevel); err != nil {
+		return r, err
+	}
+	if r.categories, err = parseAll(rc.Match.Categories, api.ParseFindingCategory); err != nil {
+		return r, err
+	}
+	if r.tags, err = parseAll(rc.Match.Tags, api.ParseFindingTag); err != nil {
+		return r, err
+	}
+	if rc.Match.OlderThan != "" {
+		if r.olderThan, err = ParseAge(rc.Match.OlderThan); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// ParseAge parses an age as a number of days such as 30d, or a Go duration
+// such as 12h
+func ParseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q (expected a number of days such as 30d, or a duration such as 12h)", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (expected a number of days such as 30d, or a duration such as 12h)", s)
+	}
+	return d, nil
+}
+
+// Matches reports whether f is forbidden by the rule at time now. A rule with
+// older_than does not match findings without a create time.
+func (r Rule) Matches(f api.Finding, now time.Time) bool {
+	if len(r.levels) > 0 && !containsAny(r.levels, f.Spec.Level) {
+		return false
+	}
+	if len(r.categories) > 0 && !containsAny(r.categories, f.Spec.FindingCategories...) {
+		return false
+	}
+	if len(r.tags) > 0 && !containsAny(r.tags, f.Spec.FindingTags...) {
+		return false
+	}
+	if len(r.ecosystems) > 0 && !containsAny(r.ecosystems, ecosystem(f.Spec.Ecosystem)) {
+		return false
+	}
+	if len(r.projects) > 0 && !containsAny(r.projects, f.Spec.ProjectUUID) {
+		return false
+	}
+	if r.reachable != nil && (f.Reachability() == api.Reachable) != *r.reachable {
+		return false
+	}
+	if r.fixAvailable != nil && f.HasTag(api.TagFixAvailable) != *r.fixAvailable {
+		return false
+	}
+	if r.olderThan > 0 {
+		created, err := time.Parse(time.RFC3339, f.Meta.CreateTime)
+		if err != nil || now.Sub(created) < r.olderThan {
+			return false
+		}
+	}
+	if r.epssMin > 0 {
+		if e := f.EPSS(); e == nil || e.ProbabilityScore < r.epssMin {
+			return false
+		}
+	}
+	if r.cvssMin > 0 {
+		if c := f.CVSS(); c == nil || c.Score < r.cvssMin {
+			return false
+		}
+	}
+	return true
+}
+
+// Result is the outcome of checking findings against a policy
+type Result struct {
+	Passed bool `json:"passed"`
+	// Findings is the number of findings checked
+	Findings int          `json:"findings"`
+	Rules    []RuleResult `json:"rules"`
+}
+
+// RuleResult is the outcome of one rule
+type RuleResult struct {
+	Name          string      `json:"name"`
+	Description   string      `json:"description,omitempty"`
+	Severity      string      `json:"severity"`
+	Passed        bool        `json:"passed"`
+	MaxViolations int         `json:"max_violations"`
+	Violations    []Violation `json:"violations"`
+}
+
+// Violation is a finding a rule forbids. Rego rules may report a message
+// instead of a finding, or with it.
+type Violation struct {
+	Message     string           `json:"message,omitempty"`
+	UUID        string           `json:"uuid,omitempty"`
+	Level       api.FindingLevel `json:"level,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Package     string           `json:"package,omitempty"`
+	ProjectUUID string           `json:"project_uuid,omitempty"`
+	CreateTime  string           `json:"create_time,omitempty"`
+}
+
+func newViolation(f api.Finding) Violation {
+	return Violation{
+		UUID:        f.UUID,
+		Level:       f.Spec.Level,
+		Name:        firstNonEmpty(f.CVE(), f.Meta.Name),
+		Package:     firstNonEmpty(f.Spec.TargetDependencyName, f.Spec.TargetDependencyPackageName),
+		ProjectUUID: f.Spec.ProjectUUID,
+		CreateTime:  f.Meta.CreateTime,
+	}
+}
+
+// Merge returns the results of two policies checked against the same findings
+func (r Result) Merge(other Result) Result {
+	r.Passed = r.Passed && other.Passed
+	r.Rules = append(append([]RuleResult(nil), r.Rules...), other.Rules...)
+	return r
+}
+
+// Failed returns the error rules that were violated
+func (r Result) Failed() []RuleResult {
+	var failed []RuleResult
+	for _, rule := range r.Rules {
+		if !rule.Passed && rule.Severity == SeverityError {
+			failed = append(failed, rule)
+		}
+	}
+	return failed
+}
+
+// Evaluate checks findings against every rule at time now. The check fails
+// when an error rule has more matching findings than it allows; violated
+// warning rules are reported only.
+func (p *Policy) Evaluate(findings []api.Finding, now time.Time) Result {
+	result := Result{Passed: true, Findings: len(findings)}
+	for _, rule := range p.Rules {
+		rr := RuleResult{
+			Name:          rule.Name,
+			Description:   rule.Description,
+			Severity:      rule.Severity,
+			MaxViolations: rule.MaxViolations,
+			Violations:    []Violation{},
+		}
+		for _, f := range findings {
+			if rule.Matches(f, now) {
+				rr.Violations = append(rr.Violations, newViolation(f))
+			}
+		}
+		rr.Passed = len(rr.Violations) <= rule.MaxViolations
+		if !rr.Passed && rule.Severity == SeverityError {
+			result.Passed = false
+		}
+		result.Rules = append(result.Rules, rr)
+	}
+	return result
+}
+
+func parseAll[T any](values []string, parse func(string) (T, error)) ([]T, error) {
+	parsed := make([]T, 0, len(values))
+	for _, v := range values {
+		p, err := parse(v)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+func containsAny[T comparable](set []T, values ...T) bool {
+	for _, v := range values {
+		for _, s := range set {
+			if s == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ecosystem returns an ecosystem in its short lower-case form, so npm matches
+// ECOSYSTEM_NPM
+func ecosystem(s string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.ToUpper(s), "ECOSYSTEM_"))
+}
+
+func ecosystems(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = ecosystem(v)
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// DefaultRegoPackage is the package whose rules are evaluated, as with conftest
+const DefaultRegoPackage = "main"
+
+// regoRules are the rules read from the package, with the severity of their
+// results; violation is conftest's structured form of deny
+var regoRules = []struct {
+	name     string
+	severity string
+}{
+	{"deny", SeverityError},
+	{"violation", SeverityError},
+	{"warn", SeverityWarning},
+}
+
+// Rego is a policy of Rego modules, evaluated by the embedded OPA. The
+// findings are the input document's findings array, and every result of
+// the package's deny, violation and warn rules is a violation.
+type Rego struct {
+	pkg   string
+	query rego.PreparedEvalQuery
+}
+
+// LoadRego compiles the Rego modules at paths, which may be directories of
+// modules and JSON or YAML data files, and prepares the rules of pkg
+func LoadRego(ctx context.Context, paths []string, pkg string) (*Rego, error) {
+	if pkg == "" {
+		pkg = DefaultRegoPackage
+	}
+	query, err := rego.New(
+		rego.Query("data."+pkg),
+		rego.Load(paths, nil),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Rego policy: %w", err)
+	}
+	return &Rego{pkg: pkg, query: query}, nil
+}
+
+// Evaluate checks findings against the Rego rules. A result of deny or
+// violation fails the check and a result of warn is reported only; each
+// result is a message, or an object with a msg and the uuid of the finding it
+// is about.
+func (r *Rego) Evaluate(ctx context.Context, findings []api.Finding) (Result, error) {
+	// The findings go through JSON so rules see the same fields as in exports
+	data, err := json.Marshal(map[string]any{"findings": findings})
+	if err != nil {
+		return Result{}, err
+	}
+	var input map[string]any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return Result{}, err
+	}
+
+	rs, err := r.query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to evaluate Rego policy: %w", err)
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return Result{}, fmt.Errorf("the Rego policy has no package %s", r.pkg)
+	}
+	doc, ok := rs[0].Expressions[0].Value.(map[string]any)
+	if !ok {
+		return Result{}, fmt.Errorf("data.%s is not a package", r.pkg)
+	}
+
+	byUUID := make(map[string]api.Finding, len(findings))
+	for _, f := range findings {
+		byUUID[f.UUID] = f
+	}
+
+	result := Result{Passed: true, Findings: len(findings)}
+	found := false
+	for _, rule := range regoRules {
+		value, ok := doc[rule.name]
+		if !ok {
+			continue
+		}
+		found = true
+		values, ok := value.([]any)
+		if !ok {
+			return Result{}, fmt.Errorf("data.%s.%s must be a set of messages or objects", r.pkg, rule.name)
+		}
+		rr := RuleResult{Name: r.pkg + "." + rule.name, Severity: rule.severity, Violations: []Violation{}}
+		for _, v := range values {
+			violation, err := regoViolation(v, byUUID)
+			if err != nil {
+				return Result{}, fmt.Errorf("data.%s.%s: %w", r.pkg, rule.name, err)
+			}
+			rr.Violations = append(rr.Violations, violation)
+		}
+		sort.SliceStable(rr.Violations, func(i, j int) bool { return rr.Violations[i].Message < rr.Violations[j].Message })
+		rr.Passed = len(rr.Violations) == 0
+		if !rr.Passed && rule.severity == SeverityError {
+			result.Passed = false
+		}
+		result.Rules = append(result.Rules, rr)
+	}
+	if !found {
+		return Result{}, fmt.Errorf("package %s has no deny, violation or warn rules", r.pkg)
+	}
+	return result, nil
+}
+
+// regoViolation converts one result of a rule: a message, or an object with
+// msg and optionally the uuid of a finding, whose details are filled in
+func regoViolation(v any, findings map[string]api.Finding) (Violation, error) {
+	switch v := v.(type) {
+	case string:
+		return Violation{Message: v}, nil
+	case map[string]any:
+		msg, _ := v["msg"].(string)
+		uuid, _ := v["uuid"].(string)
+		if msg == "" && uuid == "" {
+			return Violation{}, errors.New("objects need a msg or a uuid")
+		}
+		f, ok := findings[uuid]
+		if !ok {
+			return Violation{Message: msg, UUID: uuid}, nil
+		}
+		violation := newViolation(f)
+		violation.Message = msg
+		return violation, nil
+	}
+	return Violation{}, fmt.Errorf("unexpected result %s (expected a message or an object with msg)", strings.TrimSpace(fmt.Sprint(v)))
+}
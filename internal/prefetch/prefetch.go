@@ -0,0 +1,299 @@
+// Package prefetch keeps data for a set of keys (typically namespaces) fresh in
+// the background, so a long-running server can answer from memory instead of
+// fetching synchronously on every request.
+package prefetch
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FetchFunc loads the data for key. prev and since are the previous data and the
+// time its fetch started, or the zero values on the first fetch, so the function
+// can request only what changed and merge it into prev.
+type FetchFunc[T any] func(ctx context.Context, key string, prev T, since time.Time) (T, error)
+
+// Options control scheduling
+type Options struct {
+	// Interval is the target time between refreshes of a key
+	Interval time.Duration
+	// Jitter is the fraction of Interval each refresh is randomly moved by, so
+	// keys tracked together do not refresh in lockstep (0 to 1)
+	Jitter float64
+	// MaxStaleness is the age after which a key's data is reported as stale;
+	// 2*Interval when zero
+	MaxStaleness time.Duration
+	// MinGap is the minimum time between the start of any two fetches, limiting
+	// the load put on the API however many keys are tracked
+	MinGap time.Duration
+}
+
+// Snapshot is the data held for a key and how fresh it is
+type Snapshot[T any] struct {
+	Data      T
+	Freshness Freshness
+}
+
+// Freshness describes the state of a key's data, for API responses and metrics
+type Freshness struct {
+	Key         string        `json:"key"`
+	Ready       bool          `json:"ready"`
+	Stale       bool          `json:"stale"`
+	FetchedAt   time.Time     `json:"fetched_at,omitempty"`
+	Age         time.Duration `json:"age_ns"`
+	NextRefresh time.Time     `json:"next_refresh,omitempty"`
+	Refreshes   int           `json:"refreshes"`
+	Failures    int           `json:"failures"`
+	LastError   string        `json:"last_error,omitempty"`
+}
+
+type entry[T any] struct {
+	data        T
+	ready       bool
+	fetchedAt   time.Time
+	nextRefresh time.Time
+	refreshes   int
+	failures    int
+	lastErr     error
+}
+
+// Refresher refreshes tracked keys in the background
+type Refresher[T any] struct {
+	fetch FetchFunc[T]
+	opts  Options
+
+	mu        sync.Mutex
+	entries   map[string]*entry[T]
+	lastFetch time.Time
+	wake      chan struct{}
+	now       func() time.Time
+}
+
+// New creates a refresher that loads data with fetch
+func New[T any](fetch FetchFunc[T], opts Options) *Refresher[T] {
+	return &Refresher[T]{
+		fetch:   fetch,
+		opts:    opts.normalized(),
+		entries: make(map[string]*entry[T]),
+		wake:    make(chan struct{}, 1),
+		now:     time.Now,
+	}
+}
+
+// normalized returns opts with defaults filled in and Jitter clamped to 0..1
+func (opts Options) normalized() Options {
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Minute
+	}
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	}
+	if opts.Jitter > 1 {
+		opts.Jitter = 1
+	}
+	if opts.MaxStaleness <= 0 {
+		opts.MaxStaleness = 2 * opts.Interval
+	}
+	return opts
+}
+
+// SetOptions changes the schedule of a running refresher. Refreshes already
+// scheduled further out than the new interval are brought forward to it.
+func (r *Refresher[T]) SetOptions(opts Options) {
+	opts = opts.normalized()
+
+	r.mu.Lock()
+	r.opts = opts
+	limit := r.now().Add(opts.Interval)
+	for _, e := range r.entries {
+		if e.nextRefresh.After(limit) {
+			e.nextRefresh = limit
+		}
+	}
+	r.mu.Unlock()
+
+	r.poke()
+}
+
+// Track adds key to the refreshed set; its first fetch is scheduled immediately
+func (r *Refresher[T]) Track(key string) {
+	r.mu.Lock()
+	if _, ok := r.entries[key]; !ok {
+		r.entries[key] = &entry[T]{nextRefresh: r.now()}
+	}
+	r.mu.Unlock()
+
+	r.poke()
+}
+
+// Untrack stops refreshing key and drops its data
+func (r *Refresher[T]) Untrack(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, key)
+}
+
+// Get returns the data held for key. ok is false when key is not tracked.
+func (r *Refresher[T]) Get(key string) (snap Snapshot[T], ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[key]
+	if !ok {
+		return snap, false
+	}
+	return Snapshot[T]{Data: e.data, Freshness: r.freshness(key, e)}, true
+}
+
+// Freshness returns the state of every tracked key, sorted by key
+func (r *Refresher[T]) Freshness() []Freshness {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make([]Freshness, 0, len(r.entries))
+	for key, e := range r.entries {
+		all = append(all, r.freshness(key, e))
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Key < all[j].Key })
+	return all
+}
+
+// Run refreshes keys as they become due until ctx is cancelled. Fetches run one
+// at a time, at least MinGap apart.
+func (r *Refresher[T]) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		key, wait := r.next()
+		if key != "" {
+			r.refresh(ctx, key)
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// next returns the most overdue key that may be fetched now, or how long to
+// wait before one can be
+func (r *Refresher[T]) next() (string, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	wait := r.opts.Interval
+
+	var due string
+	var dueAt time.Time
+	for key, e := range r.entries {
+		if due == "" || e.nextRefresh.Before(dueAt) {
+			due, dueAt = key, e.nextRefresh
+		}
+	}
+	if due == "" {
+		return "", wait
+	}
+
+	// Rate limit: never start fetches closer together than MinGap
+	earliest := dueAt
+	if gapEnd := r.lastFetch.Add(r.opts.MinGap); gapEnd.After(earliest) {
+		earliest = gapEnd
+	}
+	if earliest.After(now) {
+		return "", earliest.Sub(now)
+	}
+
+	r.lastFetch = now
+	return due, 0
+}
+
+// refresh fetches key and schedules its next refresh
+func (r *Refresher[T]) refresh(ctx context.Context, key string) {
+	r.mu.Lock()
+	e, ok := r.entries[key]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	prev, since := e.data, e.fetchedAt
+	if !e.ready {
+		since = time.Time{}
+	}
+	r.mu.Unlock()
+
+	start := r.now()
+	data, err := r.fetch(ctx, key, prev, since)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The key may have been untracked during the fetch
+	if e, ok = r.entries[key]; !ok {
+		return
+	}
+	if err != nil {
+		e.failures++
+		e.lastErr = err
+	} else {
+		e.data = data
+		e.ready = true
+		e.fetchedAt = start
+		e.refreshes++
+		e.lastErr = nil
+	}
+	e.nextRefresh = r.now().Add(r.jittered())
+}
+
+// jittered returns Interval moved randomly by up to Jitter in either direction
+func (r *Refresher[T]) jittered() time.Duration {
+	spread := time.Duration(float64(r.opts.Interval) * r.opts.Jitter)
+	if spread <= 0 {
+		return r.opts.Interval
+	}
+	return r.opts.Interval - spread + time.Duration(rand.Int63n(int64(2*spread)+1))
+}
+
+func (r *Refresher[T]) freshness(key string, e *entry[T]) Freshness {
+	f := Freshness{
+		Key:         key,
+		Ready:       e.ready,
+		Stale:       true,
+		NextRefresh: e.nextRefresh,
+		Refreshes:   e.refreshes,
+		Failures:    e.failures,
+	}
+	if e.ready {
+		f.FetchedAt = e.fetchedAt
+		f.Age = r.now().Sub(e.fetchedAt)
+		f.Stale = f.Age > r.opts.MaxStaleness
+	}
+	if e.lastErr != nil {
+		f.LastError = e.lastErr.Error()
+	}
+	return f
+}
+
+// poke wakes Run to reconsider the schedule
+func (r *Refresher[T]) poke() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,247 @@
+package release
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BinaryName is the base name of released binaries
+const BinaryName = "findings-api"
+
+// ChecksumsFile and SignatureFile are written next to the binaries
+const (
+	ChecksumsFile = "checksums.txt"
+	SignatureFile = "checksums.txt.sig"
+)
+
+// buildinfoPackage is the import path whose variables carry the version
+const buildinfoPackage = "github.com/endor-labs/findings-api/internal/buildinfo"
+
+// Target is an operating system and architecture pair
+type Target struct {
+	OS   string
+	Arch string
+}
+
+func (t Target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// DefaultTargets are the platforms built when none are specified
+var DefaultTargets = []Target{
+	{"linux", "amd64"},
+	{"linux", "arm64"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"windows", "amd64"},
+	{"windows", "arm64"},
+}
+
+// ParseTargets parses a comma-separated list such as "linux/amd64,darwin/arm64"
+func ParseTargets(spec string) ([]Target, error) {
+	if strings.TrimSpace(spec) == "" {
+		return DefaultTargets, nil
+	}
+
+	var targets []Target
+	for _, item := range strings.Split(spec, ",") {
+		goos, goarch, ok := strings.Cut(strings.TrimSpace(item), "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid target %q (expected os/arch)", item)
+		}
+		targets = append(targets, Target{OS: goos, Arch: goarch})
+	}
+	return targets, nil
+}
+
+// Options configures a release build
+type Options struct {
+	Version string
+	Commit  string
+	Date    string
+	OutDir  string
+	Targets []Target
+	// Package is the main package to build, "." by default
+	Package string
+	// SigningKey signs the checksums file when set
+	SigningKey ed25519.PrivateKey
+	// Log receives progress messages; may be nil
+	Log io.Writer
+}
+
+// Artifact is a built binary and its checksum
+type Artifact struct {
+	Target Target
+	Path   string
+	SHA256 string
+}
+
+// Build cross-compiles a binary per target into OutDir, writes a checksums file
+// and, when a signing key is configured, its detached signature
+func Build(ctx context.Context, opts Options) ([]Artifact, error) {
+	if opts.Version == "" {
+		return nil, errors.New("version is required")
+	}
+	if opts.Package == "" {
+		opts.Package = "."
+	}
+	if len(opts.Targets) == 0 {
+		opts.Targets = DefaultTargets
+	}
+	logw := opts.Log
+	if logw == nil {
+		logw = io.Discard
+	}
+
+	if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ldflags := fmt.Sprintf("-s -w -X %[1]s.Version=%[2]s -X %[1]s.Commit=%[3]s -X %[1]s.Date=%[4]s",
+		buildinfoPackage, opts.Version, opts.Commit, opts.Date)
+
+	var artifacts []Artifact
+	for _, t := range opts.Targets {
+		name := fmt.Sprintf("%s_%s_%s_%s", BinaryName, strings.TrimPrefix(opts.Version, "v"), t.OS, t.Arch)
+		if t.OS == "windows" {
+			name += ".exe"
+		}
+		path := filepath.Join(opts.OutDir, name)
+
+		fmt.Fprintf(logw, "Building %s...\n", t)
+
+		cmd := exec.CommandContext(ctx, "go", "build", "-trimpath", "-ldflags", ldflags, "-o", path, opts.Package)
+		cmd.Env = append(os.Environ(), "GOOS="+t.OS, "GOARCH="+t.Arch, "CGO_ENABLED=0")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("build for %s failed: %w: %s", t, err, strings.TrimSpace(stderr.String()))
+		}
+
+		sum, err := fileSHA256(path)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, Artifact{Target: t, Path: path, SHA256: sum})
+	}
+
+	checksums := formatChecksums(artifacts)
+	checksumsPath := filepath.Join(opts.OutDir, ChecksumsFile)
+	if err := os.WriteFile(checksumsPath, checksums, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write checksums: %w", err)
+	}
+
+	if opts.SigningKey != nil {
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(opts.SigningKey, checksums))
+		if err := os.WriteFile(filepath.Join(opts.OutDir, SignatureFile), []byte(sig+"\n"), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write signature: %w", err)
+		}
+		fmt.Fprintf(logw, "Signed %s\n", ChecksumsFile)
+	}
+
+	return artifacts, nil
+}
+
+// Verify checks the checksums file signature in dir and every listed binary's checksum
+func Verify(dir string, publicKey ed25519.PublicKey) error {
+	checksums, err := os.ReadFile(filepath.Join(dir, ChecksumsFile))
+	if err != nil {
+		return fmt.Errorf("failed to read checksums: %w", err)
+	}
+
+	sigData, err := os.ReadFile(filepath.Join(dir, SignatureFile))
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if !ed25519.Verify(publicKey, checksums, sig) {
+		return errors.New("signature does not match checksums")
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(checksums)), "\n") {
+		want, name, ok := strings.Cut(line, "  ")
+		if !ok {
+			return fmt.Errorf("malformed checksums line %q", line)
+		}
+		got, err := fileSHA256(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+	}
+
+	return nil
+}
+
+// GenerateKey creates a new signing key pair, returned base64-encoded
+func GenerateKey() (publicKey, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(priv), nil
+}
+
+// ParsePrivateKey decodes a base64-encoded ed25519 private key
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// ParsePublicKey decodes a base64-encoded ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// formatChecksums renders artifacts in sha256sum format, sorted by file name
+func formatChecksums(artifacts []Artifact) []byte {
+	sorted := make([]Artifact, len(artifacts))
+	copy(sorted, artifacts)
+	sort.Slice(sorted, func(i, j int) bool {
+		return filepath.Base(sorted[i].Path) < filepath.Base(sorted[j].Path)
+	})
+
+	lines := make([]string, 0, len(sorted))
+	for _, a := range sorted {
+		lines = append(lines, fmt.Sprintf("%s  %s", a.SHA256, filepath.Base(a.Path)))
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
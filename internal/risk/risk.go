@@ -0,0 +1,293 @@
+// Package risk combines the severity, exploit probability, reachability, fix
+// availability and dependency depth of a finding into one risk score from 0
+// to 100, weighted per organization, so findings can be ranked by more than
+// any one of them. Factors are pluggable: Register adds one to the built-ins.
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/enrich"
+)
+
+// Factor rates one aspect of a finding's risk
+type Factor struct {
+	// Name is the factor's key in weights and in the stored breakdown
+	Name        string
+	Description string
+	// Rate returns the factor's value for a finding, from 0 (no risk) to 1
+	Rate func(f api.Finding) float64
+}
+
+// Built-in factor names
+const (
+	FactorSeverity     = "severity"
+	FactorEPSS         = "epss"
+	FactorReachability = "reachability"
+	FactorFix          = "fix"
+	FactorDepth        = "depth"
+)
+
+// DefaultWeights are the weights of the built-in factors when none are configured
+var DefaultWeights = map[string]float64{
+	FactorSeverity:     0.35,
+	FactorEPSS:         0.25,
+	FactorReachability: 0.2,
+	FactorFix:          0.1,
+	FactorDepth:        0.1,
+}
+
+var (
+	mu      sync.RWMutex
+	factors = map[string]Factor{}
+)
+
+func init() {
+	for _, f := range []Factor{
+		{FactorSeverity, "CVSS score out of 10, recomputed when CVSS enrichment is on, else the finding level", severity},
+		{FactorEPSS, "EPSS probability, 1 for vulnerabilities known to be exploited", epss},
+		{FactorReachability, "Reachable functions rate highest, then reachable dependencies; unreachable code lowest", reachability},
+		{FactorFix, "1 when a fix is available, so findings that can be fixed now rank higher", fix},
+		{FactorDepth, "1 for direct dependencies, 0.5 for transitive ones", depth},
+	} {
+		Register(f)
+	}
+}
+
+// Register adds a factor, or replaces the factor of the same name. Registered
+// factors get a weight only when one is configured for them.
+func Register(f Factor) {
+	mu.Lock()
+	defer mu.Unlock()
+	factors[f.Name] = f
+}
+
+// ParseWeights parses weights of the form severity=0.4,epss=0.3
+func ParseWeights(s string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed weight %q (expected factor=weight)", part)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight of %s: %w", name, err)
+		}
+		weights[strings.TrimSpace(name)] = w
+	}
+	return weights, nil
+}
+
+// FormatWeights returns weights in the form ParseWeights reads
+func FormatWeights(weights map[string]float64) string {
+	names := make([]string, 0, len(weights))
+	for name := range weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + strconv.FormatFloat(weights[name], 'f', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// weighted is a factor with its weight
+type weighted struct {
+	Factor
+	weight float64
+}
+
+// Scorer computes risk scores with fixed weights. It is an enricher storing
+// each finding's score and factor values in Finding.Enrichments under "risk".
+type Scorer struct {
+	factors []weighted
+	total   float64
+}
+
+// NewScorer returns a scorer weighting the factors by DefaultWeights, with
+// weights replacing the defaults of the factors they name. A weight of 0
+// leaves a factor out.
+func NewScorer(weights map[string]float64) (*Scorer, error) {
+	merged := make(map[string]float64, len(DefaultWeights)+len(weights))
+	for name, w := range DefaultWeights {
+		merged[name] = w
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for name, w := range weights {
+		if _, ok := factors[name]; !ok {
+			return nil, fmt.Errorf("unknown risk factor %q (expected %s)", name, strings.Join(factorNames(), ", "))
+		}
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("invalid weight %v of risk factor %s (expected 0 or more)", w, name)
+		}
+		merged[name] = w
+	}
+
+	s := &Scorer{}
+	for _, name := range sortedKeys(merged) {
+		if w := merged[name]; w > 0 {
+			s.factors = append(s.factors, weighted{factors[name], w})
+			s.total += w
+		}
+	}
+	if s.total == 0 {
+		return nil, errors.New("every risk factor has a weight of 0")
+	}
+	return s, nil
+}
+
+// Weights returns the weights in effect, by factor name
+func (s *Scorer) Weights() map[string]float64 {
+	weights := make(map[string]float64, len(s.factors))
+	for _, f := range s.factors {
+		weights[f.Name] = f.weight
+	}
+	return weights
+}
+
+// Score is a finding's risk score with the factor values it combines
+type Score struct {
+	// Score is the weighted mean of the factors, scaled to 0-100
+	Score float64 `json:"score"`
+	// Factors are the values of the factors, from 0 to 1
+	Factors map[string]float64 `json:"factors"`
+}
+
+// Score computes the risk score of f
+func (s *Scorer) Score(f api.Finding) Score {
+	score := Score{Factors: make(map[string]float64, len(s.factors))}
+	var sum float64
+	for _, factor := range s.factors {
+		v := math.Max(0, math.Min(1, factor.Rate(f)))
+		score.Factors[factor.Name] = round(v, 3)
+		sum += factor.weight * v
+	}
+	score.Score = round(100*sum/s.total, 1)
+	return score
+}
+
+func (*Scorer) Name() string { return "risk" }
+
+func (s *Scorer) Enrich(ctx context.Context, findings []api.Finding) error {
+	for i := range findings {
+		data, err := json.Marshal(s.Score(findings[i]))
+		if err != nil {
+			return err
+		}
+		if findings[i].Enrichments == nil {
+			findings[i].Enrichments = make(map[string]json.RawMessage)
+		}
+		findings[i].Enrichments[s.Name()] = data
+	}
+	return nil
+}
+
+// Of returns the risk score a Scorer stored for f. ok is false when f was
+// not scored.
+func Of(f api.Finding) (score float64, ok bool) {
+	var rec Score
+	if data, found := f.Enrichments[(*Scorer)(nil).Name()]; found && json.Unmarshal(data, &rec) == nil {
+		return rec.Score, true
+	}
+	return 0, false
+}
+
+// Sort orders findings by risk score, highest first; unscored findings come last
+func Sort(findings []api.Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, aok := Of(findings[i])
+		b, bok := Of(findings[j])
+		if aok != bok {
+			return aok
+		}
+		return a > b
+	})
+}
+
+func severity(f api.Finding) float64 {
+	if score, ok := enrich.CVSSScore(f); ok {
+		return score / 10
+	}
+	return float64(f.Spec.Level.Rank()) / float64(api.LevelCritical.Rank())
+}
+
+func epss(f api.Finding) float64 {
+	if f.HasTag(api.TagExploited) || enrich.KEV(f) != nil {
+		return 1
+	}
+	score, _ := enrich.EPSS(f)
+	return score
+}
+
+func reachability(f api.Finding) float64 {
+	switch {
+	case f.HasTag(api.TagReachableFunction):
+		return 1
+	case f.HasTag(api.TagPotentiallyReachableFunction):
+		return 0.75
+	case f.HasTag(api.TagUnreachableFunction):
+		return 0.1
+	case f.HasTag(api.TagReachableDependency):
+		return 0.6
+	case f.HasTag(api.TagPotentiallyReachableDependency):
+		return 0.5
+	case f.HasTag(api.TagUnreachableDependency):
+		return 0.05
+	}
+	// Without reachability analysis nothing is known either way
+	return 0.5
+}
+
+func fix(f api.Finding) float64 {
+	if f.HasTag(api.TagFixAvailable) {
+		return 1
+	}
+	return 0
+}
+
+// depth rates direct dependencies highest: findings carry whether their
+// dependency is direct or transitive, not how deep it is
+func depth(f api.Finding) float64 {
+	if f.HasTag(api.TagDirect) || f.Spec.Relationship == "direct" {
+		return 1
+	}
+	return 0.5
+}
+
+func factorNames() []string {
+	names := make([]string, 0, len(factors))
+	for name := range factors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
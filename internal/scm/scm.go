@@ -0,0 +1,151 @@
+package scm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Kind identifies a source control platform
+type Kind string
+
+// Supported platforms
+const (
+	GitHub Kind = "github"
+	GitLab Kind = "gitlab"
+)
+
+// Cloud API endpoints
+const (
+	GitHubAPIURL = "https://api.github.com"
+	GitLabAPIURL = "https://gitlab.com/api/v4"
+)
+
+// Config describes how to reach a GitHub or GitLab instance, cloud or self-hosted.
+// Every SCM integration builds its requests through a Config so that GitHub
+// Enterprise Server and self-managed GitLab work the same way as the cloud.
+type Config struct {
+	Kind Kind
+	// APIURL is the REST API root; empty means the cloud endpoint for Kind
+	APIURL string
+	// Token authenticates requests
+	Token string
+	// JobToken marks Token as a GitLab CI job token rather than a personal or
+	// project access token
+	JobToken bool
+}
+
+// ParseKind parses "github" or "gitlab"
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(strings.ToLower(strings.TrimSpace(s))); k {
+	case GitHub, GitLab:
+		return k, nil
+	default:
+		return "", fmt.Errorf("unsupported SCM %q (expected github or gitlab)", s)
+	}
+}
+
+// APIURLForHost returns the REST API root of a GitHub or GitLab instance at host.
+// github.com and gitlab.com map to their cloud endpoints; any other host is treated
+// as GitHub Enterprise Server (/api/v3) or self-managed GitLab (/api/v4).
+func APIURLForHost(kind Kind, host string) string {
+	host = strings.TrimSuffix(strings.TrimSpace(host), "/")
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	switch {
+	case kind == GitHub && strings.HasSuffix(host, "://github.com"):
+		return GitHubAPIURL
+	case kind == GitLab && strings.HasSuffix(host, "://gitlab.com"):
+		return GitLabAPIURL
+	case kind == GitHub:
+		return host + "/api/v3"
+	default:
+		return host + "/api/v4"
+	}
+}
+
+// FromEnv builds a Config for kind from the variables set by GitHub Actions,
+// GitLab CI and the gh/glab CLIs. GITHUB_API_URL and CI_API_V4_URL already point
+// at the right instance inside enterprise CI runners; GH_HOST and GITLAB_HOST
+// select a self-hosted instance elsewhere.
+func FromEnv(kind Kind) Config {
+	cfg := Config{Kind: kind}
+
+	switch kind {
+	case GitHub:
+		cfg.APIURL = os.Getenv("GITHUB_API_URL")
+		if cfg.APIURL == "" && os.Getenv("GH_HOST") != "" {
+			cfg.APIURL = APIURLForHost(GitHub, os.Getenv("GH_HOST"))
+		}
+		cfg.Token = firstEnv("GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "GITHUB_TOKEN", "GH_TOKEN")
+	case GitLab:
+		cfg.APIURL = os.Getenv("CI_API_V4_URL")
+		if cfg.APIURL == "" && os.Getenv("GITLAB_HOST") != "" {
+			cfg.APIURL = APIURLForHost(GitLab, os.Getenv("GITLAB_HOST"))
+		}
+		cfg.Token = firstEnv("GITLAB_TOKEN", "GL_TOKEN")
+		if cfg.Token == "" && os.Getenv("CI_JOB_TOKEN") != "" {
+			cfg.Token = os.Getenv("CI_JOB_TOKEN")
+			cfg.JobToken = true
+		}
+	}
+
+	return cfg
+}
+
+// BaseURL returns the configured API root, or the cloud endpoint for Kind
+func (c Config) BaseURL() string {
+	if c.APIURL != "" {
+		return strings.TrimRight(c.APIURL, "/")
+	}
+	if c.Kind == GitLab {
+		return GitLabAPIURL
+	}
+	return GitHubAPIURL
+}
+
+// NewRequest creates an authenticated API request. path is relative to the API root.
+func (c Config) NewRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("no %s token configured", c.Kind)
+	}
+
+	u, err := url.JoinPath(c.BaseURL(), path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s API URL: %w", c.Kind, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c.Kind == GitLab && c.JobToken:
+		req.Header.Set("JOB-TOKEN", c.Token)
+	case c.Kind == GitLab:
+		req.Header.Set("PRIVATE-TOKEN", c.Token)
+	default:
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return req, nil
+}
+
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
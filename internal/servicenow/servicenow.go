@@ -0,0 +1,330 @@
+// Package servicenow creates and updates ServiceNow incidents and
+// Vulnerability Response records through the Table API.
+package servicenow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Record types and the tables they are created in
+const (
+	RecordIncident      = "incident"
+	RecordVulnerability = "vulnerability"
+
+	IncidentTable      = "incident"
+	VulnerabilityTable = "sn_vul_vulnerable_item"
+)
+
+// RecordTypes lists the valid record types
+var RecordTypes = []string{RecordIncident, RecordVulnerability}
+
+// DefaultCorrelationDisplay marks the records the integration manages
+const DefaultCorrelationDisplay = "Endor Labs"
+
+// Config selects the ServiceNow instance, credentials and the records created
+type Config struct {
+	URL string
+	// Username and Password are basic auth credentials. Without them, Token
+	// is sent as an OAuth bearer token.
+	Username string
+	Password string
+	Token    string
+	// RecordType is one of RecordTypes; Table overrides the table it selects
+	RecordType      string
+	Table           string
+	AssignmentGroup string
+	Category        string
+	// Fields are extra field values set on created and updated records
+	Fields map[string]string
+}
+
+// Record is a created or found ServiceNow record
+type Record struct {
+	SysID  string
+	Number string
+	URL    string
+}
+
+// RecordFields are the record fields the integration manages
+type RecordFields struct {
+	ShortDescription string
+	Description      string
+	// CorrelationID is the finding UUID the record tracks, which later runs
+	// find the record by
+	CorrelationID string
+	Level         api.FindingLevel
+}
+
+// priorities maps finding levels to the impact and urgency ServiceNow derives
+// priorities from: critical is P1, high P2, medium P3 and low P4 with the
+// default priority lookup
+var priorities = map[api.FindingLevel]struct{ impact, urgency, priority string }{
+	api.LevelCritical: {"1", "1", "1"},
+	api.LevelHigh:     {"1", "2", "2"},
+	api.LevelMedium:   {"2", "2", "3"},
+	api.LevelLow:      {"2", "3", "4"},
+}
+
+// Priority returns the ServiceNow priority of a finding level, 1 to 5
+func Priority(l api.FindingLevel) string {
+	if p, ok := priorities[l]; ok {
+		return p.priority
+	}
+	return "5"
+}
+
+// Client calls the ServiceNow Table API
+type Client struct {
+	cfg        Config
+	baseURL    string
+	table      string
+	httpClient *http.Client
+}
+
+// NewClient checks cfg and returns a client for it
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("a ServiceNow instance URL is required")
+	}
+	if cfg.Token == "" && (cfg.Username == "" || cfg.Password == "") {
+		return nil, errors.New("ServiceNow credentials are required: a username and password, or an OAuth token")
+	}
+	if cfg.RecordType == "" {
+		cfg.RecordType = RecordIncident
+	}
+	table := cfg.Table
+	switch cfg.RecordType {
+	case RecordIncident:
+		table = firstNonEmpty(table, IncidentTable)
+	case RecordVulnerability:
+		table = firstNonEmpty(table, VulnerabilityTable)
+	default:
+		return nil, fmt.Errorf("invalid record type %q (expected %s)", cfg.RecordType, strings.Join(RecordTypes, " or "))
+	}
+
+	return &Client{
+		cfg:        cfg,
+		baseURL:    strings.TrimRight(cfg.URL, "/"),
+		table:      table,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Table returns the table records are created in
+func (c *Client) Table() string { return c.table }
+
+// RecordURL returns the web URL of the record sysID
+func (c *Client) RecordURL(sysID string) string {
+	return fmt.Sprintf("%s/nav_to.do?uri=%s", c.baseURL, url.QueryEscape(c.table+".do?sys_id="+sysID))
+}
+
+// Create opens a new record
+func (c *Client) Create(ctx context.Context, f RecordFields) (Record, error) {
+	var out struct {
+		Result tableRecord `json:"result"`
+	}
+	if err := c.do(ctx, "POST", c.tablePath(""), c.fields(f), &out); err != nil {
+		return Record{}, fmt.Errorf("failed to create %s record: %w", c.table, err)
+	}
+	return c.record(out.Result), nil
+}
+
+// Update replaces the managed fields of the record sysID
+func (c *Client) Update(ctx context.Context, sysID string, f RecordFields) (Record, error) {
+	var out struct {
+		Result tableRecord `json:"result"`
+	}
+	if err := c.do(ctx, "PATCH", c.tablePath(sysID), c.fields(f), &out); err != nil {
+		return Record{}, fmt.Errorf("failed to update %s record %s: %w", c.table, sysID, err)
+	}
+	return c.record(out.Result), nil
+}
+
+// FindByCorrelationID returns the most recent active record tracking the
+// finding correlationID; ok is false when there is none. Closed records are
+// not returned, so a finding that comes back gets a new record.
+func (c *Client) FindByCorrelationID(ctx context.Context, correlationID string) (rec Record, ok bool, err error) {
+	params := url.Values{}
+	params.Set("sysparm_query", fmt.Sprintf("correlation_id=%s^active=true^ORDERBYDESCsys_created_on", correlationID))
+	params.Set("sysparm_fields", "sys_id,number")
+	params.Set("sysparm_limit", "1")
+
+	var out struct {
+		Result []tableRecord `json:"result"`
+	}
+	if err := c.do(ctx, "GET", c.tablePath("")+"?"+params.Encode(), nil, &out); err != nil {
+		return Record{}, false, fmt.Errorf("failed to search %s records: %w", c.table, err)
+	}
+	if len(out.Result) == 0 {
+		return Record{}, false, nil
+	}
+	return c.record(out.Result[0]), true, nil
+}
+
+// tableRecord is the part of a Table API record the client reads
+type tableRecord struct {
+	SysID  string `json:"sys_id"`
+	Number string `json:"number"`
+}
+
+func (c *Client) record(r tableRecord) Record {
+	return Record{SysID: r.SysID, Number: firstNonEmpty(r.Number, r.SysID), URL: c.RecordURL(r.SysID)}
+}
+
+func (c *Client) tablePath(sysID string) string {
+	path := "/api/now/table/" + url.PathEscape(c.table)
+	if sysID != "" {
+		path += "/" + url.PathEscape(sysID)
+	}
+	return path
+}
+
+// fields returns the fields set on both created and updated records
+func (c *Client) fields(f RecordFields) map[string]string {
+	fields := make(map[string]string, len(c.cfg.Fields)+8)
+	for k, v := range c.cfg.Fields {
+		fields[k] = v
+	}
+	fields["short_description"] = f.ShortDescription
+	fields["description"] = f.Description
+	fields["correlation_id"] = f.CorrelationID
+	fields["correlation_display"] = DefaultCorrelationDisplay
+	p, ok := priorities[f.Level]
+	if !ok {
+		p = priorities[api.LevelLow]
+	}
+	fields["impact"], fields["urgency"] = p.impact, p.urgency
+	if c.cfg.RecordType == RecordVulnerability {
+		// Vulnerable items do not derive priority from impact and urgency
+		fields["priority"] = p.priority
+	}
+	if c.cfg.AssignmentGroup != "" {
+		fields["assignment_group"] = c.cfg.AssignmentGroup
+	}
+	if c.cfg.Category != "" {
+		fields["category"] = c.cfg.Category
+	}
+	return fields
+}
+
+// do sends a request with body encoded as JSON and decodes the response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Username != "" && c.cfg.Password != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newError(resp)
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// newError reads ServiceNow's error body: an error message and its detail
+func newError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Detail  string `json:"detail"`
+		} `json:"error"`
+	}
+	var msgs []string
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		msgs = append(msgs, body.Error.Message)
+		if body.Error.Detail != "" {
+			msgs = append(msgs, body.Error.Detail)
+		}
+	} else if text := strings.TrimSpace(string(data)); text != "" {
+		msgs = append(msgs, text)
+	}
+	if len(msgs) == 0 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return fmt.Errorf("status %d: %s", resp.StatusCode, strings.Join(msgs, "; "))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Details renders the finding metadata embedded in record descriptions, with
+// link to the finding in the Endor Labs app when it is not empty
+func Details(f api.Finding, link string) string {
+	var b strings.Builder
+	line := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+	line("Finding UUID", f.UUID)
+	line("Level", f.Spec.Level.Short())
+	project := f.Spec.ProjectUUID
+	if f.Project != nil && f.Project.Name != "" {
+		project = fmt.Sprintf("%s (%s)", f.Project.Name, f.Spec.ProjectUUID)
+	}
+	line("Project", project)
+	line("Namespace", f.Namespace)
+	line("Package", strings.TrimSuffix(f.Spec.TargetDependencyPackageName+"@"+f.Spec.TargetDependencyVersion, "@"))
+	line("Dependency files", strings.Join(f.Spec.DependencyFilePath, ", "))
+	line("Advisories", strings.Join(f.VulnerabilityIDs(), ", "))
+	line("CWEs", strings.Join(f.CWEs(), ", "))
+	if c := f.CVSS(); c != nil {
+		line("CVSS", strings.TrimSpace(fmt.Sprintf("%.1f %s", c.Score, c.Vector)))
+	}
+	if e := f.EPSS(); e != nil {
+		line("EPSS", fmt.Sprintf("%.5f (percentile %.5f)", e.ProbabilityScore, e.PercentileScore))
+	}
+	if r := f.Reachability(); r != api.ReachabilityUnknown {
+		line("Reachability", string(r))
+	}
+	line("Fix", f.UpgradeTarget())
+	line("Endor Labs", link)
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,176 @@
+// Package snapshot reads findings files written by any version of the tool and
+// migrates them to the current document schema, so commands that consume
+// earlier exports do not break when the layout changes.
+//
+// Schema versions:
+//
+//	0 - {timestamp, search_description, total_findings, findings}, written by
+//	    the first releases; findings list --format json and NDJSON files, which
+//	    hold only the findings, are read as version 0 too
+//	1 - adds fetch_report, project_errors, warnings and correlations
+//	2 - adds schema_version; findings carry the context they were found in
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/endor-labs/findings-api/internal/output"
+)
+
+// mainContext is the context every finding was fetched from before contexts
+// could be selected
+const mainContext = "CONTEXT_TYPE_MAIN"
+
+// migrations upgrade a document from the version they are keyed by to the next
+var migrations = map[int]func(doc map[string]json.RawMessage) error{
+	0: migrateV0,
+	1: migrateV1,
+}
+
+// Load reads the findings file at path and migrates it to the current schema.
+// version is the schema version the file was written with.
+func Load(path string) (doc *output.Document, version int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	doc, version, err = Parse(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return doc, version, nil
+}
+
+// Parse decodes a findings document, a JSON array of findings or NDJSON, and
+// migrates it to the current schema
+func Parse(data []byte) (doc *output.Document, version int, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, 0, errors.New("empty snapshot")
+	}
+
+	raw, err := decode(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	version = 0
+	if v, ok := raw["schema_version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, 0, fmt.Errorf("invalid schema_version: %w", err)
+		}
+	} else if _, ok := raw["fetch_report"]; ok {
+		version = 1
+	}
+	if version > output.SchemaVersion {
+		return nil, 0, fmt.Errorf("schema version %d is newer than this build supports (%d); upgrade findings-api", version, output.SchemaVersion)
+	}
+
+	for v := version; v < output.SchemaVersion; v++ {
+		if err := migrations[v](raw); err != nil {
+			return nil, 0, fmt.Errorf("failed to migrate from schema version %d: %w", v, err)
+		}
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc = &output.Document{}
+	if err := json.Unmarshal(migrated, doc); err != nil {
+		return nil, 0, err
+	}
+	return doc, version, nil
+}
+
+// decode returns the top-level fields of a document. Files holding only
+// findings are wrapped in a version 0 document.
+func decode(data []byte) (map[string]json.RawMessage, error) {
+	if data[0] == '[' {
+		var findings []json.RawMessage
+		if err := json.Unmarshal(data, &findings); err != nil {
+			return nil, err
+		}
+		return wrap(findings)
+	}
+
+	// A document holds the findings under "findings"
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err == nil {
+		if _, ok := raw["findings"]; ok {
+			return raw, nil
+		}
+	}
+
+	// Otherwise one finding per line
+	var findings []json.RawMessage
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var f struct {
+			UUID string `json:"uuid"`
+		}
+		var line json.RawMessage
+		err := dec.Decode(&line)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err == nil {
+			err = json.Unmarshal(line, &f)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("finding %d: %w", len(findings)+1, err)
+		}
+		if f.UUID == "" {
+			return nil, fmt.Errorf("finding %d has no uuid (expected a findings export, a JSON array or NDJSON)", len(findings)+1)
+		}
+		findings = append(findings, line)
+	}
+	return wrap(findings)
+}
+
+// wrap builds a version 0 document around findings
+func wrap(findings []json.RawMessage) (map[string]json.RawMessage, error) {
+	list, err := json.Marshal(findings)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]json.RawMessage{
+		"findings":       list,
+		"total_findings": json.RawMessage(fmt.Sprint(len(findings))),
+	}, nil
+}
+
+// migrateV0 adds the fetch report of version 1, left empty
+func migrateV0(doc map[string]json.RawMessage) error {
+	if _, ok := doc["fetch_report"]; !ok {
+		doc["fetch_report"] = json.RawMessage(`{}`)
+	}
+	return nil
+}
+
+// migrateV1 sets the schema version and records the main context on findings
+// without one: earlier versions only fetched the main context, unless told
+// otherwise with a raw filter
+func migrateV1(doc map[string]json.RawMessage) error {
+	var findings []map[string]json.RawMessage
+	if err := json.Unmarshal(doc["findings"], &findings); err != nil {
+		return fmt.Errorf("invalid findings: %w", err)
+	}
+	for _, f := range findings {
+		if _, ok := f["context"]; !ok {
+			f["context"] = json.RawMessage(`{"type":"` + mainContext + `"}`)
+		}
+	}
+	list, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+	doc["findings"] = list
+	doc["schema_version"] = json.RawMessage("2")
+	return nil
+}
@@ -0,0 +1,368 @@
+// Package store keeps the history of fetched findings in a local SQLite
+// database: each run is recorded with its time and selection, and its findings
+// with the fields history queries need plus the full finding as JSON.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+
+	// Registers the pure Go "sqlite" driver
+	_ "modernc.org/sqlite"
+)
+
+// schemaVersion is the database layout version, kept in PRAGMA user_version
+const schemaVersion = 1
+
+// schema creates the version 1 tables
+const schema = `
+CREATE TABLE runs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at TEXT    NOT NULL,
+	namespace  TEXT    NOT NULL DEFAULT '',
+	selection  TEXT    NOT NULL DEFAULT '',
+	filter     TEXT    NOT NULL DEFAULT '',
+	total      INTEGER NOT NULL
+);
+CREATE TABLE findings (
+	run_id       INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	uuid         TEXT    NOT NULL,
+	project_uuid TEXT    NOT NULL,
+	level        TEXT    NOT NULL,
+	package      TEXT    NOT NULL,
+	version      TEXT    NOT NULL,
+	cve          TEXT    NOT NULL,
+	name         TEXT    NOT NULL,
+	data         TEXT    NOT NULL,
+	PRIMARY KEY (run_id, uuid)
+);
+CREATE INDEX findings_uuid ON findings(uuid);
+CREATE INDEX findings_project ON findings(project_uuid);
+`
+
+// ErrNoRun is returned when a run does not exist
+var ErrNoRun = errors.New("no such run")
+
+// Run is a recorded fetch
+type Run struct {
+	ID        int64     `json:"id"`
+	StartedAt time.Time `json:"started_at"`
+	Namespace string    `json:"namespace,omitempty"`
+	Selection string    `json:"selection,omitempty"`
+	Filter    string    `json:"filter,omitempty"`
+	Total     int       `json:"total"`
+	// ByLevel counts the run's findings by short level, e.g. critical
+	ByLevel map[string]int `json:"by_level"`
+}
+
+// Sighting is a run a finding was recorded in
+type Sighting struct {
+	RunID     int64            `json:"run_id"`
+	StartedAt time.Time        `json:"started_at"`
+	Level     api.FindingLevel `json:"level"`
+	Version   string           `json:"version"`
+}
+
+// Entry is the identity and level of a finding recorded in a run
+type Entry struct {
+	UUID        string           `json:"uuid"`
+	ProjectUUID string           `json:"project_uuid"`
+	Level       api.FindingLevel `json:"level"`
+}
+
+// DefaultPath returns ~/.endor/history.db
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".endor", "history.db"), nil
+}
+
+// Store is an open history database
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it and its tables if needed
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	// SQLite allows one writer; a single connection avoids lock errors
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// migrate creates the tables of a new database and refuses newer layouts
+func (s *Store) migrate() error {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read history version: %w", err)
+	}
+	switch {
+	case version == schemaVersion:
+		return nil
+	case version > schemaVersion:
+		return fmt.Errorf("history database version %d is newer than this build supports (%d); upgrade findings-api", version, schemaVersion)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create history tables: %w", err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveRun records run and its findings, returning the run's ID
+func (s *Store) SaveRun(ctx context.Context, run Run, findings []api.Finding) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO runs (started_at, namespace, selection, filter, total) VALUES (?, ?, ?, ?, ?)",
+		run.StartedAt.UTC().Format(time.RFC3339Nano), run.Namespace, run.Selection, run.Filter, len(findings))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO findings
+		(run_id, uuid, project_uuid, level, package, version, cve, name, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, f := range findings {
+		data, err := json.Marshal(f)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal finding %s: %w", f.UUID, err)
+		}
+		if _, err := stmt.ExecContext(ctx, id, f.UUID, f.Spec.ProjectUUID, string(f.Spec.Level),
+			f.Spec.TargetDependencyPackageName, f.Spec.TargetDependencyVersion, f.CVE(), f.Meta.Name, string(data)); err != nil {
+			return 0, fmt.Errorf("failed to record finding %s: %w", f.UUID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to record run: %w", err)
+	}
+	return id, nil
+}
+
+// Runs returns the most recent runs, newest first; limit 0 returns them all
+func (s *Store) Runs(ctx context.Context, limit int) ([]Run, error) {
+	query := "SELECT id, started_at, namespace, selection, filter, total FROM runs ORDER BY id DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []Run
+	for rows.Next() {
+		r, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].ByLevel, err = s.countByLevel(ctx, runs[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
+
+// Run returns the run id
+func (s *Store) Run(ctx context.Context, id int64) (Run, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT id, started_at, namespace, selection, filter, total FROM runs WHERE id = ?", id)
+	r, err := scanRun(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Run{}, fmt.Errorf("run %d: %w", id, ErrNoRun)
+	}
+	if err != nil {
+		return Run{}, err
+	}
+	if r.ByLevel, err = s.countByLevel(ctx, id); err != nil {
+		return Run{}, err
+	}
+	return r, nil
+}
+
+// LatestRunID returns the ID of the run offset runs before the newest one:
+// 0 for the newest, 1 for the one before it
+func (s *Store) LatestRunID(ctx context.Context, offset int) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, "SELECT id FROM runs ORDER BY id DESC LIMIT 1 OFFSET ?", offset).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%d runs back: %w", offset, ErrNoRun)
+	}
+	return id, err
+}
+
+// Findings returns the findings recorded in run id
+func (s *Store) Findings(ctx context.Context, id int64) ([]api.Finding, error) {
+	if _, err := s.Run(ctx, id); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT data FROM findings WHERE run_id = ? ORDER BY rowid", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read findings: %w", err)
+	}
+	defer rows.Close()
+
+	findings := []api.Finding{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var f api.Finding
+		if err := json.Unmarshal([]byte(data), &f); err != nil {
+			return nil, fmt.Errorf("failed to decode recorded finding: %w", err)
+		}
+		findings = append(findings, f)
+	}
+	return findings, rows.Err()
+}
+
+// Entries returns the identity and level of the findings recorded in run id,
+// without decoding the findings themselves
+func (s *Store) Entries(ctx context.Context, id int64) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT uuid, project_uuid, level FROM findings WHERE run_id = ? ORDER BY rowid", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read findings: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var level string
+		if err := rows.Scan(&e.UUID, &e.ProjectUUID, &level); err != nil {
+			return nil, err
+		}
+		e.Level = api.FindingLevel(level)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Sightings returns the runs a finding was recorded in, oldest first
+func (s *Store) Sightings(ctx context.Context, findingUUID string) ([]Sighting, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT r.id, r.started_at, f.level, f.version
+		FROM findings f JOIN runs r ON r.id = f.run_id
+		WHERE f.uuid = ? ORDER BY r.id`, findingUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query finding history: %w", err)
+	}
+	defer rows.Close()
+
+	var sightings []Sighting
+	for rows.Next() {
+		var sg Sighting
+		var started, level string
+		if err := rows.Scan(&sg.RunID, &started, &level, &sg.Version); err != nil {
+			return nil, err
+		}
+		sg.StartedAt, _ = time.Parse(time.RFC3339Nano, started)
+		sg.Level = api.FindingLevel(level)
+		sightings = append(sightings, sg)
+	}
+	return sightings, rows.Err()
+}
+
+// Prune deletes all but the keep most recent runs, returning how many were deleted
+func (s *Store) Prune(ctx context.Context, keep int) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM runs WHERE id NOT IN (SELECT id FROM runs ORDER BY id DESC LIMIT ?)", keep)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune runs: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n > 0 {
+		if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+			return n, fmt.Errorf("failed to compact history: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// countByLevel counts the findings of run id by short level
+func (s *Store) countByLevel(ctx context.Context, id int64) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT level, COUNT(*) FROM findings WHERE run_id = ? GROUP BY level", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count findings: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var level string
+		var n int
+		if err := rows.Scan(&level, &n); err != nil {
+			return nil, err
+		}
+		counts[api.FindingLevel(level).Short()] = n
+	}
+	return counts, rows.Err()
+}
+
+// scanner is a *sql.Row or *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row scanner) (Run, error) {
+	var r Run
+	var started string
+	if err := row.Scan(&r.ID, &started, &r.Namespace, &r.Selection, &r.Filter, &r.Total); err != nil {
+		return Run{}, err
+	}
+	r.StartedAt, _ = time.Parse(time.RFC3339Nano, started)
+	return r, nil
+}
@@ -0,0 +1,175 @@
+// Package tickets turns findings into tracker tickets: it groups findings into
+// one ticket each or one per vulnerable package, and renders ticket summaries
+// and descriptions from templates. Tracker integrations such as Jira build on it.
+package tickets
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Grouping modes
+const (
+	// ByFinding opens a ticket per finding
+	ByFinding = "finding"
+	// ByPackage opens a ticket per vulnerable package version of a project
+	ByPackage = "package"
+)
+
+// Modes lists the valid grouping modes
+var Modes = []string{ByFinding, ByPackage}
+
+// Ticket is the set of findings one ticket tracks
+type Ticket struct {
+	// ID identifies the ticket across runs: the finding UUID, or the project
+	// and package version when grouping by package
+	ID       string
+	Findings []api.Finding
+}
+
+// UUIDs returns the UUIDs of the ticket's findings
+func (t Ticket) UUIDs() []string {
+	uuids := make([]string, len(t.Findings))
+	for i, f := range t.Findings {
+		uuids[i] = f.UUID
+	}
+	return uuids
+}
+
+// Group splits findings into tickets by mode, sorted by ID
+func Group(findings []api.Finding, mode string) ([]Ticket, error) {
+	byID := make(map[string][]api.Finding)
+	for _, f := range findings {
+		var id string
+		switch mode {
+		case ByFinding:
+			id = f.UUID
+		case ByPackage:
+			id = fmt.Sprintf("%s/%s@%s", f.Spec.ProjectUUID, f.Spec.TargetDependencyPackageName, f.Spec.TargetDependencyVersion)
+		default:
+			return nil, fmt.Errorf("invalid grouping %q (expected %s)", mode, strings.Join(Modes, " or "))
+		}
+		byID[id] = append(byID[id], f)
+	}
+
+	tickets := make([]Ticket, 0, len(byID))
+	for id, fs := range byID {
+		// Most severe first, so templates can use the first finding as the headline
+		sort.SliceStable(fs, func(i, j int) bool { return fs[i].Spec.Level.Rank() > fs[j].Spec.Level.Rank() })
+		tickets = append(tickets, Ticket{ID: id, Findings: fs})
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].ID < tickets[j].ID })
+	return tickets, nil
+}
+
+// Data is what summary and description templates are executed with
+type Data struct {
+	// Finding is the most severe finding of the ticket
+	Finding  api.Finding
+	Findings []api.Finding
+	Count    int
+	Level    string
+	Package  string
+	Version  string
+	Project  string
+	CVEs     []string
+}
+
+// newData builds the template data of t
+func newData(t Ticket) Data {
+	first := t.Findings[0]
+	d := Data{
+		Finding:  first,
+		Findings: t.Findings,
+		Count:    len(t.Findings),
+		Level:    first.Spec.Level.Short(),
+		Package:  first.Spec.TargetDependencyPackageName,
+		Version:  first.Spec.TargetDependencyVersion,
+		Project:  first.Spec.ProjectUUID,
+	}
+	if first.Project != nil && first.Project.Name != "" {
+		d.Project = first.Project.Name
+	}
+	seen := make(map[string]bool)
+	for _, f := range t.Findings {
+		if cve := f.CVE(); cve != "" && !seen[cve] {
+			seen[cve] = true
+			d.CVEs = append(d.CVEs, cve)
+		}
+	}
+	return d
+}
+
+// Default templates
+const (
+	DefaultSummaryTemplate = `[{{.Level}}] {{if eq .Count 1}}{{with .Finding.CVE}}{{.}}: {{end}}{{.Finding.Meta.Description}}{{else}}{{.Count}} vulnerabilities in {{.Package}}@{{.Version}}{{end}}`
+
+	DefaultDescriptionTemplate = `Endor Labs found {{.Count}} issue{{if ne .Count 1}}s{{end}} in {{.Package}}@{{.Version}} ({{.Project}}).
+{{range .Findings}}
+* [{{.Spec.Level.Short}}] {{with .CVE}}{{.}} {{end}}{{.Meta.Description}}
+{{- with .FixedVersions}}
+  Fixed in: {{join . ", "}}{{end}}
+  Finding: {{.UUID}}
+{{end}}`
+)
+
+// Templates render ticket summaries and descriptions
+type Templates struct {
+	summary     *template.Template
+	description *template.Template
+}
+
+var funcs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// ParseTemplates parses the summary and description templates; empty ones use the defaults
+func ParseTemplates(summary, description string) (*Templates, error) {
+	if summary == "" {
+		summary = DefaultSummaryTemplate
+	}
+	if description == "" {
+		description = DefaultDescriptionTemplate
+	}
+
+	s, err := template.New("summary").Funcs(funcs).Option("missingkey=error").Parse(summary)
+	if err != nil {
+		return nil, fmt.Errorf("invalid summary template: %w", err)
+	}
+	d, err := template.New("description").Funcs(funcs).Option("missingkey=error").Parse(description)
+	if err != nil {
+		return nil, fmt.Errorf("invalid description template: %w", err)
+	}
+	return &Templates{summary: s, description: d}, nil
+}
+
+// maxSummary is the longest summary most trackers accept
+const maxSummary = 250
+
+// Render returns the summary and description of t. Summaries are kept to one
+// line and cut to 250 characters.
+func (tm *Templates) Render(t Ticket) (summary, description string, err error) {
+	data := newData(t)
+
+	var buf bytes.Buffer
+	if err := tm.summary.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("failed to render summary: %w", err)
+	}
+	summary = strings.Join(strings.Fields(buf.String()), " ")
+	if r := []rune(summary); len(r) > maxSummary {
+		summary = string(r[:maxSummary-1]) + "…"
+	}
+
+	buf.Reset()
+	if err := tm.description.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("failed to render description: %w", err)
+	}
+	return summary, strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,140 @@
+// Package tokencache keeps auth tokens on disk between runs, so repeated
+// invocations reuse a valid token instead of calling the auth endpoint each time.
+package tokencache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// ExpiryMargin is how long before its expiry a cached token stops being used,
+// so it does not expire in the middle of a run
+const ExpiryMargin = 5 * time.Minute
+
+// DefaultPath returns ~/.endor/token.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".endor", "token.json"), nil
+}
+
+// Cache maps api.Client.CacheKey values to tokens, kept in a JSON file only
+// readable by the current user. The file is rewritten atomically on every change.
+type Cache struct {
+	path string
+
+	mu     sync.Mutex
+	tokens map[string]api.Token
+}
+
+// Open loads the cache at path; a missing file is an empty cache
+func Open(path string) (*Cache, error) {
+	c := &Cache{path: path, tokens: make(map[string]api.Token)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse token cache %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Get returns the cached token for key if it is still valid
+func (c *Cache) Get(key string) (api.Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.tokens[key]
+	if !ok || !t.Valid(ExpiryMargin) {
+		return api.Token{}, false
+	}
+	return t, true
+}
+
+// Put stores the token for key, dropping expired entries. Tokens without a
+// known expiry are not cached.
+func (c *Cache) Put(key string, t api.Token) error {
+	if !t.Valid(ExpiryMargin) {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, old := range c.tokens {
+		if !old.Valid(0) {
+			delete(c.tokens, k)
+		}
+	}
+	c.tokens[key] = t
+	return c.save()
+}
+
+// Delete removes the token for key; it reports whether there was one
+func (c *Cache) Delete(key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.tokens[key]; !ok {
+		return false, nil
+	}
+	delete(c.tokens, key)
+	return true, c.save()
+}
+
+// Clear removes the cache file
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens = make(map[string]api.Token)
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove token cache: %w", err)
+	}
+	return nil
+}
+
+// save writes the cache to a temporary file and renames it over the old one,
+// so concurrent runs never read a truncated file
+func (c *Cache) save() error {
+	data, err := json.MarshalIndent(c.tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create token cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// CreateTemp files are 0600, so the tokens are never readable by others
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write token cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+// Package trend turns the runs of the findings history into time series:
+// open findings by level, findings opened and resolved since the run before,
+// and the mean time to remediate, for the organization and per project, so
+// progress can be charted rather than read off one snapshot.
+package trend
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+	"github.com/endor-labs/findings-api/internal/store"
+)
+
+// Run is a recorded run with its findings
+type Run struct {
+	ID        int64
+	StartedAt time.Time
+	Entries   []store.Entry
+}
+
+// Counts counts findings by level
+type Counts struct {
+	Total    int `json:"total"`
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+func (c *Counts) add(level api.FindingLevel) {
+	c.Total++
+	switch level {
+	case api.LevelCritical:
+		c.Critical++
+	case api.LevelHigh:
+		c.High++
+	case api.LevelMedium:
+		c.Medium++
+	case api.LevelLow:
+		c.Low++
+	}
+}
+
+// Point is one run of a series
+type Point struct {
+	RunID     int64     `json:"run_id"`
+	StartedAt time.Time `json:"started_at"`
+	// Open counts the findings recorded in the run
+	Open Counts `json:"open"`
+	// Opened counts the findings not recorded in the run before, by their
+	// level in this run; it is zero for the first recorded run
+	Opened Counts `json:"opened"`
+	// Resolved counts the findings of the run before that this run no longer
+	// records, by their last recorded level
+	Resolved Counts `json:"resolved"`
+}
+
+// MTTR is the mean time to remediate resolved findings
+type MTTR struct {
+	Resolved int     `json:"resolved"`
+	MeanDays float64 `json:"mean_days"`
+}
+
+// Remediation is the mean time to remediate the findings resolved in the
+// window, overall and by their last recorded level
+type Remediation struct {
+	MTTR
+	ByLevel map[string]MTTR `json:"by_level"`
+}
+
+// Project is the series of one project
+type Project struct {
+	ProjectUUID string      `json:"project_uuid"`
+	Points      []Point     `json:"points"`
+	Remediation Remediation `json:"remediation"`
+}
+
+// Report is the trend of the runs in a window
+type Report struct {
+	// Since is the start of the window, nil when it includes every run
+	Since       *time.Time  `json:"since,omitempty"`
+	Points      []Point     `json:"points"`
+	Remediation Remediation `json:"remediation"`
+	// Projects are sorted by their open findings in the last run, most first
+	Projects []Project `json:"projects"`
+}
+
+// remediation accumulates the times findings took to resolve
+type remediation struct {
+	total   time.Duration
+	n       int
+	byLevel map[string]*remediation
+}
+
+func (r *remediation) add(level api.FindingLevel, d time.Duration) {
+	r.total += d
+	r.n++
+	if r.byLevel == nil {
+		r.byLevel = make(map[string]*remediation)
+	}
+	l := r.byLevel[level.Short()]
+	if l == nil {
+		l = &remediation{}
+		r.byLevel[level.Short()] = l
+	}
+	l.total += d
+	l.n++
+}
+
+func (r *remediation) mttr() MTTR {
+	if r == nil || r.n == 0 {
+		return MTTR{}
+	}
+	days := r.total.Hours() / 24 / float64(r.n)
+	return MTTR{Resolved: r.n, MeanDays: math.Round(days*100) / 100}
+}
+
+func (r *remediation) report() Remediation {
+	rep := Remediation{ByLevel: make(map[string]MTTR)}
+	if r == nil {
+		return rep
+	}
+	rep.MTTR = r.mttr()
+	for level, l := range r.byLevel {
+		rep.ByLevel[level] = l.mttr()
+	}
+	return rep
+}
+
+// Compute returns the trend of the runs started at or after since. runs must
+// be every recorded run, oldest first: findings are matched by UUID across
+// runs, so the runs before the window give the first run's opened and
+// resolved counts and the time findings open at its start were first
+// recorded. The time to remediate a finding runs from the first run recording
+// it to the first run that does not; a finding that reappears opens again.
+func Compute(runs []Run, since time.Time) Report {
+	report := Report{Points: []Point{}, Projects: []Project{}}
+	if !since.IsZero() {
+		report.Since = &since
+	}
+	var org remediation
+	byProject := make(map[string]*remediation)
+	var projectPoints []map[string]*Point
+
+	open := make(map[string]store.Entry)
+	firstSeen := make(map[string]time.Time)
+	for i, run := range runs {
+		inWindow := !run.StartedAt.Before(since)
+		point := Point{RunID: run.ID, StartedAt: run.StartedAt}
+		projects := make(map[string]*Point)
+		pointOf := func(project string) *Point {
+			p := projects[project]
+			if p == nil {
+				p = &Point{RunID: run.ID, StartedAt: run.StartedAt}
+				projects[project] = p
+			}
+			return p
+		}
+
+		current := make(map[string]store.Entry, len(run.Entries))
+		for _, e := range run.Entries {
+			current[e.UUID] = e
+			point.Open.add(e.Level)
+			pointOf(e.ProjectUUID).Open.add(e.Level)
+			if _, ok := open[e.UUID]; !ok {
+				firstSeen[e.UUID] = run.StartedAt
+				if i > 0 {
+					point.Opened.add(e.Level)
+					pointOf(e.ProjectUUID).Opened.add(e.Level)
+				}
+			}
+		}
+		for uuid, e := range open {
+			if _, ok := current[uuid]; ok {
+				continue
+			}
+			point.Resolved.add(e.Level)
+			pointOf(e.ProjectUUID).Resolved.add(e.Level)
+			if inWindow {
+				d := run.StartedAt.Sub(firstSeen[uuid])
+				org.add(e.Level, d)
+				if byProject[e.ProjectUUID] == nil {
+					byProject[e.ProjectUUID] = &remediation{}
+				}
+				byProject[e.ProjectUUID].add(e.Level, d)
+			}
+			delete(firstSeen, uuid)
+		}
+		open = current
+
+		if inWindow {
+			report.Points = append(report.Points, point)
+			projectPoints = append(projectPoints, projects)
+		}
+	}
+	report.Remediation = org.report()
+
+	// Every project active in the window gets a point per run, zero when it
+	// had no findings then, so its series line up with the organization's
+	names := make(map[string]bool)
+	for _, projects := range projectPoints {
+		for name := range projects {
+			names[name] = true
+		}
+	}
+	for name := range names {
+		p := Project{ProjectUUID: name, Points: make([]Point, len(report.Points)), Remediation: byProject[name].report()}
+		for i, projects := range projectPoints {
+			if pt := projects[name]; pt != nil {
+				p.Points[i] = *pt
+			} else {
+				p.Points[i] = Point{RunID: report.Points[i].RunID, StartedAt: report.Points[i].StartedAt}
+			}
+		}
+		report.Projects = append(report.Projects, p)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		a, b := report.Projects[i], report.Projects[j]
+		if na, nb := a.Last().Open.Total, b.Last().Open.Total; na != nb {
+			return na > nb
+		}
+		return a.ProjectUUID < b.ProjectUUID
+	})
+	return report
+}
+
+// Last returns the project's point of the last run, or a zero point
+func (p Project) Last() Point {
+	if len(p.Points) == 0 {
+		return Point{}
+	}
+	return p.Points[len(p.Points)-1]
+}
+
+// Totals sums the opened and resolved counts of points
+func Totals(points []Point) (opened, resolved Counts) {
+	for _, p := range points {
+		opened = opened.plus(p.Opened)
+		resolved = resolved.plus(p.Resolved)
+	}
+	return opened, resolved
+}
+
+func (c Counts) plus(o Counts) Counts {
+	return Counts{
+		Total:    c.Total + o.Total,
+		Critical: c.Critical + o.Critical,
+		High:     c.High + o.High,
+		Medium:   c.Medium + o.Medium,
+		Low:      c.Low + o.Low,
+	}
+}
@@ -0,0 +1,536 @@
+// Package tui implements the interactive findings browser: a list of findings
+// with a detail pane, filtered as the user types, drawn with plain ANSI escape
+// sequences on a terminal in raw mode.
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// ANSI styles
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+)
+
+// levelStyles colors findings by level
+var levelStyles = map[api.FindingLevel]string{
+	api.LevelCritical: "\x1b[1;31m",
+	api.LevelHigh:     "\x1b[31m",
+	api.LevelMedium:   "\x1b[33m",
+	api.LevelLow:      "\x1b[36m",
+}
+
+// splitWidth is the narrowest terminal the panes are shown side by side in;
+// narrower ones show the detail pane below the list
+const splitWidth = 100
+
+// Action is what the caller of HandleKey has to do next
+type Action int
+
+// Actions
+const (
+	ActionNone Action = iota
+	ActionQuit
+	// ActionOpen opens the selected finding's URL
+	ActionOpen
+)
+
+// Browser is the state of the findings browser: the findings that match the
+// filter, the selected one and the scroll positions of both panes
+type Browser struct {
+	title    string
+	findings []api.Finding
+	urls     []string
+	// search holds the lower-case text the filter matches, by finding
+	search []string
+
+	// visible indexes the findings that match the filter
+	visible []int
+	// selected indexes visible
+	selected  int
+	listTop   int
+	detailTop int
+	// listRows is the height of the list pane when last rendered
+	listRows  int
+	filter    string
+	filtering bool
+	status    string
+}
+
+// New returns a browser of findings, most severe first, titled with what
+// was fetched. url returns the link that opens a finding in the app.
+func New(title string, findings []api.Finding, url func(api.Finding) string) *Browser {
+	sorted := append([]api.Finding(nil), findings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if ri, rj := sorted[i].Spec.Level.Rank(), sorted[j].Spec.Level.Rank(); ri != rj {
+			return ri > rj
+		}
+		return sorted[i].Spec.TargetDependencyName < sorted[j].Spec.TargetDependencyName
+	})
+
+	b := &Browser{title: title, findings: sorted, listRows: 10}
+	for _, f := range sorted {
+		b.urls = append(b.urls, url(f))
+		b.search = append(b.search, searchText(f))
+	}
+	b.applyFilter()
+	return b
+}
+
+// searchText is what the filter matches a finding against
+func searchText(f api.Finding) string {
+	parts := []string{f.UUID, f.Meta.Description, f.Meta.Name, f.Spec.Level.Short(), f.Spec.TargetDependencyName,
+		f.Spec.Ecosystem, f.Spec.ProjectUUID, f.Namespace, f.Spec.Summary}
+	parts = append(parts, f.VulnerabilityIDs()...)
+	if f.Project != nil {
+		parts = append(parts, f.Project.Name)
+	}
+	for _, t := range f.Spec.FindingTags {
+		parts = append(parts, t.Short())
+	}
+	for _, c := range f.Spec.FindingCategories {
+		parts = append(parts, c.Short())
+	}
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
+
+// applyFilter selects the findings that contain every word of the filter,
+// keeping the selected finding when it still matches
+func (b *Browser) applyFilter() {
+	current := -1
+	if b.selected < len(b.visible) {
+		current = b.visible[b.selected]
+	}
+	terms := strings.Fields(strings.ToLower(b.filter))
+	b.visible = b.visible[:0]
+	b.selected = 0
+	for i, text := range b.search {
+		match := true
+		for _, t := range terms {
+			if !strings.Contains(text, t) {
+				match = false
+				break
+			}
+		}
+		if match {
+			if i == current {
+				b.selected = len(b.visible)
+			}
+			b.visible = append(b.visible, i)
+		}
+	}
+	b.detailTop = 0
+}
+
+// Selected returns the selected finding and its URL; ok is false when no
+// finding matches the filter
+func (b *Browser) Selected() (f api.Finding, url string, ok bool) {
+	if b.selected >= len(b.visible) {
+		return api.Finding{}, "", false
+	}
+	i := b.visible[b.selected]
+	return b.findings[i], b.urls[i], true
+}
+
+// SetStatus shows msg in the footer until the next key
+func (b *Browser) SetStatus(msg string) { b.status = msg }
+
+// HandleKey updates the browser for a key press
+func (b *Browser) HandleKey(k Key) Action {
+	b.status = ""
+	if k.Code == KeyCtrlC {
+		return ActionQuit
+	}
+	if b.filtering {
+		return b.handleFilterKey(k)
+	}
+
+	switch {
+	case k.Code == KeyRune && k.Rune == 'q':
+		return ActionQuit
+	case k.Code == KeyRune && (k.Rune == 'o' || k.Rune == 'O'), k.Code == KeyEnter:
+		return ActionOpen
+	case k.Code == KeyRune && k.Rune == '/':
+		b.filtering = true
+	case k.Code == KeyEscape:
+		if b.filter != "" {
+			b.filter = ""
+			b.applyFilter()
+		}
+	case k.Code == KeyRune && k.Rune == 'J':
+		b.detailTop++
+	case k.Code == KeyRune && k.Rune == 'K':
+		if b.detailTop > 0 {
+			b.detailTop--
+		}
+	default:
+		b.move(k)
+	}
+	return ActionNone
+}
+
+// handleFilterKey edits the filter as the user types
+func (b *Browser) handleFilterKey(k Key) Action {
+	switch k.Code {
+	case KeyRune:
+		b.filter += string(k.Rune)
+	case KeyBackspace:
+		if b.filter == "" {
+			b.filtering = false
+			return ActionNone
+		}
+		_, size := utf8.DecodeLastRuneInString(b.filter)
+		b.filter = b.filter[:len(b.filter)-size]
+	case KeyCtrlU:
+		b.filter = ""
+	case KeyEnter:
+		b.filtering = false
+		return ActionNone
+	case KeyEscape:
+		b.filtering = false
+		b.filter = ""
+	default:
+		b.move(k)
+		return ActionNone
+	}
+	b.applyFilter()
+	return ActionNone
+}
+
+// move changes the selection for a movement key
+func (b *Browser) move(k Key) {
+	page := b.listRows - 1
+	if page < 1 {
+		page = 1
+	}
+	selected := b.selected
+	switch {
+	case k.Code == KeyDown, k.Code == KeyRune && k.Rune == 'j':
+		selected++
+	case k.Code == KeyUp, k.Code == KeyRune && k.Rune == 'k':
+		selected--
+	case k.Code == KeyPageDown, k.Code == KeyCtrlD, k.Code == KeyRune && k.Rune == ' ':
+		selected += page
+	case k.Code == KeyPageUp, k.Code == KeyCtrlU:
+		selected -= page
+	case k.Code == KeyHome, k.Code == KeyRune && k.Rune == 'g':
+		selected = 0
+	case k.Code == KeyEnd, k.Code == KeyRune && k.Rune == 'G':
+		selected = len(b.visible) - 1
+	default:
+		return
+	}
+	if selected >= len(b.visible) {
+		selected = len(b.visible) - 1
+	}
+	if selected < 0 {
+		selected = 0
+	}
+	if selected != b.selected {
+		b.selected = selected
+		b.detailTop = 0
+	}
+}
+
+// Render draws the browser on a screen of width by height cells, one string
+// per row with its ANSI styles
+func (b *Browser) Render(width, height int) []string {
+	if width < 20 || height < 5 {
+		return []string{fit("Terminal too small", width)}
+	}
+	rows := []string{b.header(width)}
+	body := height - 2
+
+	if width >= splitWidth {
+		listWidth := width * 2 / 5
+		detailWidth := width - listWidth - 1
+		b.listRows = body
+		list := b.renderList(listWidth, body)
+		detail := b.renderDetail(detailWidth, body)
+		for i := 0; i < body; i++ {
+			rows = append(rows, list[i]+styleDim+"│"+styleReset+detail[i])
+		}
+	} else {
+		b.listRows = body / 2
+		detailRows := body - b.listRows - 1
+		rows = append(rows, b.renderList(width, b.listRows)...)
+		rows = append(rows, styleDim+strings.Repeat("─", width)+styleReset)
+		rows = append(rows, b.renderDetail(width, detailRows)...)
+	}
+	return append(rows, b.footer(width))
+}
+
+// header shows the title and how many findings match the filter
+func (b *Browser) header(width int) string {
+	count := fmt.Sprintf("%d findings", len(b.findings))
+	if b.filter != "" {
+		count = fmt.Sprintf("%d of %d findings match %q", len(b.visible), len(b.findings), b.filter)
+	}
+	text := " " + b.title + "  " + count
+	return styleReverse + styleBold + pad(fit(text, width), width) + styleReset
+}
+
+// footer shows the filter being typed, a status message or the keys
+func (b *Browser) footer(width int) string {
+	switch {
+	case b.filtering:
+		return fit("Filter: "+b.filter, width-1) + styleReverse + " " + styleReset
+	case b.status != "":
+		return styleBold + fit(b.status, width) + styleReset
+	}
+	return styleDim + fit("↑↓ move  PgUp/PgDn page  / filter  J/K scroll details  o open in browser  q quit", width) + styleReset
+}
+
+// renderList draws rows of the list pane, scrolled to show the selection
+func (b *Browser) renderList(width, rows int) []string {
+	if b.selected < b.listTop {
+		b.listTop = b.selected
+	}
+	if b.selected >= b.listTop+rows {
+		b.listTop = b.selected - rows + 1
+	}
+	if b.listTop > 0 && b.listTop > len(b.visible)-rows {
+		b.listTop = max(len(b.visible)-rows, 0)
+	}
+
+	lines := make([]string, rows)
+	for r := range lines {
+		n := b.listTop + r
+		switch {
+		case n < len(b.visible):
+			lines[r] = b.listRow(b.findings[b.visible[n]], n == b.selected, width)
+		case r == 0:
+			lines[r] = pad(fit(" No findings match the filter", width), width)
+		default:
+			lines[r] = strings.Repeat(" ", width)
+		}
+	}
+	return lines
+}
+
+// listRow draws one finding of the list: its level, advisory and package
+func (b *Browser) listRow(f api.Finding, selected bool, width int) string {
+	level := fmt.Sprintf(" %-8s ", f.Spec.Level.Short())
+	rest := title(f)
+	if pkg := packageName(f); pkg != "" && pkg != rest {
+		rest += "  " + pkg
+	}
+	levelWidth := min(utf8.RuneCountInString(level), width)
+	text := levelStyles[f.Spec.Level] + fit(level, levelWidth) + styleReset
+	if selected {
+		text = styleReverse + text + styleReverse
+	}
+	text += pad(fit(rest, width-levelWidth), width-levelWidth)
+	return text + styleReset
+}
+
+// title names a finding by its advisory, or its description without one
+func title(f api.Finding) string {
+	if ids := f.VulnerabilityIDs(); len(ids) > 0 {
+		return ids[0]
+	}
+	return firstNonEmpty(f.Meta.Description, f.Meta.Name, f.UUID)
+}
+
+// packageName is the dependency of a finding without its ecosystem
+func packageName(f api.Finding) string {
+	if _, name, ok := strings.Cut(f.Spec.TargetDependencyName, "://"); ok {
+		return name
+	}
+	return f.Spec.TargetDependencyName
+}
+
+// renderDetail draws rows of the detail pane for the selected finding
+func (b *Browser) renderDetail(width, rows int) []string {
+	var content []string
+	if f, url, ok := b.Selected(); ok {
+		content = detailLines(f, url, width-2)
+	}
+	if b.detailTop > len(content)-rows {
+		b.detailTop = max(len(content)-rows, 0)
+	}
+
+	lines := make([]string, rows)
+	for r := range lines {
+		line := ""
+		if n := b.detailTop + r; n < len(content) {
+			line = content[n]
+		}
+		// The detail lines are styled, so they are padded by their plain width
+		lines[r] = " " + line + strings.Repeat(" ", max(width-1-visibleWidth(line), 0))
+	}
+	return lines
+}
+
+// detailLines describes a finding in lines no wider than width
+func detailLines(f api.Finding, url string, width int) []string {
+	if width < 10 {
+		return nil
+	}
+	const labelWidth = 14
+	var lines []string
+	field := func(label, value string) {
+		if value == "" {
+			return
+		}
+		for i, l := range wrap(value, width-labelWidth) {
+			if i == 0 {
+				lines = append(lines, styleDim+pad(label, labelWidth)+styleReset+l)
+			} else {
+				lines = append(lines, strings.Repeat(" ", labelWidth)+l)
+			}
+		}
+	}
+	section := func(heading, text string) {
+		if text == "" {
+			return
+		}
+		lines = append(lines, "", styleBold+heading+styleReset)
+		lines = append(lines, wrap(text, width)...)
+	}
+
+	for _, l := range wrap(firstNonEmpty(f.Meta.Description, f.Meta.Name, f.UUID), width) {
+		lines = append(lines, styleBold+l+styleReset)
+	}
+	lines = append(lines, "")
+	if style, ok := levelStyles[f.Spec.Level]; ok {
+		lines = append(lines, styleDim+pad("Level", labelWidth)+styleReset+style+f.Spec.Level.Short()+styleReset)
+	} else {
+		field("Level", f.Spec.Level.Short())
+	}
+	field("Advisories", strings.Join(f.VulnerabilityIDs(), ", "))
+	if c := f.CVSS(); c != nil {
+		field("CVSS", strings.TrimSpace(fmt.Sprintf("%.1f %s", c.Score, c.Vector)))
+	}
+	if e := f.EPSS(); e != nil {
+		field("EPSS", fmt.Sprintf("%.4f (percentile %.2f)", e.ProbabilityScore, e.PercentileScore))
+	}
+	field("Package", f.Spec.TargetDependencyName)
+	field("Relationship", strings.ToLower(f.Spec.Relationship))
+	if to := f.UpgradeTarget(); to != "" {
+		field("Fix", "upgrade to "+to)
+	} else if f.HasTag(api.TagUnfixable) {
+		field("Fix", "none available")
+	}
+	if r := f.Reachability(); r != api.ReachabilityUnknown {
+		field("Reachability", strings.ReplaceAll(string(r), "_", " "))
+	}
+	project := f.Spec.ProjectUUID
+	if f.Project != nil && f.Project.Name != "" {
+		project = f.Project.Name + " (" + f.Spec.ProjectUUID + ")"
+	}
+	field("Project", project)
+	field("Namespace", f.Namespace)
+	field("Files", strings.Join(f.Spec.DependencyFilePath, ", "))
+	field("Categories", shortNames(f.Spec.FindingCategories))
+	field("Tags", shortNames(f.Spec.FindingTags))
+	field("Found", f.Meta.CreateTime)
+	field("UUID", f.UUID)
+	field("URL", url)
+	section("Summary", f.Spec.Summary)
+	section("Remediation", f.Spec.Remediation)
+	section("Explanation", f.Spec.Explanation)
+	return lines
+}
+
+// shortNames joins the short forms of enum values
+func shortNames[T interface{ Short() string }](values []T) string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = v.Short()
+	}
+	return strings.Join(names, ", ")
+}
+
+// wrap breaks text into lines of at most width characters at spaces, keeping
+// its line breaks and cutting words longer than a line
+func wrap(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				r := []rune(word)
+				lines = append(lines, string(r[:width]))
+				word = string(r[width:])
+			}
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit cuts s to width characters, marking the cut with an ellipsis
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 {
+			return ' '
+		}
+		return r
+	}, s)
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:width-1]) + "…"
+}
+
+// pad fills s with spaces to width characters
+func pad(s string, width int) string {
+	if n := width - utf8.RuneCountInString(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// visibleWidth counts the characters of s outside ANSI escape sequences
+func visibleWidth(s string) int {
+	n, escape := 0, false
+	for _, r := range s {
+		switch {
+		case escape:
+			escape = r != 'm'
+		case r == 0x1b:
+			escape = true
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
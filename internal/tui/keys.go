@@ -0,0 +1,102 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyCode identifies a key that is not a printable character
+type KeyCode int
+
+// Keys the browser responds to; KeyRune is a printable character
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyTab
+	KeyCtrlC
+	KeyCtrlD
+	KeyCtrlU
+	KeyUnknown
+)
+
+// Key is one key press
+type Key struct {
+	Code KeyCode
+	// Rune is the character typed, for KeyRune
+	Rune rune
+}
+
+// escapeSequences maps the escape sequences terminals send for special keys,
+// without the leading escape, to their keys
+var escapeSequences = map[string]KeyCode{
+	"[A": KeyUp, "OA": KeyUp,
+	"[B": KeyDown, "OB": KeyDown,
+	"[C": KeyRight, "OC": KeyRight,
+	"[D": KeyLeft, "OD": KeyLeft,
+	"[H": KeyHome, "OH": KeyHome, "[1~": KeyHome, "[7~": KeyHome,
+	"[F": KeyEnd, "OF": KeyEnd, "[4~": KeyEnd, "[8~": KeyEnd,
+	"[5~": KeyPageUp,
+	"[6~": KeyPageDown,
+}
+
+// parseKeys splits the bytes of one terminal read into key presses. An
+// escape alone is the escape key; one followed by a sequence is a special key.
+func parseKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			n, code := parseEscape(b[1:])
+			keys = append(keys, Key{Code: code})
+			b = b[1+n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case c == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case c == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case c == 0x04:
+			keys = append(keys, Key{Code: KeyCtrlD})
+		case c == 0x15:
+			keys = append(keys, Key{Code: KeyCtrlU})
+		case c < 0x20:
+			keys = append(keys, Key{Code: KeyUnknown})
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// parseEscape returns the length and key of the escape sequence b starts
+// with, following an escape byte
+func parseEscape(b []byte) (int, KeyCode) {
+	if len(b) == 0 || (b[0] != '[' && b[0] != 'O') {
+		return 0, KeyEscape
+	}
+	// A sequence ends with its first letter or tilde after the introducer
+	for i := 1; i < len(b); i++ {
+		c := b[i]
+		if c == '~' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+			if code, ok := escapeSequences[string(b[:i+1])]; ok {
+				return i + 1, code
+			}
+			return i + 1, KeyUnknown
+		}
+	}
+	return len(b), KeyUnknown
+}
@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// OpenURL opens url in the browser named by $BROWSER, or else the system's
+// default browser, without waiting for it to exit
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch browser := strings.Fields(os.Getenv("BROWSER")); {
+	case len(browser) > 0:
+		cmd = exec.Command(browser[0], append(browser[1:], url)...)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	go cmd.Wait()
+	return nil
+}
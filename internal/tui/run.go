@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// Terminal control sequences
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	cursorHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+)
+
+// Run shows the browser on the terminal in and out until the user quits or
+// ctx is done, restoring the terminal on return. open opens a finding's URL.
+// Keys are read by a goroutine that stays blocked on in after Run returns, so
+// in should not be read again by the caller.
+func Run(ctx context.Context, in, out *os.File, b *Browser, open func(url string) error) error {
+	restore, err := makeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer restore()
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+
+	keys := make(chan []Key)
+	readErr := make(chan error, 1)
+	go readKeys(in, keys, readErr)
+
+	for {
+		if err := draw(out, b); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case <-resized:
+		case pressed := <-keys:
+			for _, k := range pressed {
+				switch b.HandleKey(k) {
+				case ActionQuit:
+					return nil
+				case ActionOpen:
+					openSelected(b, open)
+				}
+			}
+		}
+	}
+}
+
+// openSelected opens the selected finding's URL, reporting the outcome in
+// the footer
+func openSelected(b *Browser, open func(url string) error) {
+	_, url, ok := b.Selected()
+	switch {
+	case !ok:
+		return
+	case url == "":
+		b.SetStatus("This finding has no app link: its namespace is unknown")
+	default:
+		if err := open(url); err != nil {
+			b.SetStatus(fmt.Sprintf("Error: %v", err))
+		} else {
+			b.SetStatus("Opened " + url)
+		}
+	}
+}
+
+// readKeys sends the keys read from in until reading fails
+func readKeys(in io.Reader, keys chan<- []Key, errs chan<- error) {
+	buf := make([]byte, 256)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			keys <- parseKeys(buf[:n])
+		}
+		if err != nil {
+			errs <- fmt.Errorf("failed to read keys: %w", err)
+			return
+		}
+	}
+}
+
+// draw renders the browser at the terminal's size in a single write
+func draw(out *os.File, b *Browser) error {
+	width, height, err := terminalSize(out)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString(cursorHome)
+	for i, row := range b.Render(width, height) {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(row)
+		sb.WriteString(clearLine)
+	}
+	sb.WriteString(clearBelow)
+	_, err = io.WriteString(out, sb.String())
+	return err
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package tui
+
+import (
+	"errors"
+	"os"
+)
+
+// errUnsupported is returned where terminal control is not implemented
+var errUnsupported = errors.New("the interactive browser is not supported on this platform")
+
+// IsTerminal reports whether f is a terminal; always false on this platform
+func IsTerminal(f *os.File) bool { return false }
+
+func makeRaw(f *os.File) (func(), error) { return nil, errUnsupported }
+
+func terminalSize(f *os.File) (width, height int, err error) { return 0, 0, errUnsupported }
+
+func notifyResize(ch chan<- os.Signal) {}
@@ -0,0 +1,184 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// gcsScope is the OAuth scope of uploads
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// gcsBackend uploads with the JSON API's simple media upload
+type gcsBackend struct {
+	bucket   string
+	endpoint string
+	// emulator is set for STORAGE_EMULATOR_HOST, which needs no credentials
+	emulator bool
+	opts     Options
+}
+
+func newGCS(bucket string, opts Options) (*gcsBackend, error) {
+	if opts.SSE != "" {
+		return nil, errors.New("server-side encryption modes apply to S3 only; GCS objects are encrypted with the bucket default or the given KMS key")
+	}
+	b := &gcsBackend{bucket: bucket, endpoint: "https://storage.googleapis.com", opts: opts}
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		b.endpoint, b.emulator = strings.TrimRight(host, "/"), true
+	}
+	return b, nil
+}
+
+func (g *gcsBackend) put(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	params := url.Values{}
+	params.Set("uploadType", "media")
+	params.Set("name", key)
+	if g.opts.KMSKey != "" {
+		params.Set("kmsKeyName", g.opts.KMSKey)
+	}
+	uploadURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", g.endpoint, url.PathEscape(g.bucket), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	token, err := googleToken(ctx)
+	switch {
+	case err == nil:
+		req.Header.Set("Authorization", "Bearer "+token)
+	case !g.emulator:
+		return "", fmt.Errorf("failed to find Google Cloud credentials: %w", err)
+	}
+
+	if _, err := send(req); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("gs://%s/%s", g.bucket, key), nil
+}
+
+// googleToken finds an OAuth access token: GOOGLE_OAUTH_ACCESS_TOKEN, a
+// service account key in GOOGLE_APPLICATION_CREDENTIALS, or the metadata
+// server of GCE, GKE and Cloud Run
+func googleToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		return serviceAccountToken(ctx, path)
+	}
+	return metadataToken(ctx)
+}
+
+// serviceAccountKey is the part of a service account key file used to sign in
+type serviceAccountKey struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// serviceAccountToken exchanges a JWT signed with the service account key for
+// an access token
+func serviceAccountToken(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read service account key: %w", err)
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", fmt.Errorf("failed to parse service account key %s: %w", path, err)
+	}
+	if key.Type != "service_account" {
+		return "", fmt.Errorf("%s is a %q credential; only service account keys are supported", path, key.Type)
+	}
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("invalid private key in %s", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key in %s: %w", path, err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("the private key in %s is not an RSA key", path)
+	}
+	tokenURI := firstNonEmpty(key.TokenURI, "https://oauth2.googleapis.com/token")
+
+	now := time.Now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   key.ClientEmail,
+		"scope": gcsScope,
+		"aud":   tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", unsigned+"."+base64.RawURLEncoding.EncodeToString(sig))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return accessToken(req)
+}
+
+// metadataToken asks the metadata server for the default service account's token
+func metadataToken(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	host := firstNonEmpty(os.Getenv("GCE_METADATA_HOST"), "metadata.google.internal")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	token, err := accessToken(req)
+	if err != nil {
+		return "", errNoCredentials
+	}
+	return token, nil
+}
+
+// accessToken sends an OAuth token request and returns the access token
+func accessToken(req *http.Request) (string, error) {
+	data, err := send(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get an access token: %w", err)
+	}
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil || out.AccessToken == "" {
+		return "", errors.New("invalid access token response")
+	}
+	return out.AccessToken, nil
+}
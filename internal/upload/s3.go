@@ -0,0 +1,247 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// imdsURL is the EC2 instance metadata service
+const imdsURL = "http://169.254.169.254"
+
+// ecsCredentialsHost serves AWS_CONTAINER_CREDENTIALS_RELATIVE_URI
+const ecsCredentialsHost = "http://169.254.170.2"
+
+// awsCredentials sign S3 requests
+type awsCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"Token"`
+}
+
+// s3Backend uploads with PutObject, signed with Signature Version 4
+type s3Backend struct {
+	bucket string
+	region string
+	// endpoint is set for S3-compatible services and addresses buckets by path
+	endpoint string
+	opts     Options
+}
+
+func newS3(bucket string, opts Options) (*s3Backend, error) {
+	if opts.SSE == "" && opts.KMSKey != "" {
+		opts.SSE = SSEKMS
+	}
+	if opts.SSE != "" && opts.SSE != SSES3 && opts.SSE != SSEKMS {
+		return nil, fmt.Errorf("invalid S3 server-side encryption %q (expected %s or %s)", opts.SSE, SSES3, SSEKMS)
+	}
+	if opts.KMSKey != "" && opts.SSE != SSEKMS {
+		return nil, fmt.Errorf("a KMS key requires %s server-side encryption", SSEKMS)
+	}
+	region := firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+	endpoint := strings.TrimRight(firstNonEmpty(os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL")), "/")
+	return &s3Backend{bucket: bucket, region: region, endpoint: endpoint, opts: opts}, nil
+}
+
+func (s *s3Backend) put(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	creds, err := awsCredentialsFromEnv(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to find AWS credentials: %w", err)
+	}
+
+	objectURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, escapePath(key))
+	if s.endpoint != "" {
+		objectURL = fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, escapePath(key))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.opts.SSE != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption", s.opts.SSE)
+	}
+	if s.opts.KMSKey != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", s.opts.KMSKey)
+	}
+	signV4(req, body, creds, s.region, "s3", time.Now().UTC())
+
+	if _, err := send(req); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("s3://%s/%s", s.bucket, key), nil
+}
+
+// signV4 adds a Signature Version 4 Authorization header to req
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsCredentialsFromEnv finds AWS credentials in the environment variables,
+// then the ECS container credentials endpoint, then the EC2 instance profile
+func awsCredentialsFromEnv(ctx context.Context) (awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); uri != "" {
+		return fetchAWSCredentials(ctx, uri, os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"))
+	}
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		return fetchAWSCredentials(ctx, ecsCredentialsHost+rel, "")
+	}
+	return instanceProfileCredentials(ctx)
+}
+
+func fetchAWSCredentials(ctx context.Context, uri, authorization string) (awsCredentials, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	data, err := send(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to fetch container credentials: %w", err)
+	}
+	var creds awsCredentials
+	if err := json.Unmarshal(data, &creds); err != nil || creds.AccessKeyID == "" {
+		return awsCredentials{}, fmt.Errorf("invalid container credentials response")
+	}
+	return creds, nil
+}
+
+// instanceProfileCredentials reads the EC2 instance profile through IMDSv2
+func instanceProfileCredentials(ctx context.Context) (awsCredentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, imdsURL+"/latest/api/token", nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "300")
+	token, err := send(req)
+	if err != nil {
+		return awsCredentials{}, errNoCredentials
+	}
+
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, imdsURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+		return send(req)
+	}
+	roles, err := get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return awsCredentials{}, errNoCredentials
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	data, err := get("/latest/meta-data/iam/security-credentials/" + role)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to fetch instance profile credentials: %w", err)
+	}
+	var creds awsCredentials
+	if err := json.Unmarshal(data, &creds); err != nil || creds.AccessKeyID == "" {
+		return awsCredentials{}, fmt.Errorf("invalid instance profile credentials response")
+	}
+	return creds, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
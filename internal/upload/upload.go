@@ -0,0 +1,190 @@
+// Package upload copies run artifacts to object storage: Amazon S3 (s3://),
+// Google Cloud Storage (gs://) and Azure Blob Storage (az://). It talks to each
+// service's REST API directly and finds credentials the way their SDKs do, from
+// the environment or the metadata service of the machine or container.
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Server-side encryption modes of S3 uploads
+const (
+	SSES3  = "AES256"
+	SSEKMS = "aws:kms"
+)
+
+// Options configure how objects are stored
+type Options struct {
+	// SSE is the S3 server-side encryption, SSES3 or SSEKMS; S3 buckets
+	// encrypt with their default encryption when it is empty
+	SSE string
+	// KMSKey is the KMS key objects are encrypted with: a KMS key ID or ARN on
+	// S3 (implying SSEKMS), a Cloud KMS key name on GCS, or an encryption
+	// scope on Azure
+	KMSKey string
+}
+
+// Uploader stores objects under the prefix of a destination URL
+type Uploader struct {
+	dest    string
+	prefix  string
+	backend backend
+}
+
+// backend stores one object in a bucket or container
+type backend interface {
+	// put stores body as the object key and returns its URL
+	put(ctx context.Context, key string, body []byte, contentType string) (string, error)
+}
+
+// httpClient sends every upload and credentials request
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// New returns an uploader for dest: s3://bucket/prefix, gs://bucket/prefix or
+// az://account/container/prefix
+func New(dest string, opts Options) (*Uploader, error) {
+	u, err := url.Parse(dest)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid upload destination %q (expected s3://bucket/prefix, gs://bucket/prefix or az://account/container/prefix)", dest)
+	}
+	prefix := strings.Trim(u.Path, "/")
+
+	var b backend
+	switch strings.ToLower(u.Scheme) {
+	case "s3":
+		b, err = newS3(u.Host, opts)
+	case "gs":
+		b, err = newGCS(u.Host, opts)
+	case "az":
+		container, rest, _ := strings.Cut(prefix, "/")
+		if container == "" {
+			return nil, fmt.Errorf("invalid upload destination %q: az:// URLs name an account and a container, e.g. az://account/container/prefix", dest)
+		}
+		prefix = rest
+		b, err = newAzure(u.Host, container, opts)
+	default:
+		return nil, fmt.Errorf("unsupported upload destination %q (expected an s3, gs or az URL)", dest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Uploader{dest: dest, prefix: prefix, backend: b}, nil
+}
+
+// String returns the destination URL
+func (u *Uploader) String() string { return u.dest }
+
+// Upload stores the file at localPath as name under the destination prefix
+// and returns the object's URL
+func (u *Uploader) Upload(ctx context.Context, localPath, name string) (string, error) {
+	body, err := os.ReadFile(localPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", localPath, err)
+	}
+	key := path.Join(u.prefix, name)
+	objectURL, err := u.backend.put(ctx, key, body, contentType(localPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", localPath, err)
+	}
+	return objectURL, nil
+}
+
+// ObjectName returns the name a local file is stored under: its path relative
+// to the current directory, or its base name when it lies outside of it
+func ObjectName(localPath string) string {
+	if !filepath.IsAbs(localPath) {
+		clean := filepath.Clean(localPath)
+		if clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(clean)
+		}
+	}
+	return filepath.Base(localPath)
+}
+
+// contentTypes covers the artifact formats mime does not know everywhere
+var contentTypes = map[string]string{
+	".json":   "application/json",
+	".sarif":  "application/sarif+json",
+	".ndjson": "application/x-ndjson",
+	".csv":    "text/csv; charset=utf-8",
+	".md":     "text/markdown; charset=utf-8",
+	".html":   "text/html; charset=utf-8",
+	".txt":    "text/plain; charset=utf-8",
+	".xml":    "application/xml",
+	".xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".zip":    "application/zip",
+	".gz":     "application/gzip",
+}
+
+func contentType(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// send performs req and returns the response body, or an error carrying the
+// service's message for unsuccessful statuses
+func send(req *http.Request) ([]byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if text := strings.TrimSpace(string(data)); text != "" {
+			if len(text) > 500 {
+				text = text[:500] + "…"
+			}
+			return nil, fmt.Errorf("status %d: %s", resp.StatusCode, text)
+		}
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return data, nil
+}
+
+// errNoCredentials is returned when no source of credentials is available
+var errNoCredentials = errors.New("no credentials found")
+
+// escapePath percent-encodes each segment of an object key, keeping slashes
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = uriEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode percent-encodes everything but the RFC 3986 unreserved characters
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
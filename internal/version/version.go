@@ -0,0 +1,121 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Compare compares two package versions segment by segment and returns
+// -1 if a < b, 0 if they are equal and +1 if a > b.
+//
+// Versions are split on dots, dashes, plus signs and letter/digit boundaries.
+// Numeric segments compare numerically, others lexically. A leading "v" is
+// ignored, and a pre-release suffix ("1.0.0-rc1") sorts before the release.
+func Compare(a, b string) int {
+	aMain, aPre := splitPrerelease(normalize(a))
+	bMain, bPre := splitPrerelease(normalize(b))
+
+	if c := compareSegments(segments(aMain), segments(bMain)); c != 0 {
+		return c
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareSegments(segments(aPre), segments(bPre))
+}
+
+// normalize trims whitespace, a leading "v" and any build metadata
+func normalize(v string) string {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	if i := strings.Index(v, "+"); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+// splitPrerelease splits "1.2.3-rc1" into "1.2.3" and "rc1"
+func splitPrerelease(v string) (string, string) {
+	if i := strings.Index(v, "-"); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+// segments splits a version into comparable parts
+func segments(v string) []string {
+	var parts []string
+	var cur strings.Builder
+	lastDigit := false
+
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range v {
+		switch {
+		case r == '.' || r == '-' || r == '_':
+			flush()
+			continue
+		case cur.Len() > 0 && unicode.IsDigit(r) != lastDigit:
+			flush()
+		}
+		cur.WriteRune(r)
+		lastDigit = unicode.IsDigit(r)
+	}
+	flush()
+
+	return parts
+}
+
+func compareSegments(a, b []string) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y string
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		// Missing segments count as zero, so "1.2" == "1.2.0"
+		if x == "" {
+			x = "0"
+		}
+		if y == "" {
+			y = "0"
+		}
+
+		xn, xErr := strconv.ParseUint(x, 10, 64)
+		yn, yErr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case xErr == nil:
+			// Numeric segments sort after textual ones
+			return 1
+		case yErr == nil:
+			return -1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+// Package webhook posts changes in findings, the findings that are new and the
+// ones that were resolved, to a webhook, signed with HMAC-SHA256 so receivers
+// can check where they came from.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/endor-labs/findings-api/internal/api"
+)
+
+// Headers of deliveries
+const (
+	// HeaderEvent is the event type, EventFindingsChanged
+	HeaderEvent = "X-Findings-Event"
+	// HeaderDelivery is a unique ID per delivery, the same across retries
+	HeaderDelivery = "X-Findings-Delivery"
+	// HeaderTimestamp is the Unix time the payload was signed at
+	HeaderTimestamp = "X-Findings-Timestamp"
+	// HeaderSignature is "sha256=" and the hex HMAC-SHA256 of the timestamp,
+	// a dot and the body, keyed with the secret
+	HeaderSignature = "X-Findings-Signature"
+)
+
+// EventFindingsChanged is the event of every delivery
+const EventFindingsChanged = "findings.changed"
+
+// maxAttempts is how many times a delivery is tried
+const maxAttempts = 3
+
+// retryDelay is the delay before the first retry; it doubles on every retry
+const retryDelay = 2 * time.Second
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	Event string `json:"event"`
+	// Source is the command that found the changes, "findings watch" or "findings diff"
+	Source   string        `json:"source"`
+	Time     time.Time     `json:"time"`
+	Summary  Summary       `json:"summary"`
+	New      []api.Finding `json:"new"`
+	Resolved []api.Finding `json:"resolved"`
+}
+
+// Summary counts the changes of a payload
+type Summary struct {
+	New      int `json:"new"`
+	Resolved int `json:"resolved"`
+}
+
+// NewPayload returns the payload of the new and resolved findings
+func NewPayload(source string, newFindings, resolved []api.Finding) Payload {
+	if newFindings == nil {
+		newFindings = []api.Finding{}
+	}
+	if resolved == nil {
+		resolved = []api.Finding{}
+	}
+	return Payload{
+		Event:    EventFindingsChanged,
+		Source:   source,
+		Time:     time.Now().UTC(),
+		Summary:  Summary{New: len(newFindings), Resolved: len(resolved)},
+		New:      newFindings,
+		Resolved: resolved,
+	}
+}
+
+// Config selects the webhook and the secret deliveries are signed with
+type Config struct {
+	URL string
+	// Secret signs deliveries; unsigned deliveries have no HeaderSignature
+	Secret string
+	// Headers are added to every delivery
+	Headers map[string]string
+}
+
+// Client delivers payloads to one webhook
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// NewClient validates cfg and returns a client for it
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("a webhook URL is required")
+	}
+	if !strings.HasPrefix(cfg.URL, "https://") && !strings.HasPrefix(cfg.URL, "http://") {
+		return nil, fmt.Errorf("invalid webhook URL %q (expected an http or https URL)", cfg.URL)
+	}
+	return &Client{cfg: cfg, http: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+// Send posts p, retrying network errors and 5xx and 429 responses
+func (c *Client) Send(ctx context.Context, p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	delivery := newDeliveryID()
+
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := c.post(ctx, body, delivery)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == maxAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// post makes one delivery attempt and reports whether a failure is worth retrying
+func (c *Client) post(ctx context.Context, body []byte, delivery string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "findings-api-webhook")
+	for k, v := range c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(HeaderEvent, EventFindingsChanged)
+	req.Header.Set(HeaderDelivery, delivery)
+	if c.cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, Sign(c.cfg.Secret, timestamp, body))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+}
+
+// Sign returns the HeaderSignature value of body signed at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,56 @@
+#!/bin/sh
+# Install a released findings-api binary for the current platform.
+#
+# Usage: VERSION=v1.2.0 ./install.sh [install-dir]
+#
+# Downloads the binary and checksums.txt from the GitHub release, verifies the
+# SHA-256 checksum and installs the binary as install-dir/findings-api
+# (default /usr/local/bin). Set GITHUB_URL to install from a GitHub Enterprise
+# Server instance, REPO to use a fork, or RELEASE_URL to install from a mirror.
+set -eu
+
+REPO="${REPO:-arsalan-learn/golang_endor_api_template}"
+GITHUB_URL="${GITHUB_URL:-https://github.com}"
+VERSION="${VERSION:?set VERSION, e.g. VERSION=v1.2.0}"
+INSTALL_DIR="${1:-/usr/local/bin}"
+RELEASE_URL="${RELEASE_URL:-${GITHUB_URL%/}/${REPO}/releases/download/${VERSION}}"
+
+os="$(uname -s | tr '[:upper:]' '[:lower:]')"
+case "$os" in
+  linux|darwin) ;;
+  mingw*|msys*|cygwin*) os="windows" ;;
+  *) echo "Unsupported OS: $os" >&2; exit 1 ;;
+esac
+
+arch="$(uname -m)"
+case "$arch" in
+  x86_64|amd64) arch="amd64" ;;
+  aarch64|arm64) arch="arm64" ;;
+  *) echo "Unsupported architecture: $arch" >&2; exit 1 ;;
+esac
+
+name="findings-api_${VERSION#v}_${os}_${arch}"
+[ "$os" = "windows" ] && name="${name}.exe"
+
+tmp="$(mktemp -d)"
+trap 'rm -rf "$tmp"' EXIT
+
+echo "Downloading ${name}..."
+curl -fsSL -o "${tmp}/${name}" "${RELEASE_URL}/${name}"
+curl -fsSL -o "${tmp}/checksums.txt" "${RELEASE_URL}/checksums.txt"
+
+echo "Verifying checksum..."
+expected="$(grep "  ${name}\$" "${tmp}/checksums.txt" | cut -d' ' -f1)"
+if command -v sha256sum >/dev/null 2>&1; then
+  actual="$(sha256sum "${tmp}/${name}" | cut -d' ' -f1)"
+else
+  actual="$(shasum -a 256 "${tmp}/${name}" | cut -d' ' -f1)"
+fi
+if [ -z "$expected" ] || [ "$expected" != "$actual" ]; then
+  echo "Checksum verification failed for ${name}" >&2
+  exit 1
+fi
+
+chmod +x "${tmp}/${name}"
+mv "${tmp}/${name}" "${INSTALL_DIR}/findings-api"
+echo "Installed findings-api ${VERSION} to ${INSTALL_DIR}/findings-api"